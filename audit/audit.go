@@ -0,0 +1,231 @@
+// audit records the write operations made through Kiali as structured events. Events are written to the
+// configured sinks and the latest ones are retained in memory to be queried through the API.
+package audit
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+)
+
+const (
+	VerbCreate   = "create"
+	VerbDelete   = "delete"
+	VerbRestore  = "restore"
+	VerbRevert   = "revert"
+	VerbRollback = "rollback"
+	VerbUpdate   = "update"
+
+	ResultFailure = "failure"
+	ResultSuccess = "success"
+)
+
+// Event is the record of a write operation made through Kiali
+//
+// swagger:model AuditEvent
+type Event struct {
+	// Unique identifier of the event
+	//
+	// required: true
+	ID string `json:"id"`
+	// Time of the operation
+	//
+	// required: true
+	Timestamp time.Time `json:"timestamp"`
+	// Subject of the user that made the operation, empty when Kiali doesn't authenticate users
+	User string `json:"user"`
+	// Address of the client that sent the request to Kiali
+	SourceIP string `json:"sourceIP"`
+	// Content of the X-Forwarded-For header of the request, set by proxies in front of Kiali
+	ForwardedFor string `json:"forwardedFor,omitempty"`
+	// Operation made
+	// example: update
+	Verb string `json:"verb"`
+	// Type of the resource changed
+	// example: virtualservices
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Object before the operation
+	Before json.RawMessage `json:"before,omitempty"`
+	// Object after the operation
+	After json.RawMessage `json:"after,omitempty"`
+	// Patch sent to update the object
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Details of operations that don't change an object, like the Envoy log levels
+	Message string `json:"message,omitempty"`
+	// success or failure
+	Result string `json:"result"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Query filters the events retained in memory. Empty fields don't filter.
+type Query struct {
+	Limit     int
+	Name      string
+	Namespace string
+	Resource  string
+	Result    string
+	Since     time.Time
+	Until     time.Time
+	User      string
+	Verb      string
+}
+
+var (
+	lock    sync.RWMutex
+	started bool
+	sinks   []Sink
+	// events is a ring buffer, first points to the oldest event once it's full
+	events []Event
+	first  int
+)
+
+// Enabled returns true when write operations have to be recorded
+func Enabled() bool {
+	return config.Get().Server.AuditLog
+}
+
+// Start creates the sinks configured. Sinks that can't be created are logged and skipped.
+func Start() {
+	lock.Lock()
+	defer lock.Unlock()
+	start()
+}
+
+func start() {
+	if started {
+		return
+	}
+	started = true
+	conf := config.Get().Server.Audit
+	events = make([]Event, 0, conf.MaxEvents)
+	first = 0
+	if conf.Stdout {
+		sinks = append(sinks, newStdoutSink())
+	}
+	if conf.File.Path != "" {
+		sink, err := newFileSink(conf.File.Path, int64(conf.File.MaxSize)*1024*1024, conf.File.MaxBackups)
+		if err != nil {
+			log.Errorf("Audit events won't be written to [%s]: %v", conf.File.Path, err)
+		} else {
+			sinks = append(sinks, sink)
+		}
+	}
+	if conf.Webhook.URL != "" {
+		sinks = append(sinks, newWebhookSink(conf.Webhook))
+	}
+}
+
+// Stop flushes and closes the sinks. Events recorded later start them again.
+func Stop() {
+	lock.Lock()
+	defer lock.Unlock()
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			log.Errorf("Error closing audit sink: %v", err)
+		}
+	}
+	sinks = nil
+	events = nil
+	started = false
+}
+
+// Record completes the event with its ID and timestamp, then writes it to the sinks and retains it in memory
+func Record(event Event) {
+	if !Enabled() {
+		return
+	}
+	if event.ID == "" {
+		event.ID = newID()
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Result == "" {
+		event.Result = ResultSuccess
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	start()
+	if cap(events) > 0 {
+		if len(events) < cap(events) {
+			events = append(events, event)
+		} else {
+			events[first] = event
+			first = (first + 1) % len(events)
+		}
+	}
+	for _, sink := range sinks {
+		if err := sink.Write(event); err != nil {
+			log.Errorf("Error writing audit event [%s]: %v", event.ID, err)
+		}
+	}
+}
+
+// Events returns the events retained in memory that match the query, newest first
+func Events(query Query) []Event {
+	lock.RLock()
+	defer lock.RUnlock()
+	matches := []Event{}
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[(first+i)%len(events)]
+		if query.matches(event) {
+			matches = append(matches, event)
+			if query.Limit > 0 && len(matches) == query.Limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+func (q Query) matches(event Event) bool {
+	return (q.User == "" || q.User == event.User) &&
+		(q.Namespace == "" || q.Namespace == event.Namespace) &&
+		(q.Resource == "" || q.Resource == event.Resource) &&
+		(q.Name == "" || q.Name == event.Name) &&
+		(q.Verb == "" || q.Verb == event.Verb) &&
+		(q.Result == "" || q.Result == event.Result) &&
+		(q.Since.IsZero() || !event.Timestamp.Before(q.Since)) &&
+		(q.Until.IsZero() || event.Timestamp.Before(q.Until))
+}
+
+// Object returns the JSON representation of an object to be recorded as the before or after state.
+// It returns nil when the object is nil or can't be marshalled.
+func Object(obj interface{}) json.RawMessage {
+	if obj == nil {
+		return nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		log.Errorf("Audit object can't be marshalled: %v", err)
+		return nil
+	}
+	return data
+}
+
+// Patch returns a patch to be recorded. Patches that are not valid JSON are recorded as a JSON string.
+func Patch(patch []byte) json.RawMessage {
+	if len(patch) == 0 {
+		return nil
+	}
+	if json.Valid(patch) {
+		return append(json.RawMessage{}, patch...)
+	}
+	return Object(string(patch))
+}
+
+func newID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		log.Errorf("Error generating audit event ID: %v", err)
+	}
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+)
+
+func setupAudit(t *testing.T, audit config.AuditConfig) {
+	conf := config.NewConfig()
+	conf.Server.Audit = audit
+	config.Set(conf)
+	Stop()
+	t.Cleanup(Stop)
+}
+
+func TestRecordRetainsLatestEvents(t *testing.T) {
+	assert := assert.New(t)
+	setupAudit(t, config.AuditConfig{MaxEvents: 3})
+
+	start := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, name := range []string{"a", "b", "c", "d"} {
+		Record(Event{Verb: VerbUpdate, Resource: "virtualservices", Namespace: "bookinfo", Name: name, Timestamp: start.Add(time.Duration(i) * time.Minute)})
+	}
+	Record(Event{Verb: VerbDelete, Resource: "gateways", Namespace: "istio-system", Name: "e", Result: ResultFailure, Timestamp: start.Add(10 * time.Minute)})
+
+	events := Events(Query{})
+	require.Len(t, events, 3)
+	assert.Equal("e", events[0].Name)
+	assert.Equal("d", events[1].Name)
+	assert.Equal("c", events[2].Name)
+	assert.NotEmpty(events[0].ID)
+	assert.NotEqual(events[0].ID, events[1].ID)
+	assert.Equal(ResultSuccess, events[1].Result)
+
+	assert.Len(Events(Query{Namespace: "bookinfo"}), 2)
+	assert.Len(Events(Query{Result: ResultFailure}), 1)
+	assert.Len(Events(Query{Verb: VerbUpdate, Limit: 1}), 1)
+	assert.Len(Events(Query{Since: start.Add(3 * time.Minute)}), 2)
+	assert.Len(Events(Query{Until: start.Add(3 * time.Minute)}), 1)
+	assert.Empty(Events(Query{User: "alice"}))
+}
+
+func TestRecordDisabled(t *testing.T) {
+	setupAudit(t, config.AuditConfig{MaxEvents: 3})
+	conf := config.Get()
+	conf.Server.AuditLog = false
+	config.Set(conf)
+
+	Record(Event{Verb: VerbUpdate, Resource: "namespaces", Name: "bookinfo"})
+	assert.Empty(t, Events(Query{}))
+}
+
+func TestPatch(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(`{"metadata":{"labels":{"a":"b"}}}`, string(Patch([]byte(`{"metadata":{"labels":{"a":"b"}}}`))))
+	assert.Equal(`"not json"`, string(Patch([]byte("not json"))))
+	assert.Nil(Patch(nil))
+}
+
+func TestFileSinkRotation(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+
+	line, _ := json.Marshal(Event{ID: "0123456789abcdef0123456789abcdef", Verb: VerbUpdate, Resource: "services", Result: ResultSuccess})
+	// Two events fit in every file
+	sink, err := newFileSink(path, int64(2*(len(line)+1)), 2)
+	require.NoError(t, err)
+	for i := 0; i < 7; i++ {
+		require.NoError(t, sink.Write(Event{ID: "0123456789abcdef0123456789abcdef", Verb: VerbUpdate, Resource: "services", Result: ResultSuccess}))
+	}
+	require.NoError(t, sink.Close())
+
+	for file, lines := range map[string]int{path: 1, path + ".1": 2, path + ".2": 2} {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(lines, strings.Count(string(content), "\n"), file)
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(os.IsNotExist(err))
+}
+
+func TestWebhookSink(t *testing.T) {
+	assert := assert.New(t)
+	received := make(chan Event, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("application/json", r.Header.Get("Content-Type"))
+		assert.Equal("Bearer secret", r.Header.Get("Authorization"))
+		event := Event{}
+		assert.NoError(json.NewDecoder(r.Body).Decode(&event))
+		received <- event
+	}))
+	defer server.Close()
+
+	setupAudit(t, config.AuditConfig{
+		MaxEvents: 10,
+		Webhook: config.AuditWebhookSink{
+			Auth:      config.Auth{Type: config.AuthTypeBearer, Token: "secret"},
+			QueueSize: 10,
+			Timeout:   5,
+			URL:       server.URL,
+		},
+	})
+	Record(Event{Verb: VerbCreate, Resource: "gateways", Namespace: "bookinfo", Name: "bookinfo-gateway", After: Object(map[string]string{"name": "bookinfo-gateway"})})
+	// Stop waits for the queued events to be posted
+	Stop()
+
+	require.Len(t, received, 1)
+	event := <-received
+	assert.Equal("bookinfo-gateway", event.Name)
+	assert.Equal(`{"name":"bookinfo-gateway"}`, string(event.After))
+}
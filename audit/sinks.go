@@ -0,0 +1,173 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+// Sink receives every audit event recorded. Writes are serialized by the caller.
+type Sink interface {
+	Write(event Event) error
+	Close() error
+}
+
+// jsonSink writes the events as JSON lines
+type jsonSink struct {
+	w io.Writer
+}
+
+func newStdoutSink() Sink {
+	return jsonSink{w: os.Stdout}
+}
+
+func (s jsonSink) Write(event Event) error {
+	return json.NewEncoder(s.w).Encode(event)
+}
+
+func (s jsonSink) Close() error {
+	return nil
+}
+
+// fileSink writes the events as JSON lines to a file. When the file reaches maxSize, it's renamed
+// with the .1 suffix, shifting the previous backups up to maxBackups.
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newFileSink(path string, maxSize int64, maxBackups int) (*fileSink, error) {
+	s := &fileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *fileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *fileSink) Write(event Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return err
+}
+
+func (s *fileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	if s.maxBackups > 0 {
+		os.Remove(s.backup(s.maxBackups))
+		for i := s.maxBackups - 1; i > 0; i-- {
+			if err := os.Rename(s.backup(i), s.backup(i+1)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		if err := os.Rename(s.path, s.backup(1)); err != nil {
+			return err
+		}
+	} else if err := os.Remove(s.path); err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *fileSink) backup(i int) string {
+	return fmt.Sprintf("%s.%d", s.path, i)
+}
+
+func (s *fileSink) Close() error {
+	return s.file.Close()
+}
+
+// webhookSink posts every event to an URL. Events are posted in the background so a slow or unavailable
+// webhook doesn't delay the operations, events that don't fit in the queue are dropped.
+type webhookSink struct {
+	url     string
+	auth    config.Auth
+	timeout time.Duration
+	queue   chan Event
+	done    sync.WaitGroup
+}
+
+func newWebhookSink(conf config.AuditWebhookSink) *webhookSink {
+	s := &webhookSink{
+		url:     conf.URL,
+		auth:    conf.Auth,
+		timeout: time.Duration(conf.Timeout) * time.Second,
+		queue:   make(chan Event, conf.QueueSize),
+	}
+	s.done.Add(1)
+	go s.post()
+	return s
+}
+
+func (s *webhookSink) Write(event Event) error {
+	select {
+	case s.queue <- event:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue is full, event dropped")
+	}
+}
+
+func (s *webhookSink) post() {
+	defer s.done.Done()
+	headers := map[string]string{"Content-Type": "application/json"}
+	for event := range s.queue {
+		body, err := json.Marshal(event)
+		if err != nil {
+			log.Errorf("Error marshalling audit event [%s]: %v", event.ID, err)
+			continue
+		}
+		_, code, err := httputil.HttpPost(s.url, &s.auth, bytes.NewReader(body), s.timeout, headers)
+		if err == nil && code >= http.StatusBadRequest {
+			err = fmt.Errorf("webhook returned status code %d", code)
+		}
+		if err != nil {
+			log.Errorf("Error posting audit event [%s]: %v", event.ID, err)
+		}
+	}
+}
+
+// Close waits for the queued events to be posted
+func (s *webhookSink) Close() error {
+	close(s.queue)
+	s.done.Wait()
+	return nil
+}
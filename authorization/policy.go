@@ -0,0 +1,288 @@
+// authorization restricts the features of Kiali available to users and groups with a Kiali policy. The policy
+// applies on top of the privileges of the tokens of the users: it can only take privileges away.
+package authorization
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/kiali/kiali/config"
+)
+
+// Features of Kiali that roles allow or deny
+const (
+	FeatureAll        = "*"
+	FeatureApiKeys    = "api_keys"
+	FeatureAudit      = "audit"
+	FeatureConfigDump = "config_dump"
+	FeatureGraph      = "graph"
+	FeatureIstioWrite = "istio_write"
+	FeaturePodLogs    = "pod_logs"
+	// FeatureRead are the read operations not covered by other features
+	FeatureRead = "read"
+	// FeatureWrite are the write operations not covered by other features
+	FeatureWrite = "write"
+)
+
+var features = []string{FeatureAll, FeatureApiKeys, FeatureAudit, FeatureConfigDump, FeatureGraph, FeatureIstioWrite, FeaturePodLogs, FeatureRead, FeatureWrite}
+
+// Policy binds users and groups to roles. A user gets the roles bound to its name and to any of its groups, or the
+// default role when none is bound. Users without roles can't use any feature.
+type Policy struct {
+	Bindings    []Binding `yaml:"bindings"`
+	DefaultRole string    `yaml:"default_role,omitempty"`
+	Roles       []Role    `yaml:"roles"`
+}
+
+// Binding grants a role to users and groups
+type Binding struct {
+	Groups []string `yaml:"groups,omitempty"`
+	Role   string   `yaml:"role"`
+	Users  []string `yaml:"users,omitempty"`
+}
+
+// Role lists the features allowed and denied. A feature is available when a rule of a role of the user allows it and
+// no rule of any role of the user denies it.
+type Role struct {
+	Allow []Rule `yaml:"allow,omitempty"`
+	Deny  []Rule `yaml:"deny,omitempty"`
+	Name  string `yaml:"name"`
+}
+
+// Rule matches features in namespaces
+type Rule struct {
+	// Features matched, * matches all of them
+	//
+	// required: true
+	Features []string `yaml:"features" json:"features"`
+	// Regular expressions of the namespaces matched, all namespaces when empty. Requests that are not for a
+	// namespace, like the list of namespaces, ignore the namespaces of the rules.
+	Namespaces []string `yaml:"namespaces,omitempty" json:"namespaces,omitempty"`
+
+	namespaceRegexps []*regexp.Regexp
+}
+
+// Permissions are the rules of the roles of a user
+//
+// swagger:model Permissions
+type Permissions struct {
+	// Roles of the user
+	//
+	// required: true
+	Roles []string `json:"roles"`
+	// Rules allowing features
+	//
+	// required: true
+	Allow []Rule `json:"allow"`
+	// Rules denying features, they win over the allow rules
+	//
+	// required: true
+	Deny []Rule `json:"deny"`
+}
+
+// Decision is the result of the authorization of a request
+type Decision struct {
+	Allowed bool
+	// Reason of the denial
+	Reason string
+}
+
+var (
+	lock          sync.Mutex
+	policy        *Policy
+	policyFile    string
+	policyModTime time.Time
+)
+
+// GetPolicy returns the policy of the configured file, or nil when no file is configured. The file is read again
+// when it changes.
+func GetPolicy() (*Policy, error) {
+	path := config.Get().Auth.PolicyFile
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Kiali policy file [%s]: %v", path, err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if policy != nil && policyFile == path && policyModTime.Equal(info.ModTime()) {
+		return policy, nil
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	policy, policyFile, policyModTime = loaded, path, info.ModTime()
+	return policy, nil
+}
+
+// Load reads and validates a policy file
+func Load(path string) (*Policy, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read Kiali policy file [%s]: %v", path, err)
+	}
+	policy := &Policy{}
+	if err := yaml.UnmarshalStrict(content, policy); err != nil {
+		return nil, fmt.Errorf("cannot parse Kiali policy file [%s]: %v", path, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid Kiali policy file [%s]: %v", path, err)
+	}
+	return policy, nil
+}
+
+func (p *Policy) validate() error {
+	roles := map[string]bool{}
+	for i := range p.Roles {
+		role := &p.Roles[i]
+		if role.Name == "" {
+			return fmt.Errorf("roles must have a name")
+		}
+		if roles[role.Name] {
+			return fmt.Errorf("role [%s] is defined more than once", role.Name)
+		}
+		roles[role.Name] = true
+		for _, rules := range [][]Rule{role.Allow, role.Deny} {
+			for j := range rules {
+				if err := rules[j].compile(); err != nil {
+					return fmt.Errorf("role [%s]: %v", role.Name, err)
+				}
+			}
+		}
+	}
+	for _, binding := range p.Bindings {
+		if !roles[binding.Role] {
+			return fmt.Errorf("binding refers to unknown role [%s]", binding.Role)
+		}
+	}
+	if p.DefaultRole != "" && !roles[p.DefaultRole] {
+		return fmt.Errorf("default role [%s] is not defined", p.DefaultRole)
+	}
+	return nil
+}
+
+func (r *Rule) compile() error {
+	if len(r.Features) == 0 {
+		return fmt.Errorf("rules must list their features")
+	}
+	for _, feature := range r.Features {
+		known := false
+		for _, f := range features {
+			known = known || f == feature
+		}
+		if !known {
+			return fmt.Errorf("unknown feature [%s], valid features are %v", feature, features)
+		}
+	}
+	r.namespaceRegexps = make([]*regexp.Regexp, 0, len(r.Namespaces))
+	for _, namespace := range r.Namespaces {
+		regex, err := regexp.Compile("^(?:" + namespace + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid namespace expression [%s]: %v", namespace, err)
+		}
+		r.namespaceRegexps = append(r.namespaceRegexps, regex)
+	}
+	return nil
+}
+
+// Permissions returns the rules of the roles bound to a user or to any of its groups
+func (p *Policy) Permissions(user string, groups []string) Permissions {
+	bound := map[string]bool{}
+	for _, binding := range p.Bindings {
+		if (user != "" && contains(binding.Users, user)) || containsAny(binding.Groups, groups) {
+			bound[binding.Role] = true
+		}
+	}
+	if len(bound) == 0 && p.DefaultRole != "" {
+		bound[p.DefaultRole] = true
+	}
+
+	permissions := Permissions{Roles: []string{}, Allow: []Rule{}, Deny: []Rule{}}
+	for _, role := range p.Roles {
+		if bound[role.Name] {
+			permissions.Roles = append(permissions.Roles, role.Name)
+			permissions.Allow = append(permissions.Allow, role.Allow...)
+			permissions.Deny = append(permissions.Deny, role.Deny...)
+		}
+	}
+	sort.Strings(permissions.Roles)
+	return permissions
+}
+
+// Authorize decides whether a feature is available in all the namespaces of a request
+func (p Permissions) Authorize(feature string, namespaces []string) Decision {
+	if len(p.Roles) == 0 {
+		return Decision{Reason: "no role of the Kiali policy is bound to the user"}
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{""}
+	}
+	for _, namespace := range namespaces {
+		location := ""
+		if namespace != "" {
+			location = fmt.Sprintf(" in namespace [%s]", namespace)
+		}
+		for _, rule := range p.Deny {
+			// Requests that are not for a namespace are only denied by rules for all namespaces
+			if rule.matches(feature, namespace) && (namespace != "" || len(rule.Namespaces) == 0) {
+				return Decision{Reason: fmt.Sprintf("feature [%s] is denied by the Kiali policy%s", feature, location)}
+			}
+		}
+		// Requests that are not for a namespace are only allowed by rules for all namespaces, since they may
+		// concern any namespace
+		allowed := false
+		for _, rule := range p.Allow {
+			allowed = allowed || (rule.matches(feature, namespace) && (namespace != "" || len(rule.Namespaces) == 0))
+		}
+		if !allowed {
+			return Decision{Reason: fmt.Sprintf("feature [%s] is not allowed by the Kiali policy to roles [%s]%s", feature, strings.Join(p.Roles, ", "), location)}
+		}
+	}
+	return Decision{Allowed: true}
+}
+
+func (r Rule) matches(feature, namespace string) bool {
+	if !contains(r.Features, feature) && !contains(r.Features, FeatureAll) {
+		return false
+	}
+	if namespace == "" || len(r.namespaceRegexps) == 0 {
+		return true
+	}
+	for _, regex := range r.namespaceRegexps {
+		if regex.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values []string, candidates []string) bool {
+	for _, candidate := range candidates {
+		if contains(values, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package authorization
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+)
+
+const testPolicy = `
+default_role: viewer
+roles:
+- name: viewer
+  allow:
+  - features: [graph]
+- name: developer
+  allow:
+  - features: ["*"]
+  deny:
+  - features: [pod_logs, config_dump]
+  - features: [istio_write]
+    namespaces: ["prod-.*"]
+- name: admin
+  allow:
+  - features: ["*"]
+bindings:
+- role: developer
+  groups: [developers]
+- role: admin
+  users: [alice]
+`
+
+func writePolicy(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "kiali-policy")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "policy.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	assert := assert.New(t)
+	policy, err := Load(writePolicy(t, testPolicy))
+	require.NoError(t, err)
+
+	viewer := policy.Permissions("bob", nil)
+	assert.Equal([]string{"viewer"}, viewer.Roles)
+	assert.True(viewer.Authorize(FeatureGraph, []string{"bookinfo"}).Allowed)
+	decision := viewer.Authorize(FeatureRead, nil)
+	assert.False(decision.Allowed)
+	assert.Equal("feature [read] is not allowed by the Kiali policy to roles [viewer]", decision.Reason)
+
+	developer := policy.Permissions("bob", []string{"developers"})
+	assert.Equal([]string{"developer"}, developer.Roles)
+	assert.True(developer.Authorize(FeatureIstioWrite, []string{"dev-bookinfo"}).Allowed)
+	assert.True(developer.Authorize(FeatureRead, nil).Allowed)
+	decision = developer.Authorize(FeatureIstioWrite, []string{"dev-bookinfo", "prod-bookinfo"})
+	assert.False(decision.Allowed)
+	assert.Equal("feature [istio_write] is denied by the Kiali policy in namespace [prod-bookinfo]", decision.Reason)
+	assert.False(developer.Authorize(FeaturePodLogs, []string{"dev-bookinfo"}).Allowed)
+
+	// Roles are merged and deny rules win
+	both := policy.Permissions("alice", []string{"developers"})
+	assert.Equal([]string{"admin", "developer"}, both.Roles)
+	assert.False(both.Authorize(FeatureConfigDump, []string{"dev-bookinfo"}).Allowed)
+	assert.True(policy.Permissions("alice", nil).Authorize(FeatureConfigDump, []string{"prod-bookinfo"}).Allowed)
+}
+
+func TestPolicyAuthorizeWithoutNamespace(t *testing.T) {
+	assert := assert.New(t)
+	policy, err := Load(writePolicy(t, "default_role: bookinfo\nroles:\n- name: bookinfo\n  allow:\n  - features: [write]\n    namespaces: [bookinfo]\n"))
+	require.NoError(t, err)
+
+	// Rules limited to namespaces don't allow the requests which are not for a namespace
+	permissions := policy.Permissions("bob", nil)
+	assert.True(permissions.Authorize(FeatureWrite, []string{"bookinfo"}).Allowed)
+	decision := permissions.Authorize(FeatureWrite, nil)
+	assert.False(decision.Allowed)
+	assert.Equal("feature [write] is not allowed by the Kiali policy to roles [bookinfo]", decision.Reason)
+}
+
+func TestPolicyWithoutDefaultRole(t *testing.T) {
+	policy, err := Load(writePolicy(t, "roles:\n- name: viewer\n  allow:\n  - features: [graph]\n"))
+	require.NoError(t, err)
+
+	decision := policy.Permissions("bob", nil).Authorize(FeatureGraph, nil)
+	assert.False(t, decision.Allowed)
+	assert.Equal(t, "no role of the Kiali policy is bound to the user", decision.Reason)
+}
+
+func TestInvalidPolicies(t *testing.T) {
+	policies := map[string]string{
+		"unknown feature":      "roles:\n- name: viewer\n  allow:\n  - features: [logs]\n",
+		"invalid namespace":    "roles:\n- name: viewer\n  allow:\n  - features: [graph]\n    namespaces: [\"(\"]\n",
+		"unknown binding role": "roles:\n- name: viewer\nbindings:\n- role: admin\n",
+		"unknown default role": "default_role: admin\nroles:\n- name: viewer\n",
+		"duplicated role":      "roles:\n- name: viewer\n- name: viewer\n",
+		"unknown field":        "roles:\n- name: viewer\n  allows: []\n",
+	}
+	for name, content := range policies {
+		_, err := Load(writePolicy(t, content))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestGetPolicy(t *testing.T) {
+	conf := config.NewConfig()
+	config.Set(conf)
+	policy, err := GetPolicy()
+	assert.NoError(t, err)
+	assert.Nil(t, policy)
+
+	conf.Auth.PolicyFile = writePolicy(t, testPolicy)
+	config.Set(conf)
+	policy, err = GetPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, "viewer", policy.DefaultRole)
+
+	conf.Auth.PolicyFile = filepath.Join(filepath.Dir(conf.Auth.PolicyFile), "missing.yaml")
+	config.Set(conf)
+	_, err = GetPolicy()
+	assert.Error(t, err)
+	config.Set(config.NewConfig())
+}
@@ -0,0 +1,405 @@
+package business
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util"
+)
+
+const (
+	apiKeyPrefix                 = "kiali_"
+	apiKeysConfigMapName         = "kiali-api-keys"
+	apiKeysLastUsedConfigMapName = "kiali-api-keys-last-used"
+	apiKeysConfigMapStore        = "configmap"
+	// Keys are read again from the store after this time, so the keys revoked through other Kiali replicas are rejected
+	apiKeysCacheExpiration = 30 * time.Second
+	// The last use of a key is saved at most once in this interval by each replica of Kiali
+	apiKeyLastUsedInterval = time.Minute
+)
+
+// ApiKeyService manages the keys issued by Kiali to automation clients. A user manages the keys of the
+// ServiceAccounts it can impersonate.
+type ApiKeyService struct {
+	k8s           kubernetes.ClientInterface
+	businessLayer *Layer
+}
+
+// storedApiKey is a key with the hash of its value, the value itself isn't kept
+type storedApiKey struct {
+	models.ApiKey
+	Hash string `json:"hash"`
+}
+
+// apiKeyStore keeps the keys and, apart from them so that authenticating doesn't write the keys, their last uses
+type apiKeyStore interface {
+	load() (map[string]storedApiKey, error)
+	// update reads the keys, applies the change and stores the result. The change is applied again to the latest
+	// keys when they were changed meanwhile.
+	update(change func(keys map[string]storedApiKey)) error
+	// loadLastUsed returns the last uses of the keys, by ID
+	loadLastUsed() (map[string]time.Time, error)
+	// recordLastUsed records a use of a key, unless a later one is already recorded
+	recordLastUsed(id string, used time.Time) error
+}
+
+// The cache avoids reading the store on every request, it's read and written under this lock, but the store isn't.
+// The uses of the keys recorded by this replica are kept to throttle their recording.
+var (
+	apiKeysLock         sync.Mutex
+	apiKeysCache        map[string]storedApiKey
+	apiKeysCacheTime    time.Time
+	apiKeysLastRecorded = map[string]time.Time{}
+)
+
+// IsApiKeysEnabled returns true when Kiali accepts API keys
+func IsApiKeysEnabled() bool {
+	return config.Get().Auth.ApiKeys.Enabled
+}
+
+// CreateApiKey issues a key. The user must be allowed to impersonate the ServiceAccount of the key and to access
+// the namespaces of the key.
+func (in *ApiKeyService) CreateApiKey(request models.ApiKeyRequest, user string) (*models.ApiKeyCreated, error) {
+	now := util.Clock.Now()
+	maxExpiration := now.Add(time.Duration(config.Get().Auth.ApiKeys.MaxExpirationSeconds) * time.Second)
+	expiresAt := maxExpiration
+	if request.ExpiresAt != nil {
+		expiresAt = *request.ExpiresAt
+	}
+	switch {
+	case request.Name == "":
+		return nil, errors.NewBadRequest("API keys must have a name")
+	case len(request.Namespaces) == 0:
+		return nil, errors.NewBadRequest("API keys must list the namespaces they can access")
+	case request.Scope != models.ApiKeyScopeRead && request.Scope != models.ApiKeyScopeWrite:
+		return nil, errors.NewBadRequest(fmt.Sprintf("API key scope must be %s or %s", models.ApiKeyScopeRead, models.ApiKeyScopeWrite))
+	case request.ServiceAccount.Namespace == "" || request.ServiceAccount.Name == "":
+		return nil, errors.NewBadRequest("API keys must set the namespace and the name of their ServiceAccount")
+	case !expiresAt.After(now) || expiresAt.After(maxExpiration):
+		return nil, errors.NewBadRequest(fmt.Sprintf("API key expiration must be in the future and before %s", maxExpiration.Format(time.RFC3339)))
+	}
+
+	if err := in.checkImpersonation(request.ServiceAccount.Namespace); err != nil {
+		return nil, err
+	}
+	for _, namespace := range request.Namespaces {
+		if _, err := in.businessLayer.Namespace.GetNamespace(namespace); err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := util.CryptoRandomBytes(8)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := util.CryptoRandomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	key := storedApiKey{
+		ApiKey: models.ApiKey{
+			ID:             hex.EncodeToString(id),
+			Name:           request.Name,
+			Namespaces:     request.Namespaces,
+			Scope:          request.Scope,
+			ServiceAccount: request.ServiceAccount,
+			CreatedAt:      now,
+			CreatedBy:      user,
+			ExpiresAt:      expiresAt,
+		},
+		Hash: hashApiKeySecret(hex.EncodeToString(secret)),
+	}
+
+	store, err := getApiKeyStore()
+	if err != nil {
+		return nil, err
+	}
+	nameTaken := false
+	err = store.update(func(keys map[string]storedApiKey) {
+		nameTaken = false
+		for _, k := range keys {
+			if k.Name == key.Name {
+				nameTaken = true
+				return
+			}
+		}
+		keys[key.ID] = key
+	})
+	resetApiKeysCache()
+	if err != nil {
+		return nil, err
+	}
+	if nameTaken {
+		return nil, errors.NewBadRequest(fmt.Sprintf("an API key named [%s] already exists", key.Name))
+	}
+	return &models.ApiKeyCreated{ApiKey: key.ApiKey, Key: apiKeyPrefix + key.ID + "_" + hex.EncodeToString(secret)}, nil
+}
+
+// GetApiKeys lists the keys of the ServiceAccounts the user can impersonate, the oldest first
+func (in *ApiKeyService) GetApiKeys() ([]models.ApiKey, error) {
+	store, err := getApiKeyStore()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadApiKeys(store, false)
+	if err != nil {
+		return nil, err
+	}
+	lastUsed, err := store.loadLastUsed()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := map[string]bool{}
+	result := []models.ApiKey{}
+	for _, key := range keys {
+		namespace := key.ServiceAccount.Namespace
+		if _, checked := allowed[namespace]; !checked {
+			allowed[namespace] = in.checkImpersonation(namespace) == nil
+		}
+		if allowed[namespace] {
+			if used, found := lastUsed[key.ID]; found {
+				key.LastUsed = &used
+			}
+			result = append(result, key.ApiKey)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// RevokeApiKey deletes a key, the requests made with it are rejected from then on
+func (in *ApiKeyService) RevokeApiKey(id string) (*models.ApiKey, error) {
+	store, err := getApiKeyStore()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadApiKeys(store, true)
+	if err != nil {
+		return nil, err
+	}
+	key, found := keys[id]
+	if !found {
+		return nil, kubernetes.NewNotFound(id, "kiali.io", "apikeys")
+	}
+	if err := in.checkImpersonation(key.ServiceAccount.Namespace); err != nil {
+		return nil, err
+	}
+	err = store.update(func(keys map[string]storedApiKey) {
+		delete(keys, id)
+	})
+	resetApiKeysCache()
+	if err != nil {
+		return nil, err
+	}
+	return &key.ApiKey, nil
+}
+
+// checkImpersonation returns a forbidden error when the user can't impersonate the ServiceAccounts of a namespace
+func (in *ApiKeyService) checkImpersonation(namespace string) error {
+	reviews, err := in.k8s.GetSelfSubjectAccessReview(namespace, "", "serviceaccounts", []string{"impersonate"})
+	if err != nil {
+		return err
+	}
+	if len(reviews) == 0 || !reviews[0].Status.Allowed {
+		return errors.NewForbidden(core_v1.Resource("serviceaccounts"), namespace, fmt.Errorf("user can't impersonate the ServiceAccounts of namespace [%s]", namespace))
+	}
+	return nil
+}
+
+// IsApiKey returns true when a bearer token has the format of the keys issued by Kiali
+func IsApiKey(token string) bool {
+	return strings.HasPrefix(token, apiKeyPrefix)
+}
+
+// AuthenticateApiKey returns the key matching the value of an API key, an error when it doesn't exist, it doesn't
+// match or it expired. The last use of the key is recorded, at most once in apiKeyLastUsedInterval.
+func AuthenticateApiKey(value string) (*models.ApiKey, error) {
+	parts := strings.Split(strings.TrimPrefix(value, apiKeyPrefix), "_")
+	if !IsApiKey(value) || len(parts) != 2 {
+		return nil, fmt.Errorf("malformed API key")
+	}
+
+	store, err := getApiKeyStore()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadApiKeys(store, false)
+	if err != nil {
+		return nil, err
+	}
+	key, found := keys[parts[0]]
+	if !found || subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashApiKeySecret(parts[1]))) != 1 {
+		return nil, fmt.Errorf("unknown API key")
+	}
+	now := util.Clock.Now()
+	if !now.Before(key.ExpiresAt) {
+		return nil, fmt.Errorf("API key [%s] expired on %s", key.Name, key.ExpiresAt.Format(time.RFC3339))
+	}
+
+	key.LastUsed = &now
+	apiKeysLock.Lock()
+	recorded, found := apiKeysLastRecorded[key.ID]
+	record := !found || now.Sub(recorded) >= apiKeyLastUsedInterval
+	if record {
+		apiKeysLastRecorded[key.ID] = now
+	}
+	apiKeysLock.Unlock()
+	if record {
+		if err := store.recordLastUsed(key.ID, now); err != nil {
+			log.Errorf("Cannot record the use of API key [%s]: %v", key.Name, err)
+		}
+	}
+	return &key.ApiKey, nil
+}
+
+func hashApiKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// loadApiKeys returns a copy of the keys, from the cache unless it expired or fresh keys are required
+func loadApiKeys(store apiKeyStore, fresh bool) (map[string]storedApiKey, error) {
+	apiKeysLock.Lock()
+	cached, cacheTime := apiKeysCache, apiKeysCacheTime
+	apiKeysLock.Unlock()
+
+	if fresh || cached == nil || util.Clock.Now().Sub(cacheTime) >= apiKeysCacheExpiration {
+		keys, err := store.load()
+		if err != nil {
+			return nil, err
+		}
+		apiKeysLock.Lock()
+		apiKeysCache, apiKeysCacheTime = keys, util.Clock.Now()
+		apiKeysLock.Unlock()
+		cached = keys
+	}
+	keys := make(map[string]storedApiKey, len(cached))
+	for id, key := range cached {
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+// resetApiKeysCache drops the cached keys after a change, so that it's seen by the following requests
+func resetApiKeysCache() {
+	apiKeysLock.Lock()
+	defer apiKeysLock.Unlock()
+	apiKeysCache = nil
+}
+
+func getApiKeyStore() (apiKeyStore, error) {
+	if config.Get().Auth.ApiKeys.Store != apiKeysConfigMapStore {
+		return memoryApiKeyStore{}, nil
+	}
+	// Users don't need privileges on the Kiali namespace to manage the keys
+	return kialiStateApiKeyStore{}, nil
+}
+
+// memoryApiKeyStore keeps the keys in memory, they are lost when Kiali restarts
+type memoryApiKeyStore struct{}
+
+var (
+	memoryApiKeysLock     sync.Mutex
+	memoryApiKeys         = map[string]storedApiKey{}
+	memoryApiKeysLastUsed = map[string]time.Time{}
+)
+
+func (memoryApiKeyStore) load() (map[string]storedApiKey, error) {
+	memoryApiKeysLock.Lock()
+	defer memoryApiKeysLock.Unlock()
+	keys := make(map[string]storedApiKey, len(memoryApiKeys))
+	for id, key := range memoryApiKeys {
+		keys[id] = key
+	}
+	return keys, nil
+}
+
+func (memoryApiKeyStore) update(change func(keys map[string]storedApiKey)) error {
+	memoryApiKeysLock.Lock()
+	defer memoryApiKeysLock.Unlock()
+	change(memoryApiKeys)
+	return nil
+}
+
+func (memoryApiKeyStore) loadLastUsed() (map[string]time.Time, error) {
+	memoryApiKeysLock.Lock()
+	defer memoryApiKeysLock.Unlock()
+	lastUsed := make(map[string]time.Time, len(memoryApiKeysLastUsed))
+	for id, used := range memoryApiKeysLastUsed {
+		lastUsed[id] = used
+	}
+	return lastUsed, nil
+}
+
+func (memoryApiKeyStore) recordLastUsed(id string, used time.Time) error {
+	memoryApiKeysLock.Lock()
+	defer memoryApiKeysLock.Unlock()
+	recordApiKeyLastUsed(memoryApiKeysLastUsed, id, used)
+	return nil
+}
+
+// kialiStateApiKeyStore keeps the keys in a Kiali state, a ConfigMap of the Kiali namespace shared by the replicas
+// of Kiali. The last uses are kept in another one, recording them doesn't conflict with the changes of the keys.
+type kialiStateApiKeyStore struct{}
+
+func (kialiStateApiKeyStore) load() (map[string]storedApiKey, error) {
+	keys := map[string]storedApiKey{}
+	if err := kialiState.load(apiKeysConfigMapName, &keys); err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = map[string]storedApiKey{}
+	}
+	return keys, nil
+}
+
+func (kialiStateApiKeyStore) update(change func(keys map[string]storedApiKey)) error {
+	keys := map[string]storedApiKey{}
+	return kialiState.update(apiKeysConfigMapName, &keys, func() {
+		if keys == nil {
+			keys = map[string]storedApiKey{}
+		}
+		change(keys)
+	})
+}
+
+func (kialiStateApiKeyStore) loadLastUsed() (map[string]time.Time, error) {
+	lastUsed := map[string]time.Time{}
+	if err := kialiState.load(apiKeysLastUsedConfigMapName, &lastUsed); err != nil {
+		return nil, err
+	}
+	return lastUsed, nil
+}
+
+func (kialiStateApiKeyStore) recordLastUsed(id string, used time.Time) error {
+	lastUsed := map[string]time.Time{}
+	return kialiState.update(apiKeysLastUsedConfigMapName, &lastUsed, func() {
+		if lastUsed == nil {
+			lastUsed = map[string]time.Time{}
+		}
+		recordApiKeyLastUsed(lastUsed, id, used)
+	})
+}
+
+// recordApiKeyLastUsed keeps the latest use of a key, the uses are recorded concurrently by the replicas of Kiali
+func recordApiKeyLastUsed(lastUsed map[string]time.Time, id string, used time.Time) {
+	if recorded, found := lastUsed[id]; !found || used.After(recorded) {
+		lastUsed[id] = used
+	}
+}
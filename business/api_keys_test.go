@@ -0,0 +1,167 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth_v1 "k8s.io/api/authorization/v1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util"
+)
+
+func setupApiKeys(t *testing.T, impersonate bool) *Layer {
+	conf := config.NewConfig()
+	conf.Auth.ApiKeys.Enabled = true
+	config.Set(conf)
+	memoryApiKeys = map[string]storedApiKey{}
+	memoryApiKeysLastUsed = map[string]time.Time{}
+	apiKeysCache = nil
+	apiKeysLastRecorded = map[string]time.Time{}
+
+	clock := util.Clock
+	util.Clock = util.ClockMock{Time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	t.Cleanup(func() { util.Clock = clock })
+
+	review := &auth_v1.SelfSubjectAccessReview{Status: auth_v1.SubjectAccessReviewStatus{Allowed: impersonate}}
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetNamespace", "bookinfo").Return(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}}, nil)
+	k8s.On("GetSelfSubjectAccessReview", "ci", "", "serviceaccounts", []string{"impersonate"}).Return([]*auth_v1.SelfSubjectAccessReview{review}, nil)
+	return NewWithBackends(k8s, nil, nil)
+}
+
+func TestApiKeyLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	layer := setupApiKeys(t, true)
+
+	request := models.ApiKeyRequest{
+		Name:           "pipeline",
+		Namespaces:     []string{"bookinfo"},
+		Scope:          models.ApiKeyScopeRead,
+		ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "pipeline"},
+	}
+	created, err := layer.ApiKeys.CreateApiKey(request, "alice")
+	require.NoError(err)
+	assert.True(IsApiKey(created.Key))
+	assert.Equal("alice", created.CreatedBy)
+	assert.Equal(util.Clock.Now().Add(90*24*time.Hour), created.ExpiresAt)
+
+	// The value of the key isn't stored
+	assert.NotContains(memoryApiKeys[created.ID].Hash, created.Key[len(apiKeyPrefix)+len(created.ID)+1:])
+
+	_, err = layer.ApiKeys.CreateApiKey(request, "alice")
+	assert.True(errors.IsBadRequest(err))
+
+	key, err := AuthenticateApiKey(created.Key)
+	require.NoError(err)
+	assert.Equal("apikey:pipeline", key.Username())
+	assert.Equal("system:serviceaccount:ci:pipeline", key.ServiceAccountUser())
+
+	keys, err := layer.ApiKeys.GetApiKeys()
+	require.NoError(err)
+	require.Len(keys, 1)
+	assert.Equal(util.Clock.Now(), *keys[0].LastUsed)
+
+	_, err = AuthenticateApiKey(created.Key[:len(created.Key)-1] + "x")
+	assert.Error(err)
+
+	util.Clock = util.ClockMock{Time: created.ExpiresAt}
+	_, err = AuthenticateApiKey(created.Key)
+	assert.Error(err)
+
+	revoked, err := layer.ApiKeys.RevokeApiKey(created.ID)
+	require.NoError(err)
+	assert.Equal("pipeline", revoked.Name)
+	_, err = AuthenticateApiKey(created.Key)
+	assert.Error(err)
+	_, err = layer.ApiKeys.RevokeApiKey(created.ID)
+	assert.True(errors.IsNotFound(err))
+}
+
+func TestApiKeyLastUsedInKialiState(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	layer := setupApiKeys(t, true)
+	conf := config.Get()
+	conf.Auth.ApiKeys.Store = apiKeysConfigMapStore
+	config.Set(conf)
+	state := useMemoryKialiState(t)
+
+	created, err := layer.ApiKeys.CreateApiKey(models.ApiKeyRequest{
+		Name:           "pipeline",
+		Namespaces:     []string{"bookinfo"},
+		Scope:          models.ApiKeyScopeRead,
+		ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "pipeline"},
+	}, "alice")
+	require.NoError(err)
+	keysState := string(state.states[apiKeysConfigMapName])
+
+	// Authenticating records the last use apart from the keys, at most once in an interval
+	_, err = AuthenticateApiKey(created.Key)
+	require.NoError(err)
+	firstUse := util.Clock.Now()
+	util.Clock = util.ClockMock{Time: firstUse.Add(apiKeyLastUsedInterval / 2)}
+	_, err = AuthenticateApiKey(created.Key)
+	require.NoError(err)
+	assert.Equal(keysState, string(state.states[apiKeysConfigMapName]))
+
+	keys, err := layer.ApiKeys.GetApiKeys()
+	require.NoError(err)
+	require.Len(keys, 1)
+	assert.Equal(firstUse, keys[0].LastUsed.UTC())
+
+	// An earlier use recorded by another replica doesn't replace a later one
+	assert.NoError(kialiStateApiKeyStore{}.recordLastUsed(created.ID, firstUse.Add(-time.Minute)))
+	lastUsed, err := kialiStateApiKeyStore{}.loadLastUsed()
+	require.NoError(err)
+	assert.Equal(firstUse, lastUsed[created.ID].UTC())
+}
+
+func TestApiKeyRequiresImpersonation(t *testing.T) {
+	layer := setupApiKeys(t, false)
+	memoryApiKeys["1234"] = storedApiKey{ApiKey: models.ApiKey{ID: "1234", ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "bot"}}}
+
+	_, err := layer.ApiKeys.CreateApiKey(models.ApiKeyRequest{
+		Name:           "pipeline",
+		Namespaces:     []string{"bookinfo"},
+		Scope:          models.ApiKeyScopeWrite,
+		ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "pipeline"},
+	}, "bob")
+	assert.True(t, errors.IsForbidden(err))
+
+	keys, err := layer.ApiKeys.GetApiKeys()
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = layer.ApiKeys.RevokeApiKey("1234")
+	assert.True(t, errors.IsForbidden(err))
+}
+
+func TestApiKeyRequestValidation(t *testing.T) {
+	layer := setupApiKeys(t, true)
+	tooLate := util.Clock.Now().Add(100 * 24 * time.Hour)
+	valid := models.ApiKeyRequest{Name: "pipeline", Namespaces: []string{"bookinfo"}, Scope: models.ApiKeyScopeRead, ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "pipeline"}}
+
+	invalid := []func(r *models.ApiKeyRequest){
+		func(r *models.ApiKeyRequest) { r.Name = "" },
+		func(r *models.ApiKeyRequest) { r.Namespaces = nil },
+		func(r *models.ApiKeyRequest) { r.Scope = "admin" },
+		func(r *models.ApiKeyRequest) { r.ServiceAccount.Name = "" },
+		func(r *models.ApiKeyRequest) { r.ExpiresAt = &tooLate },
+	}
+	for _, change := range invalid {
+		request := valid
+		change(&request)
+		_, err := layer.ApiKeys.CreateApiKey(request, "alice")
+		assert.True(t, errors.IsBadRequest(err))
+	}
+}
@@ -42,7 +42,7 @@ func (m PeerAuthenticationChecker) runChecks(peerAuthn security_v1beta.PeerAuthe
 		matchLabels = peerAuthn.Spec.Selector.MatchLabels
 	}
 	enabledCheckers = append(enabledCheckers, common.SelectorNoWorkloadFoundChecker(PeerAuthenticationCheckerType, matchLabels, m.WorkloadList))
-	if config.IsRootNamespace(peerAuthn.Namespace) {
+	if config.IsRootNamespaceOf(peerAuthn.Namespace, m.MTLSDetails.RootNamespaces) {
 		enabledCheckers = append(enabledCheckers, peerauthentications.DisabledMeshWideChecker{PeerAuthn: peerAuthn, DestinationRules: m.MTLSDetails.DestinationRules})
 	} else {
 		enabledCheckers = append(enabledCheckers, peerauthentications.DisabledNamespaceWideChecker{PeerAuthn: peerAuthn, DestinationRules: m.MTLSDetails.DestinationRules})
@@ -50,8 +50,8 @@ func (m PeerAuthenticationChecker) runChecks(peerAuthn security_v1beta.PeerAuthe
 
 	// MeshWide and NamespaceWide validations are only needed with autoMtls disabled
 	if !m.MTLSDetails.EnabledAutoMtls {
-		// PeerAuthentications into  the root namespace namespace are considered Mesh-wide objects
-		if config.IsRootNamespace(peerAuthn.Namespace) {
+		// PeerAuthentications into the root namespaces are considered Mesh-wide objects
+		if config.IsRootNamespaceOf(peerAuthn.Namespace, m.MTLSDetails.RootNamespaces) {
 			enabledCheckers = append(enabledCheckers,
 				peerauthentications.MeshMtlsChecker{MeshPolicy: peerAuthn, MTLSDetails: m.MTLSDetails, IsServiceMesh: false})
 		} else {
@@ -8,13 +8,14 @@ import (
 )
 
 type GlobalChecker struct {
-	Sidecar networking_v1alpha3.Sidecar
+	Sidecar        networking_v1alpha3.Sidecar
+	RootNamespaces []string
 }
 
 func (gc GlobalChecker) Check() ([]*models.IstioCheck, bool) {
 	checks, valid := make([]*models.IstioCheck, 0), true
 
-	if !config.IsRootNamespace(gc.Sidecar.Namespace) {
+	if !config.IsRootNamespaceOf(gc.Sidecar.Namespace, gc.RootNamespaces) {
 		return checks, valid
 	}
 
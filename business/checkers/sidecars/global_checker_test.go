@@ -68,3 +68,19 @@ func TestSidecarWithSelectorInControlPlane(t *testing.T) {
 	assert.Equal(models.WarningSeverity, vals[0].Severity)
 	assert.NoError(validations.ConfirmIstioCheckMessage("sidecar.global.selector", vals[0]))
 }
+
+func TestSidecarWithSelectorInOtherControlPlane(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	vals, valid := GlobalChecker{
+		Sidecar: *data.AddSelectorToSidecar(map[string]string{
+			"app": "reviews",
+		}, data.CreateSidecar("sidecar1", "istio-config")),
+		RootNamespaces: []string{"istio-system", "istio-config"},
+	}.Check()
+
+	assert.Len(vals, 1)
+	assert.True(valid)
+	assert.NoError(validations.ConfirmIstioCheckMessage("sidecar.global.selector", vals[0]))
+}
@@ -0,0 +1,317 @@
+package business
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+const (
+	defaultRevision = "default"
+	// Status of the control planes which Kiali can't reach, i.e. external ones
+	Unknown string = "Unknown"
+)
+
+// ControlPlane is an Istio control plane of the mesh, identified by its revision
+type ControlPlane struct {
+	// The revision of the control plane
+	//
+	// example: 1-10-0
+	// required: true
+	Revision string `json:"revision"`
+
+	// The namespace of the control plane
+	//
+	// example: istio-system
+	// required: true
+	Namespace string `json:"namespace"`
+
+	// The name of the istiod deployment, empty for external control planes
+	Istiod string `json:"istiod,omitempty"`
+
+	// The name of the config map holding the mesh config of the revision
+	ConfigMapName string `json:"configMapName"`
+
+	// When true, istiod runs outside the cluster and is reached at ExternalURL
+	External    bool   `json:"external"`
+	ExternalURL string `json:"externalUrl,omitempty"`
+
+	// The version of istiod, from its image tag
+	Version string `json:"version,omitempty"`
+
+	// The status of istiod
+	//
+	// example: Healthy
+	Status string `json:"status"`
+
+	// The istiod instances which are not healthy
+	Components IstioComponentStatus `json:"components"`
+
+	// The namespaces injected by the revision
+	Namespaces []string `json:"namespaces"`
+}
+
+// Discovering the control planes takes a few calls, they don't change often
+const controlPlanesRefreshDuration = time.Minute
+
+var (
+	controlPlanesLock    sync.Mutex
+	controlPlanesCreated time.Time
+	controlPlanes        []ControlPlane
+)
+
+// The discovered control planes are shared by all the users, they are discovered with the Kiali service account
+var controlPlanesDiscoveryClient = getKialiServiceAccountClient
+
+// GetControlPlanes returns the control planes of the mesh with their health, version and attached namespaces
+func (iss *IstioStatusService) GetControlPlanes() ([]ControlPlane, error) {
+	discovered, err := discoverControlPlanes()
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := iss.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	conf := config.Get()
+	for i := range discovered {
+		cp := &discovered[i]
+		cp.Namespaces = []string{}
+		cp.Components = IstioComponentStatus{}
+		for _, ns := range namespaces {
+			if models.NamespaceRevision(ns, conf.IstioLabels.InjectionLabelName, conf.IstioLabels.InjectionLabelRev) == cp.Revision {
+				cp.Namespaces = append(cp.Namespaces, ns.Name)
+			}
+		}
+		if cp.External {
+			cp.Status = Unknown
+			continue
+		}
+
+		deployment, err := iss.k8s.GetDeployment(cp.Namespace, cp.Istiod)
+		if err != nil {
+			cp.Status = NotFound
+			continue
+		}
+		cp.Version = imageTag(deployment)
+		cp.Status = deploymentStatus(deployment)
+
+		selector := labels.Set(deployment.Spec.Selector.MatchLabels).String()
+		istiods, err := iss.k8s.GetPods(cp.Namespace, selector)
+		if err != nil {
+			return nil, err
+		}
+		cp.Components = iss.getIstiodsReachingCheck(istiods)
+	}
+	return discovered, nil
+}
+
+// discoverControlPlanes finds the control planes from the sidecar injector webhooks, which also reference the
+// external control planes, and from the istiod deployments of the Istio namespace
+func discoverControlPlanes() ([]ControlPlane, error) {
+	controlPlanesLock.Lock()
+	defer controlPlanesLock.Unlock()
+	if controlPlanes != nil && time.Since(controlPlanesCreated) < controlPlanesRefreshDuration {
+		return copyControlPlanes(controlPlanes), nil
+	}
+
+	k8s, err := controlPlanesDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+
+	conf := config.Get()
+	revisionLabel := conf.IstioLabels.InjectionLabelRev
+	byRevision := map[string]*ControlPlane{}
+
+	webhooks, err := k8s.GetMutatingWebhookConfigurations(labels.Set{"app": "sidecar-injector"}.String())
+	if err != nil {
+		// Kiali may lack the cluster permissions, the deployments are enough for the usual setups
+		log.Debugf("Unable to list the sidecar injector webhooks: %v", err)
+	}
+	for _, webhook := range webhooks {
+		revision := revisionOf(webhook.Labels, revisionLabel)
+		if _, found := byRevision[revision]; found || len(webhook.Webhooks) == 0 {
+			continue
+		}
+		cp := &ControlPlane{Revision: revision, Namespace: conf.IstioNamespace, ConfigMapName: istioConfigMapName(revision)}
+		clientConfig := webhook.Webhooks[0].ClientConfig
+		switch {
+		case clientConfig.Service != nil:
+			cp.Namespace = clientConfig.Service.Namespace
+			cp.Istiod = clientConfig.Service.Name
+		case clientConfig.URL != nil:
+			cp.External = true
+			cp.ExternalURL = *clientConfig.URL
+		}
+		byRevision[revision] = cp
+	}
+
+	deployments, err := k8s.GetDeployments(conf.IstioNamespace)
+	if err != nil && len(byRevision) == 0 {
+		return nil, err
+	}
+	for _, deployment := range deployments {
+		if deployment.Labels["app"] != "istiod" {
+			continue
+		}
+		revision := revisionOf(deployment.Labels, revisionLabel)
+		cp, found := byRevision[revision]
+		if !found {
+			cp = &ControlPlane{Revision: revision, Namespace: conf.IstioNamespace, ConfigMapName: istioConfigMapName(revision)}
+			byRevision[revision] = cp
+		}
+		// The service of the webhook doesn't necessarily have the name of the deployment
+		if !cp.External && cp.Namespace == deployment.Namespace {
+			cp.Istiod = deployment.Name
+		}
+	}
+
+	if len(byRevision) == 0 {
+		// Nothing discovered, stick to the Kiali config
+		byRevision[defaultRevision] = &ControlPlane{
+			Revision:      defaultRevision,
+			Namespace:     conf.IstioNamespace,
+			Istiod:        conf.ExternalServices.Istio.IstiodDeploymentName,
+			ConfigMapName: conf.ExternalServices.Istio.ConfigMapName,
+		}
+	}
+
+	discovered := make([]ControlPlane, 0, len(byRevision))
+	for _, cp := range byRevision {
+		discovered = append(discovered, *cp)
+	}
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].Revision < discovered[j].Revision })
+	controlPlanes = discovered
+	controlPlanesCreated = time.Now()
+	return copyControlPlanes(discovered), nil
+}
+
+func copyControlPlanes(cps []ControlPlane) []ControlPlane {
+	return append([]ControlPlane{}, cps...)
+}
+
+func revisionOf(objectLabels map[string]string, revisionLabel string) string {
+	if revision, ok := objectLabels[revisionLabel]; ok && revision != "" {
+		return revision
+	}
+	return defaultRevision
+}
+
+// istioConfigMapName returns the name of the config map holding the mesh config of a revision: Istio suffixes
+// it with the revision, except for the default one
+func istioConfigMapName(revision string) string {
+	name := config.Get().ExternalServices.Istio.ConfigMapName
+	if revision == "" || revision == defaultRevision {
+		return name
+	}
+	return name + "-" + revision
+}
+
+// getIstioConfigMap returns the config map holding the mesh config of a revision. The default revision is looked
+// up in the Istio namespace, other revisions in the namespace of their control plane.
+func getIstioConfigMap(k8s kubernetes.ClientInterface, revision string) (*core_v1.ConfigMap, error) {
+	conf := config.Get()
+	namespace := conf.IstioNamespace
+	if revision != "" && revision != defaultRevision {
+		if cps, err := discoverControlPlanes(); err == nil {
+			for _, cp := range cps {
+				if cp.Revision == revision && !cp.External {
+					namespace = cp.Namespace
+				}
+			}
+		}
+	}
+
+	if IsNamespaceCached(namespace) {
+		return kialiCache.GetConfigMap(namespace, istioConfigMapName(revision))
+	}
+	return k8s.GetConfigMap(namespace, istioConfigMapName(revision))
+}
+
+// getMeshConfig returns the mesh config of a revision
+func getMeshConfig(k8s kubernetes.ClientInterface, revision string) (*kubernetes.IstioMeshConfig, error) {
+	istioConfig, err := getIstioConfigMap(k8s, revision)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.GetIstioConfigMap(istioConfig)
+}
+
+// rootNamespace returns the root namespace of a revision. The one of the default revision is set in the Kiali
+// config, the other revisions can set theirs in their mesh config.
+func rootNamespace(k8s kubernetes.ClientInterface, revision string) string {
+	if revision != defaultRevision {
+		if mc, err := getMeshConfig(k8s, revision); err == nil {
+			return mc.GetRootNamespace()
+		}
+	}
+	return config.Get().ExternalServices.Istio.RootNamespace
+}
+
+// rootNamespaces returns the root namespaces of all the control planes, the one of the Kiali config first
+func rootNamespaces(k8s kubernetes.ClientInterface) []string {
+	namespaces := []string{config.Get().ExternalServices.Istio.RootNamespace}
+	cps, err := discoverControlPlanes()
+	if err != nil {
+		log.Debugf("Unable to discover the control planes, only the configured root namespace is known: %v", err)
+		return namespaces
+	}
+	for _, cp := range cps {
+		if namespace := rootNamespace(k8s, cp.Revision); !config.IsRootNamespaceOf(namespace, namespaces) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// namespaceRevision returns the revision injecting a namespace, the default one when not injected or unknown
+func namespaceRevision(layer *Layer, namespace string) string {
+	ns, err := layer.Namespace.GetNamespace(namespace)
+	if err != nil {
+		return defaultRevision
+	}
+	conf := config.Get()
+	if revision := models.NamespaceRevision(*ns, conf.IstioLabels.InjectionLabelName, conf.IstioLabels.InjectionLabelRev); revision != "" {
+		return revision
+	}
+	return defaultRevision
+}
+
+func imageTag(deployment *apps_v1.Deployment) string {
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == "discovery" || len(deployment.Spec.Template.Spec.Containers) == 1 {
+			if i := strings.LastIndex(container.Image, ":"); i >= 0 {
+				return container.Image[i+1:]
+			}
+		}
+	}
+	return ""
+}
+
+func deploymentStatus(deployment *apps_v1.Deployment) string {
+	desired := int32(1)
+	if deployment.Spec.Replicas != nil {
+		desired = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	switch {
+	case desired == 0:
+		return NotReady
+	case status.AvailableReplicas == desired && status.Replicas == desired:
+		return Healthy
+	default:
+		return Unhealthy
+	}
+}
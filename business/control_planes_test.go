@@ -0,0 +1,85 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	admission_v1 "k8s.io/api/admissionregistration/v1"
+	apps_v1 "k8s.io/api/apps/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func fakeInjectorWebhook(revision string, clientConfig admission_v1.WebhookClientConfig) admission_v1.MutatingWebhookConfiguration {
+	webhook := admission_v1.MutatingWebhookConfiguration{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "istio-sidecar-injector-" + revision, Labels: map[string]string{"app": "sidecar-injector"}},
+		Webhooks:   []admission_v1.MutatingWebhook{{Name: "sidecar-injector.istio.io", ClientConfig: clientConfig}},
+	}
+	if revision != "" {
+		webhook.Labels["istio.io/rev"] = revision
+	}
+	return webhook
+}
+
+func TestDiscoverControlPlanes(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+	controlPlanes = nil
+
+	externalURL := "https://istiod.external.example.com:15017/inject"
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetMutatingWebhookConfigurations", "app=sidecar-injector").Return([]admission_v1.MutatingWebhookConfiguration{
+		fakeInjectorWebhook("1-10", admission_v1.WebhookClientConfig{Service: &admission_v1.ServiceReference{Namespace: "istio-canary", Name: "istiod-1-10"}}),
+		fakeInjectorWebhook("remote", admission_v1.WebhookClientConfig{URL: &externalURL}),
+	}, nil)
+	k8s.On("GetDeployments", "istio-system").Return([]apps_v1.Deployment{
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "istiod", Namespace: "istio-system", Labels: map[string]string{"app": "istiod"}}},
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "istio-ingressgateway", Namespace: "istio-system", Labels: map[string]string{"app": "istio-ingressgateway"}}},
+	}, nil)
+
+	discoveryClient := controlPlanesDiscoveryClient
+	controlPlanesDiscoveryClient = func() (kubernetes.ClientInterface, error) { return k8s, nil }
+	defer func() { controlPlanesDiscoveryClient = discoveryClient }()
+
+	cps, err := discoverControlPlanes()
+	assert.NoError(err)
+	assert.Len(cps, 3)
+
+	assert.Equal("1-10", cps[0].Revision)
+	assert.Equal("istio-canary", cps[0].Namespace)
+	assert.Equal("istiod-1-10", cps[0].Istiod)
+	assert.Equal("istio-1-10", cps[0].ConfigMapName)
+
+	assert.Equal("default", cps[1].Revision)
+	assert.Equal("istio-system", cps[1].Namespace)
+	assert.Equal("istiod", cps[1].Istiod)
+	assert.Equal("istio", cps[1].ConfigMapName)
+
+	assert.Equal("remote", cps[2].Revision)
+	assert.True(cps[2].External)
+	assert.Equal(externalURL, cps[2].ExternalURL)
+
+	// Discovered control planes are kept for a while
+	_, err = discoverControlPlanes()
+	assert.NoError(err)
+	k8s.AssertNumberOfCalls(t, "GetMutatingWebhookConfigurations", 1)
+	controlPlanes = nil
+}
+
+func TestRootNamespacesOfControlPlanes(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+	controlPlanes = []ControlPlane{{Revision: "1-10", Namespace: "istio-canary", ConfigMapName: "istio-1-10"}, {Revision: "default", Namespace: "istio-system"}}
+	controlPlanesCreated = time.Now()
+	defer func() { controlPlanes = nil }()
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigMap", "istio-canary", "istio-1-10").Return(&core_v1.ConfigMap{Data: map[string]string{"mesh": "rootNamespace: istio-config"}}, nil)
+
+	assert.Equal([]string{"istio-system", "istio-config"}, rootNamespaces(k8s))
+}
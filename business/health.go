@@ -1,6 +1,7 @@
 package business
 
 import (
+	"context"
 	"time"
 
 	"github.com/prometheus/common/model"
@@ -24,6 +25,27 @@ type HealthService struct {
 // Annotation Filter for Health
 var HealthAnnotation = []models.AnnotationKey{models.RateHealthAnnotation}
 
+// WithContext returns a copy of the service whose Prometheus queries are bound to ctx, such that the request
+// deadline and query budget carried by ctx apply. Namespace health is then returned without request rates,
+// rather than failing, when the queries time out. See prometheus.NewQueryBudgetContext.
+func (in HealthService) WithContext(ctx context.Context) HealthService {
+	if client, ok := in.prom.(*prometheus.Client); ok {
+		in.prom = client.WithContext(ctx)
+	}
+	return in
+}
+
+// handleRatesError returns nil for errors that are expected to produce partial results (see
+// prometheus.IsPartialError), after recording a warning in the request's query budget.
+func (in *HealthService) handleRatesError(namespace string, err error) error {
+	client, ok := in.prom.(*prometheus.Client)
+	if !ok || !prometheus.IsPartialError(client.GetContext(), err) {
+		return errors.NewServiceUnavailable(err.Error())
+	}
+	prometheus.GetQueryBudget(client.GetContext()).Warn(namespace, "istio_requests_total", err)
+	return nil
+}
+
 // GetServiceHealth returns a service health (service request error rate)
 func (in *HealthService) GetServiceHealth(namespace, service, rateInterval string, queryTime time.Time) (models.ServiceHealth, error) {
 	rqHealth, err := in.getServiceRequestsHealth(namespace, service, rateInterval, queryTime)
@@ -135,7 +157,7 @@ func (in *HealthService) getNamespaceAppHealth(namespace string, appEntities nam
 		// Fetch services requests rates
 		rates, err := in.prom.GetAllRequestRates(namespace, rateInterval, queryTime)
 		if err != nil {
-			return allHealth, errors.NewServiceUnavailable(err.Error())
+			return allHealth, in.handleRatesError(namespace, err)
 		}
 		// Fill with collected request rates
 		fillAppRequestRates(allHealth, rates)
@@ -179,7 +201,11 @@ func (in *HealthService) getNamespaceServiceHealth(namespace string, services *m
 	}
 
 	// Fetch services requests rates
-	rates, _ := in.prom.GetNamespaceServicesRequestRates(namespace, rateInterval, queryTime)
+	rates, err := in.prom.GetNamespaceServicesRequestRates(namespace, rateInterval, queryTime)
+	if err != nil {
+		// rates are not mandatory for service health, just keep track of the partial result
+		_ = in.handleRatesError(namespace, err)
+	}
 	// Fill with collected request rates
 	lblDestSvc := model.LabelName("destination_service_name")
 	for _, sample := range rates {
@@ -237,7 +263,7 @@ func (in *HealthService) getNamespacePodHealth(namespace string, pods models.Pod
 		// Fetch services requests rates
 		rates, err := in.prom.GetAllRequestRates(namespace, rateInterval, queryTime)
 		if err != nil {
-			return allHealth, errors.NewServiceUnavailable(err.Error())
+			return allHealth, in.handleRatesError(namespace, err)
 		}
 		// Fill with collected request rates
 		fillPodRequestRates(allHealth, rates)
@@ -264,7 +290,7 @@ func (in *HealthService) getNamespaceWorkloadHealth(namespace string, ws models.
 		// Fetch services requests rates
 		rates, err := in.prom.GetAllRequestRates(namespace, rateInterval, queryTime)
 		if err != nil {
-			return allHealth, errors.NewServiceUnavailable(err.Error())
+			return allHealth, in.handleRatesError(namespace, err)
 		}
 		// Fill with collected request rates
 		fillWorkloadRequestRates(allHealth, rates)
@@ -94,9 +94,7 @@ func (ics *IstioCertsService) getCertificateFromSecret(secretName, certName stri
 }
 
 func (ics *IstioCertsService) getCertsConfigFromIstioConfigMap() ([]certConfig, error) {
-	cfg := config.Get()
-
-	istioConfigMap, err := ics.k8s.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
+	istioConfigMap, err := getIstioConfigMap(ics.k8s, defaultRevision)
 	if err != nil {
 		return nil, err
 	}
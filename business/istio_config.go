@@ -426,61 +426,83 @@ func (in *IstioConfigService) GetIstioConfigDetails(namespace, objectType, objec
 		}
 	}()
 
+	err = in.fetchIstioConfigDetails(&istioConfigDetail, namespace, objectType, object)
+	wg.Wait()
+
+	return istioConfigDetail, err
+}
+
+// fetchIstioConfigDetails reads an Istio object from the Kiali cache, or from the API when it isn't cached
+func (in *IstioConfigService) fetchIstioConfigDetails(istioConfigDetail *models.IstioConfigDetails, namespace, objectType, object string) error {
+	if IsResourceCached(namespace, objectType) {
+		return in.getCachedIstioConfigDetails(istioConfigDetail, namespace, objectType, object)
+	}
+
+	var err error
 	ctx := context.TODO()
 	getOpts := meta_v1.GetOptions{}
 
 	switch objectType {
 	case kubernetes.DestinationRules:
-		istioConfigDetail.DestinationRule, err = in.k8s.Istio().NetworkingV1alpha3().DestinationRules(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.DestinationRule.Kind = kubernetes.DestinationRuleType
-		istioConfigDetail.DestinationRule.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.DestinationRule, err = in.k8s.Istio().NetworkingV1alpha3().DestinationRules(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.DestinationRule.Kind = kubernetes.DestinationRuleType
+			istioConfigDetail.DestinationRule.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.EnvoyFilters:
-		istioConfigDetail.EnvoyFilter, err = in.k8s.Istio().NetworkingV1alpha3().EnvoyFilters(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.EnvoyFilter.Kind = kubernetes.EnvoyFilterType
-		istioConfigDetail.EnvoyFilter.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.EnvoyFilter, err = in.k8s.Istio().NetworkingV1alpha3().EnvoyFilters(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.EnvoyFilter.Kind = kubernetes.EnvoyFilterType
+			istioConfigDetail.EnvoyFilter.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.Gateways:
-		istioConfigDetail.Gateway, err = in.k8s.Istio().NetworkingV1alpha3().Gateways(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.Gateway.Kind = kubernetes.GatewayType
-		istioConfigDetail.Gateway.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.Gateway, err = in.k8s.Istio().NetworkingV1alpha3().Gateways(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.Gateway.Kind = kubernetes.GatewayType
+			istioConfigDetail.Gateway.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.ServiceEntries:
-		istioConfigDetail.ServiceEntry, err = in.k8s.Istio().NetworkingV1alpha3().ServiceEntries(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.ServiceEntry.Kind = kubernetes.ServiceEntryType
-		istioConfigDetail.ServiceEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.ServiceEntry, err = in.k8s.Istio().NetworkingV1alpha3().ServiceEntries(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.ServiceEntry.Kind = kubernetes.ServiceEntryType
+			istioConfigDetail.ServiceEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.Sidecars:
-		istioConfigDetail.Sidecar, err = in.k8s.Istio().NetworkingV1alpha3().Sidecars(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.Sidecar.Kind = kubernetes.SidecarType
-		istioConfigDetail.Sidecar.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.Sidecar, err = in.k8s.Istio().NetworkingV1alpha3().Sidecars(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.Sidecar.Kind = kubernetes.SidecarType
+			istioConfigDetail.Sidecar.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.VirtualServices:
-		istioConfigDetail.VirtualService, err = in.k8s.Istio().NetworkingV1alpha3().VirtualServices(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.VirtualService.Kind = kubernetes.VirtualServiceType
-		istioConfigDetail.VirtualService.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.VirtualService, err = in.k8s.Istio().NetworkingV1alpha3().VirtualServices(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.VirtualService.Kind = kubernetes.VirtualServiceType
+			istioConfigDetail.VirtualService.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.WorkloadEntries:
-		istioConfigDetail.WorkloadEntry, err = in.k8s.Istio().NetworkingV1alpha3().WorkloadEntries(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.WorkloadEntry.Kind = kubernetes.WorkloadEntryType
-		istioConfigDetail.WorkloadEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.WorkloadEntry, err = in.k8s.Istio().NetworkingV1alpha3().WorkloadEntries(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.WorkloadEntry.Kind = kubernetes.WorkloadEntryType
+			istioConfigDetail.WorkloadEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.WorkloadGroups:
-		istioConfigDetail.WorkloadGroup, err = in.k8s.Istio().NetworkingV1alpha3().WorkloadGroups(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.WorkloadGroup.Kind = kubernetes.WorkloadGroupType
-		istioConfigDetail.WorkloadGroup.APIVersion = kubernetes.ApiNetworkingVersion
+		if istioConfigDetail.WorkloadGroup, err = in.k8s.Istio().NetworkingV1alpha3().WorkloadGroups(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.WorkloadGroup.Kind = kubernetes.WorkloadGroupType
+			istioConfigDetail.WorkloadGroup.APIVersion = kubernetes.ApiNetworkingVersion
+		}
 	case kubernetes.AuthorizationPolicies:
-		istioConfigDetail.AuthorizationPolicy, err = in.k8s.Istio().SecurityV1beta1().AuthorizationPolicies(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.AuthorizationPolicy.Kind = kubernetes.AuthorizationPoliciesType
-		istioConfigDetail.AuthorizationPolicy.APIVersion = kubernetes.ApiSecurityVersion
+		if istioConfigDetail.AuthorizationPolicy, err = in.k8s.Istio().SecurityV1beta1().AuthorizationPolicies(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.AuthorizationPolicy.Kind = kubernetes.AuthorizationPoliciesType
+			istioConfigDetail.AuthorizationPolicy.APIVersion = kubernetes.ApiSecurityVersion
+		}
 	case kubernetes.PeerAuthentications:
-		istioConfigDetail.PeerAuthentication, err = in.k8s.Istio().SecurityV1beta1().PeerAuthentications(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.PeerAuthentication.Kind = kubernetes.PeerAuthenticationsType
-		istioConfigDetail.PeerAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		if istioConfigDetail.PeerAuthentication, err = in.k8s.Istio().SecurityV1beta1().PeerAuthentications(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.PeerAuthentication.Kind = kubernetes.PeerAuthenticationsType
+			istioConfigDetail.PeerAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		}
 	case kubernetes.RequestAuthentications:
-		istioConfigDetail.RequestAuthentication, err = in.k8s.Istio().SecurityV1beta1().RequestAuthentications(namespace).Get(ctx, object, getOpts)
-		istioConfigDetail.RequestAuthentication.Kind = kubernetes.RequestAuthenticationsType
-		istioConfigDetail.RequestAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		if istioConfigDetail.RequestAuthentication, err = in.k8s.Istio().SecurityV1beta1().RequestAuthentications(namespace).Get(ctx, object, getOpts); err == nil {
+			istioConfigDetail.RequestAuthentication.Kind = kubernetes.RequestAuthenticationsType
+			istioConfigDetail.RequestAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		}
 	default:
 		err = fmt.Errorf("object type not found: %v", objectType)
 	}
 
-	wg.Wait()
-
-	return istioConfigDetail, err
+	return err
 }
 
 // GetIstioAPI provides the Kubernetes API that manages this Istio resource type
@@ -489,11 +511,124 @@ func GetIstioAPI(resourceType string) bool {
 	return kubernetes.ResourceTypesToAPI[resourceType] != ""
 }
 
+// getCachedIstioConfigDetails reads an Istio object from the Kiali cache instead of the API
+func (in *IstioConfigService) getCachedIstioConfigDetails(istioConfigDetail *models.IstioConfigDetails, namespace, objectType, object string) error {
+	var found bool
+	switch objectType {
+	case kubernetes.DestinationRules:
+		obj, err := kialiCache.GetDestinationRule(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.DestinationRule = obj.DeepCopy()
+			istioConfigDetail.DestinationRule.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.EnvoyFilters:
+		obj, err := kialiCache.GetEnvoyFilter(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.EnvoyFilter = obj.DeepCopy()
+			istioConfigDetail.EnvoyFilter.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.Gateways:
+		obj, err := kialiCache.GetGateway(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.Gateway = obj.DeepCopy()
+			istioConfigDetail.Gateway.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.ServiceEntries:
+		obj, err := kialiCache.GetServiceEntry(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.ServiceEntry = obj.DeepCopy()
+			istioConfigDetail.ServiceEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.Sidecars:
+		obj, err := kialiCache.GetSidecar(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.Sidecar = obj.DeepCopy()
+			istioConfigDetail.Sidecar.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.VirtualServices:
+		obj, err := kialiCache.GetVirtualService(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.VirtualService = obj.DeepCopy()
+			istioConfigDetail.VirtualService.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.WorkloadEntries:
+		obj, err := kialiCache.GetWorkloadEntry(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.WorkloadEntry = obj.DeepCopy()
+			istioConfigDetail.WorkloadEntry.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.WorkloadGroups:
+		obj, err := kialiCache.GetWorkloadGroup(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.WorkloadGroup = obj.DeepCopy()
+			istioConfigDetail.WorkloadGroup.APIVersion = kubernetes.ApiNetworkingVersion
+		}
+	case kubernetes.AuthorizationPolicies:
+		obj, err := kialiCache.GetAuthorizationPolicy(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.AuthorizationPolicy = obj.DeepCopy()
+			istioConfigDetail.AuthorizationPolicy.APIVersion = kubernetes.ApiSecurityVersion
+		}
+	case kubernetes.PeerAuthentications:
+		obj, err := kialiCache.GetPeerAuthentication(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.PeerAuthentication = obj.DeepCopy()
+			istioConfigDetail.PeerAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		}
+	case kubernetes.RequestAuthentications:
+		obj, err := kialiCache.GetRequestAuthentication(namespace, object)
+		if err != nil {
+			return err
+		}
+		if found = obj != nil; found {
+			istioConfigDetail.RequestAuthentication = obj.DeepCopy()
+			istioConfigDetail.RequestAuthentication.APIVersion = kubernetes.ApiSecurityVersion
+		}
+	default:
+		return fmt.Errorf("object type not found: %v", objectType)
+	}
+	if !found {
+		return kubernetes.NewNotFound(object, kubernetes.ResourceTypesToAPI[objectType], objectType)
+	}
+	return nil
+}
+
 // DeleteIstioConfigDetail deletes the given Istio resource
 func (in *IstioConfigService) DeleteIstioConfigDetail(namespace, resourceType, name string) error {
 	var err error
 	delOpts := meta_v1.DeleteOptions{}
 	ctx := context.TODO()
+	revision := cacheTypeRevision(namespace, kubernetes.PluralType[resourceType])
 	switch resourceType {
 	case kubernetes.DestinationRules:
 		err = in.k8s.Istio().NetworkingV1alpha3().DestinationRules(namespace).Delete(ctx, name, delOpts)
@@ -521,9 +656,9 @@ func (in *IstioConfigService) DeleteIstioConfigDetail(namespace, resourceType, n
 		err = fmt.Errorf("object type not found: %v", resourceType)
 	}
 
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
-	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
+	// Reads that follow a Create/Update/Delete operation have to see it
+	if err == nil {
+		waitForCacheChange(namespace, kubernetes.PluralType[resourceType], revision)
 	}
 	return err
 }
@@ -539,6 +674,7 @@ func (in *IstioConfigService) UpdateIstioConfigDetail(namespace, resourceType, n
 	bytePatch := []byte(jsonPatch)
 
 	var err error
+	revision := cacheTypeRevision(namespace, kubernetes.PluralType[resourceType])
 	switch resourceType {
 	case kubernetes.DestinationRules:
 		istioConfigDetail.DestinationRule = &networking_v1alpha3.DestinationRule{}
@@ -577,9 +713,9 @@ func (in *IstioConfigService) UpdateIstioConfigDetail(namespace, resourceType, n
 		err = fmt.Errorf("object type not found: %v", resourceType)
 	}
 
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
-	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
+	// Reads that follow a Create/Update/Delete operation have to see it
+	if err == nil {
+		waitForCacheChange(namespace, kubernetes.PluralType[resourceType], revision)
 	}
 	return istioConfigDetail, err
 }
@@ -593,6 +729,7 @@ func (in *IstioConfigService) CreateIstioConfigDetail(namespace, resourceType st
 	ctx := context.TODO()
 
 	var err error
+	revision := cacheTypeRevision(namespace, kubernetes.PluralType[resourceType])
 	switch resourceType {
 	case kubernetes.DestinationRules:
 		istioConfigDetail.DestinationRule = &networking_v1alpha3.DestinationRule{}
@@ -674,9 +811,9 @@ func (in *IstioConfigService) CreateIstioConfigDetail(namespace, resourceType st
 	default:
 		err = fmt.Errorf("object type not found: %v", resourceType)
 	}
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
-	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
+	// Reads that follow a Create/Update/Delete operation have to see it
+	if err == nil {
+		waitForCacheChange(namespace, kubernetes.PluralType[resourceType], revision)
 	}
 	return istioConfigDetail, err
 }
@@ -0,0 +1,390 @@
+package business
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+const istioConfigHistoryConfigMapStore = "configmap"
+
+// Metadata generated by the API server, dropped from the revisions so they can be compared and restored
+var istioConfigRevisionIgnoredMetadata = []string{"creationTimestamp", "generation", "managedFields", "resourceVersion", "selfLink", "uid"}
+
+// IstioConfigHistoryService keeps the revisions of the Istio objects modified through Kiali, to compare them
+// and to restore a previous one
+type IstioConfigHistoryService struct {
+	k8s           kubernetes.ClientInterface
+	businessLayer *Layer
+}
+
+type istioConfigHistoryStore interface {
+	// load returns the history of an object, empty when the object has no history
+	load(namespace, objectType, name string) (*models.IstioConfigHistory, error)
+	// update reads the history of an object, applies the change and stores the result. Concurrent changes of the
+	// same object don't lose revisions, the change is applied again to the latest history when needed.
+	update(namespace, objectType, name string, change func(history *models.IstioConfigHistory)) error
+}
+
+// IsIstioConfigHistoryEnabled returns true when the revisions of the Istio objects are kept
+func IsIstioConfigHistoryEnabled() bool {
+	return config.Get().KialiFeatureFlags.IstioConfigHistory.Enabled
+}
+
+// RecordChange adds the state of an Istio object after an operation made through Kiali to its history. The state
+// before the operation is recorded too when it isn't the latest revision, i.e. the first time the object is changed
+// through Kiali, or when it was changed outside Kiali. Errors are logged, a history can't fail the operation.
+func (in *IstioConfigHistoryService) RecordChange(namespace, objectType, name, operation, user string, before, after interface{}) {
+	if !IsIstioConfigHistoryEnabled() {
+		return
+	}
+	if err := in.recordChange(namespace, objectType, name, models.IstioConfigRevision{Operation: operation, User: user}, before, after); err != nil {
+		log.Errorf("Cannot record the revision of [namespace: %s] [type: %s] [name: %s]: %v", namespace, objectType, name, err)
+	}
+}
+
+func (in *IstioConfigHistoryService) recordChange(namespace, objectType, name string, revision models.IstioConfigRevision, before, after interface{}) error {
+	beforeObject, err := istioConfigRevisionObject(objectType, before)
+	if err != nil {
+		return err
+	}
+	if revision.Object, err = istioConfigRevisionObject(objectType, after); err != nil {
+		return err
+	}
+
+	store, err := getIstioConfigHistoryStore()
+	if err != nil {
+		return err
+	}
+	return store.update(namespace, objectType, name, func(history *models.IstioConfigHistory) {
+		if beforeObject != nil {
+			if last := history.LastRevision(); last == nil || !bytes.Equal(last.Object, beforeObject) {
+				addIstioConfigRevision(history, models.IstioConfigRevision{Operation: models.IstioConfigObserved, Object: beforeObject})
+			}
+		}
+		addIstioConfigRevision(history, revision)
+	})
+}
+
+// GetHistory returns the revisions kept for an Istio object, it's empty when the object wasn't modified through Kiali
+func (in *IstioConfigHistoryService) GetHistory(namespace, objectType, name string) (*models.IstioConfigHistory, error) {
+	// Deleted objects have a history too, the access to the namespace is checked instead
+	if _, err := in.businessLayer.Namespace.GetNamespace(namespace); err != nil {
+		return nil, err
+	}
+	store, err := getIstioConfigHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	return store.load(namespace, objectType, name)
+}
+
+// DiffRevisions compares two revisions of an Istio object
+func (in *IstioConfigHistoryService) DiffRevisions(namespace, objectType, name string, from, to int) (*models.IstioConfigRevisionDiff, error) {
+	history, err := in.GetHistory(namespace, objectType, name)
+	if err != nil {
+		return nil, err
+	}
+	fromRevision := history.GetRevision(from)
+	if fromRevision == nil {
+		return nil, istioConfigRevisionNotFound(from)
+	}
+	toRevision := history.GetRevision(to)
+	if toRevision == nil {
+		return nil, istioConfigRevisionNotFound(to)
+	}
+	return models.DiffIstioConfigRevisions(*fromRevision, *toRevision)
+}
+
+// RestoreRevision brings an Istio object back to a previous revision, recreating it when it was deleted. The object
+// is changed with the privileges of the user, the restore is recorded as a new revision. Like the other changes,
+// it's recorded after the change, the history isn't locked while the object is changed.
+func (in *IstioConfigHistoryService) RestoreRevision(namespace, objectType, name string, revision int, user string) (models.IstioConfigDetails, error) {
+	if _, err := in.businessLayer.Namespace.GetNamespace(namespace); err != nil {
+		return models.IstioConfigDetails{}, err
+	}
+	store, err := getIstioConfigHistoryStore()
+	if err != nil {
+		return models.IstioConfigDetails{}, err
+	}
+	history, err := store.load(namespace, objectType, name)
+	if err != nil {
+		return models.IstioConfigDetails{}, err
+	}
+	target := history.GetRevision(revision)
+	if target == nil {
+		return models.IstioConfigDetails{}, istioConfigRevisionNotFound(revision)
+	}
+	if len(target.Object) == 0 {
+		return models.IstioConfigDetails{}, errors.NewBadRequest(fmt.Sprintf("revision %d records the deletion of the object, it can't be restored", revision))
+	}
+
+	current := models.IstioConfigDetails{}
+	err = in.businessLayer.IstioConfig.fetchIstioConfigDetails(&current, namespace, objectType, name)
+	var restored models.IstioConfigDetails
+	var before interface{}
+	if errors.IsNotFound(err) {
+		restored, err = in.businessLayer.IstioConfig.CreateIstioConfigDetail(namespace, objectType, target.Object)
+	} else if err == nil {
+		before = current.GetObject()
+		var patch []byte
+		if patch, err = istioConfigRestorePatch(objectType, before, target.Object); err == nil {
+			restored, err = in.businessLayer.IstioConfig.UpdateIstioConfigDetail(namespace, objectType, name, string(patch))
+		}
+	}
+	if err != nil {
+		return restored, err
+	}
+
+	restore := models.IstioConfigRevision{Operation: models.IstioConfigRestored, User: user, RestoredFrom: revision}
+	if err := in.recordChange(namespace, objectType, name, restore, before, restored.GetObject()); err != nil {
+		log.Errorf("Cannot record the revision of [namespace: %s] [type: %s] [name: %s]: %v", namespace, objectType, name, err)
+	}
+	return restored, nil
+}
+
+func istioConfigRevisionNotFound(revision int) error {
+	return kubernetes.NewNotFound(strconv.Itoa(revision), "kiali.io", "istioconfigrevisions")
+}
+
+func addIstioConfigRevision(history *models.IstioConfigHistory, revision models.IstioConfigRevision) {
+	revision.Revision = 1
+	if last := history.LastRevision(); last != nil {
+		revision.Revision = last.Revision + 1
+	}
+	revision.Timestamp = time.Now()
+	history.Revisions = append(history.Revisions, revision)
+	if maxRevisions := config.Get().KialiFeatureFlags.IstioConfigHistory.MaxRevisions; maxRevisions > 0 && len(history.Revisions) > maxRevisions {
+		history.Revisions = history.Revisions[len(history.Revisions)-maxRevisions:]
+	}
+}
+
+// istioConfigRevisionObject returns the JSON of an object to be kept in a revision, without its status and
+// the metadata generated by the API server. The kind and API version are set, since the objects returned by
+// the API don't always have them. JSON objects are marshalled with sorted keys, so equal objects have the same
+// JSON. It returns nil for a nil object.
+func istioConfigRevisionObject(objectType string, obj interface{}) (json.RawMessage, error) {
+	if value := reflect.ValueOf(obj); obj == nil || (value.Kind() == reflect.Ptr && value.IsNil()) {
+		return nil, nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	delete(object, "status")
+	if kind, _ := object["kind"].(string); kind == "" {
+		object["kind"] = kubernetes.PluralType[objectType]
+	}
+	if apiVersion, _ := object["apiVersion"].(string); apiVersion == "" {
+		object["apiVersion"] = kubernetes.ApiToVersion[kubernetes.ResourceTypesToAPI[objectType]]
+	}
+	if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+		for _, field := range istioConfigRevisionIgnoredMetadata {
+			delete(metadata, field)
+		}
+	}
+	return json.Marshal(object)
+}
+
+// istioConfigRestorePatch returns the merge patch that turns the current object into the one of a revision
+func istioConfigRestorePatch(objectType string, current interface{}, target json.RawMessage) ([]byte, error) {
+	currentData, err := istioConfigRevisionObject(objectType, current)
+	if err != nil {
+		return nil, err
+	}
+	from := map[string]interface{}{}
+	if err := json.Unmarshal(currentData, &from); err != nil {
+		return nil, err
+	}
+	to := map[string]interface{}{}
+	if err := json.Unmarshal(target, &to); err != nil {
+		return nil, err
+	}
+	return json.Marshal(mergePatch(from, to))
+}
+
+// mergePatch returns the JSON merge patch (RFC 7386) from an object to another: fields missing in the target
+// are set to null, maps are patched recursively, other values are replaced.
+func mergePatch(from, to map[string]interface{}) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for k := range from {
+		if _, found := to[k]; !found {
+			patch[k] = nil
+		}
+	}
+	for k, v := range to {
+		fromMap, fromIsMap := from[k].(map[string]interface{})
+		toMap, toIsMap := v.(map[string]interface{})
+		if fromIsMap && toIsMap {
+			if sub := mergePatch(fromMap, toMap); len(sub) > 0 {
+				patch[k] = sub
+			}
+		} else if !reflect.DeepEqual(from[k], v) {
+			patch[k] = v
+		}
+	}
+	return patch
+}
+
+func getIstioConfigHistoryStore() (istioConfigHistoryStore, error) {
+	if config.Get().KialiFeatureFlags.IstioConfigHistory.Store != istioConfigHistoryConfigMapStore {
+		return memoryHistoryStore{}, nil
+	}
+	// Users don't need privileges on the Kiali namespace to keep the history of the objects they change
+	return kialiStateHistoryStore{}, nil
+}
+
+func newIstioConfigHistory(namespace, objectType, name string) *models.IstioConfigHistory {
+	return &models.IstioConfigHistory{Namespace: namespace, ObjectType: objectType, Name: name, Revisions: []models.IstioConfigRevision{}}
+}
+
+// memoryHistoryStore keeps the histories in memory, they are lost when Kiali restarts
+type memoryHistoryStore struct{}
+
+var (
+	memoryHistoriesLock sync.Mutex
+	memoryHistories     = map[string]models.IstioConfigHistory{}
+)
+
+func (memoryHistoryStore) load(namespace, objectType, name string) (*models.IstioConfigHistory, error) {
+	memoryHistoriesLock.Lock()
+	defer memoryHistoriesLock.Unlock()
+	return memoryHistoryStore{}.unlockedLoad(namespace, objectType, name), nil
+}
+
+func (memoryHistoryStore) unlockedLoad(namespace, objectType, name string) *models.IstioConfigHistory {
+	history, found := memoryHistories[istioConfigHistoryKey(namespace, objectType, name)]
+	if !found {
+		return newIstioConfigHistory(namespace, objectType, name)
+	}
+	history.Revisions = append([]models.IstioConfigRevision{}, history.Revisions...)
+	return &history
+}
+
+func (memoryHistoryStore) update(namespace, objectType, name string, change func(history *models.IstioConfigHistory)) error {
+	memoryHistoriesLock.Lock()
+	defer memoryHistoriesLock.Unlock()
+	history := memoryHistoryStore{}.unlockedLoad(namespace, objectType, name)
+	change(history)
+	memoryHistories[istioConfigHistoryKey(namespace, objectType, name)] = *history
+
+	now := time.Now()
+	for key, history := range memoryHistories {
+		if istioConfigHistoryExpired(history, now) {
+			delete(memoryHistories, key)
+		}
+	}
+	return nil
+}
+
+// kialiStateHistoryStore keeps the histories of the objects of a namespace in a Kiali state, a ConfigMap of the
+// Kiali namespace shared by the replicas of Kiali. ConfigMaps are limited to 1 MiB, the oldest revisions of the
+// namespace are dropped first when its histories don't fit.
+type kialiStateHistoryStore struct{}
+
+// The histories of the objects of a namespace, keyed by type and name
+type istioConfigHistories map[string]*models.IstioConfigHistory
+
+// Size of the histories of a namespace in JSON, leaving room in the ConfigMap for its metadata
+var maxIstioConfigHistoriesSize = 900 * 1024
+
+func (kialiStateHistoryStore) load(namespace, objectType, name string) (*models.IstioConfigHistory, error) {
+	histories := istioConfigHistories{}
+	if err := kialiState.load(istioConfigHistoryConfigMapName(namespace), &histories); err != nil {
+		return nil, err
+	}
+	if history, found := histories[istioConfigHistoryObjectKey(objectType, name)]; found {
+		return history, nil
+	}
+	return newIstioConfigHistory(namespace, objectType, name), nil
+}
+
+func (kialiStateHistoryStore) update(namespace, objectType, name string, change func(history *models.IstioConfigHistory)) error {
+	histories := istioConfigHistories{}
+	return kialiState.update(istioConfigHistoryConfigMapName(namespace), &histories, func() {
+		if histories == nil {
+			histories = istioConfigHistories{}
+		}
+		key := istioConfigHistoryObjectKey(objectType, name)
+		history, found := histories[key]
+		if !found {
+			history = newIstioConfigHistory(namespace, objectType, name)
+			histories[key] = history
+		}
+		change(history)
+
+		now := time.Now()
+		for key, history := range histories {
+			if istioConfigHistoryExpired(*history, now) {
+				delete(histories, key)
+			}
+		}
+		trimIstioConfigHistories(histories, maxIstioConfigHistoriesSize)
+	})
+}
+
+// trimIstioConfigHistories drops the oldest revisions of the histories until their JSON fits in the given size,
+// and the histories left without revisions
+func trimIstioConfigHistories(histories istioConfigHistories, maxSize int) {
+	data, err := json.Marshal(histories)
+	if err != nil || len(data) <= maxSize {
+		return
+	}
+	size := len(data)
+	for size > maxSize && len(histories) > 0 {
+		var oldestKey string
+		var oldest *models.IstioConfigRevision
+		for key, history := range histories {
+			if len(history.Revisions) > 0 && (oldest == nil || history.Revisions[0].Timestamp.Before(oldest.Timestamp)) {
+				oldestKey, oldest = key, &history.Revisions[0]
+			}
+		}
+		if oldest == nil {
+			return
+		}
+		history := histories[oldestKey]
+		log.Debugf("Dropping revision %d of [namespace: %s] [type: %s] [name: %s], the history of the namespace is too large", oldest.Revision, history.Namespace, history.ObjectType, history.Name)
+		revision, _ := json.Marshal(oldest)
+		size -= len(revision) + 1
+		history.Revisions = history.Revisions[1:]
+		if len(history.Revisions) == 0 {
+			delete(histories, oldestKey)
+		}
+	}
+}
+
+// istioConfigHistoryExpired tells whether the history of an object deleted long ago can be dropped
+func istioConfigHistoryExpired(history models.IstioConfigHistory, now time.Time) bool {
+	retention := config.Get().KialiFeatureFlags.IstioConfigHistory.RetentionAfterDeletion
+	last := history.LastRevision()
+	return retention > 0 && last != nil && last.Operation == models.IstioConfigDeleted &&
+		now.Sub(last.Timestamp) > time.Duration(retention)*time.Second
+}
+
+func istioConfigHistoryKey(namespace, objectType, name string) string {
+	return namespace + "/" + istioConfigHistoryObjectKey(objectType, name)
+}
+
+func istioConfigHistoryObjectKey(objectType, name string) string {
+	return objectType + "/" + name
+}
+
+// istioConfigHistoryConfigMapName is valid for any namespace, their names being DNS labels
+func istioConfigHistoryConfigMapName(namespace string) string {
+	return "kiali-istio-config-history-" + namespace
+}
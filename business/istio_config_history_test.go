@@ -0,0 +1,173 @@
+package business
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+)
+
+func TestIstioConfigHistoryAndRestore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	config.Set(config.NewConfig())
+	memoryHistories = map[string]models.IstioConfigHistory{}
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetNamespace", "bookinfo").Return(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}}, nil)
+	k8s.MockIstio(&networking_v1alpha3.VirtualService{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "reviews", Namespace: "bookinfo", UID: "1234"},
+		Spec:       api_networking_v1alpha3.VirtualService{Hosts: []string{"reviews"}},
+	})
+	layer := NewWithBackends(k8s, nil, nil)
+
+	before := models.IstioConfigDetails{}
+	require.NoError(layer.IstioConfig.fetchIstioConfigDetails(&before, "bookinfo", kubernetes.VirtualServices, "reviews"))
+	updated, err := layer.IstioConfig.UpdateIstioConfigDetail("bookinfo", kubernetes.VirtualServices, "reviews", `{"spec":{"hosts":["reviews","ratings"]}}`)
+	require.NoError(err)
+	layer.IstioHistory.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigUpdated, "alice", before.GetObject(), updated.GetObject())
+
+	require.NoError(layer.IstioConfig.DeleteIstioConfigDetail("bookinfo", kubernetes.VirtualServices, "reviews"))
+	layer.IstioHistory.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigDeleted, "bob", updated.GetObject(), nil)
+
+	// The original object is recorded before the first change made through Kiali
+	history, err := layer.IstioHistory.GetHistory("bookinfo", kubernetes.VirtualServices, "reviews")
+	require.NoError(err)
+	require.Len(history.Revisions, 3)
+	assert.Equal(models.IstioConfigObserved, history.Revisions[0].Operation)
+	assert.NotContains(string(history.Revisions[0].Object), "1234")
+	assert.Equal(models.IstioConfigUpdated, history.Revisions[1].Operation)
+	assert.Equal("alice", history.Revisions[1].User)
+	assert.Equal(models.IstioConfigDeleted, history.Revisions[2].Operation)
+	assert.Empty(history.Revisions[2].Object)
+
+	diff, err := layer.IstioHistory.DiffRevisions("bookinfo", kubernetes.VirtualServices, "reviews", 1, 2)
+	require.NoError(err)
+	require.Len(diff.Fields, 1)
+	assert.Equal("spec.hosts", diff.Fields[0].Path)
+	_, err = layer.IstioHistory.DiffRevisions("bookinfo", kubernetes.VirtualServices, "reviews", 1, 9)
+	assert.True(errors.IsNotFound(err))
+
+	// The deleted object is recreated
+	restored, err := layer.IstioHistory.RestoreRevision("bookinfo", kubernetes.VirtualServices, "reviews", 1, "carol")
+	require.NoError(err)
+	assert.Equal([]string{"reviews"}, restored.VirtualService.Spec.Hosts)
+
+	// The existing object is patched
+	restored, err = layer.IstioHistory.RestoreRevision("bookinfo", kubernetes.VirtualServices, "reviews", 2, "carol")
+	require.NoError(err)
+	assert.Equal([]string{"reviews", "ratings"}, restored.VirtualService.Spec.Hosts)
+
+	history, err = layer.IstioHistory.GetHistory("bookinfo", kubernetes.VirtualServices, "reviews")
+	require.NoError(err)
+	require.Len(history.Revisions, 5)
+	assert.Equal(models.IstioConfigRestored, history.Revisions[4].Operation)
+	assert.Equal(2, history.Revisions[4].RestoredFrom)
+	assert.Equal("carol", history.Revisions[4].User)
+
+	_, err = layer.IstioHistory.RestoreRevision("bookinfo", kubernetes.VirtualServices, "reviews", 3, "carol")
+	assert.True(errors.IsBadRequest(err))
+}
+
+func TestIstioConfigHistoryMaxRevisions(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.IstioConfigHistory.MaxRevisions = 2
+	config.Set(conf)
+	memoryHistories = map[string]models.IstioConfigHistory{}
+
+	service := IstioConfigHistoryService{}
+	for _, host := range []string{"a", "b", "c"} {
+		vs := &networking_v1alpha3.VirtualService{Spec: api_networking_v1alpha3.VirtualService{Hosts: []string{host}}}
+		service.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigCreated, "", nil, vs)
+	}
+
+	history, _ := memoryHistoryStore{}.load("bookinfo", kubernetes.VirtualServices, "reviews")
+	assert.Len(history.Revisions, 2)
+	assert.Equal(2, history.Revisions[0].Revision)
+	assert.Equal(3, history.Revisions[1].Revision)
+}
+
+func TestMergePatch(t *testing.T) {
+	from := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"a": "1", "b": "2"}},
+		"spec":     map[string]interface{}{"hosts": []interface{}{"reviews"}, "gateways": []interface{}{"mesh"}},
+	}
+	to := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"a": "1", "c": "3"}},
+		"spec":     map[string]interface{}{"hosts": []interface{}{"reviews", "ratings"}},
+	}
+	patch, _ := json.Marshal(mergePatch(from, to))
+	assert.JSONEq(t, `{"metadata":{"labels":{"b":null,"c":"3"}},"spec":{"gateways":null,"hosts":["reviews","ratings"]}}`, string(patch))
+}
+
+func TestKialiStateHistoryStore(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.IstioConfigHistory.Store = istioConfigHistoryConfigMapStore
+	config.Set(conf)
+	state := useMemoryKialiState(t)
+
+	store, err := getIstioConfigHistoryStore()
+	assert.NoError(err)
+	loaded, err := store.load("bookinfo", kubernetes.VirtualServices, "reviews")
+	assert.NoError(err)
+	assert.Equal(newIstioConfigHistory("bookinfo", kubernetes.VirtualServices, "reviews"), loaded)
+
+	service := IstioConfigHistoryService{}
+	vs := &networking_v1alpha3.VirtualService{Spec: api_networking_v1alpha3.VirtualService{Hosts: []string{"reviews"}}}
+	service.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigCreated, "alice", nil, vs)
+	service.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigDeleted, "bob", vs, nil)
+	assert.Contains(state.states, istioConfigHistoryConfigMapName("bookinfo"))
+
+	loaded, err = store.load("bookinfo", kubernetes.VirtualServices, "reviews")
+	assert.NoError(err)
+	assert.Equal("bookinfo", loaded.Namespace)
+	assert.Len(loaded.Revisions, 2)
+	assert.Equal("alice", loaded.Revisions[0].User)
+	assert.Equal(models.IstioConfigDeleted, loaded.Revisions[1].Operation)
+}
+
+func TestKialiStateHistoryStoreDropsExpiredAndOldestRevisions(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.KialiFeatureFlags.IstioConfigHistory.Store = istioConfigHistoryConfigMapStore
+	config.Set(conf)
+	state := useMemoryKialiState(t)
+	store := kialiStateHistoryStore{}
+
+	// The history of an object deleted long ago is dropped with the next change of the namespace
+	deleted := time.Now().Add(-time.Duration(conf.KialiFeatureFlags.IstioConfigHistory.RetentionAfterDeletion+1) * time.Second)
+	assert.NoError(store.update("bookinfo", kubernetes.VirtualServices, "details", func(history *models.IstioConfigHistory) {
+		history.Revisions = append(history.Revisions, models.IstioConfigRevision{Revision: 1, Timestamp: deleted, Operation: models.IstioConfigDeleted})
+	}))
+	service := IstioConfigHistoryService{}
+	vs := &networking_v1alpha3.VirtualService{Spec: api_networking_v1alpha3.VirtualService{Hosts: []string{"reviews"}}}
+	service.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigCreated, "alice", nil, vs)
+	loaded, _ := store.load("bookinfo", kubernetes.VirtualServices, "details")
+	assert.Empty(loaded.Revisions)
+
+	// The oldest revisions of the namespace are dropped when its histories don't fit in a ConfigMap
+	defer func(size int) { maxIstioConfigHistoriesSize = size }(maxIstioConfigHistoriesSize)
+	service.RecordChange("bookinfo", kubernetes.VirtualServices, "ratings", models.IstioConfigCreated, "bob", nil, vs)
+	maxIstioConfigHistoriesSize = len(state.states[istioConfigHistoryConfigMapName("bookinfo")])
+	service.RecordChange("bookinfo", kubernetes.VirtualServices, "reviews", models.IstioConfigUpdated, "alice", vs, vs)
+	reviews, _ := store.load("bookinfo", kubernetes.VirtualServices, "reviews")
+	ratings, _ := store.load("bookinfo", kubernetes.VirtualServices, "ratings")
+	assert.Len(ratings.Revisions, 1)
+	assert.Len(reviews.Revisions, 1)
+	assert.Equal(2, reviews.Revisions[0].Revision)
+}
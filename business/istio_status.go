@@ -264,6 +264,11 @@ func (iss *IstioStatusService) getIstiodReachingCheck() (IstioComponentStatus, e
 		return nil, err
 	}
 
+	return iss.getIstiodsReachingCheck(istiods), nil
+}
+
+// getIstiodsReachingCheck reports the running istiod pods which Kiali can't reach
+func (iss *IstioStatusService) getIstiodsReachingCheck(istiods []core_v1.Pod) IstioComponentStatus {
 	healthyIstiods := make([]*core_v1.Pod, 0, len(istiods))
 	for i, istiod := range istiods {
 		if istiod.Status.Phase == core_v1.PodRunning {
@@ -302,7 +307,7 @@ func (iss *IstioStatusService) getIstiodReachingCheck() (IstioComponentStatus, e
 		ics.merge(IstioComponentStatus{componentStatus})
 	}
 
-	return ics, nil
+	return ics
 }
 
 func getAddonStatus(name string, enabled bool, isCore bool, auth *config.Auth, url string, healthCheckUrl string, staChan chan<- IstioComponentStatus, wg *sync.WaitGroup) {
@@ -0,0 +1,649 @@
+package business
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+// IstioUpgradeService assists the canary upgrade of Istio between the revisions of config.IstioCanaryRevision
+type IstioUpgradeService struct {
+	k8s           kubernetes.ClientInterface
+	businessLayer *Layer
+}
+
+// The migrations are stored in this ConfigMap of the Kiali namespace, so that they survive restarts of Kiali
+const istioUpgradeConfigMapName = "kiali-istio-upgrade"
+
+// Beyond this number of migrations, the oldest ones with nothing left to roll back are dropped
+const maxIstioUpgradeMigrations = 20
+
+// The workload types restarted to move their proxies to a new revision, the others are owned by them or short lived
+var restartableWorkloadTypes = map[string]bool{
+	kubernetes.DeploymentType:       true,
+	kubernetes.DeploymentConfigType: true,
+	kubernetes.StatefulSetType:      true,
+	kubernetes.DaemonSetType:        true,
+}
+
+type istioUpgradeMigration struct {
+	models.IstioUpgradeMigration
+	// The injection labels of the namespaces before being migrated, nil when a label wasn't set
+	PreviousLabels map[string]map[string]*string `json:"previousLabels,omitempty"`
+	// The Kiali pod running the batches of the migration
+	Runner string `json:"runner,omitempty"`
+}
+
+// The batches of the migrations run with the Kiali ServiceAccount, once the permissions of the user are checked,
+// so that they don't depend on the session of the user
+var istioUpgradeRunnerClient = getKialiServiceAccountClient
+
+// The name of the Kiali pod, which is its hostname
+var kialiPodName = func() string {
+	hostname, _ := os.Hostname()
+	return hostname
+}
+
+// Serializes the updates of the migrations run by this Kiali
+var istioUpgradeLock sync.Mutex
+
+// IsIstioUpgradeEnabled tells whether the upgrade feature is enabled and both revisions are configured
+func IsIstioUpgradeEnabled() bool {
+	conf := config.Get()
+	revisions := conf.ExternalServices.Istio.IstioCanaryRevision
+	return conf.KialiFeatureFlags.IstioUpgradeAction && revisions.Current != "" && revisions.Upgrade != ""
+}
+
+// GetUpgradeStatus lists the namespaces and workloads of each revision, and the proxies which are not yet on the
+// revision of their namespace
+func (in *IstioUpgradeService) GetUpgradeStatus() (*models.IstioUpgradeStatus, error) {
+	conf := config.Get()
+	revisions := conf.ExternalServices.Istio.IstioCanaryRevision
+	status := &models.IstioUpgradeStatus{
+		Current:         revisions.Current,
+		Upgrade:         revisions.Upgrade,
+		Revisions:       []models.RevisionWorkloads{},
+		OutdatedProxies: []models.OutdatedProxy{},
+	}
+
+	namespaces, err := in.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	istiodRevisions, err := in.businessLayer.ProxyStatus.getIstiodRevisions()
+	if err != nil {
+		return nil, err
+	}
+
+	byRevision := map[string]*models.RevisionWorkloads{}
+	for _, ns := range namespaces {
+		revision := models.NamespaceRevision(ns, conf.IstioLabels.InjectionLabelName, conf.IstioLabels.InjectionLabelRev)
+		if revision == "" {
+			continue
+		}
+		rw, ok := byRevision[revision]
+		if !ok {
+			rw = &models.RevisionWorkloads{Revision: revision, Namespaces: []string{}, Workloads: []models.RevisionWorkload{}}
+			byRevision[revision] = rw
+		}
+		rw.Namespaces = append(rw.Namespaces, ns.Name)
+
+		workloads, err := fetchWorkloads(in.businessLayer, ns.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, w := range workloads {
+			rw.Workloads = append(rw.Workloads, models.RevisionWorkload{Namespace: ns.Name, Name: w.Name, Type: w.Type})
+			for _, pod := range w.Pods {
+				if outdated := in.outdatedProxy(ns.Name, w.Name, pod, revision, istiodRevisions); outdated != nil {
+					status.OutdatedProxies = append(status.OutdatedProxies, *outdated)
+				}
+			}
+		}
+	}
+
+	for _, rw := range byRevision {
+		status.Revisions = append(status.Revisions, *rw)
+	}
+	sort.Slice(status.Revisions, func(i, j int) bool { return status.Revisions[i].Revision < status.Revisions[j].Revision })
+	return status, nil
+}
+
+// outdatedProxy checks whether the proxy of a pod was injected by, or is connected to, another revision than the
+// one of its namespace
+func (in *IstioUpgradeService) outdatedProxy(namespace, workload string, pod *models.Pod, expected string, istiodRevisions map[string]string) *models.OutdatedProxy {
+	if !pod.IstioSidecar {
+		return nil
+	}
+	proxy := &models.OutdatedProxy{
+		Namespace:        namespace,
+		Workload:         workload,
+		Pod:              pod.Name,
+		Revision:         models.PodRevision(pod, config.Get().IstioLabels.InjectionLabelRev),
+		ExpectedRevision: expected,
+	}
+	if ps, err := in.businessLayer.ProxyStatus.GetPodProxyStatus(namespace, pod.Name); err == nil && ps != nil {
+		proxy.Istiod = ps.Pilot()
+		if revision, ok := istiodRevisions[proxy.Istiod]; ok && revision != expected {
+			proxy.Revision = revision
+		}
+	}
+	if proxy.Revision == expected {
+		return nil
+	}
+	return proxy
+}
+
+// PreCheck verifies that the istiod of the upgrade revision is healthy, and that the namespaces have no
+// validation errors which could be mistaken for regressions of the upgrade
+func (in *IstioUpgradeService) PreCheck(namespaces []string) (*models.UpgradePreCheck, error) {
+	conf := config.Get()
+	check := &models.UpgradePreCheck{
+		Passed:           true,
+		Istiods:          []string{},
+		ValidationErrors: map[string]int{},
+		Messages:         []string{},
+	}
+
+	upgrade := conf.ExternalServices.Istio.IstioCanaryRevision.Upgrade
+	istiods, err := in.getIstiods(upgrade)
+	if err != nil {
+		return nil, err
+	}
+	for _, istiod := range istiods {
+		if isPodReady(istiod) {
+			check.Istiods = append(check.Istiods, istiod.Name)
+		}
+	}
+	if len(check.Istiods) == 0 {
+		check.Passed = false
+		check.Messages = append(check.Messages, fmt.Sprintf("No healthy istiod found for revision %s", upgrade))
+	}
+
+	for _, ns := range namespaces {
+		// Checks the namespace is accessible too
+		if _, err := in.businessLayer.Namespace.GetNamespace(ns); err != nil {
+			return nil, err
+		}
+		validations, err := in.businessLayer.Validations.GetValidations(ns, "")
+		if err != nil {
+			return nil, err
+		}
+		validationErrors := 0
+		for key, validation := range validations {
+			if key.Namespace != ns {
+				continue
+			}
+			for _, check := range validation.Checks {
+				if check.Severity == models.ErrorSeverity {
+					validationErrors++
+				}
+			}
+		}
+		check.ValidationErrors[ns] = validationErrors
+		if validationErrors > 0 {
+			check.Passed = false
+			check.Messages = append(check.Messages, fmt.Sprintf("Namespace %s has %d validation errors", ns, validationErrors))
+		}
+	}
+	return check, nil
+}
+
+func (in *IstioUpgradeService) getIstiods(revision string) ([]core_v1.Pod, error) {
+	conf := config.Get()
+	selector := labels.Set{"app": "istiod", conf.IstioLabels.InjectionLabelRev: revision}.String()
+	istiods, err := in.k8s.GetPods(conf.IstioNamespace, selector)
+	if err != nil {
+		// Users don't necessarily have access to the control plane namespace
+		k8s, saErr := getKialiServiceAccountClient()
+		if saErr != nil {
+			return nil, err
+		}
+		return k8s.GetPods(conf.IstioNamespace, selector)
+	}
+	return istiods, nil
+}
+
+func isPodReady(pod core_v1.Pod) bool {
+	if pod.Status.Phase != core_v1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == core_v1.PodReady {
+			return condition.Status == core_v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// StartMigration moves namespaces to the upgrade revision, batchSize namespaces at a time. It only starts when
+// the pre-check passes, and then runs in the background: its progress is returned by GetMigration.
+func (in *IstioUpgradeService) StartMigration(namespaces []string, batchSize int, restartWorkloads bool) (*models.IstioUpgradeMigration, error) {
+	if len(namespaces) == 0 {
+		return nil, fmt.Errorf("no namespace to migrate")
+	}
+	if batchSize <= 0 {
+		batchSize = len(namespaces)
+	}
+	revisions := config.Get().ExternalServices.Istio.IstioCanaryRevision
+
+	check, err := in.PreCheck(namespaces)
+	if err != nil {
+		return nil, err
+	}
+	if err := in.checkMigrationPermissions(namespaces, restartWorkloads); err != nil {
+		return nil, err
+	}
+	runner, err := newIstioUpgradeRunner()
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	migration := &istioUpgradeMigration{
+		IstioUpgradeMigration: models.IstioUpgradeMigration{
+			ID:               hex.EncodeToString(id),
+			From:             revisions.Current,
+			To:               revisions.Upgrade,
+			RestartWorkloads: restartWorkloads,
+			Status:           models.MigrationPending,
+			Created:          time.Now(),
+			PreCheck:         *check,
+			Batches:          []models.MigrationBatch{},
+		},
+		PreviousLabels: map[string]map[string]*string{},
+		Runner:         kialiPodName(),
+	}
+	for start := 0; start < len(namespaces); start += batchSize {
+		end := start + batchSize
+		if end > len(namespaces) {
+			end = len(namespaces)
+		}
+		migration.Batches = append(migration.Batches, models.MigrationBatch{Namespaces: namespaces[start:end], Status: models.MigrationPending})
+	}
+
+	if !check.Passed {
+		migration.Status = models.MigrationFailed
+		migration.Error = "Pre-check failed"
+	}
+	istioUpgradeLock.Lock()
+	err = storeMigration(migration)
+	result := copyMigration(migration)
+	istioUpgradeLock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	if check.Passed {
+		go runner.runMigration(migration)
+	}
+	return result, nil
+}
+
+// checkMigrationPermissions verifies that the user can relabel the namespaces, and restart their workloads
+func (in *IstioUpgradeService) checkMigrationPermissions(namespaces []string, restartWorkloads bool) error {
+	if err := checkMigrationViewOnlyMode(); err != nil {
+		return err
+	}
+	resources := []schema.GroupResource{{Resource: "namespaces"}}
+	if restartWorkloads {
+		resources = append(resources,
+			schema.GroupResource{Group: "apps", Resource: "deployments"},
+			schema.GroupResource{Group: "apps", Resource: "statefulsets"},
+			schema.GroupResource{Group: "apps", Resource: "daemonsets"},
+		)
+	}
+	for _, ns := range namespaces {
+		for _, resource := range resources {
+			ssars, err := in.k8s.GetSelfSubjectAccessReview(ns, resource.Group, resource.Resource, []string{"patch"})
+			if err != nil {
+				return err
+			}
+			for _, ssar := range ssars {
+				if !ssar.Status.Allowed {
+					return errors.NewForbidden(resource, ns, fmt.Errorf("the user cannot patch %s of namespace %s", resource.Resource, ns))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkMigrationViewOnlyMode forbids relabelling the namespaces when Kiali is in view only mode
+func checkMigrationViewOnlyMode() error {
+	if config.Get().Deployment.ViewOnlyMode {
+		return errors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", fmt.Errorf("Kiali is in view only mode"))
+	}
+	return nil
+}
+
+// newIstioUpgradeRunner creates the service running the batches of the migrations
+func newIstioUpgradeRunner() (*IstioUpgradeService, error) {
+	k8s, err := istioUpgradeRunnerClient()
+	if err != nil {
+		return nil, err
+	}
+	return &NewWithBackends(k8s, prometheusClient, nil).IstioUpgrade, nil
+}
+
+func (in *IstioUpgradeService) runMigration(migration *istioUpgradeMigration) {
+	conf := config.Get()
+	injectionLabel, revisionLabel := conf.IstioLabels.InjectionLabelName, conf.IstioLabels.InjectionLabelRev
+	updateMigration(migration, func() { migration.Status = models.MigrationRunning })
+
+	for i := range migration.Batches {
+		started := time.Now()
+		updateMigration(migration, func() {
+			migration.Batches[i].Status = models.MigrationRunning
+			migration.Batches[i].Started = &started
+		})
+
+		var err error
+		for _, ns := range migration.Batches[i].Namespaces {
+			var namespace *models.Namespace
+			if namespace, err = in.businessLayer.Namespace.GetNamespace(ns); err != nil {
+				break
+			}
+			previous := map[string]*string{injectionLabel: nil, revisionLabel: nil}
+			for label := range previous {
+				if value, ok := namespace.Labels[label]; ok {
+					previous[label] = &value
+				}
+			}
+			updateMigration(migration, func() { migration.PreviousLabels[ns] = previous })
+
+			target := map[string]*string{injectionLabel: nil, revisionLabel: &migration.To}
+			if err = in.relabelNamespace(ns, target, migration.RestartWorkloads); err != nil {
+				break
+			}
+		}
+
+		completed := time.Now()
+		updateMigration(migration, func() {
+			migration.Batches[i].Completed = &completed
+			if err != nil {
+				migration.Batches[i].Status = models.MigrationFailed
+				migration.Batches[i].Error = err.Error()
+				migration.Status = models.MigrationFailed
+				migration.Error = fmt.Sprintf("Batch %d failed", i+1)
+			} else {
+				migration.Batches[i].Status = models.MigrationCompleted
+			}
+		})
+		if err != nil {
+			log.Errorf("Istio upgrade migration %s failed: %v", migration.ID, err)
+			return
+		}
+	}
+	updateMigration(migration, func() { migration.Status = models.MigrationCompleted })
+}
+
+// relabelNamespace sets the injection labels of a namespace, nil values removing them, and optionally restarts
+// its workloads so their proxies are injected by the revision of the new labels
+func (in *IstioUpgradeService) relabelNamespace(namespace string, labels map[string]*string, restartWorkloads bool) error {
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"labels": labels}})
+	if err != nil {
+		return err
+	}
+	if _, err := in.businessLayer.Namespace.UpdateNamespace(namespace, string(patch)); err != nil {
+		return err
+	}
+	if !restartWorkloads {
+		return nil
+	}
+
+	workloads, err := fetchWorkloads(in.businessLayer, namespace, "")
+	if err != nil {
+		return err
+	}
+	restart := fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{"kubectl.kubernetes.io/restartedAt":"%s"}}}}}`, time.Now().Format(time.RFC3339))
+	for _, w := range workloads {
+		if !restartableWorkloadTypes[w.Type] || !w.IstioSidecar {
+			continue
+		}
+		if _, err := in.businessLayer.Workload.UpdateWorkload(namespace, w.Name, w.Type, false, restart); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RollbackMigration restores the injection labels the migrated namespaces had before the migration, restarting
+// their workloads if the migration did
+func (in *IstioUpgradeService) RollbackMigration(id string) (*models.IstioUpgradeMigration, error) {
+	if err := checkMigrationViewOnlyMode(); err != nil {
+		return nil, err
+	}
+	migration, err := in.findMigration(id)
+	if err != nil {
+		return nil, err
+	}
+	if migration.Status == models.MigrationRunning || migration.Status == models.MigrationPending {
+		return nil, fmt.Errorf("migration %s is still running", id)
+	}
+	previousLabels := make(map[string]map[string]*string, len(migration.PreviousLabels))
+	for ns, labels := range migration.PreviousLabels {
+		previousLabels[ns] = labels
+	}
+
+	namespaces := make([]string, 0, len(previousLabels))
+	for ns := range previousLabels {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		if err := in.relabelNamespace(ns, previousLabels[ns], migration.RestartWorkloads); err != nil {
+			updateMigration(migration, func() { migration.Error = fmt.Sprintf("Rollback of namespace %s failed: %v", ns, err) })
+			return nil, err
+		}
+		updateMigration(migration, func() { delete(migration.PreviousLabels, ns) })
+	}
+
+	updateMigration(migration, func() {
+		migration.Status = models.MigrationRolledBack
+		migration.Error = ""
+	})
+	istioUpgradeLock.Lock()
+	defer istioUpgradeLock.Unlock()
+	return copyMigration(migration), nil
+}
+
+// GetMigration returns the progress of a migration
+func (in *IstioUpgradeService) GetMigration(id string) (*models.IstioUpgradeMigration, error) {
+	migration, err := in.findMigration(id)
+	if err != nil {
+		return nil, err
+	}
+	return copyMigration(migration), nil
+}
+
+// GetMigrations lists the migrations of the namespaces accessible to the user, the latest first
+func (in *IstioUpgradeService) GetMigrations() ([]models.IstioUpgradeMigration, error) {
+	stored, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	accessible, err := in.accessibleNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]models.IstioUpgradeMigration, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		if migrationAccessible(stored[i], accessible) {
+			migrations = append(migrations, *copyMigration(stored[i]))
+		}
+	}
+	return migrations, nil
+}
+
+// GetMigrationNamespaces returns the namespaces of a migration, whether the user can access them or not, so that
+// the requests about the migration can be authorized for them. It's empty when the migration doesn't exist.
+func GetMigrationNamespaces(id string) ([]string, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	namespaces := []string{}
+	for _, migration := range migrations {
+		if migration.ID == id {
+			for _, batch := range migration.Batches {
+				namespaces = append(namespaces, batch.Namespaces...)
+			}
+		}
+	}
+	return namespaces, nil
+}
+
+// findMigration loads a migration, which is only found when the user can access all its namespaces
+func (in *IstioUpgradeService) findMigration(id string) (*istioUpgradeMigration, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	for _, migration := range migrations {
+		if migration.ID != id {
+			continue
+		}
+		accessible, err := in.accessibleNamespaces()
+		if err != nil {
+			return nil, err
+		}
+		if migrationAccessible(migration, accessible) {
+			return migration, nil
+		}
+		break
+	}
+	return nil, kubernetes.NewNotFound(id, "kiali", "istio_upgrade_migrations")
+}
+
+func (in *IstioUpgradeService) accessibleNamespaces() (map[string]bool, error) {
+	namespaces, err := in.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	accessible := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		accessible[ns.Name] = true
+	}
+	return accessible, nil
+}
+
+func migrationAccessible(migration *istioUpgradeMigration, accessible map[string]bool) bool {
+	for _, batch := range migration.Batches {
+		for _, ns := range batch.Namespaces {
+			if !accessible[ns] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// StartIstioUpgradeMigrations fails the migrations whose Kiali pod stopped while running them: their batches
+// aren't resumed, as the namespaces may have changed meanwhile, but the migrated namespaces can be rolled back.
+func StartIstioUpgradeMigrations() {
+	migrations, err := loadMigrations()
+	if err != nil {
+		log.Errorf("Unable to load the Istio upgrade migrations: %v", err)
+		return
+	}
+	for _, migration := range migrations {
+		if migration.Status != models.MigrationRunning && migration.Status != models.MigrationPending {
+			continue
+		}
+		if migration.Runner != kialiPodName() && isKialiPodRunning(migration.Runner) {
+			continue
+		}
+		log.Infof("Istio upgrade migration %s was interrupted by a restart of Kiali", migration.ID)
+		updateMigration(migration, func() {
+			for i := range migration.Batches {
+				if migration.Batches[i].Status == models.MigrationRunning {
+					migration.Batches[i].Status = models.MigrationFailed
+					migration.Batches[i].Error = "Interrupted by a restart of Kiali"
+				}
+			}
+			migration.Status = models.MigrationFailed
+			migration.Error = "Interrupted by a restart of Kiali"
+		})
+	}
+}
+
+// isKialiPodRunning checks whether a pod of Kiali still exists. When it can't be checked, the pod is assumed running.
+func isKialiPodRunning(name string) bool {
+	if name == "" {
+		return false
+	}
+	k8s, err := istioUpgradeRunnerClient()
+	if err != nil {
+		return true
+	}
+	_, err = k8s.GetPod(config.Get().Deployment.Namespace, name)
+	return !errors.IsNotFound(err)
+}
+
+func loadMigrations() ([]*istioUpgradeMigration, error) {
+	var migrations []*istioUpgradeMigration
+	err := kialiState.load(istioUpgradeConfigMapName, &migrations)
+	return migrations, err
+}
+
+// storeMigration saves the migration, must be called holding istioUpgradeLock
+func storeMigration(migration *istioUpgradeMigration) error {
+	var migrations []*istioUpgradeMigration
+	return kialiState.update(istioUpgradeConfigMapName, &migrations, func() {
+		found := false
+		for i := range migrations {
+			if migrations[i].ID == migration.ID {
+				migrations[i] = migration
+				found = true
+			}
+		}
+		if !found {
+			migrations = append(migrations, migration)
+		}
+
+		// Migrations which can still be rolled back are kept
+		for i := 0; i < len(migrations) && len(migrations) > maxIstioUpgradeMigrations; {
+			m := migrations[i]
+			if m.Status != models.MigrationRunning && m.Status != models.MigrationPending && len(m.PreviousLabels) == 0 {
+				migrations = append(migrations[:i], migrations[i+1:]...)
+			} else {
+				i++
+			}
+		}
+	})
+}
+
+func updateMigration(migration *istioUpgradeMigration, update func()) {
+	istioUpgradeLock.Lock()
+	defer istioUpgradeLock.Unlock()
+	update()
+	if err := storeMigration(migration); err != nil {
+		log.Warningf("Unable to store the Istio upgrade migration %s: %v", migration.ID, err)
+	}
+}
+
+// copyMigration must be called holding istioUpgradeLock when the migration is running
+func copyMigration(migration *istioUpgradeMigration) *models.IstioUpgradeMigration {
+	c := migration.IstioUpgradeMigration
+	c.Batches = append([]models.MigrationBatch{}, migration.Batches...)
+	return &c
+}
@@ -0,0 +1,178 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	auth_v1 "k8s.io/api/authorization/v1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+)
+
+func TestIstioUpgradeMigrationAndRollback(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+
+	conf := config.NewConfig()
+	conf.ExternalServices.Istio.IstioCanaryRevision = config.IstioCanaryRevision{Current: "default", Upgrade: "canary"}
+	config.Set(conf)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetNamespace", "bookinfo").Return(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{
+		Name:   "bookinfo",
+		Labels: map[string]string{"istio-injection": "enabled"},
+	}}, nil)
+	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return([]core_v1.Namespace{{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}}}, nil)
+	k8s.On("UpdateNamespace", "bookinfo", mock.AnythingOfType("string")).Return(&core_v1.Namespace{}, nil)
+	layer := NewWithBackends(k8s, nil, nil)
+
+	migration := &istioUpgradeMigration{
+		IstioUpgradeMigration: models.IstioUpgradeMigration{
+			ID:      "migration-1",
+			From:    "default",
+			To:      "canary",
+			Status:  models.MigrationPending,
+			Batches: []models.MigrationBatch{{Namespaces: []string{"bookinfo"}, Status: models.MigrationPending}},
+		},
+		PreviousLabels: map[string]map[string]*string{},
+	}
+	assert.NoError(storeMigration(migration))
+	layer.IstioUpgrade.runMigration(migration)
+
+	result, err := layer.IstioUpgrade.GetMigration("migration-1")
+	assert.NoError(err)
+	assert.Equal(models.MigrationCompleted, result.Status)
+	assert.Equal(models.MigrationCompleted, result.Batches[0].Status)
+	k8s.AssertCalled(t, "UpdateNamespace", "bookinfo", `{"metadata":{"labels":{"istio-injection":null,"istio.io/rev":"canary"}}}`)
+
+	// A view only Kiali doesn't relabel the namespaces
+	conf.Deployment.ViewOnlyMode = true
+	config.Set(conf)
+	_, err = layer.IstioUpgrade.RollbackMigration("migration-1")
+	assert.True(errors.IsForbidden(err))
+	conf.Deployment.ViewOnlyMode = false
+	config.Set(conf)
+
+	// The namespace gets its previous labels back
+	result, err = layer.IstioUpgrade.RollbackMigration("migration-1")
+	assert.NoError(err)
+	assert.Equal(models.MigrationRolledBack, result.Status)
+	k8s.AssertCalled(t, "UpdateNamespace", "bookinfo", `{"metadata":{"labels":{"istio-injection":"enabled","istio.io/rev":null}}}`)
+
+	_, err = layer.IstioUpgrade.RollbackMigration("unknown")
+	assert.Error(err)
+}
+
+func TestIstioUpgradeMigrationsOfAccessibleNamespaces(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+	config.Set(config.NewConfig())
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return([]core_v1.Namespace{{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}}}, nil)
+	layer := NewWithBackends(k8s, nil, nil)
+
+	for id, namespaces := range map[string][]string{"migration-1": {"bookinfo"}, "migration-2": {"bookinfo", "restricted"}} {
+		assert.NoError(storeMigration(&istioUpgradeMigration{IstioUpgradeMigration: models.IstioUpgradeMigration{
+			ID:      id,
+			Status:  models.MigrationCompleted,
+			Batches: []models.MigrationBatch{{Namespaces: namespaces, Status: models.MigrationCompleted}},
+		}}))
+	}
+
+	migrations, err := layer.IstioUpgrade.GetMigrations()
+	assert.NoError(err)
+	assert.Len(migrations, 1)
+	assert.Equal("migration-1", migrations[0].ID)
+
+	_, err = layer.IstioUpgrade.GetMigration("migration-2")
+	assert.True(errors.IsNotFound(err))
+	_, err = layer.IstioUpgrade.RollbackMigration("migration-2")
+	assert.True(errors.IsNotFound(err))
+
+	// Requests are authorized for all the namespaces of the migration
+	namespaces, err := GetMigrationNamespaces("migration-2")
+	assert.NoError(err)
+	assert.Equal([]string{"bookinfo", "restricted"}, namespaces)
+	namespaces, err = GetMigrationNamespaces("unknown")
+	assert.NoError(err)
+	assert.Empty(namespaces)
+}
+
+func TestStartIstioUpgradeMigrationsFailsInterruptedMigrations(t *testing.T) {
+	assert := assert.New(t)
+	state := useMemoryKialiState(t)
+	config.Set(config.NewConfig())
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetPod", mock.Anything, "kiali-stopped").Return((*core_v1.Pod)(nil), errors.NewNotFound(core_v1.Resource("pods"), "kiali-stopped"))
+	k8s.On("GetPod", mock.Anything, "kiali-running").Return(&core_v1.Pod{}, nil)
+	runnerClient := istioUpgradeRunnerClient
+	istioUpgradeRunnerClient = func() (kubernetes.ClientInterface, error) { return k8s, nil }
+	defer func() { istioUpgradeRunnerClient = runnerClient }()
+
+	for id, runner := range map[string]string{"migration-1": "kiali-stopped", "migration-2": "kiali-running"} {
+		assert.NoError(storeMigration(&istioUpgradeMigration{
+			IstioUpgradeMigration: models.IstioUpgradeMigration{
+				ID:     id,
+				Status: models.MigrationRunning,
+				Batches: []models.MigrationBatch{
+					{Namespaces: []string{"bookinfo"}, Status: models.MigrationRunning},
+					{Namespaces: []string{"travels"}, Status: models.MigrationPending},
+				},
+			},
+			PreviousLabels: map[string]map[string]*string{"bookinfo": {"istio.io/rev": nil}},
+			Runner:         runner,
+		}))
+	}
+
+	StartIstioUpgradeMigrations()
+
+	migrations, err := loadMigrations()
+	assert.NoError(err)
+	assert.Len(migrations, 2)
+	for _, migration := range migrations {
+		if migration.ID == "migration-1" {
+			assert.Equal(models.MigrationFailed, migration.Status)
+			assert.Equal(models.MigrationFailed, migration.Batches[0].Status)
+			assert.Equal(models.MigrationPending, migration.Batches[1].Status)
+			// It can still be rolled back
+			assert.Contains(migration.PreviousLabels, "bookinfo")
+		} else {
+			assert.Equal(models.MigrationRunning, migration.Status)
+		}
+	}
+	assert.Contains(state.states, istioUpgradeConfigMapName)
+}
+
+func TestIstioUpgradeMigrationPermissions(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	allowed := func(allowed bool) []*auth_v1.SelfSubjectAccessReview {
+		return []*auth_v1.SelfSubjectAccessReview{{Status: auth_v1.SubjectAccessReviewStatus{Allowed: allowed}}}
+	}
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetSelfSubjectAccessReview", "bookinfo", "", "namespaces", []string{"patch"}).Return(allowed(true), nil)
+	k8s.On("GetSelfSubjectAccessReview", "bookinfo", "apps", mock.Anything, []string{"patch"}).Return(allowed(false), nil)
+	layer := NewWithBackends(k8s, nil, nil)
+
+	assert.NoError(layer.IstioUpgrade.checkMigrationPermissions([]string{"bookinfo"}, false))
+	assert.True(errors.IsForbidden(layer.IstioUpgrade.checkMigrationPermissions([]string{"bookinfo"}, true)))
+
+	conf := config.NewConfig()
+	conf.Deployment.ViewOnlyMode = true
+	config.Set(conf)
+	assert.True(errors.IsForbidden(layer.IstioUpgrade.checkMigrationPermissions([]string{"bookinfo"}, false)))
+	config.Set(config.NewConfig())
+}
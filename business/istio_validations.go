@@ -5,12 +5,12 @@ import (
 	"sync"
 
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
-	core_v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kiali/kiali/business/checkers"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/cache"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
@@ -21,6 +21,9 @@ type IstioValidationsService struct {
 	businessLayer *Layer
 }
 
+// The validations of the namespaces, by namespace and by token hash, which covers the impersonated identity
+var validationsCache = newComputedCache("validations")
+
 type ObjectChecker interface {
 	Check() models.IstioValidations
 }
@@ -46,6 +49,16 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 		}
 	}
 
+	// Namespace validations are kept until the Kiali cache observes a change of the resources they read
+	cacheKey, cacheable := in.validationsCacheKey(namespace, service)
+	var revision uint64
+	if cacheable {
+		revision = validationsRevision(namespace)
+		if cached, found := validationsCache.get(cacheKey, revision); found {
+			return cached.(models.IstioValidations).DeepCopy(), nil
+		}
+	}
+
 	// time this function execution so we can capture how long it takes to fully validate this namespace/service
 	timer := internalmetrics.GetValidationProcessingTimePrometheusTimer(namespace, service)
 	defer timer.ObserveDuration()
@@ -86,6 +99,9 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 
 	// Get group validations for same kind istio objects
 	validations := runObjectCheckers(objectCheckers)
+	if cacheable {
+		validationsCache.set(cacheKey, revision, validations.DeepCopy())
+	}
 
 	if service != "" {
 		// in.businessLayer.Svc.GetServiceList(criteria) on fetchServices performs the validations on the service
@@ -97,6 +113,30 @@ func (in *IstioValidationsService) GetValidations(namespace, service string) (mo
 	return validations, nil
 }
 
+// validationsCacheKey returns the key of the validations of a namespace in the validations cache. Validations
+// depend on the namespaces the user can access, they are only kept when all of them are cached.
+func (in *IstioValidationsService) validationsCacheKey(namespace, service string) (string, bool) {
+	if service != "" || !namespacesCached(in.businessLayer) {
+		return "", false
+	}
+	return in.k8s.GetTokenHash() + "/" + namespace, true
+}
+
+// validationsRevision returns the revision of the resources read by the validations of a namespace: the Istio
+// config, the workloads and the namespaces of all the namespaces, the registry status, the services of the
+// namespace and the ConfigMaps of the Istio namespace, holding the mesh config
+func validationsRevision(namespace string) uint64 {
+	types := make([]string, 0, len(kubernetes.PluralType)+7)
+	for _, istioType := range kubernetes.PluralType {
+		types = append(types, istioType)
+	}
+	types = append(types, cache.RegistryStatusType, cache.NamespaceType, kubernetes.PodType, kubernetes.DeploymentType,
+		kubernetes.ReplicaSetType, kubernetes.StatefulSetType, kubernetes.DaemonSetType)
+	return kialiCache.GetTypesRevision("", types...) +
+		kialiCache.GetTypesRevision(namespace, kubernetes.ServiceType) +
+		kialiCache.GetTypesRevision(config.Get().IstioNamespace, kubernetes.ConfigMapType)
+}
+
 func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioConfigList models.IstioConfigList, exportedResources kubernetes.ExportedResources, services models.ServiceList, workloadsPerNamespace map[string]models.WorkloadList, workloads models.WorkloadList, mtlsDetails kubernetes.MTLSDetails, rbacDetails kubernetes.RBACDetails, namespaces []models.Namespace, registryServices []*kubernetes.RegistryService) []ObjectChecker {
 	return []ObjectChecker{
 		checkers.NoServiceChecker{Namespace: namespace, Namespaces: namespaces, IstioConfigList: istioConfigList, ExportedResources: &exportedResources, ServiceList: services, WorkloadList: workloads, AuthorizationDetails: &rbacDetails, RegistryServices: registryServices},
@@ -106,7 +146,7 @@ func (in *IstioValidationsService) getAllObjectCheckers(namespace string, istioC
 		checkers.PeerAuthenticationChecker{PeerAuthentications: mtlsDetails.PeerAuthentications, MTLSDetails: mtlsDetails, WorkloadList: workloads},
 		checkers.ServiceEntryChecker{ServiceEntries: istioConfigList.ServiceEntries, Namespaces: namespaces, WorkloadEntries: istioConfigList.WorkloadEntries},
 		checkers.AuthorizationPolicyChecker{AuthorizationPolicies: rbacDetails.AuthorizationPolicies, Namespace: namespace, Namespaces: namespaces, ServiceList: services, ExportedServiceEntries: exportedResources.ServiceEntries, WorkloadList: workloads, MtlsDetails: mtlsDetails, VirtualServices: istioConfigList.VirtualServices, RegistryServices: registryServices},
-		checkers.SidecarChecker{Sidecars: istioConfigList.Sidecars, Namespaces: namespaces, WorkloadList: workloads, ServiceList: services, ExportedServiceEntries: exportedResources.ServiceEntries, RegistryServices: registryServices},
+		checkers.SidecarChecker{Sidecars: istioConfigList.Sidecars, Namespaces: namespaces, WorkloadList: workloads, ServiceList: services, ExportedServiceEntries: exportedResources.ServiceEntries, RegistryServices: registryServices, RootNamespaces: mtlsDetails.RootNamespaces},
 		checkers.RequestAuthenticationChecker{RequestAuthentications: istioConfigList.RequestAuthentications, WorkloadList: workloads},
 	}
 }
@@ -169,7 +209,8 @@ func (in *IstioValidationsService) GetIstioObjectValidations(namespace string, o
 		objectCheckers = []ObjectChecker{serviceEntryChecker}
 	case kubernetes.Sidecars:
 		sidecarsChecker := checkers.SidecarChecker{Sidecars: istioConfigList.Sidecars, Namespaces: namespaces,
-			WorkloadList: workloads, ServiceList: services, ExportedServiceEntries: exportedResources.ServiceEntries, RegistryServices: registryServices}
+			WorkloadList: workloads, ServiceList: services, ExportedServiceEntries: exportedResources.ServiceEntries, RegistryServices: registryServices,
+			RootNamespaces: mtlsDetails.RootNamespaces}
 		objectCheckers = []ObjectChecker{sidecarsChecker}
 	case kubernetes.AuthorizationPolicies:
 		authPoliciesChecker := checkers.AuthorizationPolicyChecker{AuthorizationPolicies: rbacDetails.AuthorizationPolicies,
@@ -425,14 +466,16 @@ func (in *IstioValidationsService) fetchNonLocalmTLSConfigs(mtlsDetails *kuberne
 	}
 
 	wg.Add(3)
+	revision := namespaceRevision(in.businessLayer, namespace)
 
 	go func(details *kubernetes.MTLSDetails) {
 		defer wg.Done()
 		criteria := IstioConfigCriteria{
-			Namespace:                  config.Get().ExternalServices.Istio.RootNamespace,
+			Namespace:                  rootNamespace(in.k8s, revision),
 			IncludePeerAuthentications: true,
 		}
 		istioConfig, err := in.businessLayer.IstioConfig.GetIstioConfigList(criteria)
+		details.RootNamespaces = rootNamespaces(in.k8s)
 		if err == nil {
 			details.MeshPeerAuthentications = istioConfig.PeerAuthentications
 		} else if !checkForbidden("fetchNonLocalmTLSConfigs", err, "probably Kiali doesn't have cluster permissions") {
@@ -456,20 +499,7 @@ func (in *IstioValidationsService) fetchNonLocalmTLSConfigs(mtlsDetails *kuberne
 
 	go func(details *kubernetes.MTLSDetails) {
 		defer wg.Done()
-		cfg := config.Get()
-
-		var istioConfig *core_v1.ConfigMap
-		var err error
-		if IsNamespaceCached(cfg.IstioNamespace) {
-			istioConfig, err = kialiCache.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
-		} else {
-			istioConfig, err = in.k8s.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
-		}
-		if err != nil {
-			errChan <- err
-			return
-		}
-		icm, err := kubernetes.GetIstioConfigMap(istioConfig)
+		icm, err := getMeshConfig(in.k8s, revision)
 		if err != nil {
 			errChan <- err
 		} else {
@@ -118,7 +118,7 @@ func mockMultiNamespaceGatewaysValidationService() IstioValidationsService {
 	k8s.On("GetNamespace", mock.AnythingOfType("string")).Return(&core_v1.Namespace{}, nil)
 	k8s.On("IsMaistraApi").Return(false)
 	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return(fakeNamespaces(), nil)
-	k8s.On("GetToken").Return("token")
+	k8s.On("GetTokenHash").Return("token")
 
 	fakeIstioObjects := []runtime.Object{}
 	istioConfigList := fakeCombinedIstioConfigList()
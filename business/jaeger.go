@@ -32,6 +32,11 @@ func (in *JaegerService) client() (jaeger.ClientInterface, error) {
 	return in.jaeger, in.loaderErr
 }
 
+// GetClient returns the Jaeger client, creating it on first use
+func (in *JaegerService) GetClient() (jaeger.ClientInterface, error) {
+	return in.client()
+}
+
 func (in *JaegerService) getFilteredSpans(ns, app string, query models.TracingQuery, filter SpanFilter) ([]jaeger.JaegerSpan, error) {
 	r, err := in.GetAppTraces(ns, app, query)
 	if err != nil {
@@ -102,6 +107,9 @@ func (in *JaegerService) GetAppTraces(ns, app string, query models.TracingQuery)
 	if err != nil {
 		return nil, err
 	}
+	if query.Paged {
+		return getAppTracesPage(client, ns, app, query)
+	}
 	r, err := client.GetAppTraces(ns, app, query)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,346 @@
+package business
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// DefaultErrorTags are the span tags used to locate error hotspots when none are requested
+var DefaultErrorTags = []string{"http.status_code", "grpc.status_code", "response_flags", "upstream_cluster", "peer.service"}
+
+// GetAppTracesAnalytics fetches the traces of an app and summarizes them: latency per operation, critical
+// path contribution per hop and error hotspots for the given tags.
+func (in *JaegerService) GetAppTracesAnalytics(ns, app string, query models.TracingQuery, errorTags []string) (*models.TraceAnalytics, error) {
+	r, err := in.GetAppTraces(ns, app, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(errorTags) == 0 {
+		errorTags = DefaultErrorTags
+	}
+	return analyzeTraces(r, errorTags), nil
+}
+
+type spanNode struct {
+	span     *jaegerModels.Span
+	service  string
+	start    int64
+	end      int64
+	parent   *spanNode
+	children []*spanNode
+}
+
+type operationKey struct {
+	service   string
+	operation string
+}
+
+type operationAcc struct {
+	errors    int
+	durations []int64
+	selfTimes []int64
+}
+
+type hopKey struct {
+	source    string
+	service   string
+	operation string
+}
+
+type hopAcc struct {
+	traces       int
+	criticalTime int64
+}
+
+type errorTagKey struct {
+	service string
+	tag     string
+	value   string
+}
+
+type errorTagAcc struct {
+	count  int
+	errors int
+}
+
+type traceAnalyzer struct {
+	errorTags  []string
+	latencies  []int64
+	operations map[operationKey]*operationAcc
+	hops       map[hopKey]*hopAcc
+	errorHits  map[errorTagKey]*errorTagAcc
+}
+
+func analyzeTraces(r *jaeger.JaegerResponse, errorTags []string) *models.TraceAnalytics {
+	a := traceAnalyzer{
+		errorTags:  errorTags,
+		operations: map[operationKey]*operationAcc{},
+		hops:       map[hopKey]*hopAcc{},
+		errorHits:  map[errorTagKey]*errorTagAcc{},
+	}
+	for i := range r.Data {
+		a.addTrace(&r.Data[i])
+	}
+	return a.result(len(r.Data))
+}
+
+// buildSpanTree links the spans of a trace to their parent, and returns them along with the root spans
+func buildSpanTree(trace *jaegerModels.Trace) (map[jaegerModels.SpanID]*spanNode, []*spanNode) {
+	nodes := make(map[jaegerModels.SpanID]*spanNode, len(trace.Spans))
+	for i := range trace.Spans {
+		span := &trace.Spans[i]
+		start := int64(span.StartTime)
+		nodes[span.SpanID] = &spanNode{span: span, service: trace.Processes[span.ProcessID].ServiceName, start: start, end: start + int64(span.Duration)}
+	}
+	roots := []*spanNode{}
+	for i := range trace.Spans {
+		node := nodes[trace.Spans[i].SpanID]
+		if parent, ok := nodes[parentSpanID(node.span)]; ok && parent != node {
+			node.parent = parent
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return nodes, roots
+}
+
+func (a *traceAnalyzer) addTrace(trace *jaegerModels.Trace) {
+	nodes, roots := buildSpanTree(trace)
+	for _, node := range nodes {
+		a.addSpan(node)
+	}
+	criticalTimes := map[hopKey]int64{}
+	for _, root := range roots {
+		a.latencies = append(a.latencies, root.end-root.start)
+		criticalPath(root, root.end, criticalTimes)
+	}
+	for key, t := range criticalTimes {
+		hop, ok := a.hops[key]
+		if !ok {
+			hop = &hopAcc{}
+			a.hops[key] = hop
+		}
+		hop.traces++
+		hop.criticalTime += t
+	}
+}
+
+func (a *traceAnalyzer) addSpan(node *spanNode) {
+	key := operationKey{service: node.service, operation: node.span.OperationName}
+	op, ok := a.operations[key]
+	if !ok {
+		op = &operationAcc{}
+		a.operations[key] = op
+	}
+	inError := spanInError(node.span)
+	if inError {
+		op.errors++
+	}
+	op.durations = append(op.durations, node.end-node.start)
+	op.selfTimes = append(op.selfTimes, selfTime(node))
+
+	for _, tag := range node.span.Tags {
+		for _, errorTag := range a.errorTags {
+			if tag.Key != errorTag {
+				continue
+			}
+			tagKey := errorTagKey{service: node.service, tag: tag.Key, value: fmt.Sprintf("%v", tag.Value)}
+			hit, ok := a.errorHits[tagKey]
+			if !ok {
+				hit = &errorTagAcc{}
+				a.errorHits[tagKey] = hit
+			}
+			hit.count++
+			if inError {
+				hit.errors++
+			}
+		}
+	}
+}
+
+// criticalPath attributes the time of the critical path of a span, up to the given end, to the hops
+// it goes through. Walking back from the end, the time goes to the last finishing child overlapping
+// the remaining interval, recursively, and the gaps between children to the span itself.
+func criticalPath(node *spanNode, end int64, times map[hopKey]int64) {
+	children := make([]*spanNode, len(node.children))
+	copy(children, node.children)
+	sort.Slice(children, func(i, j int) bool { return children[i].end > children[j].end })
+
+	cursor := end
+	for _, child := range children {
+		if cursor <= node.start {
+			break
+		}
+		if child.start >= cursor {
+			continue
+		}
+		childEnd := child.end
+		if childEnd > cursor {
+			childEnd = cursor
+		}
+		times[hopOf(node)] += cursor - childEnd
+		criticalPath(child, childEnd, times)
+		cursor = child.start
+	}
+	if cursor > node.start {
+		times[hopOf(node)] += cursor - node.start
+	}
+}
+
+func hopOf(node *spanNode) hopKey {
+	key := hopKey{service: node.service, operation: node.span.OperationName}
+	if node.parent != nil {
+		key.source = node.parent.service
+	}
+	return key
+}
+
+// selfTime is the span duration not covered by any of its children
+func selfTime(node *spanNode) int64 {
+	children := make([]*spanNode, len(node.children))
+	copy(children, node.children)
+	sort.Slice(children, func(i, j int) bool { return children[i].start < children[j].start })
+
+	covered := int64(0)
+	cursor := node.start
+	for _, child := range children {
+		start, end := child.start, child.end
+		if start < cursor {
+			start = cursor
+		}
+		if end > node.end {
+			end = node.end
+		}
+		if end > start {
+			covered += end - start
+			cursor = end
+		}
+	}
+	return (node.end - node.start) - covered
+}
+
+func parentSpanID(span *jaegerModels.Span) jaegerModels.SpanID {
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.ChildOf {
+			return ref.SpanID
+		}
+	}
+	return span.ParentSpanID
+}
+
+func spanInError(span *jaegerModels.Span) bool {
+	for _, tag := range span.Tags {
+		if tag.Key == "error" {
+			return fmt.Sprintf("%v", tag.Value) == "true"
+		}
+	}
+	return false
+}
+
+func (a *traceAnalyzer) result(tracesCount int) *models.TraceAnalytics {
+	result := models.TraceAnalytics{
+		TracesCount:   tracesCount,
+		Latency:       durationStats(a.latencies),
+		Operations:    []models.OperationStats{},
+		Hops:          []models.HopContribution{},
+		ErrorHotspots: []models.ErrorHotspot{},
+	}
+
+	for key, op := range a.operations {
+		result.Operations = append(result.Operations, models.OperationStats{
+			Service:    key.service,
+			Operation:  key.operation,
+			Count:      len(op.durations),
+			ErrorCount: op.errors,
+			Duration:   durationStats(op.durations),
+			SelfTime:   durationStats(op.selfTimes),
+		})
+	}
+	sort.Slice(result.Operations, func(i, j int) bool {
+		if result.Operations[i].Service != result.Operations[j].Service {
+			return result.Operations[i].Service < result.Operations[j].Service
+		}
+		return result.Operations[i].Operation < result.Operations[j].Operation
+	})
+
+	totalLatency := int64(0)
+	for _, l := range a.latencies {
+		totalLatency += l
+	}
+	for key, hop := range a.hops {
+		contribution := models.HopContribution{
+			Source:          key.source,
+			Service:         key.service,
+			Operation:       key.operation,
+			Traces:          hop.traces,
+			AvgCriticalTime: float64(hop.criticalTime) / float64(hop.traces),
+		}
+		if totalLatency > 0 {
+			contribution.LatencyShare = float64(hop.criticalTime) / float64(totalLatency)
+		}
+		result.Hops = append(result.Hops, contribution)
+	}
+	sort.Slice(result.Hops, func(i, j int) bool {
+		hi, hj := result.Hops[i], result.Hops[j]
+		if hi.LatencyShare != hj.LatencyShare {
+			return hi.LatencyShare > hj.LatencyShare
+		}
+		return hi.Source+hi.Service+hi.Operation < hj.Source+hj.Service+hj.Operation
+	})
+
+	for key, hit := range a.errorHits {
+		if hit.errors == 0 {
+			continue
+		}
+		result.ErrorHotspots = append(result.ErrorHotspots, models.ErrorHotspot{
+			Service:    key.service,
+			Tag:        key.tag,
+			Value:      key.value,
+			Count:      hit.count,
+			ErrorCount: hit.errors,
+			ErrorRate:  float64(hit.errors) / float64(hit.count),
+		})
+	}
+	sort.Slice(result.ErrorHotspots, func(i, j int) bool {
+		hi, hj := result.ErrorHotspots[i], result.ErrorHotspots[j]
+		if hi.ErrorCount != hj.ErrorCount {
+			return hi.ErrorCount > hj.ErrorCount
+		}
+		return hi.Service+hi.Tag+hi.Value < hj.Service+hj.Tag+hj.Value
+	})
+	return &result
+}
+
+func durationStats(durations []int64) models.DurationStats {
+	if len(durations) == 0 {
+		return models.DurationStats{}
+	}
+	sorted := make([]int64, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	sum := int64(0)
+	for _, d := range sorted {
+		sum += d
+	}
+	return models.DurationStats{
+		Avg: float64(sum) / float64(len(sorted)),
+		P50: percentile(sorted, 0.5),
+		P95: percentile(sorted, 0.95),
+		P99: percentile(sorted, 0.99),
+	}
+}
+
+// percentile uses the nearest-rank method on sorted values
+func percentile(sorted []int64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return float64(sorted[rank])
+}
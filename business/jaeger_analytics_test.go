@@ -0,0 +1,119 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+func analyticsSpan(id, parent, process, operation string, start, end uint64, tags ...jaegerModels.KeyValue) jaegerModels.Span {
+	span := jaegerModels.Span{
+		SpanID:        jaegerModels.SpanID(id),
+		ProcessID:     jaegerModels.ProcessID(process),
+		OperationName: operation,
+		StartTime:     start,
+		Duration:      end - start,
+		Tags:          tags,
+	}
+	if parent != "" {
+		span.References = []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, SpanID: jaegerModels.SpanID(parent)}}
+	}
+	return span
+}
+
+// productpage [0, 800] calls reviews [50, 650] and details [60, 160] in parallel,
+// reviews calls ratings twice in sequence, the first call [100, 200] fails
+var analyticsTrace = jaegerModels.Trace{
+	TraceID: "t1",
+	Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+		"p1": {ServiceName: "productpage"},
+		"p2": {ServiceName: "reviews"},
+		"p3": {ServiceName: "ratings"},
+		"p4": {ServiceName: "details"},
+	},
+	Spans: []jaegerModels.Span{
+		analyticsSpan("a", "", "p1", "GET /productpage", 0, 800),
+		analyticsSpan("b", "a", "p2", "GET /reviews", 50, 650),
+		analyticsSpan("c", "b", "p3", "GET /ratings", 100, 200, jaegerModels.KeyValue{Key: "error", Value: true}, jaegerModels.KeyValue{Key: "http.status_code", Value: 503}),
+		analyticsSpan("d", "b", "p3", "GET /ratings", 300, 600, jaegerModels.KeyValue{Key: "http.status_code", Value: 200}),
+		analyticsSpan("e", "a", "p4", "GET /details", 60, 160),
+	},
+}
+
+func findOperation(analytics *models.TraceAnalytics, service string) *models.OperationStats {
+	for i := range analytics.Operations {
+		if analytics.Operations[i].Service == service {
+			return &analytics.Operations[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzeTracesCriticalPath(t *testing.T) {
+	assert := assert.New(t)
+
+	analytics := analyzeTraces(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{analyticsTrace}}, DefaultErrorTags)
+
+	assert.Equal(1, analytics.TracesCount)
+	assert.Equal(800.0, analytics.Latency.P99)
+	// the critical path goes through productpage, reviews and both ratings calls, but not details
+	assert.Equal([]models.HopContribution{
+		{Source: "reviews", Service: "ratings", Operation: "GET /ratings", Traces: 1, AvgCriticalTime: 400, LatencyShare: 0.5},
+		{Source: "", Service: "productpage", Operation: "GET /productpage", Traces: 1, AvgCriticalTime: 200, LatencyShare: 0.25},
+		{Source: "productpage", Service: "reviews", Operation: "GET /reviews", Traces: 1, AvgCriticalTime: 200, LatencyShare: 0.25},
+	}, analytics.Hops)
+}
+
+func TestAnalyzeTracesOperations(t *testing.T) {
+	assert := assert.New(t)
+
+	short := jaegerModels.Trace{
+		TraceID:   "t2",
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{"p1": {ServiceName: "productpage"}},
+		Spans:     []jaegerModels.Span{analyticsSpan("a", "", "p1", "GET /productpage", 0, 100)},
+	}
+	analytics := analyzeTraces(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{analyticsTrace, short}}, DefaultErrorTags)
+
+	assert.Equal(models.DurationStats{Avg: 450, P50: 100, P95: 800, P99: 800}, analytics.Latency)
+	assert.Len(analytics.Operations, 4)
+
+	productpage := findOperation(analytics, "productpage")
+	assert.Equal(2, productpage.Count)
+	assert.Equal(models.DurationStats{Avg: 450, P50: 100, P95: 800, P99: 800}, productpage.Duration)
+	// reviews and details overlap: 600µs are spent in children
+	assert.Equal(models.DurationStats{Avg: 150, P50: 100, P95: 200, P99: 200}, productpage.SelfTime)
+
+	reviews := findOperation(analytics, "reviews")
+	assert.Equal(200.0, reviews.SelfTime.P50)
+
+	ratings := findOperation(analytics, "ratings")
+	assert.Equal(2, ratings.Count)
+	assert.Equal(1, ratings.ErrorCount)
+	assert.Equal(models.DurationStats{Avg: 200, P50: 100, P95: 300, P99: 300}, ratings.Duration)
+}
+
+func TestAnalyzeTracesErrorHotspots(t *testing.T) {
+	assert := assert.New(t)
+
+	analytics := analyzeTraces(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{analyticsTrace}}, DefaultErrorTags)
+	assert.Equal([]models.ErrorHotspot{
+		{Service: "ratings", Tag: "http.status_code", Value: "503", Count: 1, ErrorCount: 1, ErrorRate: 1},
+	}, analytics.ErrorHotspots)
+
+	analytics = analyzeTraces(&jaeger.JaegerResponse{Data: []jaegerModels.Trace{analyticsTrace}}, []string{"response_flags"})
+	assert.Empty(analytics.ErrorHotspots)
+}
+
+func TestAnalyzeTracesEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	analytics := analyzeTraces(&jaeger.JaegerResponse{}, DefaultErrorTags)
+	assert.Equal(0, analytics.TracesCount)
+	assert.Empty(analytics.Operations)
+	assert.Empty(analytics.Hops)
+	assert.Equal(models.DurationStats{}, analytics.Latency)
+}
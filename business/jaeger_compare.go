@@ -0,0 +1,157 @@
+package business
+
+import (
+	"math"
+	"sort"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+// fanOutThreshold is the minimal change of the average number of calls per trace reported as a fan-out change
+const fanOutThreshold = 0.5
+
+// CompareTraces compares two traces, typically a "good" and a "bad" one
+func (in *JaegerService) CompareTraces(baselineID, candidateID string) (*models.TraceComparison, error) {
+	traces := make([]jaegerModels.Trace, 0, 2)
+	for _, id := range []string{baselineID, candidateID} {
+		trace, err := in.GetJaegerTraceDetail(id)
+		if err != nil {
+			return nil, err
+		}
+		if trace == nil {
+			return nil, kubernetes.NewNotFound(id, "Jaeger", "Trace")
+		}
+		traces = append(traces, trace.Data)
+	}
+	return compareTraces(traces[:1], traces[1:]), nil
+}
+
+// CompareAppTraces compares the traces of an app in two time windows, e.g. before and after a deployment
+func (in *JaegerService) CompareAppTraces(ns, app string, baseline, candidate models.TracingQuery) (*models.TraceComparison, error) {
+	baselineTraces, err := in.GetAppTraces(ns, app, baseline)
+	if err != nil {
+		return nil, err
+	}
+	candidateTraces, err := in.GetAppTraces(ns, app, candidate)
+	if err != nil {
+		return nil, err
+	}
+	return compareTraces(baselineTraces.Data, candidateTraces.Data), nil
+}
+
+type callKey struct {
+	parentService   string
+	parentOperation string
+	service         string
+	operation       string
+}
+
+type callAcc struct {
+	count    int
+	duration int64
+}
+
+// tracesProfile aggregates the calls of a population of traces
+type tracesProfile struct {
+	traces  int
+	latency int64
+	calls   map[callKey]*callAcc
+}
+
+func profileTraces(traces []jaegerModels.Trace) tracesProfile {
+	profile := tracesProfile{traces: len(traces), calls: map[callKey]*callAcc{}}
+	for i := range traces {
+		nodes, roots := buildSpanTree(&traces[i])
+		for _, root := range roots {
+			profile.latency += root.end - root.start
+		}
+		for _, node := range nodes {
+			key := callKey{service: node.service, operation: node.span.OperationName}
+			if node.parent != nil {
+				key.parentService = node.parent.service
+				key.parentOperation = node.parent.span.OperationName
+			}
+			call, ok := profile.calls[key]
+			if !ok {
+				call = &callAcc{}
+				profile.calls[key] = call
+			}
+			call.count++
+			call.duration += node.end - node.start
+		}
+	}
+	return profile
+}
+
+func (p tracesProfile) summary() models.TracesSummary {
+	summary := models.TracesSummary{Traces: p.traces}
+	if p.traces > 0 {
+		summary.AvgLatency = float64(p.latency) / float64(p.traces)
+	}
+	return summary
+}
+
+// callStats returns the average number of calls per trace and the average duration per call
+func (p tracesProfile) callStats(key callKey) (float64, float64) {
+	call, ok := p.calls[key]
+	if !ok || p.traces == 0 {
+		return 0, 0
+	}
+	return float64(call.count) / float64(p.traces), float64(call.duration) / float64(call.count)
+}
+
+func compareTraces(baselineTraces, candidateTraces []jaegerModels.Trace) *models.TraceComparison {
+	baseline := profileTraces(baselineTraces)
+	candidate := profileTraces(candidateTraces)
+	comparison := models.TraceComparison{
+		Baseline:  baseline.summary(),
+		Candidate: candidate.summary(),
+		Calls:     []models.CallComparison{},
+	}
+	comparison.LatencyDelta = comparison.Candidate.AvgLatency - comparison.Baseline.AvgLatency
+
+	keys := map[callKey]bool{}
+	for key := range baseline.calls {
+		keys[key] = true
+	}
+	for key := range candidate.calls {
+		keys[key] = true
+	}
+	for key := range keys {
+		call := models.CallComparison{
+			ParentService:   key.parentService,
+			ParentOperation: key.parentOperation,
+			Service:         key.service,
+			Operation:       key.operation,
+		}
+		call.BaselineCalls, call.BaselineDuration = baseline.callStats(key)
+		call.CandidateCalls, call.CandidateDuration = candidate.callStats(key)
+		switch {
+		case call.BaselineCalls == 0:
+			call.Status = models.CallAdded
+		case call.CandidateCalls == 0:
+			call.Status = models.CallMissing
+		case math.Abs(call.CandidateCalls-call.BaselineCalls) >= fanOutThreshold:
+			call.Status = models.CallFanOut
+			call.DurationDelta = call.CandidateDuration - call.BaselineDuration
+		default:
+			call.Status = models.CallUnchanged
+			call.DurationDelta = call.CandidateDuration - call.BaselineDuration
+		}
+		comparison.Calls = append(comparison.Calls, call)
+	}
+
+	sort.Slice(comparison.Calls, func(i, j int) bool {
+		ci, cj := comparison.Calls[i], comparison.Calls[j]
+		if si, sj := ci.Status == models.CallUnchanged, cj.Status == models.CallUnchanged; si != sj {
+			return sj
+		}
+		if di, dj := math.Abs(ci.DurationDelta), math.Abs(cj.DurationDelta); di != dj {
+			return di > dj
+		}
+		return ci.ParentService+ci.ParentOperation+ci.Service+ci.Operation < cj.ParentService+cj.ParentOperation+cj.Service+cj.Operation
+	})
+	return &comparison
+}
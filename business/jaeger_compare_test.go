@@ -0,0 +1,80 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// Compared to analyticsTrace: details is not called anymore, reviews calls ratings 3 times and calls a cache
+var candidateTrace = jaegerModels.Trace{
+	TraceID: "t2",
+	Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+		"p1": {ServiceName: "productpage"},
+		"p2": {ServiceName: "reviews"},
+		"p3": {ServiceName: "ratings"},
+		"p5": {ServiceName: "redis"},
+	},
+	Spans: []jaegerModels.Span{
+		analyticsSpan("a", "", "p1", "GET /productpage", 0, 1000),
+		analyticsSpan("b", "a", "p2", "GET /reviews", 50, 950),
+		analyticsSpan("c", "b", "p3", "GET /ratings", 100, 300),
+		analyticsSpan("d", "b", "p3", "GET /ratings", 300, 500),
+		analyticsSpan("e", "b", "p3", "GET /ratings", 500, 700),
+		analyticsSpan("f", "b", "p5", "GET", 700, 720),
+	},
+}
+
+func TestCompareTraces(t *testing.T) {
+	assert := assert.New(t)
+
+	comparison := compareTraces([]jaegerModels.Trace{analyticsTrace}, []jaegerModels.Trace{candidateTrace})
+
+	assert.Equal(models.TracesSummary{Traces: 1, AvgLatency: 800}, comparison.Baseline)
+	assert.Equal(models.TracesSummary{Traces: 1, AvgLatency: 1000}, comparison.Candidate)
+	assert.Equal(200.0, comparison.LatencyDelta)
+	// structural differences come first
+	assert.Equal([]models.CallComparison{
+		{ParentService: "productpage", ParentOperation: "GET /productpage", Service: "details", Operation: "GET /details", Status: models.CallMissing,
+			BaselineCalls: 1, BaselineDuration: 100},
+		{ParentService: "reviews", ParentOperation: "GET /reviews", Service: "ratings", Operation: "GET /ratings", Status: models.CallFanOut,
+			BaselineCalls: 2, CandidateCalls: 3, BaselineDuration: 200, CandidateDuration: 200, DurationDelta: 0},
+		{ParentService: "reviews", ParentOperation: "GET /reviews", Service: "redis", Operation: "GET", Status: models.CallAdded,
+			CandidateCalls: 1, CandidateDuration: 20},
+		{ParentService: "productpage", ParentOperation: "GET /productpage", Service: "reviews", Operation: "GET /reviews", Status: models.CallUnchanged,
+			BaselineCalls: 1, CandidateCalls: 1, BaselineDuration: 600, CandidateDuration: 900, DurationDelta: 300},
+		{Service: "productpage", Operation: "GET /productpage", Status: models.CallUnchanged,
+			BaselineCalls: 1, CandidateCalls: 1, BaselineDuration: 800, CandidateDuration: 1000, DurationDelta: 200},
+	}, comparison.Calls)
+}
+
+func TestComparePopulations(t *testing.T) {
+	assert := assert.New(t)
+
+	// calls per trace change by 0.5 on average, for ratings and details
+	comparison := compareTraces([]jaegerModels.Trace{analyticsTrace, analyticsTrace}, []jaegerModels.Trace{analyticsTrace, candidateTrace})
+	assert.Equal(2, comparison.Candidate.Traces)
+	assert.Equal(100.0, comparison.LatencyDelta)
+	for _, call := range comparison.Calls {
+		switch call.Service {
+		case "ratings":
+			assert.Equal(models.CallFanOut, call.Status)
+			assert.Equal(2.5, call.CandidateCalls)
+		case "reviews":
+			assert.Equal(models.CallUnchanged, call.Status)
+			assert.Equal(150.0, call.DurationDelta)
+		case "details":
+			assert.Equal(models.CallFanOut, call.Status)
+			assert.Equal(0.5, call.CandidateCalls)
+		}
+	}
+
+	comparison = compareTraces([]jaegerModels.Trace{analyticsTrace}, []jaegerModels.Trace{})
+	assert.Equal(0.0, comparison.Candidate.AvgLatency)
+	for _, call := range comparison.Calls {
+		assert.Equal(models.CallMissing, call.Status)
+	}
+}
@@ -0,0 +1,116 @@
+package business
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// The traces of an app returned on the previous pages may fill the limit of a query, it's then raised up to this
+// number of times to find the traces of the page
+const maxTracesPageAttempts = 4
+
+// getAppTracesPage fetches a page of traces, from the most recent, and sets the cursor of the next page.
+// Unlike the split & join mode, walking the pages returns all the traces matching the query.
+// Backends select and order the traces by the start of the spans of the app, the cursor is the start of the
+// most recent span of the app of the last trace of the page.
+func getAppTracesPage(client jaeger.ClientInterface, ns, app string, query models.TracingQuery) (*jaeger.JaegerResponse, error) {
+	q := query
+	skip := map[jaegerModels.TraceID]bool{}
+	if query.Cursor != nil {
+		// The end is inclusive: the traces already returned that started at that time are fetched again, then skipped
+		q.End = time.Unix(0, int64(query.Cursor.StartMicros)*int64(time.Microsecond))
+		q.Limit = query.Limit + len(query.Cursor.TraceIDs)
+		for _, id := range query.Cursor.TraceIDs {
+			skip[jaegerModels.TraceID(id)] = true
+		}
+	}
+
+	var r *jaeger.JaegerResponse
+	var page []jaegerModels.Trace
+	var starts map[jaegerModels.TraceID]uint64
+	more := false
+	for attempt := 1; ; attempt++ {
+		var err error
+		r, err = client.GetAppTraces(ns, app, q)
+		if err != nil {
+			return nil, err
+		}
+		more = len(r.Data) >= q.Limit
+
+		page = make([]jaegerModels.Trace, 0, len(r.Data))
+		starts = make(map[jaegerModels.TraceID]uint64, len(r.Data))
+		for _, trace := range r.Data {
+			start := appSpansStart(&trace, app, r.JaegerServiceName, query.Operation)
+			// Traces of the app more recent than the cursor were returned by the previous pages
+			if skip[trace.TraceID] || (query.Cursor != nil && start > query.Cursor.StartMicros) {
+				continue
+			}
+			starts[trace.TraceID] = start
+			page = append(page, trace)
+		}
+		if len(page) >= query.Limit || !more || attempt == maxTracesPageAttempts {
+			break
+		}
+		q.Limit *= 2
+	}
+
+	sort.SliceStable(page, func(i, j int) bool {
+		si, sj := starts[page[i].TraceID], starts[page[j].TraceID]
+		if si != sj {
+			return si > sj
+		}
+		return page[i].TraceID < page[j].TraceID
+	})
+	if len(page) > query.Limit {
+		page = page[:query.Limit]
+		more = true
+	}
+	r.Data = page
+	r.Cursor = ""
+
+	if more && len(page) > 0 {
+		cursor := models.TracesCursor{StartMicros: starts[page[len(page)-1].TraceID]}
+		if query.Cursor != nil && query.Cursor.StartMicros == cursor.StartMicros {
+			cursor.TraceIDs = append(cursor.TraceIDs, query.Cursor.TraceIDs...)
+		}
+		for i := range page {
+			if starts[page[i].TraceID] == cursor.StartMicros {
+				cursor.TraceIDs = append(cursor.TraceIDs, string(page[i].TraceID))
+			}
+		}
+		r.Cursor = cursor.Encode()
+	}
+	return r, nil
+}
+
+// appSpansStart returns the start time of the most recent span of the app, of the operation when there's one,
+// in microseconds. It's the start time of the most recent span of the trace when the app has no span.
+func appSpansStart(trace *jaegerModels.Trace, app, serviceName, operation string) uint64 {
+	var appStart, operationStart, start uint64
+	for _, span := range trace.Spans {
+		if span.StartTime > start {
+			start = span.StartTime
+		}
+		process, found := trace.Processes[span.ProcessID]
+		if !found || (process.ServiceName != app && process.ServiceName != serviceName) {
+			continue
+		}
+		if span.StartTime > appStart {
+			appStart = span.StartTime
+		}
+		if operation != "" && span.OperationName == operation && span.StartTime > operationStart {
+			operationStart = span.StartTime
+		}
+	}
+	if operationStart != 0 {
+		return operationStart
+	}
+	if appStart != 0 {
+		return appStart
+	}
+	return start
+}
@@ -0,0 +1,139 @@
+package business
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// fakeTracesBackend returns the traces having spans of the app in the query range, up to the limit. Like Jaeger,
+// the most recent traces are returned, by the start of the most recent span of the app in the range.
+type fakeTracesBackend struct {
+	jaeger.ClientInterface
+	traces  []jaegerModels.Trace
+	queries []models.TracingQuery
+}
+
+func (f *fakeTracesBackend) GetAppTraces(ns, app string, q models.TracingQuery) (*jaeger.JaegerResponse, error) {
+	f.queries = append(f.queries, q)
+	r := jaeger.JaegerResponse{Data: []jaegerModels.Trace{}, JaegerServiceName: app}
+	ranks := map[jaegerModels.TraceID]uint64{}
+	for _, trace := range f.traces {
+		for _, span := range trace.Spans {
+			start := time.Unix(0, int64(span.StartTime)*int64(time.Microsecond))
+			if trace.Processes[span.ProcessID].ServiceName == app && !start.Before(q.Start) && !start.After(q.End) && span.StartTime >= ranks[trace.TraceID] {
+				ranks[trace.TraceID] = span.StartTime
+			}
+		}
+		if _, found := ranks[trace.TraceID]; found {
+			r.Data = append(r.Data, trace)
+		}
+	}
+	sort.Slice(r.Data, func(i, j int) bool {
+		ri, rj := ranks[r.Data[i].TraceID], ranks[r.Data[j].TraceID]
+		if ri != rj {
+			return ri > rj
+		}
+		return r.Data[i].TraceID < r.Data[j].TraceID
+	})
+	if len(r.Data) > q.Limit {
+		r.Data = r.Data[:q.Limit]
+	}
+	return &r, nil
+}
+
+// pagingTrace returns a trace with spans of the reviews app started at the given times, called by productpage
+// before them
+func pagingTrace(id string, appStartsMicros ...uint64) jaegerModels.Trace {
+	trace := jaegerModels.Trace{
+		TraceID:   jaegerModels.TraceID(id),
+		Spans:     []jaegerModels.Span{{StartTime: appStartsMicros[0] - 50, ProcessID: "p1"}},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{"p1": {ServiceName: "productpage"}, "p2": {ServiceName: "reviews"}},
+	}
+	for _, start := range appStartsMicros {
+		trace.Spans = append(trace.Spans, jaegerModels.Span{StartTime: start, ProcessID: "p2"})
+	}
+	return trace
+}
+
+func traceIDs(r *jaeger.JaegerResponse) []string {
+	ids := []string{}
+	for _, trace := range r.Data {
+		ids = append(ids, string(trace.TraceID))
+	}
+	return ids
+}
+
+func TestGetAppTracesPaged(t *testing.T) {
+	assert := assert.New(t)
+
+	backend := &fakeTracesBackend{traces: []jaegerModels.Trace{
+		pagingTrace("c", 200), pagingTrace("old", 100), pagingTrace("a", 200), pagingTrace("new", 300), pagingTrace("b", 200),
+	}}
+	svc := JaegerService{jaeger: backend}
+	query := models.TracingQuery{Start: time.Unix(0, 0), End: time.Unix(1, 0), Limit: 2, Paged: true}
+
+	r, err := svc.GetAppTraces("bookinfo", "reviews", query)
+	assert.NoError(err)
+	assert.Equal([]string{"new", "a"}, traceIDs(r))
+	assert.NotEmpty(r.Cursor)
+
+	// all the remaining traces started at the same time as the cursor
+	query.Cursor, err = models.DecodeTracesCursor(r.Cursor)
+	assert.NoError(err)
+	assert.Equal(models.TracesCursor{StartMicros: 200, TraceIDs: []string{"a"}}, *query.Cursor)
+	r, err = svc.GetAppTraces("bookinfo", "reviews", query)
+	assert.NoError(err)
+	assert.Equal([]string{"b", "c"}, traceIDs(r))
+	assert.Equal(3, backend.queries[1].Limit)
+	assert.Equal(time.Unix(0, 200000), backend.queries[1].End)
+
+	query.Cursor, err = models.DecodeTracesCursor(r.Cursor)
+	assert.NoError(err)
+	assert.Equal([]string{"a", "b", "c"}, query.Cursor.TraceIDs)
+	r, err = svc.GetAppTraces("bookinfo", "reviews", query)
+	assert.NoError(err)
+	assert.Equal([]string{"old"}, traceIDs(r))
+	assert.Empty(r.Cursor)
+}
+
+func TestGetAppTracesPagedByAppSpans(t *testing.T) {
+	assert := assert.New(t)
+
+	// The trace "long" is found by its most recent span of the app, which is more recent than the spans of "a"
+	backend := &fakeTracesBackend{traces: []jaegerModels.Trace{
+		pagingTrace("a", 200), pagingTrace("long", 150, 250), pagingTrace("b", 100),
+	}}
+	svc := JaegerService{jaeger: backend}
+	query := models.TracingQuery{Start: time.Unix(0, 0), End: time.Unix(1, 0), Limit: 1, Paged: true}
+
+	var ids []string
+	for page := 0; page < 5; page++ {
+		r, err := svc.GetAppTraces("bookinfo", "reviews", query)
+		assert.NoError(err)
+		ids = append(ids, traceIDs(r)...)
+		if r.Cursor == "" {
+			break
+		}
+		query.Cursor, err = models.DecodeTracesCursor(r.Cursor)
+		assert.NoError(err)
+	}
+	assert.Equal([]string{"long", "a", "b"}, ids)
+
+	// The traces of the previous pages filled the limit of the last page, it was raised to find "b"
+	assert.Len(backend.queries, 4)
+	assert.Equal(4, backend.queries[3].Limit)
+}
+
+func TestSearchTags(t *testing.T) {
+	q := models.TracingQuery{Tags: map[string]string{"foo": "bar"}, HTTPStatusCode: "503", ErrorOnly: true}
+	assert.Equal(t, map[string]string{"foo": "bar", "http.status_code": "503", "error": "true"}, q.SearchTags())
+	// the query tags are unchanged
+	assert.Len(t, q.Tags, 1)
+}
@@ -0,0 +1,131 @@
+package business
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/cache"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+// Reads that follow a change made through Kiali wait up to this timeout for the Kiali cache to observe it
+const cacheChangeTimeout = 2 * time.Second
+
+// Results computed from the Kiali cache are kept for this number of keys at most, the least recently used are
+// dropped first
+const maxComputedResults = 500
+
+// computedCache keeps results computed from the Kiali cache until its informers observe a change of the resources
+// they were computed from. Every result is kept with the revision of these resources, given by the caller, a result
+// computed at another revision is never served. Results also expire after the resync period of the Kiali cache, as
+// some of the resources they read may not be cached.
+type computedCache struct {
+	name    string
+	lock    sync.Mutex
+	source  cache.KialiCache
+	results map[string]*list.Element
+	lru     *list.List // front is the most recently used
+}
+
+type computedResult struct {
+	key      string
+	revision uint64
+	created  time.Time
+	result   interface{}
+}
+
+func newComputedCache(name string) *computedCache {
+	return &computedCache{name: name, results: map[string]*list.Element{}, lru: list.New()}
+}
+
+// get returns the result stored for the key, when it was computed at the given revision
+func (cc *computedCache) get(key string, revision uint64) (interface{}, bool) {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+	cc.reset()
+	elem, found := cc.results[key]
+	if found {
+		computed := elem.Value.(*computedResult)
+		ttl := time.Duration(config.Get().KubernetesConfig.CacheDuration) * time.Second
+		if computed.revision != revision || time.Since(computed.created) > ttl {
+			cc.remove(elem)
+			found = false
+		}
+	}
+	if !found {
+		internalmetrics.GetKialiCacheMissesMetric(cc.name).Inc()
+		return nil, false
+	}
+	internalmetrics.GetKialiCacheHitsMetric(cc.name).Inc()
+	cc.lru.MoveToFront(elem)
+	return elem.Value.(*computedResult).result, true
+}
+
+// set stores a result computed at the given revision, taken before computing it. A result outdated by a change
+// observed meanwhile is not served, get is called with the new revision.
+func (cc *computedCache) set(key string, revision uint64, result interface{}) {
+	cc.lock.Lock()
+	defer cc.lock.Unlock()
+	cc.reset()
+	if elem, found := cc.results[key]; found {
+		cc.remove(elem)
+	}
+	cc.results[key] = cc.lru.PushFront(&computedResult{key: key, revision: revision, created: time.Now(), result: result})
+	for cc.lru.Len() > maxComputedResults {
+		cc.remove(cc.lru.Back())
+	}
+}
+
+func (cc *computedCache) remove(elem *list.Element) {
+	delete(cc.results, cc.lru.Remove(elem).(*computedResult).key)
+}
+
+// reset drops the results computed from another Kiali cache
+func (cc *computedCache) reset() {
+	if kialiCache != cc.source {
+		cc.source = kialiCache
+		cc.results = map[string]*list.Element{}
+		cc.lru.Init()
+	}
+}
+
+// namespacesCached tells whether all the namespaces of the user are cached, so the results computed across them
+// can be kept until the Kiali cache observes a change
+func namespacesCached(layer *Layer) bool {
+	if kialiCache == nil || layer == nil {
+		return false
+	}
+	namespaces, err := layer.Namespace.GetNamespaces()
+	if err != nil {
+		return false
+	}
+	for _, ns := range namespaces {
+		if !kialiCache.CheckNamespace(ns.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheTypeRevision returns the revision of a resource type in a namespace, taken before a change made through
+// Kiali to wait for it with waitForCacheChange
+func cacheTypeRevision(namespace, resourceType string) uint64 {
+	if kialiCache == nil {
+		return 0
+	}
+	return kialiCache.GetTypeRevision(namespace, resourceType)
+}
+
+// waitForCacheChange waits for the Kiali cache to observe a change made through Kiali, so the reads that follow
+// it are consistent with it
+func waitForCacheChange(namespace, resourceType string, revision uint64) {
+	if kialiCache == nil || !kialiCache.CheckNamespace(namespace) {
+		return
+	}
+	if !kialiCache.WaitForTypeChange(namespace, resourceType, revision, cacheChangeTimeout) {
+		log.Debugf("Kiali cache didn't observe a change of [resource: %s] in [namespace: %s] in %v", resourceType, namespace, cacheChangeTimeout)
+	}
+}
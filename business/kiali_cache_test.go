@@ -0,0 +1,120 @@
+package business
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/cache"
+	"github.com/kiali/kiali/models"
+)
+
+func TestComputedCacheInvalidation(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(previous cache.KialiCache) { kialiCache = previous }(kialiCache)
+	kialiCache = cache.FakeGatewaysKialiCache(nil)
+	cc := newComputedCache("test")
+
+	_, found := cc.get("bookinfo", 1)
+	assert.False(found)
+	cc.set("bookinfo", 1, "result")
+	result, found := cc.get("bookinfo", 1)
+	assert.True(found)
+	assert.Equal("result", result)
+
+	// A change of the resources of a namespace doesn't invalidate the results of the others
+	cc.set("travels", 1, "travels")
+	_, found = cc.get("bookinfo", 2)
+	assert.False(found)
+	result, found = cc.get("travels", 1)
+	assert.True(found)
+	assert.Equal("travels", result)
+
+	// Results computed before a change are not served after it
+	cc.set("bookinfo", 2, "result")
+	_, found = cc.get("bookinfo", 3)
+	assert.False(found)
+
+	// Results of another cache are not served
+	cc.set("bookinfo", 3, "result")
+	kialiCache = cache.FakeGatewaysKialiCache(nil)
+	_, found = cc.get("bookinfo", 3)
+	assert.False(found)
+}
+
+func TestComputedCacheBounds(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(previous cache.KialiCache) { kialiCache = previous }(kialiCache)
+	kialiCache = cache.FakeGatewaysKialiCache(nil)
+	defer config.Set(config.Get())
+	conf := config.NewConfig()
+	config.Set(conf)
+	cc := newComputedCache("test")
+
+	// The least recently used results are dropped first
+	for i := 0; i < maxComputedResults; i++ {
+		cc.set(fmt.Sprintf("ns%d", i), 1, i)
+		if i == 0 {
+			cc.set("bookinfo", 1, "result")
+		}
+		_, found := cc.get("bookinfo", 1)
+		assert.True(found)
+	}
+	_, found := cc.get("ns0", 1)
+	assert.False(found)
+	_, found = cc.get("ns1", 1)
+	assert.True(found)
+	assert.Equal(maxComputedResults, cc.lru.Len())
+
+	// Results expire with the resync period of the Kiali cache
+	conf.KubernetesConfig.CacheDuration = 0
+	config.Set(conf)
+	_, found = cc.get("bookinfo", 1)
+	assert.False(found)
+}
+
+func TestValidationsRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(previous cache.KialiCache) { kialiCache = previous }(kialiCache)
+	kialiCache = cache.FakeGatewaysKialiCache(nil)
+
+	revision := validationsRevision("bookinfo")
+	kialiCache.SetRegistryStatus(&kubernetes.RegistryStatus{})
+	assert.NotEqual(revision, validationsRevision("bookinfo"))
+
+	// Namespaces listed again change the revision only when they differ
+	revision = validationsRevision("bookinfo")
+	kialiCache.SetNamespaces("token", []models.Namespace{{Name: "bookinfo"}})
+	assert.NotEqual(revision, validationsRevision("bookinfo"))
+	revision = validationsRevision("bookinfo")
+	kialiCache.SetNamespaces("token", []models.Namespace{{Name: "bookinfo"}})
+	assert.Equal(revision, validationsRevision("bookinfo"))
+	kialiCache.SetNamespaces("token", []models.Namespace{{Name: "bookinfo"}, {Name: "travels"}})
+	assert.NotEqual(revision, validationsRevision("bookinfo"))
+}
+
+func TestServiceListRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	defer func(previous cache.KialiCache) { kialiCache = previous }(kialiCache)
+	kialiCache = cache.FakeGatewaysKialiCache(nil)
+	criteria := ServiceCriteria{Namespace: "bookinfo"}
+	withIstio := ServiceCriteria{Namespace: "bookinfo", IncludeIstioResources: true}
+
+	revision, istioRevision := serviceListRevision(criteria), serviceListRevision(withIstio)
+	kialiCache.SetRegistryStatus(&kubernetes.RegistryStatus{})
+	assert.NotEqual(revision, serviceListRevision(criteria))
+	assert.NotEqual(istioRevision, serviceListRevision(withIstio))
+
+	// Namespaces only matter to the lists referencing the Istio resources of all the namespaces
+	revision, istioRevision = serviceListRevision(criteria), serviceListRevision(withIstio)
+	kialiCache.SetNamespaces("token", []models.Namespace{{Name: "bookinfo"}})
+	assert.Equal(revision, serviceListRevision(criteria))
+	assert.NotEqual(istioRevision, serviceListRevision(withIstio))
+}
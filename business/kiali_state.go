@@ -0,0 +1,114 @@
+package business
+
+import (
+	"encoding/json"
+	"reflect"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// The ConfigMaps keep the state under this key, as JSON
+const kialiStateDataKey = "state"
+
+// Optimistic concurrency: an update is retried when another replica of Kiali changed the state meanwhile
+const maxKialiStateUpdateAttempts = 3
+
+// kialiStateStore keeps the state of the long running operations of Kiali (i.e. pending reverts, migrations),
+// so that they survive restarts of Kiali and are shared by its replicas
+type kialiStateStore interface {
+	// load reads the state into the value pointed by state. It's left empty when nothing is stored.
+	load(name string, state interface{}) error
+	// update reads the state, applies the change and stores the result
+	update(name string, state interface{}, change func()) error
+}
+
+var kialiState kialiStateStore = configMapKialiState{client: getKialiServiceAccountClient}
+
+// configMapKialiState keeps each state in a ConfigMap of the Kiali namespace, using the Kiali ServiceAccount. It
+// needs get, create and update permissions on the ConfigMaps of the Kiali namespace (see the README).
+type configMapKialiState struct {
+	client func() (kubernetes.ClientInterface, error)
+}
+
+func (s configMapKialiState) load(name string, state interface{}) error {
+	k8s, err := s.client()
+	if err != nil {
+		return err
+	}
+	_, err = loadKialiStateConfigMap(k8s, name, state)
+	return err
+}
+
+func (s configMapKialiState) update(name string, state interface{}, change func()) error {
+	k8s, err := s.client()
+	if err != nil {
+		return err
+	}
+	namespace := config.Get().Deployment.Namespace
+
+	for attempt := 1; ; attempt++ {
+		configMap, err := loadKialiStateConfigMap(k8s, name, state)
+		if err != nil {
+			return err
+		}
+		change()
+		data, err := json.Marshal(state)
+		if err != nil {
+			return err
+		}
+
+		if configMap == nil {
+			configMap = &core_v1.ConfigMap{
+				ObjectMeta: meta_v1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels: map[string]string{
+						"app.kubernetes.io/part-of": "kiali",
+					},
+				},
+				Data: map[string]string{kialiStateDataKey: string(data)},
+			}
+			_, err = k8s.CreateConfigMap(namespace, configMap)
+		} else {
+			configMap = configMap.DeepCopy()
+			if configMap.Data == nil {
+				configMap.Data = map[string]string{}
+			}
+			configMap.Data[kialiStateDataKey] = string(data)
+			_, err = k8s.UpdateConfigMap(namespace, configMap)
+		}
+
+		if (errors.IsConflict(err) || errors.IsAlreadyExists(err)) && attempt < maxKialiStateUpdateAttempts {
+			continue
+		}
+		return err
+	}
+}
+
+// loadKialiStateConfigMap resets the state and reads it from its ConfigMap, which is nil if it doesn't exist yet
+func loadKialiStateConfigMap(k8s kubernetes.ClientInterface, name string, state interface{}) (*core_v1.ConfigMap, error) {
+	resetKialiState(state)
+	configMap, err := k8s.GetConfigMap(config.Get().Deployment.Namespace, name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if data := configMap.Data[kialiStateDataKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), state); err != nil {
+			return nil, err
+		}
+	}
+	return configMap, nil
+}
+
+// resetKialiState sets the value pointed by state to its zero value, so that unmarshalling doesn't merge stale entries
+func resetKialiState(state interface{}) {
+	value := reflect.ValueOf(state).Elem()
+	value.Set(reflect.Zero(value.Type()))
+}
@@ -0,0 +1,89 @@
+package business
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+// memoryKialiState keeps the states in memory, instead of in ConfigMaps
+type memoryKialiState struct {
+	lock   sync.Mutex
+	states map[string][]byte
+}
+
+func (s *memoryKialiState) load(name string, state interface{}) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.unlockedLoad(name, state)
+}
+
+func (s *memoryKialiState) unlockedLoad(name string, state interface{}) error {
+	resetKialiState(state)
+	if data, found := s.states[name]; found {
+		return json.Unmarshal(data, state)
+	}
+	return nil
+}
+
+func (s *memoryKialiState) update(name string, state interface{}, change func()) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if err := s.unlockedLoad(name, state); err != nil {
+		return err
+	}
+	change()
+	data, err := json.Marshal(state)
+	s.states[name] = data
+	return err
+}
+
+func useMemoryKialiState(t *testing.T) *memoryKialiState {
+	previous := kialiState
+	state := &memoryKialiState{states: map[string][]byte{}}
+	kialiState = state
+	t.Cleanup(func() { kialiState = previous })
+	return state
+}
+
+func TestConfigMapKialiStateRetriesConflicts(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.Deployment.Namespace = "istio-system"
+	config.Set(conf)
+
+	stored := &core_v1.ConfigMap{
+		ObjectMeta: meta_v1.ObjectMeta{Name: "kiali-test-state", Namespace: "istio-system"},
+		Data:       map[string]string{kialiStateDataKey: `{"a":1}`},
+	}
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigMap", "istio-system", "kiali-test-state").Return(stored, nil)
+	conflict := errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "kiali-test-state", nil)
+	k8s.On("UpdateConfigMap", "istio-system", mock.AnythingOfType("*v1.ConfigMap")).Return((*core_v1.ConfigMap)(nil), conflict).Once()
+	k8s.On("UpdateConfigMap", "istio-system", mock.MatchedBy(func(cm *core_v1.ConfigMap) bool {
+		return cm.Data[kialiStateDataKey] == `{"a":1,"b":2}`
+	})).Return(stored, nil).Once()
+	store := configMapKialiState{client: func() (kubernetes.ClientInterface, error) { return k8s, nil }}
+
+	state := map[string]int{"stale": 3}
+	changes := 0
+	err := store.update("kiali-test-state", &state, func() {
+		changes++
+		state["b"] = 2
+	})
+	assert.NoError(err)
+	assert.Equal(2, changes)
+	assert.Equal(map[string]int{"a": 1, "b": 2}, state)
+	k8s.AssertNumberOfCalls(t, "UpdateConfigMap", 2)
+}
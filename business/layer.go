@@ -11,15 +11,20 @@ import (
 	"github.com/kiali/kiali/kubernetes/cache"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/prometheus"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+	"github.com/kiali/kiali/tempo"
 )
 
 // Layer is a container for fast access to inner services
 type Layer struct {
+	ApiKeys        ApiKeyService
 	App            AppService
 	Health         HealthService
 	IstioConfig    IstioConfigService
+	IstioHistory   IstioConfigHistoryService
 	IstioStatus    IstioStatusService
 	IstioCerts     IstioCertsService
+	IstioUpgrade   IstioUpgradeService
 	Iter8          Iter8Service
 	Jaeger         JaegerService
 	k8s            kubernetes.ClientInterface
@@ -61,17 +66,32 @@ func initKialiCache() {
 
 func IsNamespaceCached(namespace string) bool {
 	ok := kialiCache != nil && kialiCache.CheckNamespace(namespace)
+	countInformersLookup(ok)
 	return ok
 }
 
 func IsResourceCached(namespace string, resource string) bool {
-	ok := IsNamespaceCached(namespace)
+	ok := kialiCache != nil && kialiCache.CheckNamespace(namespace)
 	if ok && resource != "" {
 		ok = kialiCache.CheckIstioResource(resource)
 	}
+	countInformersLookup(ok)
 	return ok
 }
 
+// countInformersLookup counts the lookups served from the informers of the Kiali cache, and the ones that
+// fall back to the API server
+func countInformersLookup(cached bool) {
+	if kialiCache == nil {
+		return
+	}
+	if cached {
+		internalmetrics.GetKialiCacheHitsMetric("informers").Inc()
+	} else {
+		internalmetrics.GetKialiCacheMissesMetric("informers").Inc()
+	}
+}
+
 // Get the business.Layer
 func Get(authInfo *api.AuthInfo) (*Layer, error) {
 	// Kiali Cache will be initialized once at first use of Business layer
@@ -106,7 +126,7 @@ func Get(authInfo *api.AuthInfo) (*Layer, error) {
 	jaegerLoader := func() (jaeger.ClientInterface, error) {
 		var err error
 		if jaegerClient == nil {
-			jaegerClient, err = jaeger.NewClient(authInfo.Token)
+			jaegerClient, err = newTracingClient(authInfo.Token)
 			if err != nil {
 				jaegerClient = nil
 			}
@@ -117,6 +137,22 @@ func Get(authInfo *api.AuthInfo) (*Layer, error) {
 	return NewWithBackends(k8s, prometheusClient, jaegerLoader), nil
 }
 
+// newTracingClient creates the client of the configured tracing backend
+func newTracingClient(token string) (jaeger.ClientInterface, error) {
+	if config.Get().ExternalServices.Tracing.Provider == config.TracingProviderTempo {
+		client, err := tempo.NewClient(token)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+	client, err := jaeger.NewClient(token)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 // SetWithBackends allows for specifying the ClientFactory and Prometheus clients to be used.
 // Mock friendly. Used only with tests.
 func SetWithBackends(cf kubernetes.ClientFactory, prom prometheus.ClientInterface) {
@@ -127,11 +163,14 @@ func SetWithBackends(cf kubernetes.ClientFactory, prom prometheus.ClientInterfac
 // NewWithBackends creates the business layer using the passed k8s and prom clients
 func NewWithBackends(k8s kubernetes.ClientInterface, prom prometheus.ClientInterface, jaegerClient JaegerLoader) *Layer {
 	temporaryLayer := &Layer{}
+	temporaryLayer.ApiKeys = ApiKeyService{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.App = AppService{prom: prom, k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.Health = HealthService{prom: prom, k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.IstioConfig = IstioConfigService{k8s: k8s, businessLayer: temporaryLayer}
+	temporaryLayer.IstioHistory = IstioConfigHistoryService{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.IstioStatus = IstioStatusService{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.IstioCerts = IstioCertsService{k8s: k8s, businessLayer: temporaryLayer}
+	temporaryLayer.IstioUpgrade = IstioUpgradeService{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.Iter8 = Iter8Service{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.Jaeger = JaegerService{loader: jaegerClient, businessLayer: temporaryLayer}
 	temporaryLayer.k8s = k8s
@@ -140,7 +179,7 @@ func NewWithBackends(k8s kubernetes.ClientInterface, prom prometheus.ClientInter
 	temporaryLayer.OpenshiftOAuth = OpenshiftOAuthService{k8s: k8s}
 	temporaryLayer.ProxyStatus = ProxyStatusService{k8s: k8s, businessLayer: temporaryLayer}
 	// Out of order because it relies on ProxyStatus
-	temporaryLayer.ProxyLogging = ProxyLoggingService{k8s: k8s, proxyStatus: &temporaryLayer.ProxyStatus}
+	temporaryLayer.ProxyLogging = ProxyLoggingService{k8s: k8s, proxyStatus: &temporaryLayer.ProxyStatus, businessLayer: temporaryLayer}
 	temporaryLayer.RegistryStatus = RegistryStatusService{k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.Svc = SvcService{prom: prom, k8s: k8s, businessLayer: temporaryLayer}
 	temporaryLayer.TLS = TLSService{k8s: k8s, businessLayer: temporaryLayer}
@@ -151,6 +190,12 @@ func NewWithBackends(k8s kubernetes.ClientInterface, prom prometheus.ClientInter
 	return temporaryLayer
 }
 
+// Start resumes the operations of Kiali interrupted by a restart
+func Start() {
+	StartProxyLoggingReverts()
+	StartIstioUpgradeMigrations()
+}
+
 func Stop() {
 	if kialiCache != nil {
 		kialiCache.Stop()
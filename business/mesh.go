@@ -142,7 +142,7 @@ func (in *MeshService) IsMeshConfigured() (bool, error) {
 
 	cfg := config.Get()
 
-	istioConfig, err := in.k8s.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
+	istioConfig, err := getIstioConfigMap(in.k8s, defaultRevision)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			err = fmt.Errorf("%w in namespace \"%s\"", err, cfg.IstioNamespace)
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"sync"
 
+	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus"
 )
@@ -76,9 +77,21 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 		*histo = h
 	}
 
+	fetchExemplars := func(p8sFamilyName string, exemplars *[]prometheus.ExemplarQueryResult) {
+		defer wg.Done()
+		e, err := in.prom.FetchExemplars(p8sFamilyName, labels, &q.RangeQuery)
+		if err != nil {
+			// Exemplars are optional, metrics are still returned without them
+			log.Warningf("Cannot fetch exemplars for %s: %v", p8sFamilyName, err)
+			return
+		}
+		*exemplars = e
+	}
+
 	type resultHolder struct {
 		metric     prometheus.Metric
 		histo      prometheus.Histogram
+		exemplars  []prometheus.ExemplarQueryResult
 		definition istioMetric
 	}
 	maxResults := len(istioMetrics)
@@ -104,6 +117,10 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 			results = append(results, &result)
 			if istioMetric.isHisto {
 				go fetchHisto(istioMetric.istioName, &result.histo)
+				if q.IncludeExemplars && istioMetric.withExemplars {
+					wg.Add(1)
+					go fetchExemplars(istioMetric.istioName, &result.exemplars)
+				}
 			} else {
 				labelsToUse := istioMetric.labelsToUse(labels, labelsError)
 				go fetchRate(istioMetric.istioName, &result.metric, labelsToUse)
@@ -130,6 +147,9 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 				if err != nil {
 					return nil, err
 				}
+				if len(result.exemplars) > 0 {
+					models.AttachExemplars(converted, result.exemplars, conversionParams.Scale)
+				}
 			} else {
 				converted, err = models.ConvertMetric(result.definition.kialiName, result.metric, conversionParams)
 				if err != nil {
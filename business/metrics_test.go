@@ -7,6 +7,7 @@ import (
 
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/models"
@@ -468,3 +469,64 @@ func createSample(value float64) *model.Sample {
 		Metric:    model.Metric{},
 	}
 }
+
+func TestGetMetricsWithExemplars(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	prom := new(prometheustest.PromClientMock)
+	histo := prometheus.Histogram{
+		"avg": prometheus.Metric{Matrix: model.Matrix{
+			&model.SampleStream{Metric: model.Metric{"source_workload": "reviews-v1"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 10}, {Timestamp: 2000, Value: 11}}},
+			&model.SampleStream{Metric: model.Metric{"source_workload": "reviews-v2"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 20}, {Timestamp: 2000, Value: 21}}},
+		}},
+		"0.99": prometheus.Metric{Matrix: model.Matrix{
+			&model.SampleStream{Metric: model.Metric{"source_workload": "reviews-v1"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 30}, {Timestamp: 2000, Value: 31}}},
+		}},
+	}
+	prom.On("FetchHistogramRange", "istio_request_duration_milliseconds", mock.AnythingOfType("string"), "source_workload", mock.AnythingOfType("*prometheus.RangeQuery")).Return(histo)
+	prom.On("FetchExemplars", "istio_request_duration_milliseconds", mock.AnythingOfType("string"), mock.AnythingOfType("*prometheus.RangeQuery")).Return([]prometheus.ExemplarQueryResult{{
+		SeriesLabels: model.LabelSet{"source_workload": "reviews-v1", "le": "25"},
+		Exemplars: []prometheus.Exemplar{
+			{Labels: model.LabelSet{"trace_id": "0af7651916cd43dd8448eb211c80319c", "span_id": "00f067aa0ba902b7"}, Value: 12, Timestamp: 1500},
+			{Labels: model.LabelSet{"foo": "bar"}, Value: 13, Timestamp: 1600},
+		},
+	}}, nil)
+
+	q := models.IstioMetricsQuery{Namespace: "bookinfo", App: "reviews", Filters: []string{"request_duration_millis"}, IncludeExemplars: true}
+	q.FillDefaults()
+	q.ByLabels = []string{"source_workload"}
+	metrics, err := NewMetricsService(prom).GetMetrics(q, nil)
+
+	assert.NoError(err)
+	// The exemplars are attached once to the series of their labels, not to each stat
+	series := metrics["request_duration_millis"]
+	assert.Len(series, 3)
+	assert.Equal("0.99", series[0].Stat)
+	assert.Equal([]models.Exemplar{{
+		TraceID:   "0af7651916cd43dd8448eb211c80319c",
+		Labels:    map[string]string{"span_id": "00f067aa0ba902b7"},
+		Timestamp: 1500,
+		Value:     12,
+		Bucket:    "25",
+		Datapoint: 2000,
+	}}, series[0].Exemplars)
+	assert.Empty(series[1].Exemplars)
+	assert.Empty(series[2].Exemplars)
+}
+
+func TestGetMetricsExemplarsAreOptional(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	prom := new(prometheustest.PromClientMock)
+	prom.On("FetchHistogramRange", "istio_request_duration_milliseconds", mock.AnythingOfType("string"), "", mock.AnythingOfType("*prometheus.RangeQuery")).Return(prometheus.Histogram{"avg": prometheus.Metric{Matrix: model.Matrix{}}})
+	prom.On("FetchExemplars", "istio_request_duration_milliseconds", mock.AnythingOfType("string"), mock.AnythingOfType("*prometheus.RangeQuery")).Return([]prometheus.ExemplarQueryResult{}, fmt.Errorf("exemplar storage disabled"))
+
+	q := models.IstioMetricsQuery{Namespace: "bookinfo", App: "reviews", Filters: []string{"request_duration_millis"}, IncludeExemplars: true}
+	q.FillDefaults()
+	metrics, err := NewMetricsService(prom).GetMetrics(q, nil)
+
+	assert.NoError(err)
+	assert.Contains(metrics, "request_duration_millis")
+}
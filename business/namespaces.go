@@ -60,7 +60,7 @@ func NewNamespaceService(k8s kubernetes.ClientInterface) NamespaceService {
 // Returns a list of the given namespaces / projects
 func (in *NamespaceService) GetNamespaces() ([]models.Namespace, error) {
 	if kialiCache != nil {
-		if ns := kialiCache.GetNamespaces(in.k8s.GetToken()); ns != nil {
+		if ns := kialiCache.GetNamespaces(in.k8s.GetTokenHash()); ns != nil {
 			return ns, nil
 		}
 	}
@@ -143,7 +143,7 @@ func (in *NamespaceService) GetNamespaces() ([]models.Namespace, error) {
 	}
 
 	if kialiCache != nil {
-		kialiCache.SetNamespaces(in.k8s.GetToken(), result)
+		kialiCache.SetNamespaces(in.k8s.GetTokenHash(), result)
 	}
 
 	return result, nil
@@ -177,7 +177,7 @@ func (in *NamespaceService) GetNamespace(namespace string) (*models.Namespace, e
 
 	// Cache already has included/excluded namespaces applied
 	if kialiCache != nil {
-		if ns := kialiCache.GetNamespace(in.k8s.GetToken(), namespace); ns != nil {
+		if ns := kialiCache.GetNamespace(in.k8s.GetTokenHash(), namespace); ns != nil {
 			return ns, nil
 		}
 	}
@@ -227,15 +227,23 @@ func (in *NamespaceService) UpdateNamespace(namespace string, jsonPatch string)
 		return nil, err
 	}
 
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
+	// Namespaces are cached per token, the cache of the resources of the namespace is not affected
 	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
 		kialiCache.RefreshTokenNamespaces()
 	}
 	// Call GetNamespace to update the caching
 	return in.GetNamespace(namespace)
 }
 
+// IsClusterAdmin returns true when the user can do anything in any namespace of the cluster
+func (in *NamespaceService) IsClusterAdmin() (bool, error) {
+	reviews, err := in.k8s.GetSelfSubjectAccessReview("", "*", "*", []string{"*"})
+	if err != nil {
+		return false, err
+	}
+	return len(reviews) > 0 && reviews[0].Status.Allowed, nil
+}
+
 func (in *NamespaceService) getNamespacesUsingKialiSA(labelSelector string, forwardedError error) ([]core_v1.Namespace, error) {
 	// Check if we already are using the Kiali ServiceAccount token. If we are, no need to do further processing, since
 	// this would just circle back to the same results.
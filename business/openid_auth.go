@@ -52,20 +52,31 @@ type OpenIdMetadata struct {
 	// Some extra fields
 	ScopesSupported        []string `json:"scopes_supported"`
 	ResponseTypesSupported []string `json:"response_types_supported"`
+	EndSessionURL          string   `json:"end_session_endpoint"`
 }
 
 type OpenIdCallbackParams struct {
 	AccessToken   string
 	Code          string
 	ExpiresOn     time.Time
+	Groups        []string
 	IdToken       string
 	Nonce         string
 	NonceHash     []byte
 	ParsedIdToken *jwt.Token
+	RefreshToken  string
 	State         string
 	Subject       string
 }
 
+// openIdTokenResponse is the response of the token endpoint of the OpenId provider
+type openIdTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	IdToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
 var cachedOpenIdKeySet *jose.JSONWebKeySet
 var cachedOpenIdMetadata *OpenIdMetadata
 var openIdFlightGroup singleflight.Group
@@ -76,14 +87,23 @@ func BuildOpenIdJwtClaims(openIdParams *OpenIdCallbackParams, useAccessToken boo
 		sessionId = openIdParams.AccessToken
 	}
 
-	return &config.IanaClaims{
-		SessionId: sessionId,
+	claims := &config.IanaClaims{
+		SessionId:    sessionId,
+		Groups:       openIdParams.Groups,
+		RefreshToken: openIdParams.RefreshToken,
 		StandardClaims: jwt.StandardClaims{
 			Subject:   openIdParams.Subject,
 			ExpiresAt: openIdParams.ExpiresOn.Unix(),
+			IssuedAt:  util.Clock.Now().Unix(),
 			Issuer:    config.AuthStrategyOpenIdIssuer,
 		},
 	}
+	if useAccessToken {
+		// The id_token is still needed to logout the session
+		claims.IdToken = openIdParams.IdToken
+	}
+
+	return claims
 }
 
 func CallbackCleanup(w http.ResponseWriter) {
@@ -182,6 +202,19 @@ func ParseOpenIdToken(openIdParams *OpenIdCallbackParams) error {
 		openIdParams.Subject = userClaim.(string)
 	}
 
+	// Extract the groups of the user, which can be a list or a single group
+	openIdParams.Groups = nil
+	switch groupsClaim := idTokenClaims[config.Get().Auth.OpenId.GroupsClaim].(type) {
+	case string:
+		openIdParams.Groups = []string{groupsClaim}
+	case []interface{}:
+		for _, group := range groupsClaim {
+			if groupName, ok := group.(string); ok {
+				openIdParams.Groups = append(openIdParams.Groups, groupName)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -239,6 +272,17 @@ func GetJwkFromKeySet(keyId string) (*jose.JSONWebKey, error) {
 	return foundKey, nil
 }
 
+// GetOpenIdSessionIdToken returns the id_token of an OpenId session, or an empty string if it's unknown
+func GetOpenIdSessionIdToken(session *config.IanaClaims) string {
+	if len(session.IdToken) != 0 {
+		return session.IdToken
+	}
+	if config.Get().Auth.OpenId.ApiToken != "access_token" {
+		return session.SessionId
+	}
+	return ""
+}
+
 func GetOpenIdAesSession(r *http.Request) (*config.IanaClaims, error) {
 	authCookie, err := r.Cookie(config.TokenCookieName + "-aes")
 	if err != nil {
@@ -509,31 +553,160 @@ func IsOpenIdCodeFlowPossible() bool {
 	return false
 }
 
+// OpenIdCodeVerifier derives the PKCE code verifier of a login from its nonce code. Like for the state parameter,
+// the Kiali's signing key is added so that the verifier isn't known by anybody intercepting the authorization code,
+// and no additional cookie is needed.
+func OpenIdCodeVerifier(nonceCode string) string {
+	verifierHash := sha256.Sum256([]byte(fmt.Sprintf("%s+pkce+%s", nonceCode, config.GetSigningKey())))
+	return base64.RawURLEncoding.EncodeToString(verifierHash[:])
+}
+
+// OpenIdCodeChallenge returns the S256 PKCE code challenge of a code verifier (RFC 7636)
+func OpenIdCodeChallenge(codeVerifier string) string {
+	challengeHash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(challengeHash[:])
+}
+
 func RequestOpenIdToken(openIdParams *OpenIdCallbackParams, redirect_uri string) error {
-	openIdMetadata, err := GetOpenIdMetadata()
+	// Exchange authorization code for a token
+	requestParams := url.Values{}
+	requestParams.Set("code", openIdParams.Code)
+	requestParams.Set("code_verifier", OpenIdCodeVerifier(openIdParams.Nonce))
+	requestParams.Set("grant_type", "authorization_code")
+	requestParams.Set("redirect_uri", redirect_uri)
+
+	tokenResponse, err := requestOpenIdTokens(requestParams)
 	if err != nil {
 		return err
 	}
 
+	if len(tokenResponse.IdToken) == 0 {
+		return errors.New("the IdP did not provide an id_token")
+	}
+
+	openIdParams.IdToken = tokenResponse.IdToken
+	openIdParams.AccessToken = tokenResponse.AccessToken
+	openIdParams.RefreshToken = tokenResponse.RefreshToken
+	return nil
+}
+
+// RefreshOpenIdSession uses the refresh token of a session of the authorization code flow to get new tokens from
+// the OpenId provider and returns the renewed session. Concurrent refreshes of the same session are done once, because
+// the OpenId provider may allow to use a refresh token only once.
+func RefreshOpenIdSession(session *config.IanaClaims) (*config.IanaClaims, error) {
+	if len(session.RefreshToken) == 0 {
+		return nil, errors.New("the session has no refresh token")
+	}
+
+	refreshTokenHash := sha256.Sum256([]byte(session.RefreshToken))
+	refreshedSession, refreshError, _ := openIdFlightGroup.Do(fmt.Sprintf("refresh-%x", refreshTokenHash), func() (interface{}, error) {
+		return refreshOpenIdSession(session)
+	})
+
+	if refreshError != nil {
+		return nil, refreshError
+	}
+
+	return refreshedSession.(*config.IanaClaims), nil
+}
+
+func refreshOpenIdSession(session *config.IanaClaims) (*config.IanaClaims, error) {
+	cfg := config.Get().Auth.OpenId
+	useAccessToken := cfg.ApiToken == "access_token"
+
+	requestParams := url.Values{}
+	requestParams.Set("grant_type", "refresh_token")
+	requestParams.Set("refresh_token", session.RefreshToken)
+
+	tokenResponse, err := requestOpenIdTokens(requestParams)
+	if err != nil {
+		return nil, err
+	}
+
+	openIdParams := &OpenIdCallbackParams{
+		AccessToken:  tokenResponse.AccessToken,
+		IdToken:      tokenResponse.IdToken,
+		RefreshToken: tokenResponse.RefreshToken,
+	}
+
+	// The OpenId provider may keep the same refresh token
+	if len(openIdParams.RefreshToken) == 0 {
+		openIdParams.RefreshToken = session.RefreshToken
+	}
+
+	if useAccessToken && len(openIdParams.AccessToken) == 0 {
+		return nil, errors.New("the IdP did not provide an access_token")
+	}
+
+	if len(openIdParams.IdToken) == 0 {
+		// Issuing a new id_token is optional when refreshing. Without it, only a session
+		// using the access_token can be renewed, until the access_token expires.
+		if !useAccessToken || tokenResponse.ExpiresIn <= 0 {
+			return nil, errors.New("the IdP did not provide a new id_token")
+		}
+
+		openIdParams.IdToken = session.IdToken
+		openIdParams.ExpiresOn = util.Clock.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+		openIdParams.Groups = session.Groups
+		openIdParams.Subject = session.Subject
+	} else {
+		if err := ParseOpenIdToken(openIdParams); err != nil {
+			return nil, err
+		}
+
+		// The OpenID spec requires the new id_token to be about the same user
+		if openIdParams.Subject != session.Subject {
+			return nil, fmt.Errorf("the refreshed id_token is about another user; got '%s'", openIdParams.Subject)
+		}
+
+		if cfg.DisableRBAC {
+			if err := ValidateOpenTokenInHouse(openIdParams); err != nil {
+				return nil, fmt.Errorf("the refreshed OpenID token was rejected: %w", err)
+			}
+		}
+	}
+
+	// The refreshed session keeps the time of the login, its lifetime isn't extended
+	refreshedSession := BuildOpenIdJwtClaims(openIdParams, useAccessToken)
+	if session.IssuedAt != 0 {
+		refreshedSession.IssuedAt = session.IssuedAt
+	}
+	return refreshedSession, nil
+}
+
+// GetOpenIdSessionEnd returns when a session of the authorization code flow ends, however it's refreshed: the
+// lifetime of Kiali sessions after the login. The sessions created before the time of the login was kept in them
+// are given a full lifetime, which they keep once refreshed.
+func GetOpenIdSessionEnd(session *config.IanaClaims) time.Time {
+	lifetime := time.Duration(config.Get().LoginToken.ExpirationSeconds) * time.Second
+	if session.IssuedAt == 0 {
+		return util.Clock.Now().Add(lifetime)
+	}
+	return time.Unix(session.IssuedAt, 0).Add(lifetime)
+}
+
+// requestOpenIdTokens sends a request to the token endpoint of the OpenId provider, authenticating with the
+// configured client credentials
+func requestOpenIdTokens(requestParams url.Values) (*openIdTokenResponse, error) {
+	openIdMetadata, err := GetOpenIdMetadata()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := config.Get().Auth.OpenId
 
 	httpClient, err := createHttpClient(openIdMetadata.TokenURL)
 	if err != nil {
-		return fmt.Errorf("failure when creating http client to request open id token: %w", err)
+		return nil, fmt.Errorf("failure when creating http client to request open id token: %w", err)
 	}
 
-	// Exchange authorization code for a token
-	requestParams := url.Values{}
-	requestParams.Set("code", openIdParams.Code)
-	requestParams.Set("grant_type", "authorization_code")
-	requestParams.Set("redirect_uri", redirect_uri)
 	if len(cfg.ClientSecret) == 0 {
 		requestParams.Set("client_id", cfg.ClientId)
 	}
 
 	tokenRequest, err := http.NewRequest(http.MethodPost, openIdMetadata.TokenURL, strings.NewReader(requestParams.Encode()))
 	if err != nil {
-		return fmt.Errorf("failure when creating the token request: %w", err)
+		return nil, fmt.Errorf("failure when creating the token request: %w", err)
 	}
 
 	if len(cfg.ClientSecret) > 0 {
@@ -543,38 +716,28 @@ func RequestOpenIdToken(openIdParams *OpenIdCallbackParams, redirect_uri string)
 	tokenRequest.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	response, err := httpClient.Do(tokenRequest)
 	if err != nil {
-		return fmt.Errorf("failure when requesting token from IdP: %w", err)
+		return nil, fmt.Errorf("failure when requesting token from IdP: %w", err)
 	}
 
 	defer response.Body.Close()
 	rawTokenResponse, err := ioutil.ReadAll(response.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read token response from IdP: %w", err)
+		return nil, fmt.Errorf("failed to read token response from IdP: %w", err)
 	}
 
 	if response.StatusCode != 200 {
 		log.Debugf("OpenId token request failed with response: %s", string(rawTokenResponse))
-		return fmt.Errorf("request failed (HTTP response status = %s)", response.Status)
+		return nil, fmt.Errorf("request failed (HTTP response status = %s)", response.Status)
 	}
 
 	// Parse token response
-	var tokenResponse struct {
-		IdToken     string `json:"id_token"`
-		AccessToken string `json:"access_token"`
-	}
-
+	var tokenResponse openIdTokenResponse
 	err = json.Unmarshal(rawTokenResponse, &tokenResponse)
 	if err != nil {
-		return fmt.Errorf("cannot parse OpenId token response: %w", err)
-	}
-
-	if len(tokenResponse.IdToken) == 0 {
-		return errors.New("the IdP did not provide an id_token")
+		return nil, fmt.Errorf("cannot parse OpenId token response: %w", err)
 	}
 
-	openIdParams.IdToken = tokenResponse.IdToken
-	openIdParams.AccessToken = tokenResponse.AccessToken
-	return nil
+	return &tokenResponse, nil
 }
 
 func ValidateOpenIdNonceCode(openIdParams *OpenIdCallbackParams) (validationFailure string) {
@@ -704,10 +867,15 @@ func ValidateOpenTokenInHouse(openIdParams *OpenIdCallbackParams) error {
 	// If execution flow reached this point, all claims look valid, but that won't guarantee that
 	// the id_token hasn't been tampered. So, we check the signature to find if
 	// the token is genuine
-	if kidHeader, ok := openIdParams.ParsedIdToken.Header["kid"]; !ok {
+	return verifyOpenIdTokenSignature(openIdParams.ParsedIdToken, openIdParams.IdToken)
+}
+
+// verifyOpenIdTokenSignature checks that a token is signed with one of the keys of the OpenId provider
+func verifyOpenIdTokenSignature(parsedToken *jwt.Token, rawToken string) error {
+	if kidHeader, ok := parsedToken.Header["kid"]; !ok {
 		return errors.New("the OpenId token is missing the kid header claim")
 	} else {
-		if jws, parseErr := jose.ParseSigned(openIdParams.IdToken); parseErr != nil {
+		if jws, parseErr := jose.ParseSigned(rawToken); parseErr != nil {
 			return fmt.Errorf("error when parsing the OpenId token: %w", parseErr)
 		} else {
 			if len(jws.Signatures) == 0 {
@@ -0,0 +1,196 @@
+package business
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/square/go-jose.v2"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util"
+)
+
+func setupOpenId(t *testing.T, metadata *OpenIdMetadata) {
+	conf := config.NewConfig()
+	conf.Auth.Strategy = config.AuthStrategyOpenId
+	conf.Auth.OpenId.ClientId = "kiali"
+	conf.LoginToken.SigningKey = "kiali-signing-key-of-32-chars-xx"
+	config.Set(conf)
+
+	clock := util.Clock
+	util.Clock = util.ClockMock{Time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	cachedOpenIdMetadata = metadata
+	t.Cleanup(func() {
+		util.Clock = clock
+		cachedOpenIdMetadata = nil
+		cachedOpenIdKeySet = nil
+		config.Set(config.NewConfig())
+	})
+}
+
+func TestOpenIdCodeChallenge(t *testing.T) {
+	setupOpenId(t, nil)
+
+	// Example of appendix B of RFC 7636
+	assert.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", OpenIdCodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+
+	verifier := OpenIdCodeVerifier("nonce")
+	assert.Len(t, verifier, 43)
+	assert.Equal(t, verifier, OpenIdCodeVerifier("nonce"))
+	assert.NotEqual(t, verifier, OpenIdCodeVerifier("other-nonce"))
+}
+
+func TestRefreshOpenIdSession(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	newIdToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":    "jdoe",
+		"exp":    time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC).Unix(),
+		"groups": []string{"editors"},
+	}).SignedString([]byte("idp-key"))
+	require.NoError(err)
+
+	var refreshRequests int
+	idp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshRequests++
+		require.NoError(r.ParseForm())
+		if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh-1" || r.PostForm.Get("client_id") != "kiali" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"id_token": newIdToken, "access_token": "access-2", "refresh_token": "refresh-2"})
+	}))
+	defer idp.Close()
+	setupOpenId(t, &OpenIdMetadata{Issuer: idp.URL, TokenURL: idp.URL + "/token"})
+
+	session := &config.IanaClaims{SessionId: "id-token-1", RefreshToken: "refresh-1", StandardClaims: jwt.StandardClaims{Subject: "jdoe"}}
+	refreshed, err := RefreshOpenIdSession(session)
+	require.NoError(err)
+	assert.Equal(newIdToken, refreshed.SessionId)
+	assert.Equal("refresh-2", refreshed.RefreshToken)
+	assert.Equal("jdoe", refreshed.Subject)
+	assert.Equal([]string{"editors"}, refreshed.Groups)
+	assert.Equal(time.Date(2021, 1, 1, 1, 0, 0, 0, time.UTC).Unix(), refreshed.ExpiresAt)
+	assert.Equal(util.Clock.Now().Unix(), refreshed.IssuedAt)
+	assert.Equal(1, refreshRequests)
+
+	// The refreshed session keeps the time of the login, it doesn't outlive the lifetime of Kiali sessions
+	session.IssuedAt = util.Clock.Now().Add(-time.Hour).Unix()
+	refreshed, err = RefreshOpenIdSession(session)
+	require.NoError(err)
+	assert.Equal(session.IssuedAt, refreshed.IssuedAt)
+	assert.Equal(time.Unix(session.IssuedAt, 0).Add(time.Duration(config.Get().LoginToken.ExpirationSeconds)*time.Second), GetOpenIdSessionEnd(refreshed))
+
+	// The id_token must be about the user of the session
+	session.Subject = "someone-else"
+	_, err = RefreshOpenIdSession(session)
+	assert.Error(err)
+
+	// The refresh token was refused
+	session.RefreshToken = "refresh-0"
+	_, err = RefreshOpenIdSession(session)
+	assert.Error(err)
+
+	_, err = RefreshOpenIdSession(&config.IanaClaims{SessionId: "id-token-1"})
+	assert.Error(err)
+}
+
+func TestOpenIdBackChannelLogout(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	setupOpenId(t, &OpenIdMetadata{Issuer: "https://idp.example.com"})
+	state := useMemoryKialiState(t)
+	openIdLogouts, openIdLogoutsLoaded = map[string]time.Time{}, time.Time{}
+
+	idpKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(err)
+	cachedOpenIdKeySet = &jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &idpKey.PublicKey, KeyID: "idp", Algorithm: "RS256", Use: "sig"}}}
+
+	now := util.Clock.Now()
+	logoutToken := func(key *rsa.PrivateKey, change func(claims jwt.MapClaims)) string {
+		claims := jwt.MapClaims{
+			"iss":    "https://idp.example.com",
+			"aud":    []string{"kiali"},
+			"iat":    now.Unix(),
+			"exp":    now.Add(time.Minute).Unix(),
+			"events": map[string]interface{}{openIdBackChannelLogoutEvent: map[string]interface{}{}},
+			"sid":    "session-1",
+		}
+		if change != nil {
+			change(claims)
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "idp"
+		signed, err := token.SignedString(key)
+		require.NoError(err)
+		return signed
+	}
+	idToken := func(sid string, issuedAt time.Time) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "jdoe", "sid": sid, "iat": issuedAt.Unix()}).SignedString([]byte("idp-key"))
+		require.NoError(err)
+		return signed
+	}
+
+	invalidTokens := []string{
+		logoutToken(otherKey, nil),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { claims["iss"] = "https://other.example.com" }),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { claims["aud"] = "other-client" }),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { claims["exp"] = now.Add(-time.Minute).Unix() }),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { delete(claims, "events") }),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { delete(claims, "sid") }),
+		logoutToken(idpKey, func(claims jwt.MapClaims) { claims["nonce"] = "1234" }),
+		"not-a-token",
+	}
+	for _, token := range invalidTokens {
+		assert.Error(OpenIdBackChannelLogout(token))
+	}
+	assert.Empty(openIdLogouts)
+	assert.False(IsOpenIdSessionLoggedOut(idToken("session-1", now.Add(-time.Hour))))
+
+	// Logout of one session
+	require.NoError(OpenIdBackChannelLogout(logoutToken(idpKey, nil)))
+	assert.True(IsOpenIdSessionLoggedOut(idToken("session-1", now.Add(-time.Hour))))
+	assert.False(IsOpenIdSessionLoggedOut(idToken("session-2", now.Add(-time.Hour))))
+
+	// Logout of all the sessions of the user
+	require.NoError(OpenIdBackChannelLogout(logoutToken(idpKey, func(claims jwt.MapClaims) {
+		delete(claims, "sid")
+		claims["sub"] = "jdoe"
+	})))
+	assert.True(IsOpenIdSessionLoggedOut(idToken("session-2", now.Add(-time.Hour))))
+	assert.False(IsOpenIdSessionLoggedOut(idToken("session-3", now.Add(time.Minute))))
+	assert.False(IsOpenIdSessionLoggedOut(""))
+
+	// The logouts are shared with the other replicas of Kiali
+	assert.Contains(string(state.states[openIdLogoutsConfigMapName]), "sid:session-1")
+	openIdLogouts, openIdLogoutsLoaded = map[string]time.Time{}, time.Time{}
+	assert.True(IsOpenIdSessionLoggedOut(idToken("session-1", now.Add(-time.Hour))))
+
+	// A logout received by another replica is seen after the reload interval
+	logouts := map[string]time.Time{}
+	require.NoError(kialiState.update(openIdLogoutsConfigMapName, &logouts, func() { logouts["sid:session-4"] = now }))
+	assert.False(IsOpenIdSessionLoggedOut(idToken("session-4", now.Add(time.Minute))))
+	util.Clock = util.ClockMock{Time: now.Add(openIdLogoutsReloadInterval)}
+	assert.True(IsOpenIdSessionLoggedOut(idToken("session-4", now.Add(time.Minute))))
+
+	// The logouts are kept for the lifetime of Kiali sessions
+	util.Clock = util.ClockMock{Time: now.Add(time.Duration(config.Get().LoginToken.ExpirationSeconds)*time.Second + time.Minute)}
+	require.NoError(OpenIdBackChannelLogout(logoutToken(idpKey, func(claims jwt.MapClaims) {
+		claims["sid"] = "session-5"
+		claims["iat"] = util.Clock.Now().Unix()
+		claims["exp"] = util.Clock.Now().Add(time.Minute).Unix()
+	})))
+	assert.Len(openIdLogouts, 1)
+	assert.Contains(openIdLogouts, "sid:session-5")
+}
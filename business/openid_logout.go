@@ -0,0 +1,219 @@
+package business
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util"
+)
+
+const (
+	openIdBackChannelLogoutEvent = "http://schemas.openid.net/event/backchannel-logout"
+	openIdLogoutsConfigMapName   = "kiali-openid-logouts"
+	// The logouts received by the other replicas of Kiali are read again after this time
+	openIdLogoutsReloadInterval = 10 * time.Second
+)
+
+// The sessions of the OpenId provider ended with a back-channel logout, with the time of the logout. Keys are
+// either "sid:" with the session id of the provider, or "sub:" with the subject when all sessions of a user are
+// ended. Kiali sessions are cookies, so they are checked against this list. The list is kept in a Kiali state, so
+// that a logout applies to all the replicas of Kiali, for the lifetime of Kiali sessions: a session logged in
+// before a logout can't outlive it. It's cached here, the cached map is replaced but never changed.
+var (
+	openIdLogouts       = map[string]time.Time{}
+	openIdLogoutsLoaded time.Time
+	openIdLogoutsMutex  sync.RWMutex
+)
+
+// OpenIdBackChannelLogout validates a logout token sent by the OpenId provider, as described in
+// https://openid.net/specs/openid-connect-backchannel-1_0.html, and ends the Kiali sessions it identifies.
+func OpenIdBackChannelLogout(logoutToken string) error {
+	logoutClaims, err := validateOpenIdLogoutToken(logoutToken)
+	if err != nil {
+		return err
+	}
+
+	// A sid claim ends one session of the user, otherwise all sessions of the user are ended
+	sub, _ := logoutClaims["sub"].(string)
+	logoutKey := "sub:" + sub
+	if sid, ok := logoutClaims["sid"].(string); ok && len(sid) != 0 {
+		logoutKey = "sid:" + sid
+	}
+
+	now := util.Clock.Now()
+	retention := time.Duration(config.Get().LoginToken.ExpirationSeconds) * time.Second
+
+	logouts := map[string]time.Time{}
+	err = kialiState.update(openIdLogoutsConfigMapName, &logouts, func() {
+		if logouts == nil {
+			logouts = map[string]time.Time{}
+		}
+		for key, loggedOutAt := range logouts {
+			if now.Sub(loggedOutAt) > retention {
+				delete(logouts, key)
+			}
+		}
+		logouts[logoutKey] = now
+	})
+
+	openIdLogoutsMutex.Lock()
+	defer openIdLogoutsMutex.Unlock()
+	if err != nil {
+		// The logout still applies to this replica of Kiali, until the logouts are read again
+		logouts = make(map[string]time.Time, len(openIdLogouts)+1)
+		for key, loggedOutAt := range openIdLogouts {
+			logouts[key] = loggedOutAt
+		}
+		logouts[logoutKey] = now
+		openIdLogouts = logouts
+		return fmt.Errorf("cannot record the logout for the other replicas of Kiali: %w", err)
+	}
+	openIdLogouts, openIdLogoutsLoaded = logouts, now
+
+	return nil
+}
+
+// getOpenIdLogouts returns the logouts, read again from the Kiali state when the cached ones are too old
+func getOpenIdLogouts() map[string]time.Time {
+	openIdLogoutsMutex.RLock()
+	logouts, loaded := openIdLogouts, openIdLogoutsLoaded
+	openIdLogoutsMutex.RUnlock()
+
+	now := util.Clock.Now()
+	if now.Sub(loaded) < openIdLogoutsReloadInterval {
+		return logouts
+	}
+
+	reloaded := map[string]time.Time{}
+	if err := kialiState.load(openIdLogoutsConfigMapName, &reloaded); err != nil {
+		// The cached logouts are kept, the Kiali state is read again after the interval
+		log.Errorf("Cannot read the OpenID logouts: %v", err)
+		reloaded = logouts
+	} else if reloaded == nil {
+		reloaded = map[string]time.Time{}
+	}
+
+	openIdLogoutsMutex.Lock()
+	defer openIdLogoutsMutex.Unlock()
+	openIdLogouts, openIdLogoutsLoaded = reloaded, now
+	return reloaded
+}
+
+// IsOpenIdSessionLoggedOut checks if the session of an id_token was ended by a back-channel logout
+func IsOpenIdSessionLoggedOut(idToken string) bool {
+	if len(idToken) == 0 {
+		return false
+	}
+
+	parsedIdToken, _, err := new(jwt.Parser).ParseUnverified(idToken, jwt.MapClaims{})
+	if err != nil {
+		return false
+	}
+	idTokenClaims := parsedIdToken.Claims.(jwt.MapClaims)
+	logouts := getOpenIdLogouts()
+
+	// The session id of the provider isn't reused, but the id_token of a refresh keeps it
+	if sid, ok := idTokenClaims["sid"].(string); ok && len(sid) != 0 {
+		if _, loggedOut := logouts["sid:"+sid]; loggedOut {
+			return true
+		}
+	}
+
+	// The user may have logged in again after the logout of all of its sessions
+	if sub, ok := idTokenClaims["sub"].(string); ok && len(sub) != 0 {
+		if loggedOutAt, loggedOut := logouts["sub:"+sub]; loggedOut {
+			issuedAt := int64(0)
+			if iatClaim, ok := idTokenClaims["iat"]; ok {
+				issuedAt, _ = parseTimeClaim(iatClaim)
+			}
+			return !time.Unix(issuedAt, 0).After(loggedOutAt)
+		}
+	}
+
+	return false
+}
+
+func validateOpenIdLogoutToken(logoutToken string) (jwt.MapClaims, error) {
+	parsedLogoutToken, _, err := new(jwt.Parser).ParseUnverified(logoutToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse the logout token: %w", err)
+	}
+	logoutClaims := parsedLogoutToken.Claims.(jwt.MapClaims)
+
+	oidMetadata, err := GetOpenIdMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	if issuerClaim, _ := logoutClaims["iss"].(string); issuerClaim != oidMetadata.Issuer {
+		return nil, fmt.Errorf("the logout token has unexpected issuer claim; got iss = '%s'", issuerClaim)
+	}
+
+	if !isOpenIdAudience(logoutClaims["aud"]) {
+		return nil, fmt.Errorf("the logout token is not targeted for Kiali; got aud = '%v'", logoutClaims["aud"])
+	}
+
+	if iatClaim, ok := logoutClaims["iat"]; !ok {
+		return nil, errors.New("the logout token has no iat claim")
+	} else if _, parseErr := parseTimeClaim(iatClaim); parseErr != nil {
+		return nil, fmt.Errorf("the logout token has an invalid iat claim: %w", parseErr)
+	}
+
+	if expClaim, ok := logoutClaims["exp"]; ok {
+		parsedExp, parseErr := parseTimeClaim(expClaim)
+		if parseErr != nil {
+			return nil, fmt.Errorf("the logout token has an invalid exp claim: %w", parseErr)
+		}
+		if !util.Clock.Now().Before(time.Unix(parsedExp, 0)) {
+			return nil, fmt.Errorf("the logout token has expired; exp = '%d'", parsedExp)
+		}
+	}
+
+	events, _ := logoutClaims["events"].(map[string]interface{})
+	if _, ok := events[openIdBackChannelLogoutEvent].(map[string]interface{}); !ok {
+		return nil, errors.New("the logout token has no back-channel logout event")
+	}
+
+	sid, _ := logoutClaims["sid"].(string)
+	sub, _ := logoutClaims["sub"].(string)
+	if len(sid) == 0 && len(sub) == 0 {
+		return nil, errors.New("the logout token has neither a sid nor a sub claim")
+	}
+
+	// The nonce claim is prohibited, so that an id_token can't be used as a logout token
+	if _, ok := logoutClaims["nonce"]; ok {
+		return nil, errors.New("the logout token has a nonce claim")
+	}
+
+	if algHeader, ok := parsedLogoutToken.Header["alg"].(string); !ok || algHeader != "RS256" {
+		return nil, fmt.Errorf("the logout token has unexpected alg header claim; got alg = '%s'", algHeader)
+	}
+
+	if err := verifyOpenIdTokenSignature(parsedLogoutToken, logoutToken); err != nil {
+		return nil, err
+	}
+
+	return logoutClaims, nil
+}
+
+// isOpenIdAudience checks that the aud claim of a token contains the client id of Kiali
+func isOpenIdAudience(audienceClaim interface{}) bool {
+	clientId := config.Get().Auth.OpenId.ClientId
+	switch audience := audienceClaim.(type) {
+	case string:
+		return audience == clientId
+	case []interface{}:
+		for _, audienceItem := range audience {
+			if audienceItem == clientId {
+				return true
+			}
+		}
+	}
+	return false
+}
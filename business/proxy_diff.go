@@ -0,0 +1,140 @@
+package business
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util"
+)
+
+// Snapshots are kept in memory, only with the resources the diff needs. They expire after some time, and the
+// oldest ones are dropped first when there are too many of them or when they take too much memory.
+const (
+	maxConfigDumpSnapshots = 50
+	configDumpSnapshotTTL  = 24 * time.Hour
+)
+
+// The memory taken by the snapshots, approximated by the size of their resources in JSON
+var maxConfigDumpSnapshotsBytes = 64 << 20
+
+type configDumpSnapshot struct {
+	models.ConfigDumpSnapshot
+	resources *models.ConfigDumpResources
+	size      int
+}
+
+var (
+	configDumpSnapshotsLock sync.Mutex
+	configDumpSnapshots     []configDumpSnapshot
+)
+
+// DiffConfigDumps compares the config of a pod's proxy with the one of a reference pod, e.g. a healthy replica
+func (in *ProxyStatusService) DiffConfigDumps(namespace, pod, refNamespace, refPod string) (*models.EnvoyConfigDiff, error) {
+	refDump, err := in.k8s.GetConfigDump(refNamespace, refPod)
+	if err != nil {
+		return nil, err
+	}
+	dump, err := in.k8s.GetConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := models.DiffConfigDumps(refDump, dump)
+	diff.From = fmt.Sprintf("%s/%s", refNamespace, refPod)
+	diff.To = fmt.Sprintf("%s/%s", namespace, pod)
+	return diff, nil
+}
+
+// SnapshotConfigDump keeps the current config of a pod's proxy, to be compared later with DiffConfigDumpSnapshot
+func (in *ProxyStatusService) SnapshotConfigDump(namespace, pod string) (*models.ConfigDumpSnapshot, error) {
+	dump, err := in.k8s.GetConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := models.NewConfigDumpResources(dump)
+	serialized, err := json.Marshal(resources)
+	if err != nil {
+		return nil, err
+	}
+	if len(serialized) > maxConfigDumpSnapshotsBytes {
+		return nil, fmt.Errorf("the config of %s/%s is too large to be kept as a snapshot (%d bytes)", namespace, pod, len(serialized))
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	snapshot := configDumpSnapshot{
+		ConfigDumpSnapshot: models.ConfigDumpSnapshot{
+			ID:        hex.EncodeToString(id),
+			Namespace: namespace,
+			Pod:       pod,
+			Timestamp: util.Clock.Now(),
+		},
+		resources: resources,
+		size:      len(serialized),
+	}
+
+	configDumpSnapshotsLock.Lock()
+	defer configDumpSnapshotsLock.Unlock()
+	configDumpSnapshots = append(configDumpSnapshots, snapshot)
+	pruneConfigDumpSnapshots()
+	return &snapshot.ConfigDumpSnapshot, nil
+}
+
+// DiffConfigDumpSnapshot compares the current config of a pod's proxy with a snapshot taken earlier
+func (in *ProxyStatusService) DiffConfigDumpSnapshot(namespace, pod, snapshotID string) (*models.EnvoyConfigDiff, error) {
+	// The live dump is fetched first: it also checks the user can access the pod
+	dump, err := in.k8s.GetConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := findConfigDumpSnapshot(namespace, pod, snapshotID)
+	if snapshot == nil {
+		return nil, kubernetes.NewNotFound(snapshotID, "kiali", "config_dump_snapshots")
+	}
+
+	diff := models.DiffConfigDumpResources(snapshot.resources, models.NewConfigDumpResources(dump))
+	diff.From = fmt.Sprintf("%s/%s@%s", namespace, pod, snapshot.Timestamp.Format(time.RFC3339))
+	diff.To = fmt.Sprintf("%s/%s", namespace, pod)
+	return diff, nil
+}
+
+func findConfigDumpSnapshot(namespace, pod, id string) *configDumpSnapshot {
+	configDumpSnapshotsLock.Lock()
+	defer configDumpSnapshotsLock.Unlock()
+	pruneConfigDumpSnapshots()
+	for i := range configDumpSnapshots {
+		s := configDumpSnapshots[i]
+		if s.ID == id && s.Namespace == namespace && s.Pod == pod {
+			return &s
+		}
+	}
+	return nil
+}
+
+// pruneConfigDumpSnapshots drops the expired snapshots, then the oldest ones until the limits are met. The lock
+// must be held.
+func pruneConfigDumpSnapshots() {
+	expiry := util.Clock.Now().Add(-configDumpSnapshotTTL)
+	size := 0
+	kept := 0
+	// Snapshots are ordered by time, the most recent ones are kept
+	for i := len(configDumpSnapshots) - 1; i >= 0; i-- {
+		s := configDumpSnapshots[i]
+		if kept == maxConfigDumpSnapshots || s.Timestamp.Before(expiry) || size+s.size > maxConfigDumpSnapshotsBytes {
+			configDumpSnapshots = configDumpSnapshots[i+1:]
+			return
+		}
+		size += s.size
+		kept++
+	}
+}
@@ -0,0 +1,108 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/util"
+)
+
+func fakeClustersDump(names ...string) *kubernetes.ConfigDump {
+	clusters := []interface{}{}
+	for _, name := range names {
+		clusters = append(clusters, map[string]interface{}{"cluster": map[string]interface{}{"name": name}})
+	}
+	return &kubernetes.ConfigDump{Configs: []interface{}{
+		map[string]interface{}{
+			"@type":                   "type.googleapis.com/envoy.admin.v3.ClustersConfigDump",
+			"dynamic_active_clusters": clusters,
+		},
+	}}
+}
+
+func TestDiffConfigDumpSnapshot(t *testing.T) {
+	assert := assert.New(t)
+	clock := util.Clock
+	util.Clock = util.ClockMock{Time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	t.Cleanup(func() { util.Clock = clock })
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v1").Return(fakeClustersDump("outbound|9080||ratings"), nil).Once()
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v1").Return(fakeClustersDump("outbound|9080||ratings", "outbound|9080||details"), nil)
+	svc := ProxyStatusService{k8s: k8s}
+
+	snapshot, err := svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	assert.NoError(err)
+	assert.Len(snapshot.ID, 32)
+
+	diff, err := svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v1", snapshot.ID)
+	assert.NoError(err)
+	assert.Equal("bookinfo/reviews-v1", diff.To)
+	assert.Len(diff.Clusters, 1)
+	assert.Equal("outbound|9080||details", diff.Clusters[0].Name)
+
+	// Snapshots are bound to their pod
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v2").Return(fakeClustersDump(), nil)
+	_, err = svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v2", snapshot.ID)
+	assert.True(errors.IsNotFound(err))
+}
+
+func TestDiffConfigDumpsBetweenPods(t *testing.T) {
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v1").Return(fakeClustersDump("outbound|9080||ratings"), nil)
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v2").Return(fakeClustersDump(), nil)
+	svc := ProxyStatusService{k8s: k8s}
+
+	diff, err := svc.DiffConfigDumps("bookinfo", "reviews-v2", "bookinfo", "reviews-v1")
+	assert.NoError(t, err)
+	assert.Equal(t, "bookinfo/reviews-v1", diff.From)
+	assert.Equal(t, "bookinfo/reviews-v2", diff.To)
+	assert.Equal(t, "removed", diff.Clusters[0].Status)
+}
+
+func TestConfigDumpSnapshotsExpireAndAreBoundBySize(t *testing.T) {
+	assert := assert.New(t)
+	clock := util.Clock
+	maxBytes := maxConfigDumpSnapshotsBytes
+	t.Cleanup(func() {
+		util.Clock = clock
+		maxConfigDumpSnapshotsBytes = maxBytes
+		configDumpSnapshots = nil
+	})
+	configDumpSnapshots = nil
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: now}
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetConfigDump", "bookinfo", "reviews-v1").Return(fakeClustersDump("outbound|9080||ratings"), nil)
+	svc := ProxyStatusService{k8s: k8s}
+
+	expired, err := svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	assert.NoError(err)
+	size := configDumpSnapshots[0].size
+	util.Clock = util.ClockMock{Time: now.Add(configDumpSnapshotTTL + time.Minute)}
+	_, err = svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v1", expired.ID)
+	assert.True(errors.IsNotFound(err))
+
+	// Room for two snapshots only: the oldest one is dropped
+	maxConfigDumpSnapshotsBytes = 2 * size
+	first, _ := svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	second, _ := svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	third, _ := svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	_, err = svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v1", first.ID)
+	assert.True(errors.IsNotFound(err))
+	_, err = svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v1", second.ID)
+	assert.NoError(err)
+	_, err = svc.DiffConfigDumpSnapshot("bookinfo", "reviews-v1", third.ID)
+	assert.NoError(err)
+
+	// A config larger than the whole store isn't kept
+	maxConfigDumpSnapshotsBytes = 1
+	_, err = svc.SnapshotConfigDump("bookinfo", "reviews-v1")
+	assert.Error(err)
+}
@@ -2,8 +2,18 @@ package business
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
 )
 
 var (
@@ -11,6 +21,22 @@ var (
 	ValidProxyLogLevels = []string{"off", "trace", "debug", "info", "warning", "error", "critical"}
 )
 
+// The log level of the proxies injected by Istio, restored when the levels before a change are unknown
+const defaultProxyLogLevel = "warning"
+
+// Bulk changes don't port-forward to more pods than this at once
+const maxConcurrentProxyLogging = 10
+
+// The proxies whose levels were raised are kept in this ConfigMap of the Kiali namespace, so that
+// their levels are still reverted after a restart of Kiali
+const proxyLoggingConfigMapName = "kiali-proxy-logging"
+
+// A failed revert is retried after this delay, until the pod is gone
+const proxyLoggingRevertRetry = time.Minute
+
+// The client reverting the levels when the client of the user can't
+var proxyLoggingRevertClient = getKialiServiceAccountClient
+
 // IsValidLogLevel determines if the provided string is a valid proxy log level.
 // This can be called before calling SetLogLevel.
 func IsValidProxyLogLevel(level string) bool {
@@ -22,16 +48,390 @@ func IsValidProxyLogLevel(level string) bool {
 	return false
 }
 
+// ParseProxyLoggerLevels parses levels per logger, i.e. "rbac:debug,jwt:trace"
+func ParseProxyLoggerLevels(loggers string) (map[string]string, error) {
+	levels := map[string]string{}
+	for _, pair := range strings.Split(loggers, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%s is not a logger:level pair", pair)
+		}
+		if !IsValidProxyLogLevel(parts[1]) {
+			return nil, fmt.Errorf("%s is an invalid log level. Valid log levels are: %s", parts[1], strings.Join(ValidProxyLogLevels, ", "))
+		}
+		levels[parts[0]] = parts[1]
+	}
+	return levels, nil
+}
+
 // ProxyLoggingService is a thin layer over the kube interface for proxy logging functions.
 type ProxyLoggingService struct {
-	k8s         kubernetes.ClientInterface
-	proxyStatus *ProxyStatusService
+	k8s           kubernetes.ClientInterface
+	proxyStatus   *ProxyStatusService
+	businessLayer *Layer
+}
+
+// ProxyLoggingSelector selects the pods of a bulk change: the pods of a workload, of an app, or matching a label selector
+type ProxyLoggingSelector struct {
+	Workload      string
+	App           string
+	LabelSelector string
+}
+
+type proxyLoggingRecord struct {
+	storedProxyLogging
+	timer *time.Timer
+	// The client of the user who raised the levels, nil when the record was loaded after a restart
+	k8s kubernetes.ClientInterface
+}
+
+// storedProxyLogging is the part of a record kept across restarts
+type storedProxyLogging struct {
+	models.ProxyLogging
+	// The levels to restore by logger, empty when the levels before the change are unknown
+	Restore map[string]string `json:"restore,omitempty"`
+	Global  bool              `json:"global,omitempty"`
 }
 
+// The proxies whose levels were raised through Kiali, by namespace/pod
+var (
+	proxyLoggingLock    sync.Mutex
+	proxyLoggingRecords = map[string]*proxyLoggingRecord{}
+)
+
 // SetLogLevel sets the pod's proxy log level.
 func (in *ProxyLoggingService) SetLogLevel(namespace, pod, level string) error {
+	return in.SetLogLevels(namespace, pod, models.ProxyLogLevels{Level: level}, 0)
+}
+
+// SetLogLevels sets the pod's proxy log levels. With a TTL, the previous levels are restored once it expires.
+func (in *ProxyLoggingService) SetLogLevels(namespace, pod string, levels models.ProxyLogLevels, ttl time.Duration) error {
 	if _, err := in.proxyStatus.GetPodProxyStatus(namespace, pod); err != nil {
 		return fmt.Errorf("unable to detect proxy for Pod: %s in Namespace: %s", pod, namespace)
 	}
-	return in.k8s.SetProxyLogLevel(namespace, pod, level)
+
+	var current map[string]string
+	if ttl > 0 {
+		var err error
+		if current, err = in.k8s.GetProxyLogLevels(namespace, pod); err != nil {
+			return err
+		}
+	}
+	if levels.Level != "" {
+		if err := in.k8s.SetProxyLogLevel(namespace, pod, levels.Level); err != nil {
+			return err
+		}
+	}
+	if len(levels.Loggers) > 0 {
+		if err := in.k8s.SetProxyLoggerLevels(namespace, pod, levels.Loggers); err != nil {
+			return err
+		}
+	}
+
+	in.record(namespace, pod, levels, current, ttl)
+	return nil
+}
+
+// SetPodsLogLevels sets the proxy log levels of all the selected pods. The result of each pod is reported
+// separately, a failure on a pod doesn't stop the others.
+func (in *ProxyLoggingService) SetPodsLogLevels(namespace string, selector ProxyLoggingSelector, levels models.ProxyLogLevels, ttl time.Duration) ([]models.ProxyLoggingResult, error) {
+	pods, err := in.selectPods(namespace, selector)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]models.ProxyLoggingResult, len(pods))
+	semaphore := make(chan struct{}, maxConcurrentProxyLogging)
+	wg := sync.WaitGroup{}
+	for i, pod := range pods {
+		wg.Add(1)
+		go func(i int, pod string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			results[i].Pod = pod
+			if err := in.SetLogLevels(namespace, pod, levels, ttl); err != nil {
+				results[i].Error = err.Error()
+			}
+		}(i, pod)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// RevertLogLevels restores the pod's proxy log levels as they were before being raised through Kiali
+func (in *ProxyLoggingService) RevertLogLevels(namespace, pod string) error {
+	key := namespace + "/" + pod
+	record := getProxyLoggingRecord(key)
+	if record == nil {
+		return kubernetes.NewNotFound(pod, "kiali", "proxy_logging")
+	}
+	// The record is kept until the levels are reverted, a failed revert is still retried once the TTL expires
+	if err := revertProxyLogLevels(in.k8s, record); err != nil {
+		return err
+	}
+	if takeProxyLoggingRecord(key, record) != nil {
+		syncProxyLoggingRecord(key)
+	}
+	return nil
+}
+
+// GetElevatedProxies lists the proxies whose log levels were raised through Kiali, in the namespaces the user can access
+func (in *ProxyLoggingService) GetElevatedProxies() ([]models.ProxyLogging, error) {
+	namespaces, err := in.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	accessible := map[string]bool{}
+	for _, ns := range namespaces {
+		accessible[ns.Name] = true
+	}
+
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+	proxies := []models.ProxyLogging{}
+	for _, record := range proxyLoggingRecords {
+		if accessible[record.Namespace] {
+			proxies = append(proxies, record.ProxyLogging)
+		}
+	}
+	sort.Slice(proxies, func(i, j int) bool {
+		if proxies[i].Namespace != proxies[j].Namespace {
+			return proxies[i].Namespace < proxies[j].Namespace
+		}
+		return proxies[i].Pod < proxies[j].Pod
+	})
+	return proxies, nil
+}
+
+func (in *ProxyLoggingService) selectPods(namespace string, selector ProxyLoggingSelector) ([]string, error) {
+	var pods models.Pods
+	var err error
+	switch {
+	case selector.Workload != "":
+		var workload *models.Workload
+		if workload, err = fetchWorkload(in.businessLayer, namespace, selector.Workload, ""); err == nil {
+			pods = workload.Pods
+		}
+	case selector.App != "":
+		appLabel := labels.Set{config.Get().IstioLabels.AppLabelName: selector.App}
+		pods, err = in.businessLayer.Workload.GetPods(namespace, appLabel.String())
+	default:
+		pods, err = in.businessLayer.Workload.GetPods(namespace, selector.LabelSelector)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for _, pod := range pods {
+		if pod.IstioSidecar {
+			names = append(names, pod.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (in *ProxyLoggingService) record(namespace, pod string, levels models.ProxyLogLevels, current map[string]string, ttl time.Duration) {
+	if in.updateRecord(namespace, pod, levels, current, ttl) {
+		syncProxyLoggingRecord(namespace + "/" + pod)
+	}
+}
+
+// updateRecord changes the record of a proxy in memory, it returns false when there was nothing to change
+func (in *ProxyLoggingService) updateRecord(namespace, pod string, levels models.ProxyLogLevels, current map[string]string, ttl time.Duration) bool {
+	key := namespace + "/" + pod
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+
+	previous := proxyLoggingRecords[key]
+	if previous != nil && previous.timer != nil {
+		previous.timer.Stop()
+	}
+	if ttl == 0 && !isElevated(levels) {
+		// The levels were explicitly set back to a quiet level
+		delete(proxyLoggingRecords, key)
+		return previous != nil
+	}
+
+	record := &proxyLoggingRecord{
+		storedProxyLogging: storedProxyLogging{
+			ProxyLogging: models.ProxyLogging{
+				ProxyLogLevels: levels,
+				Namespace:      namespace,
+				Pod:            pod,
+				Since:          time.Now(),
+			},
+			Restore: map[string]string{},
+			Global:  levels.Level != "",
+		},
+		k8s: in.k8s,
+	}
+	if previous != nil {
+		// The levels to restore are the ones before the first change
+		record.Since = previous.Since
+		record.Global = record.Global || previous.Global
+		for logger, level := range previous.Restore {
+			record.Restore[logger] = level
+		}
+	}
+	for logger, level := range current {
+		if _, found := record.Restore[logger]; !found && (levels.Level != "" || levels.Loggers[logger] != "") {
+			record.Restore[logger] = level
+		}
+	}
+	if ttl > 0 {
+		revertAt := time.Now().Add(ttl)
+		record.RevertAt = &revertAt
+		record.timer = time.AfterFunc(ttl, func() { revertExpiredProxyLogLevels(key, record) })
+	}
+	proxyLoggingRecords[key] = record
+	return true
+}
+
+// syncProxyLoggingRecord stores the current record of a proxy, or removes it when there is none anymore. It's
+// called after each change, outside of proxyLoggingLock, so the last store always reflects the last change.
+func syncProxyLoggingRecord(key string) {
+	stored := map[string]storedProxyLogging{}
+	err := kialiState.update(proxyLoggingConfigMapName, &stored, func() {
+		if stored == nil {
+			stored = map[string]storedProxyLogging{}
+		}
+		proxyLoggingLock.Lock()
+		record, found := proxyLoggingRecords[key]
+		var storedRecord storedProxyLogging
+		if found {
+			storedRecord = record.storedProxyLogging
+		}
+		proxyLoggingLock.Unlock()
+		if found {
+			stored[key] = storedRecord
+		} else {
+			delete(stored, key)
+		}
+	})
+	if err != nil {
+		log.Warningf("Unable to store the log levels of the proxy of %s, they won't be reverted if Kiali restarts: %v", key, err)
+	}
+}
+
+// StartProxyLoggingReverts loads the proxies whose levels were raised before Kiali started. The levels of the
+// proxies past their revert time are reverted, the other reverts are scheduled again.
+func StartProxyLoggingReverts() {
+	stored := map[string]storedProxyLogging{}
+	if err := kialiState.load(proxyLoggingConfigMapName, &stored); err != nil {
+		log.Errorf("Unable to load the proxies whose log levels were raised through Kiali: %v", err)
+		return
+	}
+
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+	for key, storedRecord := range stored {
+		if _, found := proxyLoggingRecords[key]; found {
+			continue
+		}
+		record := &proxyLoggingRecord{storedProxyLogging: storedRecord}
+		if record.RevertAt != nil {
+			key := key
+			delay := time.Until(*record.RevertAt)
+			if delay < 0 {
+				delay = 0
+			}
+			record.timer = time.AfterFunc(delay, func() { revertExpiredProxyLogLevels(key, record) })
+		}
+		proxyLoggingRecords[key] = record
+	}
+}
+
+func isElevated(levels models.ProxyLogLevels) bool {
+	verbose := func(level string) bool { return level == "debug" || level == "trace" }
+	if verbose(levels.Level) {
+		return true
+	}
+	for _, level := range levels.Loggers {
+		if verbose(level) {
+			return true
+		}
+	}
+	return false
+}
+
+func getProxyLoggingRecord(key string) *proxyLoggingRecord {
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+	return proxyLoggingRecords[key]
+}
+
+// takeProxyLoggingRecord removes the record of a proxy from memory, if it wasn't replaced meanwhile. The
+// caller syncs the stored records once the removal succeeded.
+func takeProxyLoggingRecord(key string, expected *proxyLoggingRecord) *proxyLoggingRecord {
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+	record := proxyLoggingRecords[key]
+	if record == nil || record != expected {
+		return nil
+	}
+	if record.timer != nil {
+		record.timer.Stop()
+	}
+	delete(proxyLoggingRecords, key)
+	return record
+}
+
+func revertProxyLogLevels(k8s kubernetes.ClientInterface, record *proxyLoggingRecord) error {
+	if len(record.Restore) == 0 {
+		return k8s.SetProxyLogLevel(record.Namespace, record.Pod, defaultProxyLogLevel)
+	}
+	if record.Global {
+		// Most often all the loggers had the same level: restore it in a single call
+		same := true
+		var level string
+		for _, l := range record.Restore {
+			if level == "" {
+				level = l
+			}
+			same = same && l == level
+		}
+		if same {
+			return k8s.SetProxyLogLevel(record.Namespace, record.Pod, level)
+		}
+	}
+	return k8s.SetProxyLoggerLevels(record.Namespace, record.Pod, record.Restore)
+}
+
+func revertExpiredProxyLogLevels(key string, record *proxyLoggingRecord) {
+	if getProxyLoggingRecord(key) != record {
+		// Changed or reverted meanwhile
+		return
+	}
+	var err error
+	if record.k8s != nil {
+		err = revertProxyLogLevels(record.k8s, record)
+	}
+	if record.k8s == nil || err != nil {
+		// The token of the user who raised the levels may have expired since, or is unknown after a restart
+		k8s, saErr := proxyLoggingRevertClient()
+		if saErr != nil {
+			err = saErr
+		} else if err = revertProxyLogLevels(k8s, record); err != nil {
+			if _, podErr := k8s.GetPod(record.Namespace, record.Pod); errors.IsNotFound(podErr) {
+				log.Infof("The pod of the proxy of %s is gone, its log levels don't need to be reverted", key)
+				err = nil
+			}
+		}
+	}
+	if err != nil {
+		log.Errorf("Unable to revert the log levels of the proxy of %s, retrying in %v: %v", key, proxyLoggingRevertRetry, err)
+		proxyLoggingLock.Lock()
+		defer proxyLoggingLock.Unlock()
+		if proxyLoggingRecords[key] == record {
+			record.timer = time.AfterFunc(proxyLoggingRevertRetry, func() { revertExpiredProxyLogLevels(key, record) })
+		}
+		return
+	}
+	if takeProxyLoggingRecord(key, record) != nil {
+		syncProxyLoggingRecord(key)
+	}
+	log.Infof("Reverted the log levels of the proxy of %s", key)
 }
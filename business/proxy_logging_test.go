@@ -0,0 +1,160 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+)
+
+func proxyLoggingRecorded(namespace, pod string) bool {
+	proxyLoggingLock.Lock()
+	defer proxyLoggingLock.Unlock()
+	_, found := proxyLoggingRecords[namespace+"/"+pod]
+	return found
+}
+
+func TestSetLogLevelsRevertsAfterTTL(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetProxyLogLevels", "bookinfo", "reviews-v1").Return(map[string]string{"rbac": "info", "jwt": "warning"}, nil)
+	k8s.On("SetProxyLoggerLevels", "bookinfo", "reviews-v1", map[string]string{"rbac": "debug"}).Return(nil)
+	reverted := make(chan struct{})
+	k8s.On("SetProxyLoggerLevels", "bookinfo", "reviews-v1", map[string]string{"rbac": "info"}).Run(func(mock.Arguments) { close(reverted) }).Return(nil)
+	svc := ProxyLoggingService{k8s: k8s, proxyStatus: &ProxyStatusService{k8s: k8s}}
+
+	err := svc.SetLogLevels("bookinfo", "reviews-v1", models.ProxyLogLevels{Loggers: map[string]string{"rbac": "debug"}}, 10*time.Millisecond)
+	assert.NoError(err)
+
+	assert.True(proxyLoggingRecorded("bookinfo", "reviews-v1"))
+
+	// Only the changed logger is restored
+	select {
+	case <-reverted:
+	case <-time.After(time.Second):
+		t.Fatal("the log levels were not reverted")
+	}
+	assert.False(proxyLoggingRecorded("bookinfo", "reviews-v1"))
+}
+
+func TestRevertLogLevels(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetProxyLogLevels", "bookinfo", "ratings-v1").Return(map[string]string{"rbac": "info", "jwt": "info"}, nil)
+	k8s.On("SetProxyLogLevel").Return(nil)
+	svc := ProxyLoggingService{k8s: k8s, proxyStatus: &ProxyStatusService{k8s: k8s}}
+
+	err := svc.SetLogLevels("bookinfo", "ratings-v1", models.ProxyLogLevels{Level: "trace"}, time.Hour)
+	assert.NoError(err)
+	assert.True(proxyLoggingRecorded("bookinfo", "ratings-v1"))
+
+	assert.NoError(svc.RevertLogLevels("bookinfo", "ratings-v1"))
+	assert.False(proxyLoggingRecorded("bookinfo", "ratings-v1"))
+	k8s.AssertNumberOfCalls(t, "SetProxyLogLevel", 2)
+
+	err = svc.RevertLogLevels("bookinfo", "ratings-v1")
+	assert.True(errors.IsNotFound(err))
+}
+
+func TestRevertLogLevelsFailureKeepsRecord(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetProxyLogLevels", "bookinfo", "ratings-v2").Return(map[string]string{"rbac": "info"}, nil)
+	// The level is raised, then the revert fails
+	k8s.On("SetProxyLogLevel").Return(nil).Once()
+	k8s.On("SetProxyLogLevel").Return(errors.NewServiceUnavailable("port-forward failed")).Once()
+	svc := ProxyLoggingService{k8s: k8s, proxyStatus: &ProxyStatusService{k8s: k8s}}
+	assert.NoError(svc.SetLogLevels("bookinfo", "ratings-v2", models.ProxyLogLevels{Level: "trace"}, time.Hour))
+
+	// The levels are still raised, the revert is kept for when the TTL expires
+	assert.Error(svc.RevertLogLevels("bookinfo", "ratings-v2"))
+	assert.True(proxyLoggingRecorded("bookinfo", "ratings-v2"))
+	stored := map[string]storedProxyLogging{}
+	assert.NoError(kialiState.load(proxyLoggingConfigMapName, &stored))
+	assert.Contains(stored, "bookinfo/ratings-v2")
+
+	proxyLoggingLock.Lock()
+	proxyLoggingRecords["bookinfo/ratings-v2"].timer.Stop()
+	delete(proxyLoggingRecords, "bookinfo/ratings-v2")
+	proxyLoggingLock.Unlock()
+}
+
+func TestProxyLoggingRevertsAfterRestart(t *testing.T) {
+	assert := assert.New(t)
+	useMemoryKialiState(t)
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("GetProxyLogLevels", "bookinfo", mock.Anything).Return(map[string]string{"rbac": "info"}, nil)
+	k8s.On("SetProxyLoggerLevels", "bookinfo", mock.Anything, map[string]string{"rbac": "debug"}).Return(nil)
+	svc := ProxyLoggingService{k8s: k8s, proxyStatus: &ProxyStatusService{k8s: k8s}}
+	assert.NoError(svc.SetLogLevels("bookinfo", "details-v1", models.ProxyLogLevels{Loggers: map[string]string{"rbac": "debug"}}, time.Hour))
+	assert.NoError(svc.SetLogLevels("bookinfo", "productpage-v1", models.ProxyLogLevels{Loggers: map[string]string{"rbac": "debug"}}, time.Hour))
+
+	// Kiali restarts after the revert time of details-v1
+	stored := map[string]storedProxyLogging{}
+	assert.NoError(kialiState.update(proxyLoggingConfigMapName, &stored, func() {
+		record := stored["bookinfo/details-v1"]
+		revertAt := time.Now().Add(-time.Minute)
+		record.RevertAt = &revertAt
+		stored["bookinfo/details-v1"] = record
+	}))
+	proxyLoggingLock.Lock()
+	for key, record := range proxyLoggingRecords {
+		record.timer.Stop()
+		delete(proxyLoggingRecords, key)
+	}
+	proxyLoggingLock.Unlock()
+
+	saK8s := new(kubetest.K8SClientMock)
+	reverted := make(chan struct{})
+	saK8s.On("SetProxyLoggerLevels", "bookinfo", "details-v1", map[string]string{"rbac": "info"}).Run(func(mock.Arguments) { close(reverted) }).Return(nil)
+	revertClient := proxyLoggingRevertClient
+	proxyLoggingRevertClient = func() (kubernetes.ClientInterface, error) { return saK8s, nil }
+	defer func() { proxyLoggingRevertClient = revertClient }()
+
+	StartProxyLoggingReverts()
+	select {
+	case <-reverted:
+	case <-time.After(time.Second):
+		t.Fatal("the log levels were not reverted")
+	}
+	assert.Eventually(func() bool {
+		assert.NoError(kialiState.load(proxyLoggingConfigMapName, &stored))
+		_, found := stored["bookinfo/details-v1"]
+		return !found && !proxyLoggingRecorded("bookinfo", "details-v1")
+	}, time.Second, 10*time.Millisecond)
+
+	// The revert of productpage-v1 is scheduled again
+	assert.True(proxyLoggingRecorded("bookinfo", "productpage-v1"))
+	assert.Contains(stored, "bookinfo/productpage-v1")
+	proxyLoggingLock.Lock()
+	assert.NotNil(proxyLoggingRecords["bookinfo/productpage-v1"].timer)
+	proxyLoggingRecords["bookinfo/productpage-v1"].timer.Stop()
+	delete(proxyLoggingRecords, "bookinfo/productpage-v1")
+	proxyLoggingLock.Unlock()
+}
+
+func TestParseProxyLoggerLevels(t *testing.T) {
+	assert := assert.New(t)
+
+	levels, err := ParseProxyLoggerLevels("rbac:debug, jwt:trace")
+	assert.NoError(err)
+	assert.Equal(map[string]string{"rbac": "debug", "jwt": "trace"}, levels)
+
+	_, err = ParseProxyLoggerLevels("rbac:verbose")
+	assert.Error(err)
+	_, err = ParseProxyLoggerLevels("rbac")
+	assert.Error(err)
+}
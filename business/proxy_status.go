@@ -1,8 +1,12 @@
 package business
 
 import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/models"
 )
@@ -17,8 +21,16 @@ func (in *ProxyStatusService) GetPodProxyStatus(ns, pod string) (*kubernetes.Pro
 		return nil, nil
 	}
 
+	if err := in.refreshProxyStatus(); err != nil {
+		return nil, err
+	}
+	return kialiCache.GetPodProxyStatus(ns, pod), nil
+}
+
+// refreshProxyStatus fetches the proxy status from istiod when the cached one is outdated
+func (in *ProxyStatusService) refreshProxyStatus() error {
 	if kialiCache.CheckProxyStatus() {
-		return kialiCache.GetPodProxyStatus(ns, pod), nil
+		return nil
 	}
 
 	var proxyStatus []*kubernetes.ProxyStatus
@@ -26,31 +38,108 @@ func (in *ProxyStatusService) GetPodProxyStatus(ns, pod string) (*kubernetes.Pro
 
 	if proxyStatus, err = in.k8s.GetProxyStatus(); err != nil {
 		if proxyStatus, err = in.getProxyStatusUsingKialiSA(); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
 	kialiCache.SetProxyStatus(proxyStatus)
-	return kialiCache.GetPodProxyStatus(ns, pod), nil
+	return nil
+}
+
+// GetPodProxySyncHistory returns how the proxy of a pod synchronized with the control plane since Kiali has been watching it
+func (in *ProxyStatusService) GetPodProxySyncHistory(ns, pod string) (*models.ProxySyncHistory, error) {
+	if _, err := in.GetPodProxyStatus(ns, pod); err != nil {
+		return nil, err
+	}
+
+	var history *models.ProxySyncHistory
+	if kialiCache != nil {
+		history = kialiCache.GetProxySyncHistory(ns, pod)
+	}
+	if history == nil {
+		return nil, kubernetes.NewNotFound(pod, "kiali", "proxy_sync_history")
+	}
+	return history, nil
+}
+
+// GetStaleProxies lists the current proxies of the accessible namespaces which are out of sync for longer than the threshold,
+// grouped by the revision of the istiod they are connected to
+func (in *ProxyStatusService) GetStaleProxies(threshold time.Duration) ([]models.StaleProxies, error) {
+	if kialiCache == nil {
+		return []models.StaleProxies{}, nil
+	}
+	if err := in.refreshProxyStatus(); err != nil {
+		return nil, err
+	}
+
+	namespaces, err := in.businessLayer.Namespace.GetNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	accessible := map[string]bool{}
+	for _, ns := range namespaces {
+		accessible[ns.Name] = true
+	}
+	histories := []models.ProxySyncHistory{}
+	for _, history := range kialiCache.GetProxySyncHistories() {
+		if accessible[history.Namespace] {
+			histories = append(histories, history)
+		}
+	}
+
+	revisions, err := in.getIstiodRevisions()
+	if err != nil {
+		return nil, err
+	}
+	return models.GroupStaleProxies(histories, revisions, threshold, time.Now()), nil
+}
+
+// getIstiodRevisions returns the revision of each istiod instance, by name
+func (in *ProxyStatusService) getIstiodRevisions() (map[string]string, error) {
+	conf := config.Get()
+	selector := labels.Set{"app": "istiod"}.String()
+	istiods, err := in.k8s.GetPods(conf.IstioNamespace, selector)
+	if err != nil {
+		// Users don't necessarily have access to the control plane namespace
+		k8s, saErr := getKialiServiceAccountClient()
+		if saErr != nil {
+			return nil, err
+		}
+		if istiods, err = k8s.GetPods(conf.IstioNamespace, selector); err != nil {
+			return nil, err
+		}
+	}
+
+	revisions := map[string]string{}
+	for _, istiod := range istiods {
+		if revision, ok := istiod.Labels[conf.IstioLabels.InjectionLabelRev]; ok {
+			revisions[istiod.Name] = revision
+		}
+	}
+	return revisions, nil
 }
 
 func (in *ProxyStatusService) getProxyStatusUsingKialiSA() ([]*kubernetes.ProxyStatus, error) {
-	clientFactory, err := kubernetes.GetClientFactory()
+	k8s, err := getKialiServiceAccountClient()
 	if err != nil {
 		return nil, err
 	}
 
+	return k8s.GetProxyStatus()
+}
+
+func getKialiServiceAccountClient() (kubernetes.ClientInterface, error) {
 	kialiToken, err := kubernetes.GetKialiToken()
 	if err != nil {
 		return nil, err
 	}
 
-	k8s, err := clientFactory.GetClient(&api.AuthInfo{Token: kialiToken})
+	clientFactory, err := kubernetes.GetClientFactory()
 	if err != nil {
 		return nil, err
 	}
 
-	return k8s.GetProxyStatus()
+	return clientFactory.GetClient(&api.AuthInfo{Token: kialiToken})
 }
 
 func castProxyStatus(ps *kubernetes.ProxyStatus) *models.ProxyStatus {
@@ -59,34 +148,30 @@ func castProxyStatus(ps *kubernetes.ProxyStatus) *models.ProxyStatus {
 	}
 
 	return &models.ProxyStatus{
-		CDS: xdsStatus(ps.ClusterSent, ps.ClusterAcked),
-		EDS: xdsStatus(ps.EndpointSent, ps.EndpointAcked),
-		LDS: xdsStatus(ps.ListenerSent, ps.ListenerAcked),
-		RDS: xdsStatus(ps.RouteSent, ps.RouteAcked),
+		CDS: models.XdsStatus(ps.ClusterSent, ps.ClusterAcked),
+		EDS: models.XdsStatus(ps.EndpointSent, ps.EndpointAcked),
+		LDS: models.XdsStatus(ps.ListenerSent, ps.ListenerAcked),
+		RDS: models.XdsStatus(ps.RouteSent, ps.RouteAcked),
 	}
 }
 
-func xdsStatus(sent, acked string) string {
-	if sent == "" {
-		return "NOT_SENT"
-	}
-	if sent == acked {
-		return "Synced"
-	}
-	// acked will be empty string when there is never Acknowledged
-	if acked == "" {
-		return "Stale (Never Acknowledged)"
-	}
-	// Since the Nonce changes to uuid, so there is no more any time diff info
-	return "Stale"
-}
-
 func (in *ProxyStatusService) GetConfigDump(namespace, pod string) (models.EnvoyProxyDump, error) {
 	dump, err := in.k8s.GetConfigDump(namespace, pod)
 	return models.EnvoyProxyDump{ConfigDump: dump}, err
 }
 
 func (in *ProxyStatusService) GetConfigDumpResourceEntries(namespace, pod, resource string) (*models.EnvoyProxyDump, error) {
+	// Endpoints aren't part of the config dump, Envoy reports them along with the clusters status
+	if resource == "endpoints" {
+		status, err := in.k8s.GetClustersStatus(namespace, pod)
+		if err != nil {
+			return nil, err
+		}
+		endpoints := &models.ClusterEndpoints{}
+		endpoints.Parse(status)
+		return &models.EnvoyProxyDump{Endpoints: endpoints}, nil
+	}
+
 	dump, err := in.k8s.GetConfigDump(namespace, pod)
 	if err != nil {
 		return nil, err
@@ -126,7 +211,57 @@ func buildDump(dump *kubernetes.ConfigDump, resource string, namespaces []models
 		summary := &models.Listeners{}
 		err = summary.Parse(dump)
 		response.Listeners = summary
+	case "secrets":
+		summary := &models.Secrets{}
+		err = summary.Parse(dump)
+		response.Secrets = summary
+	case "ecds":
+		summary := &models.ExtensionConfigs{}
+		err = summary.Parse(dump)
+		response.Ecds = summary
 	}
 
 	return response, err
 }
+
+// SimulateRoute tells where the proxy of a pod would send a request, without sending it
+func (in *ProxyStatusService) SimulateRoute(namespace, pod string, req models.RouteSimulationRequest) (*models.RouteSimulation, error) {
+	dump, err := in.k8s.GetConfigDump(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	return models.SimulateRoute(dump, req)
+}
+
+// GetProxyStats fetches the stats of the proxy of a pod, filtered by a regex on their names
+func (in *ProxyStatusService) GetProxyStats(namespace, pod, filter string) (*models.ProxyStats, error) {
+	families, err := in.k8s.GetProxyStats(namespace, pod, filter)
+	if err != nil {
+		return nil, err
+	}
+	stats := &models.ProxyStats{}
+	stats.Parse(families)
+	return stats, nil
+}
+
+// GetProxyClusters fetches the circuit breakers, outlier detection and endpoints health of the proxy of a pod
+func (in *ProxyStatusService) GetProxyClusters(namespace, pod string) (*models.ProxyClusters, error) {
+	status, err := in.k8s.GetClustersStatus(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	clusters := &models.ProxyClusters{}
+	clusters.Parse(status)
+	return clusters, nil
+}
+
+// GetProxyServerInfo fetches the state, version and uptime of the proxy of a pod
+func (in *ProxyStatusService) GetProxyServerInfo(namespace, pod string) (*models.ProxyServerInfo, error) {
+	info, err := in.k8s.GetProxyServerInfo(namespace, pod)
+	if err != nil {
+		return nil, err
+	}
+	serverInfo := &models.ProxyServerInfo{}
+	serverInfo.Parse(info)
+	return serverInfo, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/kiali/kiali/business/checkers"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/cache"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus"
@@ -33,6 +34,11 @@ type ServiceCriteria struct {
 	ServiceSelector        string
 }
 
+// The Istio resources referenced by the services of a list
+var serviceListIstioResources = []string{kubernetes.DestinationRules, kubernetes.Gateways, kubernetes.ServiceEntries, kubernetes.VirtualServices}
+
+var serviceListsCache = newComputedCache("services")
+
 // GetServiceList returns a list of all services for a given criteria
 func (in *SvcService) GetServiceList(criteria ServiceCriteria) (*models.ServiceList, error) {
 	var svcs []core_v1.Service
@@ -48,6 +54,16 @@ func (in *SvcService) GetServiceList(criteria ServiceCriteria) (*models.ServiceL
 		return nil, err
 	}
 
+	// Service lists are kept until the Kiali cache observes a change of the resources they are built from
+	cacheKey, cacheable := in.serviceListCacheKey(criteria)
+	var revision uint64
+	if cacheable {
+		revision = serviceListRevision(criteria)
+		if cached, found := serviceListsCache.get(cacheKey, revision); found {
+			return copyServiceList(cached.(*models.ServiceList)), nil
+		}
+	}
+
 	nFetches := 4
 	if criteria.IncludeIstioResources {
 		nFetches = 5
@@ -160,7 +176,55 @@ func (in *SvcService) GetServiceList(criteria ServiceCriteria) (*models.ServiceL
 	}
 
 	// Convert to Kiali model
-	return in.buildServiceList(models.Namespace{Name: criteria.Namespace}, svcs, rSvcs, pods, deployments, istioConfigList), nil
+	serviceList := in.buildServiceList(models.Namespace{Name: criteria.Namespace}, svcs, rSvcs, pods, deployments, istioConfigList)
+	if cacheable {
+		serviceListsCache.set(cacheKey, revision, copyServiceList(serviceList))
+	}
+	return serviceList, nil
+}
+
+// serviceListCacheKey returns the key of a service list in the service lists cache. Lists are only kept when
+// all the resources they are built from are read from the Kiali cache.
+func (in *SvcService) serviceListCacheKey(criteria ServiceCriteria) (string, bool) {
+	if !IsNamespaceCached(criteria.Namespace) {
+		return "", false
+	}
+	if criteria.IncludeIstioResources {
+		// Istio resources are read from all the namespaces
+		if !namespacesCached(in.businessLayer) {
+			return "", false
+		}
+		for _, resource := range serviceListIstioResources {
+			if !kialiCache.CheckIstioResource(resource) {
+				return "", false
+			}
+		}
+	}
+	return fmt.Sprintf("%s/%s/%t/%t/%s", in.k8s.GetTokenHash(), criteria.Namespace, criteria.IncludeIstioResources, criteria.IncludeOnlyDefinitions, criteria.ServiceSelector), true
+}
+
+// serviceListRevision returns the revision of the resources a service list is built from: the services, pods
+// and deployments of its namespace, the registry status and, when the list includes them, the Istio resources of
+// all the namespaces
+func serviceListRevision(criteria ServiceCriteria) uint64 {
+	revision := kialiCache.GetTypesRevision(criteria.Namespace, kubernetes.ServiceType, kubernetes.PodType, kubernetes.DeploymentType) +
+		kialiCache.GetTypesRevision("", cache.RegistryStatusType)
+	if criteria.IncludeIstioResources {
+		types := []string{cache.NamespaceType}
+		for _, resource := range serviceListIstioResources {
+			types = append(types, kubernetes.PluralType[resource])
+		}
+		revision += kialiCache.GetTypesRevision("", types...)
+	}
+	return revision
+}
+
+// copyServiceList copies a list kept in the service lists cache, so the callers can't change it
+func copyServiceList(serviceList *models.ServiceList) *models.ServiceList {
+	copied := *serviceList
+	copied.Services = append([]models.ServiceOverview{}, serviceList.Services...)
+	copied.Validations = serviceList.Validations.DeepCopy()
+	return &copied
 }
 
 func getVSKialiScenario(vs []networking_v1alpha3.VirtualService) string {
@@ -535,15 +599,14 @@ func (in *SvcService) GetServiceDetails(namespace, service, interval string, que
 
 func (in *SvcService) UpdateService(namespace, service string, interval string, queryTime time.Time, jsonPatch string) (*models.ServiceDetails, error) {
 	// Identify controller and apply patch to workload
+	revision := cacheTypeRevision(namespace, kubernetes.ServiceType)
 	err := updateService(in.businessLayer, namespace, service, jsonPatch)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
-	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
-	}
+	// Reads that follow an Update operation have to see it
+	waitForCacheChange(namespace, kubernetes.ServiceType, revision)
 
 	// After the update we fetch the whole workload
 	return in.GetServiceDetails(namespace, service, interval, queryTime)
@@ -3,9 +3,7 @@ package business
 import (
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	security_v1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
-	core_v1 "k8s.io/api/core/v1"
 
-	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/util/mtls"
@@ -35,7 +33,11 @@ func (in *TLSService) MeshWidemTLSStatus(namespaces []string) (models.MTLSStatus
 		return models.MTLSStatus{}, err
 	}
 
-	pas := kubernetes.FilterPeerAuthenticationByNamespace(config.Get().ExternalServices.Istio.RootNamespace, istioConfigList.PeerAuthentications)
+	// Every control plane has its mesh-wide PeerAuthentications in its root namespace
+	pas := []security_v1beta1.PeerAuthentication{}
+	for _, rootNamespace := range rootNamespaces(in.k8s) {
+		pas = append(pas, kubernetes.FilterPeerAuthenticationByNamespace(rootNamespace, istioConfigList.PeerAuthentications)...)
+	}
 	drs := kubernetes.FilterDestinationRulesByNamespaces(namespaces, istioConfigList.DestinationRules)
 
 	mtlsStatus := mtls.MtlsStatus{
@@ -66,8 +68,9 @@ func (in TLSService) NamespaceWidemTLSStatus(namespace string) (models.MTLSStatu
 		return models.MTLSStatus{}, err2
 	}
 
+	revision := namespaceRevision(in.businessLayer, namespace)
 	pas := kubernetes.FilterPeerAuthenticationByNamespace(namespace, istioConfigList.PeerAuthentications)
-	if config.IsRootNamespace(namespace) {
+	if namespace == rootNamespace(in.k8s, revision) {
 		pas = []security_v1beta1.PeerAuthentication{}
 	}
 	drs := kubernetes.FilterDestinationRulesByNamespaces(nss, istioConfigList.DestinationRules)
@@ -76,7 +79,7 @@ func (in TLSService) NamespaceWidemTLSStatus(namespace string) (models.MTLSStatu
 		Namespace:           namespace,
 		PeerAuthentications: pas,
 		DestinationRules:    drs,
-		AutoMtlsEnabled:     in.hasAutoMTLSEnabled(),
+		AutoMtlsEnabled:     in.hasAutoMTLSEnabledInRevision(revision),
 		AllowPermissive:     false,
 	}
 
@@ -131,18 +134,7 @@ func (in *TLSService) hasAutoMTLSEnabled() bool {
 		return *in.enabledAutoMtls
 	}
 
-	cfg := config.Get()
-	var istioConfig *core_v1.ConfigMap
-	var err error
-	if IsNamespaceCached(cfg.IstioNamespace) {
-		istioConfig, err = kialiCache.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
-	} else {
-		istioConfig, err = in.k8s.GetConfigMap(cfg.IstioNamespace, cfg.ExternalServices.Istio.ConfigMapName)
-	}
-	if err != nil {
-		return true
-	}
-	mc, err := kubernetes.GetIstioConfigMap(istioConfig)
+	mc, err := getMeshConfig(in.k8s, defaultRevision)
 	if err != nil {
 		return true
 	}
@@ -150,3 +142,16 @@ func (in *TLSService) hasAutoMTLSEnabled() bool {
 	in.enabledAutoMtls = &autoMtls
 	return autoMtls
 }
+
+// hasAutoMTLSEnabledInRevision tells whether auto mTLS is enabled in the mesh config of a revision
+func (in *TLSService) hasAutoMTLSEnabledInRevision(revision string) bool {
+	if revision == defaultRevision {
+		return in.hasAutoMTLSEnabled()
+	}
+
+	mc, err := getMeshConfig(in.k8s, revision)
+	if err != nil {
+		return true
+	}
+	return mc.GetEnableAutoMtls()
+}
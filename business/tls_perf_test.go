@@ -72,7 +72,7 @@ func testPerfScenario(exStatus string, nss []core_v1.Namespace, drs []networking
 	k8s.On("IsOpenShift").Return(false)
 	k8s.On("IsMaistraApi").Return(false)
 	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return(nss, nil)
-	k8s.On("GetToken").Return("token")
+	k8s.On("GetTokenHash").Return("token")
 	nsNames := []string{}
 	for _, ns := range nss {
 		k8s.On("GetNamespace", ns.Name).Return(&ns, nil)
@@ -34,7 +34,7 @@ func TestMeshStatusEnabled(t *testing.T) {
 	k8s.On("IsMaistraApi").Return(false)
 	k8s.On("IsOpenShift").Return(false)
 	k8s.On("GetNamespaces", mock.AnythingOfType("string")).Return(&core_v1.Namespace{}, nil)
-	k8s.On("GetToken").Return("token")
+	k8s.On("GetTokenHash").Return("token")
 
 	tlsService := getTLSService(k8s, false, ns, pa, dr)
 	status, err := (tlsService).MeshWidemTLSStatus(ns)
@@ -282,7 +282,7 @@ func TestNamespaceHasDestinationRuleEnabledDifferentNs(t *testing.T) {
 	k8s.On("IsMaistraApi").Return(false)
 	k8s.On("GetProjects", mock.AnythingOfType("string")).Return(projects, nil)
 	k8s.On("GetProject", mock.AnythingOfType("string")).Return(&projects[0], nil)
-	k8s.On("GetToken").Return("token")
+	k8s.On("GetTokenHash").Return("token")
 
 	autoMtls := false
 	kialiCache = cache.FakeTlsKialiCache("token", nss, ps, drs)
@@ -310,7 +310,7 @@ func testNamespaceScenario(exStatus string, drs []networking_v1alpha3.Destinatio
 	k8s.On("IsMaistraApi").Return(false)
 	k8s.On("GetProjects", mock.AnythingOfType("string")).Return(projects, nil)
 	k8s.On("GetProject", mock.AnythingOfType("string")).Return(&projects[0], nil)
-	k8s.On("GetToken").Return("token")
+	k8s.On("GetTokenHash").Return("token")
 
 	config.Set(config.NewConfig())
 
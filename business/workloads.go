@@ -285,14 +285,16 @@ func (in *WorkloadService) GetWorkload(namespace string, workloadName string, wo
 
 func (in *WorkloadService) UpdateWorkload(namespace string, workloadName string, workloadType string, includeServices bool, jsonPatch string) (*models.Workload, error) {
 	// Identify controller and apply patch to workload
+	revision := cacheTypeRevision(namespace, workloadType)
 	err := updateWorkload(in.businessLayer, namespace, workloadName, workloadType, jsonPatch)
 	if err != nil {
 		return nil, err
 	}
 
-	// Cache is stopped after a Create/Update/Delete operation to force a refresh
-	if kialiCache != nil && err == nil {
-		kialiCache.RefreshNamespace(namespace)
+	// Reads that follow an Update operation have to see it
+	// Without a type, the updated controller is unknown
+	if workloadType != "" {
+		waitForCacheChange(namespace, workloadType, revision)
 	}
 
 	// After the update we fetch the whole workload
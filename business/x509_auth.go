@@ -0,0 +1,112 @@
+package business
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/kiali/kiali/config"
+)
+
+const (
+	X509UsernameSourceSpiffe  = "spiffe"
+	X509UsernameSourceSubject = "subject"
+)
+
+// X509Identity is the user of a client certificate, impersonated on the Kubernetes API
+type X509Identity struct {
+	User   string
+	Groups []string
+}
+
+// LoadX509ClientCAs reads the bundle of the certificate authorities issuing the client certificates
+func LoadX509ClientCAs() (*x509.CertPool, error) {
+	path := config.Get().Auth.X509.ClientCAFile
+	bundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read client CA file [%s]: %w", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("client CA file [%s] has no PEM certificate", path)
+	}
+	return pool, nil
+}
+
+// GetX509Identity maps the client certificate of a connection to a user. The certificate must have been verified
+// by the TLS handshake against the client CAs.
+func GetX509Identity(state *tls.ConnectionState) (*X509Identity, error) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return nil, errors.New("no verified client certificate")
+	}
+	cert := state.VerifiedChains[0][0]
+	conf := config.Get().Auth.X509
+
+	if conf.UsernameSource == X509UsernameSourceSubject {
+		if cert.Subject.CommonName == "" {
+			return nil, errors.New("client certificate subject has no common name")
+		}
+		return &X509Identity{User: cert.Subject.CommonName, Groups: subjectGroups(cert.Subject.Organization, conf.SubjectGroups)}, nil
+	}
+
+	var spiffeId string
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			if spiffeId != "" {
+				return nil, errors.New("client certificate has more than one SPIFFE ID")
+			}
+			spiffeId = uri.String()
+		}
+	}
+	if spiffeId == "" {
+		return nil, errors.New("client certificate has no SPIFFE ID")
+	}
+	trustDomain, path := splitSpiffeId(spiffeId)
+	accepted := len(conf.TrustDomains) == 0
+	for _, domain := range conf.TrustDomains {
+		accepted = accepted || domain == trustDomain
+	}
+	if !accepted {
+		return nil, fmt.Errorf("SPIFFE trust domain [%s] is not accepted", trustDomain)
+	}
+
+	// Kubernetes doesn't add the groups of an impersonated service account, they are set like the API server does
+	segments := strings.Split(path, "/")
+	if conf.MapSpiffeServiceAccounts && len(segments) == 5 && segments[1] == "ns" && segments[3] == "sa" && segments[2] != "" && segments[4] != "" {
+		return &X509Identity{
+			User:   "system:serviceaccount:" + segments[2] + ":" + segments[4],
+			Groups: []string{"system:serviceaccounts", "system:serviceaccounts:" + segments[2], "system:authenticated"},
+		}, nil
+	}
+	return &X509Identity{User: spiffeId}, nil
+}
+
+// subjectGroups returns the organizations of a subject which are allowed to be mapped to groups. The groups of
+// Kubernetes are never mapped, an organization like system:masters would otherwise grant the cluster admin role.
+func subjectGroups(organizations []string, allowed []string) []string {
+	var groups []string
+	for _, organization := range organizations {
+		if strings.HasPrefix(organization, "system:") {
+			continue
+		}
+		for _, group := range allowed {
+			if organization == group {
+				groups = append(groups, organization)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// splitSpiffeId returns the trust domain and the path of a SPIFFE ID
+func splitSpiffeId(spiffeId string) (string, string) {
+	id := strings.TrimPrefix(spiffeId, "spiffe://")
+	if i := strings.Index(id, "/"); i >= 0 {
+		return id[:i], id[i:]
+	}
+	return id, ""
+}
@@ -0,0 +1,124 @@
+package business
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+)
+
+func verifiedConnection(cert *x509.Certificate) *tls.ConnectionState {
+	return &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+}
+
+func spiffeCertificate(ids ...string) *x509.Certificate {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "bot", Organization: []string{"ci"}}}
+	for _, id := range ids {
+		uri, _ := url.Parse(id)
+		cert.URIs = append(cert.URIs, uri)
+	}
+	return cert
+}
+
+func TestGetX509IdentityFromSpiffeId(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.Auth.X509.TrustDomains = []string{"cluster.local"}
+	config.Set(conf)
+
+	identity, err := GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://cluster.local/ns/ci/sa/pipeline")))
+	assert.NoError(err)
+	assert.Equal("system:serviceaccount:ci:pipeline", identity.User)
+	assert.Equal([]string{"system:serviceaccounts", "system:serviceaccounts:ci", "system:authenticated"}, identity.Groups)
+
+	identity, err = GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://cluster.local/tools/dashboard")))
+	assert.NoError(err)
+	assert.Equal("spiffe://cluster.local/tools/dashboard", identity.User)
+	assert.Empty(identity.Groups)
+
+	conf.Auth.X509.MapSpiffeServiceAccounts = false
+	config.Set(conf)
+	identity, err = GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://cluster.local/ns/ci/sa/pipeline")))
+	assert.NoError(err)
+	assert.Equal("spiffe://cluster.local/ns/ci/sa/pipeline", identity.User)
+
+	_, err = GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://example.com/ns/ci/sa/pipeline")))
+	assert.Error(err)
+	_, err = GetX509Identity(verifiedConnection(spiffeCertificate()))
+	assert.Error(err)
+	_, err = GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://cluster.local/a", "spiffe://cluster.local/b")))
+	assert.Error(err)
+	_, err = GetX509Identity(&tls.ConnectionState{})
+	assert.Error(err)
+	_, err = GetX509Identity(nil)
+	assert.Error(err)
+}
+
+func TestGetX509IdentityFromSubject(t *testing.T) {
+	conf := config.NewConfig()
+	conf.Auth.X509.UsernameSource = X509UsernameSourceSubject
+	config.Set(conf)
+
+	// Organizations aren't mapped to groups unless they are allowed
+	identity, err := GetX509Identity(verifiedConnection(spiffeCertificate("spiffe://cluster.local/ns/ci/sa/pipeline")))
+	assert.NoError(t, err)
+	assert.Equal(t, "bot", identity.User)
+	assert.Empty(t, identity.Groups)
+
+	conf.Auth.X509.SubjectGroups = []string{"ci", "system:masters"}
+	config.Set(conf)
+	cert := spiffeCertificate()
+	cert.Subject.Organization = []string{"ci", "qa", "system:masters"}
+	identity, err = GetX509Identity(verifiedConnection(cert))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ci"}, identity.Groups)
+
+	_, err = GetX509Identity(verifiedConnection(&x509.Certificate{}))
+	assert.Error(t, err)
+}
+
+func TestLoadX509ClientCAs(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "client-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	caFile, err := ioutil.TempFile("", "kiali-client-ca")
+	require.NoError(t, err)
+	defer os.Remove(caFile.Name())
+	require.NoError(t, pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	caFile.Close()
+
+	conf := config.NewConfig()
+	conf.Auth.X509.ClientCAFile = caFile.Name()
+	config.Set(conf)
+	pool, err := LoadX509ClientCAs()
+	assert.NoError(t, err)
+	assert.NotNil(t, pool)
+
+	require.NoError(t, ioutil.WriteFile(caFile.Name(), []byte("not a certificate"), 0600))
+	_, err = LoadX509ClientCAs()
+	assert.Error(t, err)
+}
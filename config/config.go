@@ -44,6 +44,7 @@ const (
 	AuthStrategyToken     = "token"
 	AuthStrategyOpenId    = "openid"
 	AuthStrategyHeader    = "header"
+	AuthStrategyX509      = "x509"
 
 	TokenCookieName             = "kiali-token"
 	AuthStrategyOpenshiftIssuer = "kiali-openshift"
@@ -67,25 +68,55 @@ const (
 	DashboardsDiscoveryAuto    = "auto"
 )
 
+// Tracing backends, as set in external_services.tracing.provider
+const (
+	TracingProviderJaeger = "jaeger"
+	TracingProviderTempo  = "tempo"
+)
+
 // Global configuration for the application.
 var configuration Config
 var rwMutex sync.RWMutex
 
+// AuditConfig describes where the audit events of the write operations are sent
+type AuditConfig struct {
+	File      AuditFileSink    `yaml:"file,omitempty"`
+	MaxEvents int              `yaml:"max_events,omitempty"` // Latest events retained in memory to be queried through the API
+	Stdout    bool             `yaml:"stdout,omitempty"`     // When true, events are written to the standard output as JSON lines
+	Webhook   AuditWebhookSink `yaml:"webhook,omitempty"`
+}
+
+// AuditFileSink writes the audit events as JSON lines to a file, rotated when it reaches a maximum size
+type AuditFileSink struct {
+	MaxBackups int    `yaml:"max_backups,omitempty"` // Rotated files kept, older ones are removed
+	MaxSize    int    `yaml:"max_size,omitempty"`    // Megabytes written to the file before it is rotated
+	Path       string `yaml:"path,omitempty"`        // The sink is disabled when empty
+}
+
+// AuditWebhookSink posts every audit event as JSON to an URL
+type AuditWebhookSink struct {
+	Auth      Auth   `yaml:"auth,omitempty"`
+	QueueSize int    `yaml:"queue_size,omitempty"` // Events waiting to be posted, new events are dropped when the queue is full
+	Timeout   int    `yaml:"timeout,omitempty"`    // Seconds
+	URL       string `yaml:"url,omitempty"`        // The sink is disabled when empty
+}
+
 // Server configuration
 type Server struct {
-	Address                    string `yaml:",omitempty"`
-	AuditLog                   bool   `yaml:"audit_log,omitempty"` // When true, allows additional audit logging on Write operations
-	CORSAllowAll               bool   `yaml:"cors_allow_all,omitempty"`
-	GzipEnabled                bool   `yaml:"gzip_enabled,omitempty"`
-	MetricsEnabled             bool   `yaml:"metrics_enabled,omitempty"`
-	MetricsPort                int    `yaml:"metrics_port,omitempty"`
-	Port                       int    `yaml:",omitempty"`
-	StaticContentRootDirectory string `yaml:"static_content_root_directory,omitempty"`
-	WebFQDN                    string `yaml:"web_fqdn,omitempty"`
-	WebPort                    string `yaml:"web_port,omitempty"`
-	WebRoot                    string `yaml:"web_root,omitempty"`
-	WebHistoryMode             string `yaml:"web_history_mode,omitempty"`
-	WebSchema                  string `yaml:"web_schema,omitempty"`
+	Address                    string      `yaml:",omitempty"`
+	Audit                      AuditConfig `yaml:"audit,omitempty"`
+	AuditLog                   bool        `yaml:"audit_log,omitempty"` // When true, write operations are recorded as audit events
+	CORSAllowAll               bool        `yaml:"cors_allow_all,omitempty"`
+	GzipEnabled                bool        `yaml:"gzip_enabled,omitempty"`
+	MetricsEnabled             bool        `yaml:"metrics_enabled,omitempty"`
+	MetricsPort                int         `yaml:"metrics_port,omitempty"`
+	Port                       int         `yaml:",omitempty"`
+	StaticContentRootDirectory string      `yaml:"static_content_root_directory,omitempty"`
+	WebFQDN                    string      `yaml:"web_fqdn,omitempty"`
+	WebPort                    string      `yaml:"web_port,omitempty"`
+	WebRoot                    string      `yaml:"web_root,omitempty"`
+	WebHistoryMode             string      `yaml:"web_history_mode,omitempty"`
+	WebSchema                  string      `yaml:"web_schema,omitempty"`
 }
 
 // Auth provides authentication data for external services
@@ -121,12 +152,29 @@ type PrometheusConfig struct {
 	// Enable cache for Prometheus queries
 	CacheEnabled bool `yaml:"cache_enabled,omitempty"`
 	// Global cache expiration expressed in seconds
-	CacheExpiration int               `yaml:"cache_expiration,omitempty"`
-	CustomHeaders   map[string]string `yaml:"custom_headers,omitempty"`
-	HealthCheckUrl  string            `yaml:"health_check_url,omitempty"`
-	IsCore          bool              `yaml:"is_core,omitempty"`
-	ThanosProxy     ThanosProxy       `yaml:"thanos_proxy,omitempty"`
-	URL             string            `yaml:"url,omitempty"`
+	CacheExpiration int `yaml:"cache_expiration,omitempty"`
+	// Maximum size of the cached query results expressed in bytes, least recently used results are evicted first
+	CacheMaxSize   int               `yaml:"cache_max_size,omitempty"`
+	CustomHeaders  map[string]string `yaml:"custom_headers,omitempty"`
+	HealthCheckUrl string            `yaml:"health_check_url,omitempty"`
+	IsCore         bool              `yaml:"is_core,omitempty"`
+	// Limits applied to the queries issued on behalf of a single API request
+	QueryBudget PrometheusQueryBudget `yaml:"query_budget,omitempty"`
+	ThanosProxy ThanosProxy           `yaml:"thanos_proxy,omitempty"`
+	URL         string                `yaml:"url,omitempty"`
+}
+
+// PrometheusQueryBudget limits the Prometheus work performed for a single API request (i.e. a graph or
+// a namespace health). When the budget is exhausted, or the timeout expires, the remaining queries are
+// skipped and the request returns partial results along with warnings. Zero values mean no limit, which
+// is the default.
+type PrometheusQueryBudget struct {
+	// Maximum number of queries issued per request
+	MaxQueries int `yaml:"max_queries,omitempty"`
+	// Maximum number of series returned per request
+	MaxSeries int `yaml:"max_series,omitempty"`
+	// Overall deadline for the queries of a request, expressed in seconds
+	Timeout int `yaml:"timeout,omitempty"`
 }
 
 // CustomDashboardsConfig describes configuration specific to Custom Dashboards
@@ -170,8 +218,9 @@ type TracingConfig struct {
 	InClusterURL         string   `yaml:"in_cluster_url"`
 	IsCore               bool     `yaml:"is_core,omitempty"`
 	NamespaceSelector    bool     `yaml:"namespace_selector"`
+	Provider             string   `yaml:"provider"` // "jaeger" (default) or "tempo"
 	URL                  string   `yaml:"url"`
-	UseGRPC              bool     `yaml:"use_grpc"`
+	UseGRPC              bool     `yaml:"use_grpc"` // Jaeger only
 	WhiteListIstioSystem []string `yaml:"whitelist_istio_system"`
 }
 
@@ -257,14 +306,24 @@ type AdditionalDisplayItem struct {
 // KubernetesConfig holds the k8s client, caching and performance configuration
 type KubernetesConfig struct {
 	Burst int `yaml:"burst,omitempty"`
+	// Cache uses a single set of watchers for all the namespaces instead of one set per namespace. It requires
+	// cluster wide list and watch permissions, lookups are still limited to the CacheNamespaces.
+	CacheClusterScoped bool `yaml:"cache_cluster_scoped,omitempty"`
 	// Cache duration expressed in seconds
-	// Cache uses watchers to sync with the backend, after a CacheDuration watchers are closed and re-opened
+	// Cache uses watchers to sync with the backend, the changes are applied as they are observed. CacheDuration is the
+	// resync period of the watchers and the maximum age of the Istio registry status.
 	CacheDuration int `yaml:"cache_duration,omitempty"`
 	// Enable cache for kubernetes and istio resources
 	CacheEnabled bool `yaml:"cache_enabled,omitempty"`
-	// Kiali can cache VirtualService,DestinationRule,Gateway and ServiceEntry Istio resources if they are present
-	// on this list of Istio types. Other Istio types are not yet supported.
+	// Field selector limiting the resources held by the cache, of all types, so only the metadata.name and
+	// metadata.namespace fields are supported. Resources not matching it are not visible in the cached namespaces.
+	CacheFieldSelector string `yaml:"cache_field_selector,omitempty"`
+	// Kiali can cache the Istio resources of the networking and security APIs if their types are present on this list
+	// of Istio types, i.e. VirtualService or PeerAuthentication.
 	CacheIstioTypes []string `yaml:"cache_istio_types,omitempty"`
+	// Label selector limiting the resources held by the cache, of all types, i.e. to reduce its memory usage.
+	// Resources not matching it are not visible in the cached namespaces.
+	CacheLabelSelector string `yaml:"cache_label_selector,omitempty"`
 	// List of namespaces or regex defining namespaces to include in a cache
 	CacheNamespaces []string `yaml:"cache_namespaces,omitempty"`
 	// Cache duration expressed in seconds
@@ -292,9 +351,43 @@ type ApiNamespacesConfig struct {
 
 // AuthConfig provides details on how users are to authenticate
 type AuthConfig struct {
+	ApiKeys   ApiKeysConfig   `yaml:"api_keys,omitempty"`
 	OpenId    OpenIdConfig    `yaml:"openid,omitempty"`
 	OpenShift OpenShiftConfig `yaml:"openshift,omitempty"`
-	Strategy  string          `yaml:"strategy,omitempty"`
+	// PolicyFile is the path of a Kiali policy restricting the features available to users and groups,
+	// on top of the privileges of their tokens. No restrictions apply when it's empty.
+	PolicyFile string     `yaml:"policy_file,omitempty"`
+	Strategy   string     `yaml:"strategy,omitempty"`
+	X509       X509Config `yaml:"x509,omitempty"`
+}
+
+// ApiKeysConfig contains the configuration of the API keys issued by Kiali to automation clients.
+// Store can be "memory", where keys are lost when Kiali restarts, or "configmap" to keep the keys in a ConfigMap
+// of the Kiali namespace, which requires privileges for the Kiali service account to write ConfigMaps there.
+type ApiKeysConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MaxExpirationSeconds is the longest validity of a key, also used when the key doesn't set its expiration
+	MaxExpirationSeconds int    `yaml:"max_expiration_seconds,omitempty"`
+	Store                string `yaml:"store,omitempty"`
+}
+
+// X509Config contains specific configuration for authentication with client certificates. The identity of the
+// certificate is impersonated on the Kubernetes API, which requires privileges for the Kiali service account to
+// impersonate users, groups and service accounts.
+type X509Config struct {
+	// ClientCAFile is the bundle of the certificate authorities issuing the client certificates
+	ClientCAFile string `yaml:"client_ca_file,omitempty"`
+	// MapSpiffeServiceAccounts maps the SPIFFE IDs of the Kubernetes service accounts, i.e.
+	// spiffe://<trust domain>/ns/<namespace>/sa/<name>, to the service account users
+	MapSpiffeServiceAccounts bool `yaml:"map_spiffe_service_accounts,omitempty"`
+	// SubjectGroups are the organizations of the subject mapped to groups by the "subject" username source, none
+	// when empty. The groups of Kubernetes, prefixed by "system:", are never mapped.
+	SubjectGroups []string `yaml:"subject_groups,omitempty"`
+	// TrustDomains are the SPIFFE trust domains accepted, any when empty
+	TrustDomains []string `yaml:"trust_domains,omitempty"`
+	// UsernameSource is "spiffe" to use the SPIFFE ID of the URI SAN as the user, or "subject" to use the common
+	// name of the subject as the user and its organizations allowed by SubjectGroups as the groups
+	UsernameSource string `yaml:"username_source,omitempty"`
 }
 
 // OpenShiftConfig contains specific configuration for authentication when on OpenShift
@@ -314,10 +407,12 @@ type OpenIdConfig struct {
 	ClientId                string            `yaml:"client_id,omitempty"`
 	ClientSecret            string            `yaml:"client_secret,omitempty"`
 	DisableRBAC             bool              `yaml:"disable_rbac,omitempty"`
+	GroupsClaim             string            `yaml:"groups_claim,omitempty"`
 	HTTPProxy               string            `yaml:"http_proxy,omitempty"`
 	HTTPSProxy              string            `yaml:"https_proxy,omitempty"`
 	InsecureSkipVerifyTLS   bool              `yaml:"insecure_skip_verify_tls,omitempty"`
 	IssuerUri               string            `yaml:"issuer_uri,omitempty"`
+	RefreshBeforeExpiry     int               `yaml:"refresh_before_expiry,omitempty"`
 	Scopes                  []string          `yaml:"scopes,omitempty"`
 	UsernameClaim           string            `yaml:"username_claim,omitempty"`
 }
@@ -395,9 +490,18 @@ type CertificatesInformationIndicators struct {
 	Secrets []string `yaml:"secrets,omitempty" json:"secrets,omitempty"`
 }
 
+// IstioConfigHistory defines configuration of the revisions kept for the Istio objects modified through Kiali
+type IstioConfigHistory struct {
+	Enabled                bool   `yaml:"enabled,omitempty" json:"enabled"`
+	MaxRevisions           int    `yaml:"max_revisions,omitempty" json:"maxRevisions"`                      // Revisions kept per object, the oldest ones are dropped first
+	RetentionAfterDeletion int    `yaml:"retention_after_deletion,omitempty" json:"retentionAfterDeletion"` // Seconds the history of a deleted object is kept
+	Store                  string `yaml:"store,omitempty" json:"store"`                                     // "memory", or "configmap" to keep them in a ConfigMap of the Kiali namespace per namespace
+}
+
 // KialiFeatureFlags available from the CR
 type KialiFeatureFlags struct {
 	CertificatesInformationIndicators CertificatesInformationIndicators `yaml:"certificates_information_indicators,omitempty" json:"certificatesInformationIndicators"`
+	IstioConfigHistory                IstioConfigHistory                `yaml:"istio_config_history,omitempty" json:"istioConfigHistory"`
 	IstioInjectionAction              bool                              `yaml:"istio_injection_action,omitempty" json:"istioInjectionAction"`
 	IstioUpgradeAction                bool                              `yaml:"istio_upgrade_action,omitempty" json:"istioUpgradeAction"`
 	UIDefaults                        UIDefaults                        `yaml:"ui_defaults,omitempty" json:"uiDefaults,omitempty"`
@@ -465,6 +569,11 @@ func NewConfig() (c *Config) {
 		},
 		Auth: AuthConfig{
 			Strategy: "anonymous",
+			ApiKeys: ApiKeysConfig{
+				Enabled:              false,
+				MaxExpirationSeconds: 7776000,
+				Store:                "memory",
+			},
 			OpenId: OpenIdConfig{
 				AdditionalRequestParams: map[string]string{},
 				AllowedDomains:          []string{},
@@ -476,14 +585,22 @@ func NewConfig() (c *Config) {
 				ClientId:                "",
 				ClientSecret:            "",
 				DisableRBAC:             false,
+				GroupsClaim:             "groups",
 				InsecureSkipVerifyTLS:   false,
 				IssuerUri:               "",
+				RefreshBeforeExpiry:     60,
 				Scopes:                  []string{"openid", "profile", "email"},
 				UsernameClaim:           "sub",
 			},
 			OpenShift: OpenShiftConfig{
 				ClientIdPrefix: "kiali",
 			},
+			X509: X509Config{
+				ClientCAFile:             "",
+				MapSpiffeServiceAccounts: true,
+				TrustDomains:             []string{},
+				UsernameSource:           "spiffe",
+			},
 		},
 		CustomDashboards: dashboards.GetBuiltInMonitoringDashboards(),
 		Deployment: DeploymentConfig{
@@ -564,8 +681,10 @@ func NewConfig() (c *Config) {
 				CacheDuration: 7,
 				// Prom Cache expires and it forces to repopulate cache
 				CacheExpiration: 300,
-				CustomHeaders:   map[string]string{},
-				URL:             "http://100.2.216.231:29090/prometheus",
+				// 100MB
+				CacheMaxSize:  100 * 1024 * 1024,
+				CustomHeaders: map[string]string{},
+				URL:           "http://100.2.216.231:29090/prometheus",
 			},
 			Tracing: TracingConfig{
 				Auth: Auth{
@@ -575,6 +694,7 @@ func NewConfig() (c *Config) {
 				InClusterURL:         "http://tracing.istio-system:16685/jaeger",
 				IsCore:               false,
 				NamespaceSelector:    true,
+				Provider:             TracingProviderJaeger,
 				URL:                  "",
 				UseGRPC:              true,
 				WhiteListIstioSystem: []string{"jaeger-query", "istio-ingressgateway"},
@@ -591,6 +711,12 @@ func NewConfig() (c *Config) {
 				Enabled: true,
 				Secrets: []string{"cacerts", "istio-ca-secret"},
 			},
+			IstioConfigHistory: IstioConfigHistory{
+				Enabled:                true,
+				MaxRevisions:           10,
+				RetentionAfterDeletion: 7 * 24 * 60 * 60,
+				Store:                  "memory",
+			},
 			IstioInjectionAction: true,
 			IstioUpgradeAction:   false,
 			UIDefaults: UIDefaults{
@@ -663,6 +789,18 @@ func NewConfig() (c *Config) {
 			SigningKey:        "kiali",
 		},
 		Server: Server{
+			Audit: AuditConfig{
+				File: AuditFileSink{
+					MaxBackups: 5,
+					MaxSize:    100,
+				},
+				MaxEvents: 1000,
+				Stdout:    true,
+				Webhook: AuditWebhookSink{
+					QueueSize: 1000,
+					Timeout:   10,
+				},
+			},
 			AuditLog:                   true,
 			GzipEnabled:                true,
 			MetricsEnabled:             true,
@@ -744,6 +882,7 @@ func (conf Config) String() (str string) {
 	obf.ExternalServices.Grafana.Auth.Obfuscate()
 	obf.ExternalServices.Prometheus.Auth.Obfuscate()
 	obf.ExternalServices.Tracing.Auth.Obfuscate()
+	obf.Server.Audit.Webhook.Auth.Obfuscate()
 	obf.Identity.Obfuscate()
 	obf.LoginToken.Obfuscate()
 	obf.Auth.OpenId.ClientSecret = "xxx"
@@ -898,3 +1037,17 @@ func IsIstioNamespace(namespace string) bool {
 func IsRootNamespace(namespace string) bool {
 	return namespace == configuration.ExternalServices.Istio.RootNamespace
 }
+
+// IsRootNamespaceOf returns true if the namespace is one of the root namespaces of the control planes, or the root
+// namespace when they are unknown
+func IsRootNamespaceOf(namespace string, rootNamespaces []string) bool {
+	if len(rootNamespaces) == 0 {
+		return IsRootNamespace(namespace)
+	}
+	for _, rootNamespace := range rootNamespaces {
+		if namespace == rootNamespace {
+			return true
+		}
+	}
+	return false
+}
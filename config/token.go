@@ -15,6 +15,11 @@ import (
 // See examples for how to use this with your own claim types
 type IanaClaims struct {
 	SessionId string `json:"sid,omitempty"`
+	// Groups of the user, used to bind the user to the roles of the Kiali policy
+	Groups []string `json:"groups,omitempty"`
+	// Tokens of the OpenId authorization code flow, only kept in the ciphered session cookie
+	IdToken      string `json:"idt,omitempty"`
+	RefreshToken string `json:"rt,omitempty"`
 	jwt.StandardClaims
 }
 
@@ -3,6 +3,7 @@ package main
 import (
 	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/graph/config/cytoscape"
 	"github.com/kiali/kiali/handlers"
@@ -34,7 +35,7 @@ type AggregateValueParam struct {
 	Name string `json:"aggregateValue"`
 }
 
-// swagger:parameters appMetrics appDetails graphApp graphAppVersion appDashboard appSpans appTraces errorTraces
+// swagger:parameters appMetrics appDetails graphApp graphAppVersion appDashboard appSpans appTraces appTracesAnalytics appTracesComparison errorTraces
 type AppParam struct {
 	// The app name (label value).
 	//
@@ -70,16 +71,45 @@ type ContainerParam struct {
 	Name string `json:"container"`
 }
 
-// swagger:parameters podProxyLogging
+// swagger:parameters podProxyLogging namespaceProxyLogging
 type LoggingParam struct {
-	// The log level for the pod's proxy.
+	// The log level for all the loggers of the pod's proxy. Required unless loggers is set.
 	//
 	// in: query
-	// required: true
+	// required: false
 	Level ProxyLogLevel `json:"level"`
+	// The log levels of some loggers of the pod's proxy, i.e. rbac:debug,jwt:trace
+	//
+	// in: query
+	// required: false
+	Loggers string `json:"loggers"`
+	// How long the levels are kept before the previous ones are restored, i.e. 15m. Kept until changed when not set.
+	//
+	// in: query
+	// required: false
+	TTL string `json:"ttl"`
+}
+
+// swagger:parameters namespaceProxyLogging
+type LoggingSelectorParam struct {
+	// The workload whose pods are updated.
+	//
+	// in: query
+	// required: false
+	Workload string `json:"workload"`
+	// The app whose pods are updated.
+	//
+	// in: query
+	// required: false
+	App string `json:"app"`
+	// The label selector of the pods to update.
+	//
+	// in: query
+	// required: false
+	LabelSelector string `json:"labelSelector"`
 }
 
-// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppVersion graphNamespace graphService graphWorkload namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations getIter8Experiments postIter8Experiments patchIter8Experiments deleteIter8Experiments podProxyDump podProxyResource podProxyLogging
+// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces appTracesAnalytics appTracesComparison serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppVersion graphNamespace graphService graphWorkload namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations getIter8Experiments postIter8Experiments patchIter8Experiments deleteIter8Experiments podProxyDump podProxyResource podProxyDumpDiff podProxyDumpSnapshot podRouteSimulation podProxyStats podProxyClusters podProxyServerInfo podProxyLogging podProxyLoggingRevert namespaceProxyLogging podProxySyncHistory istioConfigHistory istioConfigRevisionDiff istioConfigRevisionRestore
 type NamespaceParam struct {
 	// The namespace name.
 	//
@@ -97,7 +127,7 @@ type NameParam struct {
 	Name string `json:"name"`
 }
 
-// swagger:parameters istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype
+// swagger:parameters istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype istioConfigHistory istioConfigRevisionDiff istioConfigRevisionRestore
 type ObjectNameParam struct {
 	// The Istio object name.
 	//
@@ -106,7 +136,7 @@ type ObjectNameParam struct {
 	Name string `json:"object"`
 }
 
-// swagger:parameters istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype istioConfigCreate istioConfigCreateSubtype
+// swagger:parameters istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype istioConfigCreate istioConfigCreateSubtype istioConfigHistory istioConfigRevisionDiff istioConfigRevisionRestore
 type ObjectTypeParam struct {
 	// The Istio object type.
 	//
@@ -125,7 +155,7 @@ type ValidateParam struct {
 	Name string `json:"validate"`
 }
 
-// swagger:parameters podDetails podLogs podProxyDump podProxyResource podProxyLogging
+// swagger:parameters podDetails podLogs podProxyDump podProxyResource podProxyDumpDiff podProxyDumpSnapshot podRouteSimulation podProxyStats podProxyClusters podProxyServerInfo podProxyLogging podProxyLoggingRevert podProxySyncHistory
 type PodParam struct {
 	// The pod name.
 	//
@@ -136,13 +166,50 @@ type PodParam struct {
 
 // swagger:parameters podProxyResource
 type ResourceParam struct {
-	// The discovery service resource
+	// The discovery service resource: clusters, routes, bootstrap, listeners, secrets, endpoints or ecds
 	//
 	// in: path
 	// required: true
 	Name string `json:"resource"`
 }
 
+// swagger:parameters podProxyDumpDiff
+type ConfigDumpDiffParams struct {
+	// The ID of a snapshot of the pod's config, to compare with its live config.
+	//
+	// in: query
+	// required: false
+	Snapshot string `json:"snapshot"`
+	// The pod to compare with, when not comparing with a snapshot.
+	//
+	// in: query
+	// required: false
+	ComparePod string `json:"comparePod"`
+	// The namespace of the pod to compare with. Defaults to the namespace of the pod.
+	//
+	// in: query
+	// required: false
+	CompareNamespace string `json:"compareNamespace"`
+}
+
+// swagger:parameters podRouteSimulation
+type RouteSimulationParam struct {
+	// The request to simulate: host, port, path, method and headers.
+	//
+	// in: body
+	// required: true
+	Body models.RouteSimulationRequest
+}
+
+// swagger:parameters podProxyStats
+type ProxyStatsFilterParam struct {
+	// A regex the name of the stats must match, i.e. cluster\..*outlier_detection.*
+	//
+	// in: query
+	// required: false
+	Filter string `json:"filter"`
+}
+
 // swagger:parameters serviceDetails serviceUpdate serviceMetrics graphService graphAggregateByService serviceDashboard serviceSpans serviceTraces
 type ServiceParam struct {
 	// The service name.
@@ -152,6 +219,65 @@ type ServiceParam struct {
 	Name string `json:"service"`
 }
 
+// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics namespaceMetrics
+type MetricsFormatParam struct {
+	// The response format. CSV and OpenMetrics flatten the series, with their labels, for offline analysis.
+	// Parquet is not supported, it is rejected with a 400.
+	//
+	// in: query
+	// required: false
+	// enum: json,csv,openmetrics
+	// default: json
+	Name string `json:"format"`
+}
+
+// swagger:parameters serviceMetrics appMetrics workloadMetrics serviceDashboard appDashboard workloadDashboard
+type IncludeExemplarsParam struct {
+	// Adds the exemplars of the request duration histograms to the series, each one carrying the ID of its trace.
+	// Requires the Prometheus exemplar storage.
+	//
+	// in: query
+	// required: false
+	// default: false
+	Name bool `json:"includeExemplars"`
+}
+
+// swagger:parameters traceDetails
+type TraceCompareWithParam struct {
+	// ID of a second trace to compare the trace with
+	//
+	// in: query
+	// required: false
+	Name string `json:"compareWith"`
+}
+
+// swagger:parameters appTracesComparison
+type TraceBaselineStartParam struct {
+	// Start of the baseline time window, in microseconds since epoch
+	//
+	// in: query
+	// required: true
+	Name string `json:"baselineStartMicros"`
+}
+
+// swagger:parameters appTracesComparison
+type TraceBaselineEndParam struct {
+	// End of the baseline time window, in microseconds since epoch
+	//
+	// in: query
+	// required: true
+	Name string `json:"baselineEndMicros"`
+}
+
+// swagger:parameters appTracesAnalytics
+type TraceAnalyticsErrorTagsParam struct {
+	// Comma-separated span tags used to locate error hotspots. Defaults to http.status_code, grpc.status_code, response_flags, upstream_cluster and peer.service.
+	//
+	// in: query
+	// required: false
+	Name string `json:"errorTags"`
+}
+
 // swagger:parameters podLogs
 type SinceTimeParam struct {
 	// The start time for fetching logs. UNIX time in seconds. Default is all logs.
@@ -514,6 +640,20 @@ type BadRequestError struct {
 	} `json:"body"`
 }
 
+// A ForbiddenError is the error message that is generated when the request isn't allowed
+//
+// swagger:response forbiddenError
+type ForbiddenError struct {
+	// in: body
+	Body struct {
+		// HTTP status code
+		// example: 403
+		// default: 403
+		Code    int32 `json:"code"`
+		Message error `json:"message"`
+	} `json:"body"`
+}
+
 // A NotFoundError is the error message that is generated when server could not find what was requested.
 //
 // swagger:response notFoundError
@@ -668,6 +808,20 @@ type TraceDetailsResponse struct {
 	Body []jaegerModels.Trace
 }
 
+// Latency and error analytics computed over a set of traces
+// swagger:response traceAnalyticsResponse
+type TraceAnalyticsResponse struct {
+	// in:body
+	Body models.TraceAnalytics
+}
+
+// Call by call comparison of two traces or two populations of traces
+// swagger:response traceComparisonResponse
+type TraceComparisonResponse struct {
+	// in:body
+	Body models.TraceComparison
+}
+
 // Number of traces in error
 // swagger:response errorTracesResponse
 type ErrorTracesResponse struct {
@@ -780,6 +934,274 @@ type ConfigDumpResourceResponse struct {
 	Body map[string]interface{}
 }
 
+// Return the differences between two configurations of envoy proxies
+// swagger:response configDumpDiff
+type ConfigDumpDiffResponse struct {
+	// in:body
+	Body models.EnvoyConfigDiff
+}
+
+// Return the snapshot of the configuration of a given envoy proxy
+// swagger:response configDumpSnapshot
+type ConfigDumpSnapshotResponse struct {
+	// in:body
+	Body models.ConfigDumpSnapshot
+}
+
+// Return where an envoy proxy would send a request
+// swagger:response routeSimulation
+type RouteSimulationResponse struct {
+	// in:body
+	Body models.RouteSimulation
+}
+
+// Return the stats of an envoy proxy
+// swagger:response proxyStats
+type ProxyStatsResponse struct {
+	// in:body
+	Body models.ProxyStats
+}
+
+// Return the runtime state of the clusters of an envoy proxy
+// swagger:response proxyClusters
+type ProxyClustersResponse struct {
+	// in:body
+	Body models.ProxyClusters
+}
+
+// Return the state, version and uptime of an envoy proxy
+// swagger:response proxyServerInfo
+type ProxyServerInfoResponse struct {
+	// in:body
+	Body models.ProxyServerInfo
+}
+
+// Return the outcome of a proxy log level change for each pod
+// swagger:response proxyLoggingResults
+type ProxyLoggingResultsResponse struct {
+	// in:body
+	Body []models.ProxyLoggingResult
+}
+
+// Return the proxies whose log levels were raised through Kiali
+// swagger:response proxyLoggingList
+type ProxyLoggingListResponse struct {
+	// in:body
+	Body []models.ProxyLogging
+}
+
+// swagger:parameters meshStaleProxies
+type StaleProxiesThresholdParam struct {
+	// How long a proxy must have been out of sync to be listed, i.e. 5m. Defaults to 1m.
+	//
+	// in: query
+	// required: false
+	Threshold string `json:"threshold"`
+}
+
+// Return how the proxy of a pod synchronized with the control plane over time
+// swagger:response proxySyncHistory
+type ProxySyncHistoryResponse struct {
+	// in:body
+	Body models.ProxySyncHistory
+}
+
+// Return the proxies out of sync, grouped by istiod revision
+// swagger:response staleProxies
+type StaleProxiesResponse struct {
+	// in:body
+	Body []models.StaleProxies
+}
+
+// swagger:parameters istioUpgradePreCheck
+type IstioUpgradeNamespacesParam struct {
+	// Comma separated list of the namespaces to migrate.
+	//
+	// in: query
+	// required: true
+	Namespaces string `json:"namespaces"`
+}
+
+// swagger:parameters istioUpgradeMigrationCreate
+type IstioUpgradeMigrationParam struct {
+	// The namespaces to migrate, the number of namespaces migrated at once, and whether their workloads are restarted.
+	//
+	// in: body
+	// required: true
+	Body handlers.IstioUpgradeMigrationRequest
+}
+
+// swagger:parameters istioUpgradeMigrationDetails istioUpgradeMigrationRollback
+type IstioUpgradeMigrationIDParam struct {
+	// The id of the migration.
+	//
+	// in: path
+	// required: true
+	ID string `json:"migration"`
+}
+
+// Return the namespaces and workloads of each revision, and the outdated proxies
+// swagger:response istioUpgradeStatus
+type IstioUpgradeStatusResponse struct {
+	// in:body
+	Body models.IstioUpgradeStatus
+}
+
+// Return whether namespaces can be migrated to the upgrade revision
+// swagger:response upgradePreCheck
+type UpgradePreCheckResponse struct {
+	// in:body
+	Body models.UpgradePreCheck
+}
+
+// Return the progress of a migration to the upgrade revision
+// swagger:response istioUpgradeMigration
+type IstioUpgradeMigrationResponse struct {
+	// in:body
+	Body models.IstioUpgradeMigration
+}
+
+// Return the migrations to the upgrade revision
+// swagger:response istioUpgradeMigrations
+type IstioUpgradeMigrationsResponse struct {
+	// in:body
+	Body []models.IstioUpgradeMigration
+}
+
+// swagger:parameters auditEvents
+type AuditEventsParams struct {
+	// Only events of the user subject.
+	//
+	// in: query
+	// required: false
+	User string `json:"user"`
+	// Only events of the namespace.
+	//
+	// in: query
+	// required: false
+	Namespace string `json:"namespace"`
+	// Only events of the resource type, i.e. virtualservices, workloads, services, namespaces, proxylogging.
+	//
+	// in: query
+	// required: false
+	Resource string `json:"resource"`
+	// Only events of the resource name.
+	//
+	// in: query
+	// required: false
+	Name string `json:"name"`
+	// Only events of the operation, i.e. create, update, delete.
+	//
+	// in: query
+	// required: false
+	Verb string `json:"verb"`
+	// Only successful or failed operations, success or failure.
+	//
+	// in: query
+	// required: false
+	Result string `json:"result"`
+	// Only events at or after this time, in RFC3339 format.
+	//
+	// in: query
+	// required: false
+	Since string `json:"since"`
+	// Only events before this time, in RFC3339 format.
+	//
+	// in: query
+	// required: false
+	Until string `json:"until"`
+	// Maximum number of events returned. Defaults to 100.
+	//
+	// in: query
+	// required: false
+	Limit int `json:"limit"`
+}
+
+// Return the latest write operations made through Kiali
+// swagger:response auditEvents
+type AuditEventsResponse struct {
+	// in:body
+	Body []audit.Event
+}
+
+// swagger:parameters istioConfigRevisionDiff
+type IstioConfigRevisionDiffParams struct {
+	// The revision compared.
+	//
+	// in: query
+	// required: true
+	From int `json:"from"`
+	// The revision compared to.
+	//
+	// in: query
+	// required: true
+	To int `json:"to"`
+}
+
+// swagger:parameters istioConfigRevisionRestore
+type IstioConfigRevisionParam struct {
+	// The revision to restore.
+	//
+	// in: path
+	// required: true
+	Revision int `json:"revision"`
+}
+
+// Return the revisions kept for an Istio object modified through Kiali
+// swagger:response istioConfigHistory
+type IstioConfigHistoryResponse struct {
+	// in:body
+	Body models.IstioConfigHistory
+}
+
+// Return the fields that differ from a revision of an Istio object to another
+// swagger:response istioConfigRevisionDiff
+type IstioConfigRevisionDiffResponse struct {
+	// in:body
+	Body models.IstioConfigRevisionDiff
+}
+
+// swagger:parameters apiKeyCreate
+type ApiKeyCreateParams struct {
+	// The key to create.
+	//
+	// in: body
+	// required: true
+	Body models.ApiKeyRequest
+}
+
+// swagger:parameters apiKeyRevoke
+type ApiKeyParam struct {
+	// The API key identifier.
+	//
+	// in: path
+	// required: true
+	Name string `json:"key"`
+}
+
+// List of API keys
+// swagger:response apiKeys
+type ApiKeysResponse struct {
+	// in:body
+	Body []models.ApiKey
+}
+
+// Return the API key created, with its value
+// swagger:response apiKeyCreated
+type ApiKeyCreatedResponse struct {
+	// in:body
+	Body models.ApiKeyCreated
+}
+
+// swagger:parameters openidBackChannelLogout
+type OpenIdLogoutTokenParam struct {
+	// The logout token signed by the OpenID provider.
+	//
+	// in: formData
+	// required: true
+	Name string `json:"logout_token"`
+}
+
 //////////////////
 // SWAGGER MODELS
 //////////////////
@@ -827,6 +1249,13 @@ type IstioStatusResponse struct {
 	Body business.IstioComponentStatus
 }
 
+// Return the control planes of the mesh along their status
+// swagger:response istioControlPlanesResponse
+type IstioControlPlanesResponse struct {
+	// in: body
+	Body []business.ControlPlane
+}
+
 // Return a list of certificates information
 // swagger:response certsInfoResponse
 type CertsInfoResponse struct {
@@ -1,20 +1,25 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/graph/telemetry"
 	"github.com/kiali/kiali/graph/telemetry/istio"
+	"github.com/kiali/kiali/graph/telemetry/tracing"
+	"github.com/kiali/kiali/jaeger"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
 
-// GraphNamespaces generates a namespaces graph using the provided options
-func GraphNamespaces(business *business.Layer, o graph.Options) (code int, config interface{}) {
+// GraphNamespaces generates a namespaces graph using the provided options. The Prometheus queries are
+// bound to ctx and limited by the configured query budget, see prometheus.NewQueryBudgetContext.
+func GraphNamespaces(ctx context.Context, business *business.Layer, o graph.Options) (code int, config interface{}) {
 	// time how long it takes to generate this graph
 	promtimer := internalmetrics.GetGraphGenerationTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
 	defer promtimer.ObserveDuration()
@@ -23,7 +28,13 @@ func GraphNamespaces(business *business.Layer, o graph.Options) (code int, confi
 	case graph.VendorIstio:
 		prom, err := prometheus.NewClient()
 		graph.CheckError(err)
-		code, config = graphNamespacesIstio(business, prom, o)
+		budgetCtx, cancel := prometheus.NewQueryBudgetContext(ctx)
+		defer cancel()
+		code, config = graphNamespacesIstio(business, prom.WithContext(budgetCtx), o)
+	case graph.VendorTracing:
+		client, err := business.Jaeger.GetClient()
+		graph.CheckUnavailable(err)
+		code, config = graphNamespacesTracing(business, client, o)
 	default:
 		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
 	}
@@ -40,15 +51,22 @@ func graphNamespacesIstio(business *business.Layer, prom *prometheus.Client, o g
 	// Create a 'global' object to store the business. Global only to the request.
 	globalInfo := graph.NewAppenderGlobalInfo()
 	globalInfo.Business = business
+	globalInfo.PromClient = prom
 
 	trafficMap := istio.BuildNamespacesTrafficMap(o.TelemetryOptions, prom, globalInfo)
-	code, config = generateGraph(trafficMap, o)
+	if o.IncludeTraces {
+		client, err := business.Jaeger.GetClient()
+		graph.CheckUnavailable(err)
+		appendTraceTraffic(trafficMap, client, globalInfo, o)
+	}
+	code, config = generateGraph(trafficMap, o, prometheus.GetQueryBudget(prom.GetContext()).Warnings())
 
 	return code, config
 }
 
-// GraphNode generates a node graph using the provided options
-func GraphNode(business *business.Layer, o graph.Options) (code int, config interface{}) {
+// GraphNode generates a node graph using the provided options. The Prometheus queries are bound to ctx
+// and limited by the configured query budget, see prometheus.NewQueryBudgetContext.
+func GraphNode(ctx context.Context, business *business.Layer, o graph.Options) (code int, config interface{}) {
 	if len(o.Namespaces) != 1 {
 		graph.Error("Node graph does not support the 'namespaces' query parameter or the 'all' namespace")
 	}
@@ -61,7 +79,13 @@ func GraphNode(business *business.Layer, o graph.Options) (code int, config inte
 	case graph.VendorIstio:
 		prom, err := prometheus.NewClient()
 		graph.CheckError(err)
-		code, config = graphNodeIstio(business, prom, o)
+		budgetCtx, cancel := prometheus.NewQueryBudgetContext(ctx)
+		defer cancel()
+		code, config = graphNodeIstio(business, prom.WithContext(budgetCtx), o)
+	case graph.VendorTracing:
+		client, err := business.Jaeger.GetClient()
+		graph.CheckUnavailable(err)
+		code, config = graphNodeTracing(business, client, o)
 	default:
 		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
 	}
@@ -77,14 +101,47 @@ func graphNodeIstio(business *business.Layer, client *prometheus.Client, o graph
 	// Create a 'global' object to store the business. Global only to the request.
 	globalInfo := graph.NewAppenderGlobalInfo()
 	globalInfo.Business = business
+	globalInfo.PromClient = client
 
 	trafficMap := istio.BuildNodeTrafficMap(o.TelemetryOptions, client, globalInfo)
-	code, config = generateGraph(trafficMap, o)
+	code, config = generateGraph(trafficMap, o, prometheus.GetQueryBudget(client.GetContext()).Warnings())
 
 	return code, config
 }
 
-func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface{}) {
+// appendTraceTraffic adds to an Istio traffic map the edges only known from traces
+func appendTraceTraffic(trafficMap graph.TrafficMap, client jaeger.ClientInterface, globalInfo *graph.AppenderGlobalInfo, o graph.Options) {
+	tracing.AppendTraceTraffic(trafficMap, o.TelemetryOptions, client, globalInfo)
+
+	// traces can add nodes and turn traffic generators into destinations
+	for _, n := range trafficMap {
+		delete(n.Metadata, graph.IsRoot)
+	}
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o.TelemetryOptions)
+	telemetry.MarkTrafficGenerators(trafficMap)
+}
+
+// graphNamespacesTracing provides a test hook that accepts mock clients
+func graphNamespacesTracing(business *business.Layer, client jaeger.ClientInterface, o graph.Options) (code int, config interface{}) {
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap := tracing.BuildNamespacesTrafficMap(o.TelemetryOptions, client, globalInfo)
+	return generateGraph(trafficMap, o, nil)
+}
+
+// graphNodeTracing provides a test hook that accepts mock clients
+func graphNodeTracing(business *business.Layer, client jaeger.ClientInterface, o graph.Options) (code int, config interface{}) {
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+
+	trafficMap := tracing.BuildNodeTrafficMap(o.TelemetryOptions, client, globalInfo)
+	return generateGraph(trafficMap, o, nil)
+}
+
+// generateGraph produces the vendor config for the traffic map. Warnings are reported for the parts of the
+// graph that may be incomplete, typically because of a Prometheus query timeout.
+func generateGraph(trafficMap graph.TrafficMap, o graph.Options, warnings []prometheus.QueryWarning) (int, interface{}) {
 	log.Tracef("Generating config for [%s] graph...", o.ConfigVendor)
 
 	promtimer := internalmetrics.GetGraphMarshalTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
@@ -93,7 +150,9 @@ func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface
 	var vendorConfig interface{}
 	switch o.ConfigVendor {
 	case graph.VendorCytoscape:
-		vendorConfig = cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+		cytoscapeConfig := cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+		cytoscapeConfig.Warnings = warnings
+		vendorConfig = cytoscapeConfig
 	default:
 		graph.Error(fmt.Sprintf("ConfigVendor [%s] not supported", o.ConfigVendor))
 	}
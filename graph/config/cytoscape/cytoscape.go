@@ -21,6 +21,7 @@ import (
 	"strings"
 
 	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/prometheus"
 )
 
 // ResponseFlags is a map of maps. Each response code is broken down by responseFlags:percentageOfTraffic, e.g.:
@@ -93,6 +94,8 @@ type NodeData struct {
 	Pod                   string              `json:"pod,omitempty"`
 	Aggregate             string              `json:"aggregate,omitempty"`             // set like "<aggregate>=<aggregateVal>"
 	DestServices          []graph.ServiceName `json:"destServices,omitempty"`          // requested services for [dest] node
+	ExternalKind          string              `json:"externalKind,omitempty"`          // set for external nodes found in traces: [ 'database', 'messaging', 'service' ]
+	FromTraces            bool                `json:"fromTraces,omitempty"`            // true (only known from traces) | false
 	Traffic               []ProtocolTraffic   `json:"traffic,omitempty"`               // traffic rates for all detected protocols
 	HasCB                 bool                `json:"hasCB,omitempty"`                 // true (has circuit breaker) | false
 	HasFaultInjection     bool                `json:"hasFaultInjection,omitempty"`     // true (vs has fault injection) | false
@@ -123,6 +126,7 @@ type EdgeData struct {
 
 	// App Fields (not required by Cytoscape)
 	DestPrincipal   string          `json:"destPrincipal,omitempty"`   // principal used for the edge destination
+	FromTraces      bool            `json:"fromTraces,omitempty"`      // true (only known from traces) | false
 	IsMTLS          string          `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
 	ResponseTime    string          `json:"responseTime,omitempty"`    // in millis
 	SourcePrincipal string          `json:"sourcePrincipal,omitempty"` // principal used for the edge source
@@ -144,10 +148,11 @@ type Elements struct {
 }
 
 type Config struct {
-	Timestamp int64    `json:"timestamp"`
-	Duration  int64    `json:"duration"`
-	GraphType string   `json:"graphType"`
-	Elements  Elements `json:"elements"`
+	Timestamp int64                     `json:"timestamp"`
+	Duration  int64                     `json:"duration"`
+	GraphType string                    `json:"graphType"`
+	Elements  Elements                  `json:"elements"`
+	Warnings  []prometheus.QueryWarning `json:"warnings,omitempty"` // set when the graph is partial, e.g. on query timeouts
 }
 
 func nodeHash(id string) string {
@@ -268,6 +273,14 @@ func buildConfig(trafficMap graph.TrafficMap, nodes *[]*NodeWrapper, edges *[]*E
 			nd.IsRoot = val.(bool)
 		}
 
+		// node may be an external system only known from traces
+		if val, ok := n.Metadata[graph.FromTraces]; ok {
+			nd.FromTraces = val.(bool)
+		}
+		if val, ok := n.Metadata[graph.ExternalKind]; ok {
+			nd.ExternalKind = val.(string)
+		}
+
 		// node is not accessible to the current user
 		if val, ok := n.Metadata[graph.IsInaccessible]; ok {
 			nd.IsInaccessible = val.(bool)
@@ -441,6 +454,9 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 		throughput := val.(float64)
 		ed.Throughput = fmt.Sprintf("%.0f", throughput)
 	}
+	if val, ok := e.Metadata[graph.FromTraces]; ok {
+		ed.FromTraces = val.(bool)
+	}
 
 	// an edge represents traffic for at most one protocol
 	for _, p := range graph.Protocols {
@@ -17,6 +17,8 @@ const (
 	AggregateValue        MetadataKey = "aggregateValue"
 	DestPrincipal         MetadataKey = "destPrincipal"
 	DestServices          MetadataKey = "destServices"
+	ExternalKind          MetadataKey = "externalKind" // database | messaging | service, for external nodes found in traces
+	FromTraces            MetadataKey = "fromTraces"   // Identifies the nodes and edges only known from traces
 	HasCB                 MetadataKey = "hasCB"
 	HasFaultInjection     MetadataKey = "hasFaultInjection"
 	HasHealthConfig       MetadataKey = "hasHealthConfig"
@@ -23,6 +23,7 @@ import (
 const (
 	VendorCytoscape        string = "cytoscape"
 	VendorIstio            string = "istio"
+	VendorTracing          string = "tracing"
 	defaultConfigVendor    string = VendorCytoscape
 	defaultTelemetryVendor string = VendorIstio
 )
@@ -46,6 +47,7 @@ const (
 	defaultRateGrpc           string = RateRequests
 	defaultRateHttp           string = RateRequests
 	defaultRateTcp            string = RateSent
+	defaultIncludeTraces      bool   = false
 )
 
 const (
@@ -95,6 +97,7 @@ type TelemetryOptions struct {
 	AccessibleNamespaces map[string]time.Time
 	Appenders            RequestedAppenders // requested appenders, nil if param not supplied
 	IncludeIdleEdges     bool               // include edges with request rates of 0
+	IncludeTraces        bool               // add the edges only known from traces (istio vendor)
 	InjectServiceNodes   bool               // inject destination service nodes between source and destination nodes.
 	Namespaces           NamespaceInfoMap
 	Rates                RequestedRates
@@ -125,6 +128,7 @@ func NewOptions(r *net_http.Request) Options {
 	params := r.URL.Query()
 	var duration model.Duration
 	var includeIdleEdges bool
+	var includeTraces bool
 	var injectServiceNodes bool
 	var queryTime int64
 	appenders := RequestedAppenders{All: true}
@@ -134,6 +138,7 @@ func NewOptions(r *net_http.Request) Options {
 	durationString := params.Get("duration")
 	graphType := params.Get("graphType")
 	includeIdleEdgesString := params.Get("includeIdleEdges")
+	includeTracesString := params.Get("includeTraces")
 	injectServiceNodesString := params.Get("injectServiceNodes")
 	namespaces := params.Get("namespaces") // csl of namespaces
 	queryTimeString := params.Get("queryTime")
@@ -201,6 +206,15 @@ func NewOptions(r *net_http.Request) Options {
 			BadRequest(fmt.Sprintf("Invalid includeIdleEdges [%s]", includeIdleEdgesString))
 		}
 	}
+	if includeTracesString == "" {
+		includeTraces = defaultIncludeTraces
+	} else {
+		var includeTracesErr error
+		includeTraces, includeTracesErr = strconv.ParseBool(includeTracesString)
+		if includeTracesErr != nil {
+			BadRequest(fmt.Sprintf("Invalid includeTraces [%s]", includeTracesString))
+		}
+	}
 	if injectServiceNodesString == "" {
 		injectServiceNodes = defaultInjectServiceNodes
 	} else {
@@ -221,7 +235,7 @@ func NewOptions(r *net_http.Request) Options {
 	}
 	if telemetryVendor == "" {
 		telemetryVendor = defaultTelemetryVendor
-	} else if telemetryVendor != VendorIstio {
+	} else if telemetryVendor != VendorIstio && telemetryVendor != VendorTracing {
 		BadRequest(fmt.Sprintf("Invalid telemetryVendor [%s]", telemetryVendor))
 	}
 
@@ -338,6 +352,7 @@ func NewOptions(r *net_http.Request) Options {
 			AccessibleNamespaces: accessibleNamespaces,
 			Appenders:            appenders,
 			IncludeIdleEdges:     includeIdleEdges,
+			IncludeTraces:        includeTraces,
 			InjectServiceNodes:   injectServiceNodes,
 			Namespaces:           namespaceMap,
 			Rates:                rates,
@@ -11,6 +11,7 @@ import (
 
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
 
@@ -22,10 +23,15 @@ func promQuery(query string, queryTime time.Time, ctx context.Context, api prom_
 	log.Tracef("Appender query:\n%s&time=%v (now=%v, %v)\n", query, queryTime.Format(graph.TF), time.Now().Format(graph.TF), queryTime.Unix())
 
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Appender-" + a.Name())
-	value, warnings, err := api.Query(ctx, query, queryTime)
+	value, warnings, err := prometheus.CachedQuery(ctx, api, query, queryTime)
 	if warnings != nil && len(warnings) > 0 {
 		log.Warningf("promQuery. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
+	// a partial graph is preferable to a failed one, the appender just skips its decorations
+	if prometheus.IsPartialError(ctx, err) {
+		prometheus.GetQueryBudget(ctx).Warn("", a.Name(), err)
+		return model.Vector{}
+	}
 	graph.CheckUnavailable(err)
 	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
 
@@ -23,7 +23,6 @@ import (
 	"strings"
 	"time"
 
-	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
 	"github.com/kiali/kiali/graph"
@@ -104,7 +103,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 		populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 		// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
@@ -114,7 +113,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 		populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -124,7 +123,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 		populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 	}
 
@@ -155,7 +154,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
@@ -165,7 +164,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -175,7 +174,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -208,7 +207,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
@@ -218,7 +217,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -228,7 +227,7 @@ func buildNamespaceTrafficMap(namespace string, o graph.TelemetryOptions, client
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -569,7 +568,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			vector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			vector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &vector, metric, o)
 
 			// 1.b) query dest telemetry for requests to the service, serviced by service workloads
@@ -585,7 +584,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 		default:
 			graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 		}
-		inVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		inVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 		populateTrafficMap(trafficMap, &inVector, metric, o)
 
 		// 2) query for outbound traffic
@@ -625,7 +624,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 		default:
 			graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 		}
-		outVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		outVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 		populateTrafficMap(trafficMap, &outVector, metric, o)
 	}
 
@@ -693,7 +692,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) query for outbound traffic
@@ -733,7 +732,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -802,7 +801,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) query for outbound traffic
@@ -842,7 +841,7 @@ func buildNodeTrafficMap(cluster, namespace string, n graph.Node, o graph.Teleme
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -914,29 +913,37 @@ func buildAggregateNodeTrafficMap(namespace string, n graph.Node, o graph.Teleme
 	query := fmt.Sprintf(`(%s) OR (%s)`, httpQuery, tcpQuery)
 	*/
 	query := httpQuery
-	vector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+	vector := promQuery(query, time.Unix(o.QueryTime, 0), client, namespace, metric)
 	populateTrafficMap(trafficMap, &vector, metric, o)
 
 	return trafficMap
 }
 
-func promQuery(query string, queryTime time.Time, api prom_v1.API) model.Vector {
+// promQuery performs the query within the budget carried by the client's context. Queries that time out, or
+// that exceed the budget, produce an empty result and a warning for the namespace and metric, such that the
+// graph is returned partially rather than failing. Any other error panics with StatusServiceUnavailable.
+func promQuery(query string, queryTime time.Time, client *prometheus.Client, namespace, metric string) model.Vector {
 	if query == "" {
 		return model.Vector{}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(client.GetContext())
 	defer cancel()
+	budget := prometheus.GetQueryBudget(ctx)
 
 	// wrap with a round() to be in line with metrics api
 	query = fmt.Sprintf("round(%s,0.001)", query)
 	log.Tracef("Graph query:\n%s@time=%v (now=%v, %v)\n", query, queryTime.Format(graph.TF), time.Now().Format(graph.TF), queryTime.Unix())
 
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Generation")
-	value, warnings, err := api.Query(ctx, query, queryTime)
+	value, warnings, err := prometheus.CachedQuery(ctx, client.API(), query, queryTime)
 	if warnings != nil && len(warnings) > 0 {
 		log.Warningf("promQuery. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
+	if prometheus.IsPartialError(ctx, err) {
+		budget.Warn(namespace, metric, err)
+		return model.Vector{}
+	}
 	graph.CheckUnavailable(err)
 	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
 
@@ -0,0 +1,479 @@
+// Package tracing provides a graph/TelemetryVendor deriving the traffic from sampled traces.
+package tracing
+
+// Tracing.go is responsible for generating TrafficMaps from the traces stored in the tracing backend.
+// Prometheus only knows the hops instrumented by the mesh, traces also show the calls made to databases,
+// queues and other services that Istio never sees.
+//
+// The algorithm:
+//   Sample the traces of every app of the requested namespaces, over the requested time window.
+//   Every span whose parent span belongs to another app produces an edge from the parent app to the
+//   span's app. Leaf spans carrying a peer.service, db.system or messaging.system tag produce an edge
+//   to an external service node named after the tag.
+//
+// Rates are estimated from the number of sampled calls over the sampled period. When the sample limit is
+// reached for an app, only the period covered by the sampled traces is accounted for. Non-HTTP calls are
+// reported as HTTP requests, failed spans counting as 5xx.
+//
+// Supports one vendor-specific query parameter:
+//   tracesLimit: The maximum number of traces sampled per app (default: 100)
+//
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+const defaultTracesLimit = 100
+
+// Tags used to identify the nodes and the external calls
+const (
+	tagCanonicalRevision = "istio.canonical_revision"
+	tagCanonicalService  = "istio.canonical_service"
+	tagDBSystem          = "db.system"
+	tagError             = "error"
+	tagGRPCStatus        = "grpc.status_code"
+	tagHTTPStatus        = "http.status_code"
+	tagMessagingSystem   = "messaging.system"
+	tagNamespace         = "istio.namespace"
+	tagPeerService       = "peer.service"
+	tagRPCSystem         = "rpc.system"
+)
+
+// sampledTrace is a trace along with the rate that a single call in the trace accounts for
+type sampledTrace struct {
+	trace     jaegerModels.Trace
+	namespace string // the namespace the trace was sampled for
+	weight    float64
+}
+
+// BuildNamespacesTrafficMap is required by the graph/TelemtryVendor interface
+func BuildNamespacesTrafficMap(o graph.TelemetryOptions, client jaeger.ClientInterface, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	log.Tracef("Build trace-derived [%s] graph for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
+
+	trafficMap := graph.NewTrafficMap()
+	AppendTraceTraffic(trafficMap, o, client, globalInfo)
+
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o)
+	telemetry.MarkTrafficGenerators(trafficMap)
+
+	return trafficMap
+}
+
+// BuildNodeTrafficMap is required by the graph/TelemtryVendor interface
+func BuildNodeTrafficMap(o graph.TelemetryOptions, client jaeger.ClientInterface, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	checkGraphType(o.GraphType)
+	if o.NodeOptions.App == "" {
+		graph.BadRequest("Trace-derived node graphs are only supported for app nodes")
+	}
+	n := graph.NewNode(graph.Unknown, o.NodeOptions.Namespace, "", o.NodeOptions.Namespace, "", o.NodeOptions.App, o.NodeOptions.Version, o.GraphType, "")
+
+	log.Tracef("Build trace-derived graph for node [%+v]", n)
+
+	traces := fetchTraces(client, o.NodeOptions.Namespace, []string{o.NodeOptions.App}, o)
+	fullMap := graph.NewTrafficMap()
+	newBuilder(fullMap, o).addTraces(traces)
+
+	// keep only the edges leading to or from the node
+	trafficMap := graph.NewTrafficMap()
+	for _, source := range fullMap {
+		for _, e := range source.Edges {
+			if isSameApp(source, &n) || isSameApp(e.Dest, &n) {
+				trafficMap[source.ID] = source
+				trafficMap[e.Dest.ID] = e.Dest
+			}
+		}
+	}
+	for _, source := range trafficMap {
+		edges := source.Edges[:0]
+		for _, e := range source.Edges {
+			if isSameApp(source, &n) || isSameApp(e.Dest, &n) {
+				edges = append(edges, e)
+			}
+		}
+		source.Edges = edges
+	}
+
+	telemetry.MarkOutsideOrInaccessible(trafficMap, o)
+	telemetry.MarkTrafficGenerators(trafficMap)
+
+	return trafficMap
+}
+
+// AppendTraceTraffic adds the traffic derived from the traces of the requested namespaces to trafficMap,
+// typically a graph built from the Istio telemetry. The nodes already in trafficMap are reused, and the
+// edges already in trafficMap are left untouched, traces only add the edges that were not known.
+func AppendTraceTraffic(trafficMap graph.TrafficMap, o graph.TelemetryOptions, client jaeger.ClientInterface, globalInfo *graph.AppenderGlobalInfo) {
+	checkGraphType(o.GraphType)
+
+	b := newBuilder(trafficMap, o)
+	for _, namespace := range o.Namespaces {
+		apps, err := globalInfo.Business.App.GetAppList(namespace.Name, false)
+		graph.CheckError(err)
+
+		appNames := make([]string, 0, len(apps.Apps))
+		for _, app := range apps.Apps {
+			appNames = append(appNames, app.Name)
+		}
+		b.addTraces(fetchTraces(client, namespace.Name, appNames, o))
+	}
+}
+
+func checkGraphType(graphType string) {
+	// traces do not carry reliable workload information
+	if graphType != graph.GraphTypeApp && graphType != graph.GraphTypeVersionedApp {
+		graph.BadRequest(fmt.Sprintf("Trace-derived traffic requires graphType [%s] or [%s]", graph.GraphTypeApp, graph.GraphTypeVersionedApp))
+	}
+}
+
+func isSameApp(n1, n2 *graph.Node) bool {
+	return n1.Namespace == n2.Namespace && n1.App == n2.App && (n2.Version == "" || n1.Version == n2.Version)
+}
+
+// fetchTraces samples the traces of the given apps. A trace involving several apps is returned several times.
+func fetchTraces(client jaeger.ClientInterface, namespace string, apps []string, o graph.TelemetryOptions) []sampledTrace {
+	limit := defaultTracesLimit
+	if tracesLimit := o.Params.Get("tracesLimit"); tracesLimit != "" {
+		var err error
+		if limit, err = strconv.Atoi(tracesLimit); err != nil || limit <= 0 {
+			graph.BadRequest(fmt.Sprintf("Invalid tracesLimit [%s]", tracesLimit))
+		}
+	}
+
+	duration := o.Namespaces[namespace].Duration
+	if duration == 0 {
+		duration = o.Duration
+	}
+	end := time.Unix(o.QueryTime, 0)
+	query := models.TracingQuery{
+		Start: end.Add(-duration),
+		End:   end,
+		Limit: limit,
+	}
+
+	traces := []sampledTrace{}
+	for _, app := range apps {
+		response, err := client.GetAppTraces(namespace, app, query)
+		if err != nil {
+			graph.CheckUnavailable(fmt.Errorf("unable to fetch traces for app [%s.%s]: %v", app, namespace, err))
+		}
+		if response == nil || len(response.Data) == 0 {
+			continue
+		}
+
+		// When the limit is reached, the traces only cover the most recent part of the time window
+		period := duration
+		if len(response.Data) >= limit {
+			earliest := query.End
+			for _, t := range response.Data {
+				if start := traceStart(t); start.Before(earliest) {
+					earliest = start
+				}
+			}
+			if covered := query.End.Sub(earliest); covered > 0 && covered < period {
+				period = covered
+			}
+		}
+		weight := 1.0 / period.Seconds()
+
+		for _, t := range response.Data {
+			traces = append(traces, sampledTrace{trace: t, namespace: namespace, weight: weight})
+		}
+	}
+	return traces
+}
+
+func traceStart(t jaegerModels.Trace) time.Time {
+	var start uint64
+	for _, s := range t.Spans {
+		if start == 0 || s.StartTime < start {
+			start = s.StartTime
+		}
+	}
+	return time.Unix(0, int64(start)*int64(time.Microsecond))
+}
+
+// builder adds the traffic of the traces to a traffic map
+type builder struct {
+	trafficMap    graph.TrafficMap
+	o             graph.TelemetryOptions
+	knownEdges    map[string]bool        // the edges found in the traffic map before any trace is added
+	appNodes      map[string]*graph.Node // the app nodes by namespace, app and version, whatever their cluster
+	seenTraces    map[jaegerModels.TraceID]bool
+	responseTimes map[*graph.Edge]*responseTime
+}
+
+type responseTime struct {
+	total float64 // milliseconds
+	count int
+}
+
+func newBuilder(trafficMap graph.TrafficMap, o graph.TelemetryOptions) *builder {
+	b := &builder{
+		trafficMap:    trafficMap,
+		o:             o,
+		knownEdges:    make(map[string]bool),
+		appNodes:      make(map[string]*graph.Node),
+		seenTraces:    make(map[jaegerModels.TraceID]bool),
+		responseTimes: make(map[*graph.Edge]*responseTime),
+	}
+	for _, n := range trafficMap {
+		b.indexNode(n)
+		for _, e := range n.Edges {
+			b.knownEdges[edgeKey(n, e.Dest, e.Metadata[graph.ProtocolKey])] = true
+		}
+	}
+	return b
+}
+
+func (b *builder) indexNode(n *graph.Node) {
+	if n.NodeType == graph.NodeTypeApp {
+		b.appNodes[appKey(n.Namespace, n.App, n.Version)] = n
+	}
+}
+
+func appKey(namespace, app, version string) string {
+	return fmt.Sprintf("%s %s %s", namespace, app, version)
+}
+
+func edgeKey(source, dest *graph.Node, protocol interface{}) string {
+	return fmt.Sprintf("%s %s %v", source.ID, dest.ID, protocol)
+}
+
+func (b *builder) addTraces(traces []sampledTrace) {
+	for _, t := range traces {
+		// the same trace can be sampled for several apps, count it once
+		if b.seenTraces[t.trace.TraceID] {
+			continue
+		}
+		b.seenTraces[t.trace.TraceID] = true
+		b.addTrace(t)
+	}
+	for e, rt := range b.responseTimes {
+		e.Metadata[graph.ResponseTime] = rt.total / float64(rt.count)
+	}
+}
+
+func (b *builder) addTrace(t sampledTrace) {
+	spans := make(map[jaegerModels.SpanID]*jaegerModels.Span, len(t.trace.Spans))
+	nodes := make(map[jaegerModels.SpanID]*graph.Node, len(t.trace.Spans))
+	for i := range t.trace.Spans {
+		span := &t.trace.Spans[i]
+		spans[span.SpanID] = span
+		nodes[span.SpanID] = b.spanNode(t, span)
+	}
+
+	// spans having a child span in another node are not leaves
+	callsOut := make(map[jaegerModels.SpanID]bool)
+	for i := range t.trace.Spans {
+		span := &t.trace.Spans[i]
+		parentID := parentSpanID(span)
+		parent, ok := spans[parentID]
+		if !ok {
+			continue
+		}
+		source, dest := nodes[parentID], nodes[span.SpanID]
+		if source == nil || dest == nil || source.ID == dest.ID {
+			continue
+		}
+		callsOut[parentID] = true
+		b.addCall(source, dest, parent, span, t.weight)
+	}
+
+	for i := range t.trace.Spans {
+		span := &t.trace.Spans[i]
+		source := nodes[span.SpanID]
+		if source == nil || callsOut[span.SpanID] {
+			continue
+		}
+		if dest := b.externalNode(source, span); dest != nil {
+			b.addCall(source, dest, span, span, t.weight)
+		}
+	}
+}
+
+// addCall adds a call to the edge from source to dest. The status comes from the client span when available
+// and the response time from the server span.
+func (b *builder) addCall(source, dest *graph.Node, clientSpan, serverSpan *jaegerModels.Span, weight float64) {
+	protocol, code := spanStatus(clientSpan)
+	if code == "" {
+		protocol, code = spanStatus(serverSpan)
+	}
+	if code == "" {
+		code = "200"
+		if spanHasError(clientSpan) || spanHasError(serverSpan) {
+			code = "500"
+		}
+	}
+
+	var edge *graph.Edge
+	for _, e := range source.Edges {
+		if dest.ID == e.Dest.ID && e.Metadata[graph.ProtocolKey] == protocol {
+			edge = e
+			break
+		}
+	}
+	if edge == nil {
+		if b.knownEdges[edgeKey(source, dest, protocol)] {
+			return
+		}
+		edge = source.AddEdge(dest)
+		edge.Metadata[graph.ProtocolKey] = protocol
+		edge.Metadata[graph.FromTraces] = true
+	} else if _, ok := edge.Metadata[graph.FromTraces]; !ok {
+		// the edge is known from the Istio telemetry
+		return
+	}
+	graph.AddToMetadata(protocol, weight, code, "-", dest.Service, source.Metadata, dest.Metadata, edge.Metadata)
+
+	rt, ok := b.responseTimes[edge]
+	if !ok {
+		rt = &responseTime{}
+		b.responseTimes[edge] = rt
+	}
+	rt.total += float64(serverSpan.Duration) / 1000
+	rt.count++
+}
+
+// spanNode returns the app node the span belongs to, creating it if necessary
+func (b *builder) spanNode(t sampledTrace, span *jaegerModels.Span) *graph.Node {
+	process := span.Process
+	if process == nil {
+		if p, ok := t.trace.Processes[span.ProcessID]; ok {
+			process = &p
+		}
+	}
+
+	namespace := tagValue(span, process, tagNamespace)
+	app := tagValue(span, process, tagCanonicalService)
+	version := tagValue(span, process, tagCanonicalRevision)
+	if app == "" && process != nil {
+		app = process.ServiceName
+		// see jaeger.BuildServiceName
+		if config.Get().ExternalServices.Tracing.NamespaceSelector {
+			if i := strings.LastIndex(app, "."); i > 0 {
+				if _, ok := b.o.AccessibleNamespaces[app[i+1:]]; ok {
+					if namespace == "" {
+						namespace = app[i+1:]
+					}
+					app = app[:i]
+				}
+			}
+		}
+	}
+	if app == "" {
+		return nil
+	}
+	if namespace == "" {
+		// assume the span belongs to the namespace the trace was sampled for
+		namespace = t.namespace
+	}
+	if b.o.GraphType != graph.GraphTypeVersionedApp {
+		version = ""
+	}
+
+	if n, ok := b.appNodes[appKey(namespace, app, version)]; ok {
+		return n
+	}
+	return b.addNode(graph.NewNode(graph.Unknown, namespace, "", namespace, "", app, version, b.o.GraphType, ""))
+}
+
+// externalNode returns the node of the external system called by a leaf span, if any
+func (b *builder) externalNode(source *graph.Node, span *jaegerModels.Span) *graph.Node {
+	kind, name := "", ""
+	switch {
+	case spanTag(span, tagDBSystem) != "":
+		kind, name = "database", spanTag(span, tagPeerService)
+		if name == "" {
+			name = spanTag(span, tagDBSystem)
+		}
+	case spanTag(span, tagMessagingSystem) != "":
+		kind, name = "messaging", spanTag(span, tagPeerService)
+		if name == "" {
+			name = spanTag(span, tagMessagingSystem)
+		}
+	case spanTag(span, tagPeerService) != "":
+		kind, name = "service", spanTag(span, tagPeerService)
+	default:
+		return nil
+	}
+
+	id, _ := graph.Id(source.Cluster, source.Namespace, name, "", "", "", "", b.o.GraphType, "")
+	if n, ok := b.trafficMap[id]; ok {
+		return n
+	}
+	n := b.addNode(graph.NewNode(source.Cluster, source.Namespace, name, "", "", "", "", b.o.GraphType, ""))
+	n.Metadata[graph.FromTraces] = true
+	n.Metadata[graph.ExternalKind] = kind
+	n.Metadata[graph.IsServiceEntry] = &graph.SEInfo{
+		Hosts:     []string{name},
+		Location:  "MESH_EXTERNAL",
+		Namespace: source.Namespace,
+	}
+	return n
+}
+
+func (b *builder) addNode(n graph.Node) *graph.Node {
+	if existing, ok := b.trafficMap[n.ID]; ok {
+		return existing
+	}
+	b.trafficMap[n.ID] = &n
+	b.indexNode(&n)
+	return &n
+}
+
+func parentSpanID(span *jaegerModels.Span) jaegerModels.SpanID {
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.ChildOf {
+			return ref.SpanID
+		}
+	}
+	return span.ParentSpanID
+}
+
+// spanStatus returns the protocol and response code found in the span tags, code is empty when unknown
+func spanStatus(span *jaegerModels.Span) (protocol, code string) {
+	if status := spanTag(span, tagGRPCStatus); status != "" {
+		return graph.GRPC.Name, status
+	}
+	if spanTag(span, tagRPCSystem) == "grpc" {
+		return graph.GRPC.Name, ""
+	}
+	return graph.HTTP.Name, spanTag(span, tagHTTPStatus)
+}
+
+func spanHasError(span *jaegerModels.Span) bool {
+	return spanTag(span, tagError) == "true"
+}
+
+func tagValue(span *jaegerModels.Span, process *jaegerModels.Process, key string) string {
+	if value := spanTag(span, key); value != "" {
+		return value
+	}
+	if process != nil {
+		return findTag(process.Tags, key)
+	}
+	return ""
+}
+
+func spanTag(span *jaegerModels.Span, key string) string {
+	return findTag(span.Tags, key)
+}
+
+func findTag(tags []jaegerModels.KeyValue, key string) string {
+	for _, tag := range tags {
+		if tag.Key == key {
+			return fmt.Sprintf("%v", tag.Value)
+		}
+	}
+	return ""
+}
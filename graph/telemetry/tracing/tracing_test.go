@@ -0,0 +1,170 @@
+package tracing
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/jaeger/jaegertest"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+)
+
+func tag(key string, value interface{}) jaegerModels.KeyValue {
+	return jaegerModels.KeyValue{Key: key, Value: value}
+}
+
+func span(id, parent, process string, duration uint64, tags ...jaegerModels.KeyValue) jaegerModels.Span {
+	s := jaegerModels.Span{
+		SpanID:    jaegerModels.SpanID(id),
+		ProcessID: jaegerModels.ProcessID(process),
+		StartTime: uint64(time.Unix(1000, 0).UnixNano() / 1000),
+		Duration:  duration,
+		Tags:      tags,
+	}
+	if parent != "" {
+		s.References = []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, SpanID: jaegerModels.SpanID(parent)}}
+	}
+	return s
+}
+
+// productpage -> reviews (error), reviews -> mysql (db), reviews -> kafka (messaging)
+func fakeTrace(id string) jaegerModels.Trace {
+	return jaegerModels.Trace{
+		TraceID: jaegerModels.TraceID(id),
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "productpage.bookinfo"},
+			"p2": {ServiceName: "reviews.bookinfo", Tags: []jaegerModels.KeyValue{tag("istio.canonical_revision", "v2")}},
+		},
+		Spans: []jaegerModels.Span{
+			span("1", "", "p1", 50000),
+			span("2", "1", "p1", 40000, tag("http.status_code", 503)),
+			span("3", "2", "p2", 30000, tag("error", "true")),
+			span("4", "3", "p2", 10000, tag("db.system", "mysql"), tag("peer.service", "ratings-db")),
+			span("5", "3", "p2", 10000, tag("messaging.system", "kafka")),
+		},
+	}
+}
+
+func fakeOptions(graphType string) graph.TelemetryOptions {
+	return graph.TelemetryOptions{
+		AccessibleNamespaces: map[string]time.Time{"bookinfo": {}},
+		Namespaces:           graph.NamespaceInfoMap{"bookinfo": graph.NamespaceInfo{Name: "bookinfo", Duration: 100 * time.Second}},
+		CommonOptions: graph.CommonOptions{
+			Duration:  100 * time.Second,
+			GraphType: graphType,
+			Params:    url.Values{},
+			QueryTime: 1060,
+		},
+	}
+}
+
+func findEdge(trafficMap graph.TrafficMap, sourceID, destID string) *graph.Edge {
+	if source, ok := trafficMap[sourceID]; ok {
+		for _, e := range source.Edges {
+			if e.Dest.ID == destID {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+func TestAddTracesDerivesEdges(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap := graph.NewTrafficMap()
+	newBuilder(trafficMap, fakeOptions(graph.GraphTypeApp)).addTraces([]sampledTrace{
+		{trace: fakeTrace("t1"), namespace: "bookinfo", weight: 0.01},
+		{trace: fakeTrace("t1"), namespace: "bookinfo", weight: 0.01}, // sampled again for another app
+		{trace: fakeTrace("t2"), namespace: "bookinfo", weight: 0.01},
+	})
+
+	assert.Len(trafficMap, 4)
+	productpage := "app_unknown_bookinfo_productpage"
+	reviews := "app_unknown_bookinfo_reviews"
+
+	edge := findEdge(trafficMap, productpage, reviews)
+	assert.NotNil(edge)
+	assert.Equal(true, edge.Metadata[graph.FromTraces])
+	assert.Equal(graph.HTTP.Name, edge.Metadata[graph.ProtocolKey])
+	assert.Equal(30.0, edge.Metadata[graph.ResponseTime])
+	assert.InDelta(0.02, trafficMap[reviews].Metadata["httpIn"], 0.0001)
+	assert.InDelta(0.02, trafficMap[reviews].Metadata["httpIn5xx"], 0.0001)
+
+	db := trafficMap["svc_unknown_bookinfo_ratings-db"]
+	assert.NotNil(db)
+	assert.Equal("database", db.Metadata[graph.ExternalKind])
+	assert.Equal([]string{"ratings-db"}, db.Metadata[graph.IsServiceEntry].(*graph.SEInfo).Hosts)
+	assert.NotNil(findEdge(trafficMap, reviews, db.ID))
+
+	kafka := trafficMap["svc_unknown_bookinfo_kafka"]
+	assert.NotNil(kafka)
+	assert.Equal("messaging", kafka.Metadata[graph.ExternalKind])
+	assert.NotNil(findEdge(trafficMap, reviews, kafka.ID))
+}
+
+func TestAddTracesVersionedApp(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	trafficMap := graph.NewTrafficMap()
+	newBuilder(trafficMap, fakeOptions(graph.GraphTypeVersionedApp)).addTraces([]sampledTrace{{trace: fakeTrace("t1"), namespace: "bookinfo", weight: 1}})
+
+	assert.NotNil(findEdge(trafficMap, "app_unknown_bookinfo_productpage", "vapp_unknown_bookinfo_reviews_v2"))
+}
+
+func TestAddTracesKeepsKnownEdges(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	// the Istio telemetry already knows productpage -> reviews
+	trafficMap := graph.NewTrafficMap()
+	productpage := graph.NewNode("Kubernetes", "bookinfo", "", "bookinfo", "", "productpage", "", graph.GraphTypeApp, "")
+	reviews := graph.NewNode("Kubernetes", "bookinfo", "", "bookinfo", "", "reviews", "", graph.GraphTypeApp, "")
+	trafficMap[productpage.ID] = &productpage
+	trafficMap[reviews.ID] = &reviews
+	edge := productpage.AddEdge(&reviews)
+	edge.Metadata[graph.ProtocolKey] = graph.HTTP.Name
+	graph.AddToMetadata(graph.HTTP.Name, 5, "200", "-", "", productpage.Metadata, reviews.Metadata, edge.Metadata)
+
+	newBuilder(trafficMap, fakeOptions(graph.GraphTypeApp)).addTraces([]sampledTrace{{trace: fakeTrace("t1"), namespace: "bookinfo", weight: 1}})
+
+	// the app nodes are reused, whatever their cluster, and the known edge is untouched
+	assert.Len(trafficMap, 4)
+	assert.Len(productpage.Edges, 1)
+	assert.Equal(5.0, edge.Metadata["http"])
+	assert.Nil(edge.Metadata[graph.FromTraces])
+	assert.NotNil(findEdge(trafficMap, reviews.ID, "svc_Kubernetes_bookinfo_ratings-db"))
+}
+
+func TestFetchTracesEstimatesSampledPeriod(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	client := new(jaegertest.JaegerClientMock)
+	full := &jaeger.JaegerResponse{Data: []jaegerModels.Trace{fakeTrace("t1"), fakeTrace("t2")}}
+	partial := &jaeger.JaegerResponse{Data: []jaegerModels.Trace{fakeTrace("t3")}}
+	client.On("GetAppTraces", "bookinfo", "productpage", mock.Anything).Return(full, nil)
+	client.On("GetAppTraces", "bookinfo", "reviews", mock.Anything).Return(partial, nil)
+
+	o := fakeOptions(graph.GraphTypeApp)
+	o.Params.Set("tracesLimit", "2")
+	traces := fetchTraces(client, "bookinfo", []string{"productpage", "reviews"}, o)
+
+	assert.Len(traces, 3)
+	// the limit is reached for productpage, the traces cover [1000, 1060] only
+	assert.InDelta(1.0/60, traces[0].weight, 0.0001)
+	assert.InDelta(1.0/100, traces[2].weight, 0.0001)
+}
+
+func TestCheckGraphType(t *testing.T) {
+	assert.Panics(t, func() { checkGraphType(graph.GraphTypeWorkload) })
+	assert.NotPanics(t, func() { checkGraphType(graph.GraphTypeApp) })
+}
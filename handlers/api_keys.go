@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+// getApiKeyFromRequest returns the API key sent as a bearer token, empty when there is none or API keys are disabled
+func getApiKeyFromRequest(r *http.Request) string {
+	if !business.IsApiKeysEnabled() {
+		return ""
+	}
+	if token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "); business.IsApiKey(token) {
+		return token
+	}
+	return ""
+}
+
+func checkApiKeySession(r *http.Request, value string) (int, *models.ApiKey) {
+	apiKey, err := business.AuthenticateApiKey(value)
+	if err != nil {
+		log.Warningf("API key rejected: %v", err)
+		return http.StatusUnauthorized, nil
+	}
+	// Internal header used to propagate the subject of the request for audit and authorization purposes
+	r.Header.Add("Kiali-User", apiKey.Username())
+	return http.StatusOK, apiKey
+}
+
+func withApiKey(ctx context.Context, apiKey *models.ApiKey) context.Context {
+	return context.WithValue(ctx, "apiKey", apiKey)
+}
+
+// GetApiKey returns the API key that authenticated the request, nil when the request wasn't made with an API key
+func GetApiKey(r *http.Request) *models.ApiKey {
+	apiKey, _ := r.Context().Value("apiKey").(*models.ApiKey)
+	return apiKey
+}
+
+// ApiKeys lists the API keys of the ServiceAccounts the user can impersonate
+func ApiKeys(w http.ResponseWriter, r *http.Request) {
+	if !checkApiKeysEnabled(w) {
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	keys, err := business.ApiKeys.GetApiKeys()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, keys)
+}
+
+// ApiKeyCreate issues an API key, its value is only returned in this response
+func ApiKeyCreate(w http.ResponseWriter, r *http.Request) {
+	if !checkApiKeysEnabled(w) {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "API key request with bad body: "+err.Error())
+		return
+	}
+	request := models.ApiKeyRequest{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "API key request with bad body: "+err.Error())
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	created, err := business.ApiKeys.CreateApiKey(request, r.Header.Get("Kiali-User"))
+	event := audit.Event{Verb: audit.VerbCreate, Resource: "apikeys", Name: request.Name, Patch: audit.Patch(body)}
+	if err == nil {
+		event.After = audit.Object(created.ApiKey)
+	}
+	auditRecord(r, event, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, created)
+}
+
+// ApiKeyRevoke deletes an API key
+func ApiKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	if !checkApiKeysEnabled(w) {
+		return
+	}
+	id := mux.Vars(r)["key"]
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	revoked, err := business.ApiKeys.RevokeApiKey(id)
+	event := audit.Event{Verb: audit.VerbDelete, Resource: "apikeys", Message: "Key: " + id}
+	if err == nil {
+		event.Name = revoked.Name
+		event.Before = audit.Object(revoked)
+	}
+	auditRecord(r, event, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithCode(w, http.StatusOK)
+}
+
+func checkApiKeysEnabled(w http.ResponseWriter) bool {
+	if !business.IsApiKeysEnabled() {
+		RespondWithError(w, http.StatusNotFound, "API keys are disabled")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth_v1 "k8s.io/api/authorization/v1"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util"
+)
+
+func TestApiKeyAuthentication(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyToken
+	cfg.Auth.ApiKeys.Enabled = true
+	config.Set(cfg)
+	defer config.Set(config.NewConfig())
+	util.Clock = util.RealClock{}
+
+	k8s := new(kubetest.K8SClientMock)
+	k8s.On("IsOpenShift").Return(false)
+	k8s.On("GetNamespace", "bookinfo").Return(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}}, nil)
+	k8s.On("GetSelfSubjectAccessReview", "ci", "", "serviceaccounts", []string{"impersonate"}).Return([]*auth_v1.SelfSubjectAccessReview{{Status: auth_v1.SubjectAccessReviewStatus{Allowed: true}}}, nil)
+	created, err := business.NewWithBackends(k8s, nil, nil).ApiKeys.CreateApiKey(models.ApiKeyRequest{
+		Name:           "handlers-pipeline",
+		Namespaces:     []string{"bookinfo"},
+		Scope:          models.ApiKeyScopeRead,
+		ServiceAccount: models.ApiKeyServiceAccount{Namespace: "ci", Name: "pipeline"},
+	}, "alice")
+	require.NoError(t, err)
+	defer business.NewWithBackends(k8s, nil, nil).ApiKeys.RevokeApiKey(created.ID)
+
+	var user string
+	var apiKey *models.ApiKey
+	var authInfo *api.AuthInfo
+	handler := AuthenticationHandler{saToken: "kiali-token"}.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.Header.Get("Kiali-User")
+		apiKey = GetApiKey(r)
+		authInfo = r.Context().Value("authInfo").(*api.AuthInfo)
+	}))
+
+	request := httptest.NewRequest("GET", "http://kiali/api/namespaces", nil)
+	request.Header.Set("Authorization", "Bearer "+created.Key)
+	request.Header.Set("Kiali-User", "spoofed")
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "apikey:handlers-pipeline", user)
+	require.NotNil(t, apiKey)
+	assert.Equal(t, created.ID, apiKey.ID)
+	assert.Equal(t, "kiali-token", authInfo.Token)
+	assert.Equal(t, "system:serviceaccount:ci:pipeline", authInfo.Impersonate)
+
+	request = httptest.NewRequest("GET", "http://kiali/api/namespaces", nil)
+	request.Header.Set("Authorization", "Bearer "+created.Key+"x")
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+}
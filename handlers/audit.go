@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/authorization"
+)
+
+// auditRecord records a write operation made through the request. The user and the source address are taken from
+// the request, a failed operation is recorded with its error.
+func auditRecord(r *http.Request, event audit.Event, err error) {
+	if !audit.Enabled() {
+		return
+	}
+	// Internal header set by the authentication handler
+	event.User = r.Header.Get("Kiali-User")
+	event.SourceIP = r.RemoteAddr
+	if host, _, splitErr := net.SplitHostPort(r.RemoteAddr); splitErr == nil {
+		event.SourceIP = host
+	}
+	event.ForwardedFor = r.Header.Get("X-Forwarded-For")
+	if err != nil {
+		event.Result = audit.ResultFailure
+		event.Error = err.Error()
+	}
+	audit.Record(event)
+}
+
+// AuditEvents lists the latest write operations made through Kiali, newest first. The audit log is for the cluster
+// admins, and for the users granted the audit feature by the Kiali policy. For the latter, events of namespaces
+// not accessible by the user, and events not related to a namespace, are filtered out.
+func AuditEvents(w http.ResponseWriter, r *http.Request) {
+	if !audit.Enabled() {
+		RespondWithError(w, http.StatusNotFound, "Audit log is disabled")
+		return
+	}
+	query, err := readAuditQuery(r.URL.Query())
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+	admin, err := business.Namespace.IsClusterAdmin()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	if admin {
+		RespondWithJSON(w, http.StatusOK, audit.Events(query))
+		return
+	}
+	// Without a policy, nobody is granted the audit feature
+	if policy, err := authorization.GetPolicy(); err != nil || policy == nil {
+		RespondWithError(w, http.StatusForbidden, "Audit log is only available to cluster admins")
+		return
+	}
+
+	namespaces, err := business.Namespace.GetNamespaces()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	accessible := make(map[string]bool, len(namespaces))
+	for _, ns := range namespaces {
+		accessible[ns.Name] = true
+	}
+
+	limit := query.Limit
+	query.Limit = 0
+	events := []audit.Event{}
+	for _, event := range audit.Events(query) {
+		if accessible[event.Namespace] {
+			events = append(events, event)
+			if len(events) == limit {
+				break
+			}
+		}
+	}
+	RespondWithJSON(w, http.StatusOK, events)
+}
+
+func readAuditQuery(values url.Values) (audit.Query, error) {
+	q := audit.Query{
+		Limit:     100,
+		Name:      values.Get("name"),
+		Namespace: values.Get("namespace"),
+		Resource:  values.Get("resource"),
+		Result:    values.Get("result"),
+		User:      values.Get("user"),
+		Verb:      values.Get("verb"),
+	}
+	if v := values.Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return audit.Query{}, fmt.Errorf("Cannot parse parameter 'since': " + err.Error())
+		}
+		q.Since = since
+	}
+	if v := values.Get("until"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return audit.Query{}, fmt.Errorf("Cannot parse parameter 'until': " + err.Error())
+		}
+		q.Until = until
+	}
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return audit.Query{}, fmt.Errorf("Cannot parse parameter 'limit': must be a positive integer")
+		}
+		q.Limit = limit
+	}
+	return q, nil
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth_v1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func TestAuditRecord(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.Server.Audit = config.AuditConfig{MaxEvents: 10}
+	config.Set(conf)
+	audit.Stop()
+	defer audit.Stop()
+
+	r := httptest.NewRequest("PATCH", "/api/namespaces/bookinfo/services/reviews", nil)
+	r.RemoteAddr = "10.0.0.1:41234"
+	r.Header.Set("X-Forwarded-For", "192.168.1.10")
+	r.Header.Set("Kiali-User", "alice")
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "services", Namespace: "bookinfo", Name: "reviews"}, nil)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "services", Namespace: "bookinfo", Name: "ratings"}, errors.New("forbidden"))
+
+	events := audit.Events(audit.Query{User: "alice"})
+	require.Len(t, events, 2)
+	assert.Equal("ratings", events[0].Name)
+	assert.Equal(audit.ResultFailure, events[0].Result)
+	assert.Equal("forbidden", events[0].Error)
+	assert.Equal(audit.ResultSuccess, events[1].Result)
+	assert.Equal("10.0.0.1", events[1].SourceIP)
+	assert.Equal("192.168.1.10", events[1].ForwardedFor)
+}
+
+func TestReadAuditQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	q, err := readAuditQuery(url.Values{"user": {"alice"}, "verb": {"delete"}, "since": {"2021-10-01T12:00:00Z"}, "limit": {"5"}})
+	require.NoError(t, err)
+	assert.Equal("alice", q.User)
+	assert.Equal("delete", q.Verb)
+	assert.Equal(time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC), q.Since)
+	assert.True(q.Until.IsZero())
+	assert.Equal(5, q.Limit)
+
+	q, err = readAuditQuery(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(100, q.Limit)
+
+	_, err = readAuditQuery(url.Values{"since": {"yesterday"}})
+	assert.Error(err)
+	_, err = readAuditQuery(url.Values{"limit": {"0"}})
+	assert.Error(err)
+}
+
+func TestAuditEventsForClusterAdmins(t *testing.T) {
+	assert := assert.New(t)
+	conf := config.NewConfig()
+	conf.Server.Audit = config.AuditConfig{MaxEvents: 10}
+	config.Set(conf)
+	audit.Stop()
+	defer audit.Stop()
+	audit.Record(audit.Event{Verb: audit.VerbCreate, Resource: "apikeys", Name: "pipeline"})
+	audit.Record(audit.Event{Verb: audit.VerbUpdate, Resource: "services", Namespace: "bookinfo", Name: "reviews"})
+
+	for _, admin := range []bool{true, false} {
+		k8s := kubetest.NewK8SClientMock()
+		k8s.On("GetSelfSubjectAccessReview", "", "*", "*", []string{"*"}).Return([]*auth_v1.SelfSubjectAccessReview{{Status: auth_v1.SubjectAccessReviewStatus{Allowed: admin}}}, nil)
+		business.SetWithBackends(kubetest.NewK8SClientFactoryMock(k8s), nil)
+
+		r := httptest.NewRequest("GET", "/api/audit", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "authInfo", &api.AuthInfo{Token: "test"}))
+		w := httptest.NewRecorder()
+		AuditEvents(w, r)
+
+		if admin {
+			require.Equal(t, http.StatusOK, w.Code)
+			var events []audit.Event
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &events))
+			assert.Len(events, 2)
+		} else {
+			// Without a Kiali policy granting the audit feature, only the cluster admins get the events
+			assert.Equal(http.StatusForbidden, w.Code)
+		}
+	}
+}
@@ -19,10 +19,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/kiali/kiali/authorization"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/util"
 	"github.com/kiali/kiali/util/httputil"
 )
@@ -38,6 +40,8 @@ type AuthInfo struct {
 	LogoutRedirect        string      `json:"logoutRedirect,omitempty"`
 	SessionInfo           sessionInfo `json:"sessionInfo"`
 	SecretMissing         bool        `json:"secretMissing,omitempty"`
+	// Permissions of the user when a Kiali policy is configured
+	Permissions *authorization.Permissions `json:"permissions,omitempty"`
 }
 
 type sessionInfo struct {
@@ -370,6 +374,7 @@ func performHeaderAuthentication(w http.ResponseWriter, r *http.Request) bool {
 	timeExpire := util.Clock.Now().Add(time.Second * time.Duration(config.Get().LoginToken.ExpirationSeconds))
 	tokenClaims := config.IanaClaims{
 		SessionId: string(uuid.NewUUID()),
+		Groups:    authInfo.ImpersonateGroups,
 		StandardClaims: jwt.StandardClaims{
 			Subject:   tokenSubject,
 			ExpiresAt: timeExpire.Unix(),
@@ -474,6 +479,20 @@ func performTokenAuthentication(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+// performX509Authentication returns the identity of the client certificate. No session is created, the
+// certificate is presented on every request.
+func performX509Authentication(w http.ResponseWriter, r *http.Request) bool {
+	identity, err := business.GetX509Identity(r.TLS)
+	if err != nil {
+		RespondWithError(w, http.StatusUnauthorized, "Client certificate rejected: "+err.Error())
+		return false
+	}
+
+	expiresOn := r.TLS.VerifiedChains[0][0].NotAfter
+	RespondWithJSONIndent(w, http.StatusOK, TokenResponse{ExpiresOn: expiresOn.Format(time.RFC1123Z), Username: identity.User})
+	return true
+}
+
 func performOpenshiftLogout(r *http.Request) (int, error) {
 	tokenString := getTokenStringFromRequest(r)
 	if tokenString == "" {
@@ -533,6 +552,7 @@ func checkOpenshiftSession(w http.ResponseWriter, r *http.Request) (int, string)
 func checkOpenIdSession(w http.ResponseWriter, r *http.Request) (int, string) {
 	// First, check presence of a session for the "implicit flow"
 	var claims *config.IanaClaims
+	isAesSession := false
 
 	tokenString := getTokenStringFromRequest(r)
 	if len(tokenString) != 0 {
@@ -553,6 +573,7 @@ func checkOpenIdSession(w http.ResponseWriter, r *http.Request) (int, string) {
 			log.Warning("User seems to not be logged in")
 			return http.StatusUnauthorized, ""
 		}
+		isAesSession = true
 	}
 
 	// Session ID claim must be present
@@ -561,6 +582,37 @@ func checkOpenIdSession(w http.ResponseWriter, r *http.Request) (int, string) {
 		return http.StatusUnauthorized, ""
 	}
 
+	// The OpenId provider may have ended the session through a back-channel logout
+	if business.IsOpenIdSessionLoggedOut(business.GetOpenIdSessionIdToken(claims)) {
+		log.Infof("Session of user [%s] was ended by the OpenID provider", claims.Subject)
+		return http.StatusUnauthorized, ""
+	}
+
+	if isAesSession {
+		if !util.Clock.Now().Before(business.GetOpenIdSessionEnd(claims)) {
+			log.Infof("Session of user [%s] has reached the lifetime of Kiali sessions", claims.Subject)
+			return http.StatusUnauthorized, ""
+		}
+
+		// Renew the session of the "authorization code" flow shortly before the tokens expire, if there is a refresh token
+		refreshBefore := time.Duration(config.Get().Auth.OpenId.RefreshBeforeExpiry) * time.Second
+		if len(claims.RefreshToken) != 0 && !util.Clock.Now().Add(refreshBefore).Before(time.Unix(claims.ExpiresAt, 0)) {
+			if refreshedClaims, err := business.RefreshOpenIdSession(claims); err != nil {
+				log.Warningf("Could not refresh the session of user [%s]: %v", claims.Subject, err)
+			} else if err := setOpenIdAesSessionCookies(w, r, refreshedClaims); err != nil {
+				log.Warningf("Could not set the refreshed session of user [%s]: %v", claims.Subject, err)
+			} else {
+				claims = refreshedClaims
+			}
+		}
+
+		// Unlike the JWT of the "implicit" flow, the expiration of the session isn't checked when decoding it
+		if !util.Clock.Now().Before(time.Unix(claims.ExpiresAt, 0)) {
+			log.Infof("Session of user [%s] has expired", claims.Subject)
+			return http.StatusUnauthorized, ""
+		}
+	}
+
 	business, err := business.Get(&api.AuthInfo{Token: claims.SessionId})
 	if err != nil {
 		log.Warningf("Could not get the business layer!!: %v", err)
@@ -595,8 +647,11 @@ func checkOpenIdSession(w http.ResponseWriter, r *http.Request) (int, string) {
 		}
 	}
 
-	// Internal header used to propagate the subject of the request for audit purposes
+	// Internal headers used to propagate the subject of the request for audit and authorization purposes
 	r.Header.Add("Kiali-User", claims.Subject)
+	for _, group := range claims.Groups {
+		r.Header.Add("Kiali-Groups", group)
+	}
 	return http.StatusOK, claims.SessionId
 }
 
@@ -630,6 +685,23 @@ func checkTokenSession(w http.ResponseWriter, r *http.Request) (int, string) {
 	return http.StatusUnauthorized, ""
 }
 
+// checkX509Session authenticates the identity of the verified client certificate, which is impersonated on
+// the Kubernetes API with the Kiali token
+func checkX509Session(r *http.Request, saToken string) (int, *api.AuthInfo) {
+	identity, err := business.GetX509Identity(r.TLS)
+	if err != nil {
+		log.Warningf("Client certificate rejected: %v", err)
+		return http.StatusUnauthorized, nil
+	}
+
+	// Internal headers used to propagate the subject of the request for audit and authorization purposes
+	r.Header.Add("Kiali-User", identity.User)
+	for _, group := range identity.Groups {
+		r.Header.Add("Kiali-Groups", group)
+	}
+	return http.StatusOK, &api.AuthInfo{Token: saToken, Impersonate: identity.User, ImpersonateGroups: identity.Groups}
+}
+
 func NewAuthenticationHandler() (AuthenticationHandler, error) {
 	// Read token from the filesystem
 	saToken, err := kubernetes.GetKialiToken()
@@ -643,38 +715,59 @@ func (aHandler AuthenticationHandler) Handle(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		statusCode := http.StatusOK
 		conf := config.Get()
+		// The user of the audit events and its groups are only set below, once authenticated
+		r.Header.Del("Kiali-User")
+		r.Header.Del("Kiali-Groups")
 
 		var authInfo *api.AuthInfo
 		var token string
+		var apiKey *models.ApiKey
 
-		switch conf.Auth.Strategy {
-		case config.AuthStrategyOpenshift:
-			statusCode, token = checkOpenshiftSession(w, r)
-			authInfo = &api.AuthInfo{Token: token}
-		case config.AuthStrategyOpenId:
-			statusCode, token = checkOpenIdSession(w, r)
-			if conf.Auth.OpenId.DisableRBAC {
-				// If RBAC is off, it's assumed that the kubernetes cluster will reject the OpenId token.
-				// Instead, we use the Kiali token an this has the side effect that all users will share the
-				// same privileges.
-				token = aHandler.saToken
+		if apiKeyValue := getApiKeyFromRequest(r); apiKeyValue != "" {
+			// The API keys of automation clients are accepted whatever the strategy of the users
+			statusCode, apiKey = checkApiKeySession(r, apiKeyValue)
+			if apiKey != nil {
+				authInfo = &api.AuthInfo{Token: aHandler.saToken, Impersonate: apiKey.ServiceAccountUser()}
 			}
+		} else {
+			switch conf.Auth.Strategy {
+			case config.AuthStrategyOpenshift:
+				statusCode, token = checkOpenshiftSession(w, r)
+				authInfo = &api.AuthInfo{Token: token}
+			case config.AuthStrategyOpenId:
+				statusCode, token = checkOpenIdSession(w, r)
+				if conf.Auth.OpenId.DisableRBAC {
+					// If RBAC is off, it's assumed that the kubernetes cluster will reject the OpenId token.
+					// Instead, we use the Kiali token an this has the side effect that all users will share the
+					// same privileges.
+					token = aHandler.saToken
+				}
 
-			authInfo = &api.AuthInfo{Token: token}
-		case config.AuthStrategyToken:
-			statusCode, token = checkTokenSession(w, r)
-			authInfo = &api.AuthInfo{Token: token}
-		case config.AuthStrategyAnonymous:
-			log.Tracef("Access to the server endpoint is not secured with credentials - letting request come in. Url: [%s]", r.URL.String())
-			token = aHandler.saToken
-			authInfo = &api.AuthInfo{Token: token}
-		case config.AuthStrategyHeader:
-			log.Tracef("Using header for authentication, Url: [%s]", r.URL.String())
-			authInfo = getTokenStringFromHeader(r)
-			if authInfo == nil || authInfo.Token == "" {
-				statusCode = http.StatusUnauthorized
-			} else {
-				statusCode = http.StatusOK
+				authInfo = &api.AuthInfo{Token: token}
+			case config.AuthStrategyToken:
+				statusCode, token = checkTokenSession(w, r)
+				authInfo = &api.AuthInfo{Token: token}
+			case config.AuthStrategyAnonymous:
+				log.Tracef("Access to the server endpoint is not secured with credentials - letting request come in. Url: [%s]", r.URL.String())
+				token = aHandler.saToken
+				authInfo = &api.AuthInfo{Token: token}
+			case config.AuthStrategyHeader:
+				log.Tracef("Using header for authentication, Url: [%s]", r.URL.String())
+				authInfo = getTokenStringFromHeader(r)
+				if authInfo == nil || authInfo.Token == "" {
+					statusCode = http.StatusUnauthorized
+				} else {
+					statusCode = http.StatusOK
+					// The subject of the token is only known when impersonating, reviewing the token on each request is too costly
+					if authInfo.Impersonate != "" {
+						r.Header.Add("Kiali-User", authInfo.Impersonate)
+					}
+					for _, group := range authInfo.ImpersonateGroups {
+						r.Header.Add("Kiali-Groups", group)
+					}
+				}
+			case config.AuthStrategyX509:
+				statusCode, authInfo = checkX509Session(r, aHandler.saToken)
 			}
 		}
 
@@ -685,6 +778,9 @@ func (aHandler AuthenticationHandler) Handle(next http.Handler) http.Handler {
 				log.Errorf("No authInfo: %v", http.StatusBadRequest)
 			}
 			context := context.WithValue(r.Context(), "authInfo", authInfo)
+			if apiKey != nil {
+				context = withApiKey(context, apiKey)
+			}
 			next.ServeHTTP(w, r.WithContext(context))
 		case http.StatusUnauthorized:
 			deleteTokenCookies(w, r)
@@ -714,6 +810,8 @@ func Authenticate(w http.ResponseWriter, r *http.Request) {
 		performTokenAuthentication(w, r)
 	case config.AuthStrategyHeader:
 		performHeaderAuthentication(w, r)
+	case config.AuthStrategyX509:
+		performX509Authentication(w, r)
 	case config.AuthStrategyAnonymous:
 		log.Warning("Authentication attempt with anonymous access enabled.")
 	default:
@@ -751,10 +849,31 @@ func AuthenticationInfo(w http.ResponseWriter, r *http.Request) {
 		// Do the redirection through an intermediary own endpoint
 		response.AuthorizationEndpoint = fmt.Sprintf("%s/api/auth/openid_redirect",
 			httputil.GuessKialiURL(r))
+
+		// Same for the logout at the OpenId provider, if it's supported
+		if openIdMetadata, err := business.GetOpenIdMetadata(); err != nil {
+			log.Warningf("Error fetching OpenID provider metadata: %s", err.Error())
+		} else if len(openIdMetadata.EndSessionURL) != 0 {
+			response.LogoutEndpoint = fmt.Sprintf("%s/api/auth/openid_logout",
+				httputil.GuessKialiURL(r))
+		}
 	}
 
 	token := getTokenStringFromRequest(r)
 	claims, _ := config.GetTokenClaimsIfValid(token)
+	if conf.Auth.Strategy == config.AuthStrategyX509 {
+		// The client certificate is the session, its identity is set in claims for convenience
+		claims = nil
+		if identity, err := business.GetX509Identity(r.TLS); err == nil {
+			claims = &config.IanaClaims{
+				Groups: identity.Groups,
+				StandardClaims: jwt.StandardClaims{
+					Subject:   identity.User,
+					ExpiresAt: r.TLS.VerifiedChains[0][0].NotAfter.Unix(),
+				},
+			}
+		}
+	}
 	if claims == nil && conf.Auth.Strategy == config.AuthStrategyOpenId {
 		var aes error
 		claims, aes = business.GetOpenIdAesSession(r)
@@ -770,6 +889,19 @@ func AuthenticationInfo(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	policy, err := authorization.GetPolicy()
+	if err != nil {
+		log.Errorf("Kiali policy can't be loaded: %v", err)
+	} else if policy != nil && (claims != nil || conf.Auth.Strategy == config.AuthStrategyAnonymous) {
+		var permissions authorization.Permissions
+		if claims != nil {
+			permissions = policy.Permissions(claims.Subject, claims.Groups)
+		} else {
+			permissions = policy.Permissions("", nil)
+		}
+		response.Permissions = &permissions
+	}
+
 	RespondWithJSON(w, http.StatusOK, response)
 }
 
@@ -869,6 +1001,14 @@ func OpenIdRedirect(w http.ResponseWriter, r *http.Request) {
 		url.QueryEscape(fmt.Sprintf("%x-%s", csrfHash, nowTime.UTC().Format("060102150405"))),
 	)
 
+	if responseType == "code" {
+		// PKCE binds the authorization code to this login. The code verifier is derived from the nonce code.
+		redirectUri = fmt.Sprintf("%s&code_challenge=%s&code_challenge_method=S256",
+			redirectUri,
+			url.QueryEscape(business.OpenIdCodeChallenge(business.OpenIdCodeVerifier(nonceCode))),
+		)
+	}
+
 	if len(conf.Auth.OpenId.AdditionalRequestParams) > 0 {
 		urlParams := make([]string, 0, len(conf.Auth.OpenId.AdditionalRequestParams))
 		for k, v := range conf.Auth.OpenId.AdditionalRequestParams {
@@ -880,6 +1020,89 @@ func OpenIdRedirect(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectUri, http.StatusFound)
 }
 
+// OpenIdLogout ends the session of the user in Kiali and redirects the browser to the end session
+// endpoint of the OpenId provider, to end the session of the user in the provider too.
+func OpenIdLogout(w http.ResponseWriter, r *http.Request) {
+	conf := config.Get()
+
+	// This endpoint should be available only when OpenId strategy
+	if conf.Auth.Strategy != config.AuthStrategyOpenId {
+		RespondWithError(w, http.StatusNotFound, "OpenId strategy is not enabled")
+		return
+	}
+
+	openIdMetadata, err := business.GetOpenIdMetadata()
+	if err != nil {
+		RespondWithDetailedError(w, http.StatusInternalServerError, "Error fetching OpenID provider metadata.", err.Error())
+		return
+	}
+	if len(openIdMetadata.EndSessionURL) == 0 {
+		RespondWithError(w, http.StatusNotFound, "The OpenID provider doesn't support logging out users")
+		return
+	}
+
+	endSessionUrl, err := url.Parse(openIdMetadata.EndSessionURL)
+	if err != nil {
+		RespondWithDetailedError(w, http.StatusInternalServerError, "The end session endpoint of the OpenID provider is invalid.", err.Error())
+		return
+	}
+
+	// The post logout redirect URI must be allowed for the client in the OpenId provider
+	logoutParams := endSessionUrl.Query()
+	logoutParams.Set("client_id", conf.Auth.OpenId.ClientId)
+	logoutParams.Set("post_logout_redirect_uri", httputil.GuessKialiURL(r))
+
+	// The id_token lets the OpenId provider know which session to end without asking the user
+	var claims *config.IanaClaims
+	if tokenString := getTokenStringFromRequest(r); len(tokenString) != 0 {
+		claims, _ = config.GetTokenClaimsIfValid(tokenString)
+	} else {
+		claims, _ = business.GetOpenIdAesSession(r)
+	}
+	if claims != nil {
+		if idToken := business.GetOpenIdSessionIdToken(claims); len(idToken) != 0 {
+			logoutParams.Set("id_token_hint", idToken)
+		}
+	}
+	endSessionUrl.RawQuery = logoutParams.Encode()
+
+	deleteTokenCookies(w, r)
+	http.Redirect(w, r, endSessionUrl.String(), http.StatusFound)
+}
+
+// OpenIdBackChannelLogout receives the logout tokens sent by the OpenId provider when
+// the session of a user ends in the provider, to end the session of the user in Kiali too.
+func OpenIdBackChannelLogout(w http.ResponseWriter, r *http.Request) {
+	conf := config.Get()
+
+	// This endpoint should be available only when OpenId strategy
+	if conf.Auth.Strategy != config.AuthStrategyOpenId {
+		RespondWithError(w, http.StatusNotFound, "OpenId strategy is not enabled")
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if err := r.ParseForm(); err != nil {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Error parsing form info: %s", err.Error()))
+		return
+	}
+
+	logoutToken := r.PostForm.Get("logout_token")
+	if len(logoutToken) == 0 {
+		RespondWithError(w, http.StatusBadRequest, "The logout token is missing")
+		return
+	}
+
+	if err := business.OpenIdBackChannelLogout(logoutToken); err != nil {
+		log.Warningf("OpenID back-channel logout rejected: %s", err.Error())
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("Logout rejected: %s", err.Error()))
+		return
+	}
+
+	RespondWithCode(w, http.StatusOK)
+}
+
 func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 	conf := config.Get()
 	webRoot := conf.Server.WebRoot
@@ -986,42 +1209,57 @@ func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 	// "IanaClaims" type just for convenience to avoid creating new types and
 	// to bring some type convergence on types for the auth source code.
 	sessionData := business.BuildOpenIdJwtClaims(openIdParams, useAccessToken)
-	sessionDataJson, err := json.Marshal(sessionData)
-	if err != nil {
-		msg := fmt.Sprintf("Error when creating credentials - failed to marshal json: %s", err.Error())
+	if err := setOpenIdAesSessionCookies(w, r, sessionData); err != nil {
+		msg := fmt.Sprintf("Error when creating credentials - %s", err.Error())
 		log.Error(msg)
 		http.Redirect(w, r, fmt.Sprintf("%s?openid_error=%s", webRootWithSlash, url.QueryEscape(msg)), http.StatusFound)
 		return true
 	}
 
+	// Let's redirect (remove the openid params) to let the Kiali-UI to boot
+	http.Redirect(w, r, webRootWithSlash, http.StatusFound)
+
+	return true
+}
+
+// setOpenIdAesSessionCookies ciphers the session data of the OpenId "authorization code" flow
+// and sets it in the session cookies. Stale chunks of a previous session are dropped.
+func setOpenIdAesSessionCookies(w http.ResponseWriter, r *http.Request, sessionData *config.IanaClaims) error {
+	conf := config.Get()
+
+	sessionDataJson, err := json.Marshal(sessionData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal json: %w", err)
+	}
+
 	// Cipher the session data and encode to base64
 	block, err := aes.NewCipher([]byte(config.GetSigningKey()))
 	if err != nil {
-		msg := fmt.Sprintf("Error when creating credentials - failed to create cipher: %s", err.Error())
-		log.Error(msg)
-		http.Redirect(w, r, fmt.Sprintf("%s?openid_error=%s", webRootWithSlash, url.QueryEscape(msg)), http.StatusFound)
-		return true
+		return fmt.Errorf("failed to create cipher: %w", err)
 	}
 
 	aesGcm, err := cipher.NewGCM(block)
 	if err != nil {
-		msg := fmt.Sprintf("Error when creating credentials - failed to create gcm: %s", err.Error())
-		log.Error(msg)
-		http.Redirect(w, r, fmt.Sprintf("%s?openid_error=%s", webRootWithSlash, url.QueryEscape(msg)), http.StatusFound)
-		return true
+		return fmt.Errorf("failed to create gcm: %w", err)
 	}
 
 	aesGcmNonce, err := util.CryptoRandomBytes(aesGcm.NonceSize())
 	if err != nil {
-		msg := fmt.Sprintf("Error when creating credentials - failed to generate random bytes: %s", err.Error())
-		log.Error(msg)
-		http.Redirect(w, r, fmt.Sprintf("%s?openid_error=%s", webRootWithSlash, url.QueryEscape(msg)), http.StatusFound)
-		return true
+		return fmt.Errorf("failed to generate random bytes: %w", err)
 	}
 
 	cipherSessionData := aesGcm.Seal(aesGcmNonce, aesGcmNonce, sessionDataJson, nil)
 	base64SessionData := base64.StdEncoding.EncodeToString(cipherSessionData)
 
+	// The session expires with the tokens, unless it can be refreshed. Then, it's kept until the
+	// OpenId provider refuses to refresh it. Either way, it ends with the lifetime of Kiali sessions
+	// after the login, the refreshes don't extend it.
+	expiresOn := time.Unix(sessionData.ExpiresAt, 0)
+	sessionEnd := business.GetOpenIdSessionEnd(sessionData)
+	if len(sessionData.RefreshToken) != 0 || expiresOn.After(sessionEnd) {
+		expiresOn = sessionEnd
+	}
+
 	// If resulting session data is large, it may not fit in one cookie. So, the resulting
 	// session data is broken in chunks and multiple cookies are used, as is needed.
 	sessionDataChunks := chunkString(base64SessionData, business.SessionCookieMaxSize)
@@ -1040,7 +1278,7 @@ func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 		authCookie := http.Cookie{
 			Name:     cookieName,
 			Value:    chunk,
-			Expires:  openIdParams.ExpiresOn,
+			Expires:  expiresOn,
 			HttpOnly: true,
 			Path:     conf.Server.WebRoot,
 			SameSite: http.SameSiteStrictMode,
@@ -1048,6 +1286,33 @@ func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 		http.SetCookie(w, &authCookie)
 	}
 
+	// A refreshed session may need less chunks than the previous one
+	previousNumChunks := 0
+	if numChunksCookie, chunksCookieErr := r.Cookie(config.TokenCookieName + "-chunks"); chunksCookieErr == nil {
+		if numChunks, convErr := strconv.Atoi(numChunksCookie.Value); convErr == nil && numChunks <= 180 {
+			previousNumChunks = numChunks
+		}
+	}
+	staleCookies := make([]string, 0, previousNumChunks)
+	for i := len(sessionDataChunks); i < previousNumChunks; i++ {
+		staleCookies = append(staleCookies, fmt.Sprintf("%s-aes-%d", config.TokenCookieName, i))
+	}
+	if len(sessionDataChunks) == 1 && previousNumChunks != 0 {
+		staleCookies = append(staleCookies, config.TokenCookieName+"-chunks")
+	}
+	for _, cookieName := range staleCookies {
+		staleCookie := http.Cookie{
+			Name:     cookieName,
+			Value:    "",
+			Expires:  time.Unix(0, 0),
+			HttpOnly: true,
+			MaxAge:   -1,
+			Path:     conf.Server.WebRoot,
+			SameSite: http.SameSiteStrictMode,
+		}
+		http.SetCookie(w, &staleCookie)
+	}
+
 	if len(sessionDataChunks) > 1 {
 		// Set a cookie with the number of chunks of the session data.
 		// This is to protect against reading spurious chunks of data if there is
@@ -1055,7 +1320,7 @@ func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 		chunksCookie := http.Cookie{
 			Name:     config.TokenCookieName + "-chunks",
 			Value:    strconv.Itoa(len(sessionDataChunks)),
-			Expires:  openIdParams.ExpiresOn,
+			Expires:  expiresOn,
 			HttpOnly: true,
 			Path:     conf.Server.WebRoot,
 			SameSite: http.SameSiteStrictMode,
@@ -1063,10 +1328,7 @@ func OpenIdCodeFlowHandler(w http.ResponseWriter, r *http.Request) bool {
 		http.SetCookie(w, &chunksCookie)
 	}
 
-	// Let's redirect (remove the openid params) to let the Kiali-UI to boot
-	http.Redirect(w, r, webRootWithSlash, http.StatusFound)
-
-	return true
+	return nil
 }
 
 func deleteTokenCookies(w http.ResponseWriter, r *http.Request) {
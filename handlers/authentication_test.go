@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"regexp"
 	"strings"
 	"testing"
@@ -17,7 +20,9 @@ import (
 	osproject_v1 "github.com/openshift/api/project/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
@@ -535,6 +540,7 @@ func TestStrategyHeaderOidcWithImpersonationAuthentication(t *testing.T) {
 	request := httptest.NewRequest("POST", "http://kiali/api/authenticate", nil)
 	request.Header.Set("Authorization", "Bearer "+oidcToken)
 	request.Header.Set("Impersonate-User", "mmosley")
+	request.Header.Add("Impersonate-Group", "developers")
 	request.PostForm = form
 
 	// Add a stale token to the request. Authentication should succeed even if a stale
@@ -567,10 +573,35 @@ func TestStrategyHeaderOidcWithImpersonationAuthentication(t *testing.T) {
 	claimFromCookie := fromCookie.Claims.(*config.IanaClaims)
 
 	assert.Equal(t, "mmosley", claimFromCookie.Subject)
+	assert.Equal(t, []string{"developers"}, claimFromCookie.Groups)
 	assert.Equal(t, config.AuthStrategyHeaderIssuer, claimFromCookie.Issuer)
 	assert.True(t, IsValidUUID(claimFromCookie.SessionId))
 }
 
+func TestAuthenticationInfoPermissions(t *testing.T) {
+	policyFile, err := ioutil.TempFile("", "kiali-policy")
+	require.NoError(t, err)
+	defer os.Remove(policyFile.Name())
+	_, err = policyFile.WriteString("default_role: viewer\nroles:\n- name: viewer\n  allow:\n  - features: [graph]\n")
+	require.NoError(t, err)
+	policyFile.Close()
+
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyAnonymous
+	cfg.Auth.PolicyFile = policyFile.Name()
+	config.Set(cfg)
+	defer config.Set(config.NewConfig())
+
+	responseRecorder := httptest.NewRecorder()
+	AuthenticationInfo(responseRecorder, httptest.NewRequest("GET", "http://kiali/api/auth/info", nil))
+
+	var info AuthInfo
+	require.NoError(t, json.Unmarshal(responseRecorder.Body.Bytes(), &info))
+	require.NotNil(t, info.Permissions)
+	assert.Equal(t, []string{"viewer"}, info.Permissions.Roles)
+	assert.Equal(t, []string{"graph"}, info.Permissions.Allow[0].Features)
+}
+
 func mockK8s(reject bool) {
 	kubernetes.KialiToken = "notrealtoken"
 	k8s := kubetest.NewK8SClientMock()
@@ -596,3 +627,164 @@ func IsValidUUID(uuid string) bool {
 	r := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-5][0-9a-f]{3}-[089ab][0-9a-f]{3}-[0-9a-f]{12}$")
 	return r.MatchString(uuid)
 }
+
+func TestStrategyX509Authentication(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyX509
+	config.Set(cfg)
+	defer config.Set(config.NewConfig())
+
+	var user string
+	var authInfo *api.AuthInfo
+	handler := AuthenticationHandler{saToken: "kiali-token"}.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user = r.Header.Get("Kiali-User")
+		authInfo = r.Context().Value("authInfo").(*api.AuthInfo)
+	}))
+
+	spiffeId, _ := url.Parse("spiffe://cluster.local/ns/ci/sa/pipeline")
+	request := httptest.NewRequest("GET", "https://kiali/api/namespaces", nil)
+	request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{URIs: []*url.URL{spiffeId}}}}}
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	assert.Equal(t, "system:serviceaccount:ci:pipeline", user)
+	assert.Equal(t, "kiali-token", authInfo.Token)
+	assert.Equal(t, "system:serviceaccount:ci:pipeline", authInfo.Impersonate)
+	assert.Contains(t, authInfo.ImpersonateGroups, "system:serviceaccounts:ci")
+
+	// Requests without a verified client certificate are rejected
+	request = httptest.NewRequest("GET", "https://kiali/api/namespaces", nil)
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+}
+
+// TestStrategyOpenIdSessionRefresh checks that a session of the OpenId authorization code flow is
+// refreshed before the id_token expires, and that it can be logged out at the OpenId provider
+func TestStrategyOpenIdSessionRefresh(t *testing.T) {
+	clockTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: clockTime}
+
+	idToken := func(expiresOn time.Time) string {
+		signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "jdoe", "exp": expiresOn.Unix()}).SignedString([]byte("idp-key"))
+		return signed
+	}
+	refreshedIdToken := idToken(clockTime.Add(time.Hour))
+
+	var idp *httptest.Server
+	idp = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"issuer":                   idp.URL,
+				"authorization_endpoint":   idp.URL + "/auth",
+				"token_endpoint":           idp.URL + "/token",
+				"end_session_endpoint":     idp.URL + "/logout",
+				"response_types_supported": []string{"code"},
+				"scopes_supported":         []string{"openid", "profile", "email"},
+			})
+		case "/token":
+			if r.FormValue("grant_type") != "refresh_token" || r.FormValue("refresh_token") != "refresh-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"id_token": refreshedIdToken, "refresh_token": "refresh-2"})
+		}
+	}))
+	defer idp.Close()
+
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.OpenId.ClientId = "kiali"
+	cfg.Auth.OpenId.IssuerUri = idp.URL
+	cfg.LoginToken.SigningKey = "kiali-signing-key-of-32-chars-xx"
+	cfg.KubernetesConfig.CacheEnabled = false
+	config.Set(cfg)
+	defer config.Set(config.NewConfig())
+	mockK8s(false)
+
+	// The session expires in 30 seconds, so it's refreshed
+	sessionRecorder := httptest.NewRecorder()
+	require.NoError(t, setOpenIdAesSessionCookies(sessionRecorder, httptest.NewRequest("GET", "http://kiali/", nil), &config.IanaClaims{
+		SessionId:      idToken(clockTime.Add(30 * time.Second)),
+		RefreshToken:   "refresh-1",
+		StandardClaims: jwt.StandardClaims{Subject: "jdoe", ExpiresAt: clockTime.Add(30 * time.Second).Unix()},
+	}))
+
+	var authInfo *api.AuthInfo
+	handler := AuthenticationHandler{saToken: "kiali-token"}.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authInfo = r.Context().Value("authInfo").(*api.AuthInfo)
+	}))
+	request := httptest.NewRequest("GET", "http://kiali/api/namespaces", nil)
+	for _, cookie := range sessionRecorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	responseRecorder := httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+
+	assert.Equal(t, http.StatusOK, responseRecorder.Code)
+	require.NotNil(t, authInfo)
+	assert.Equal(t, refreshedIdToken, authInfo.Token)
+
+	refreshedRequest := httptest.NewRequest("GET", "http://kiali/api/auth/openid_logout", nil)
+	for _, cookie := range responseRecorder.Result().Cookies() {
+		assert.Equal(t, clockTime.Add(time.Duration(cfg.LoginToken.ExpirationSeconds)*time.Second).Unix(), cookie.Expires.Unix())
+		refreshedRequest.AddCookie(cookie)
+	}
+	session, err := business.GetOpenIdAesSession(refreshedRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-2", session.RefreshToken)
+	assert.Equal(t, clockTime.Add(time.Hour).Unix(), session.ExpiresAt)
+
+	// The logout of the session is sent to the OpenId provider with the id_token
+	responseRecorder = httptest.NewRecorder()
+	OpenIdLogout(responseRecorder, refreshedRequest)
+	assert.Equal(t, http.StatusFound, responseRecorder.Code)
+	logoutUrl, err := url.Parse(responseRecorder.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, idp.URL+"/logout", fmt.Sprintf("%s://%s%s", logoutUrl.Scheme, logoutUrl.Host, logoutUrl.Path))
+	assert.Equal(t, refreshedIdToken, logoutUrl.Query().Get("id_token_hint"))
+	assert.Equal(t, "kiali", logoutUrl.Query().Get("client_id"))
+	for _, cookie := range responseRecorder.Result().Cookies() {
+		assert.Empty(t, cookie.Value)
+	}
+
+	// The login uses PKCE
+	responseRecorder = httptest.NewRecorder()
+	OpenIdRedirect(responseRecorder, httptest.NewRequest("GET", "http://kiali/api/auth/openid_redirect", nil))
+	assert.Contains(t, responseRecorder.Header().Get("Location"), "&code_challenge_method=S256")
+
+	// The refreshes don't extend the session beyond the lifetime of Kiali sessions after the login
+	lifetime := time.Duration(cfg.LoginToken.ExpirationSeconds) * time.Second
+	sessionRecorder = httptest.NewRecorder()
+	require.NoError(t, setOpenIdAesSessionCookies(sessionRecorder, httptest.NewRequest("GET", "http://kiali/", nil), &config.IanaClaims{
+		SessionId:      refreshedIdToken,
+		RefreshToken:   "refresh-2",
+		StandardClaims: jwt.StandardClaims{Subject: "jdoe", ExpiresAt: clockTime.Add(time.Hour).Unix(), IssuedAt: clockTime.Add(time.Minute - lifetime).Unix()},
+	}))
+	request = httptest.NewRequest("GET", "http://kiali/api/namespaces", nil)
+	for _, cookie := range sessionRecorder.Result().Cookies() {
+		assert.Equal(t, clockTime.Add(time.Minute).Unix(), cookie.Expires.Unix())
+		request.AddCookie(cookie)
+	}
+	util.Clock = util.ClockMock{Time: clockTime.Add(time.Minute)}
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+
+	// Without a refresh token, an expired session is rejected
+	util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Hour)}
+	sessionRecorder = httptest.NewRecorder()
+	require.NoError(t, setOpenIdAesSessionCookies(sessionRecorder, httptest.NewRequest("GET", "http://kiali/", nil), &config.IanaClaims{
+		SessionId:      refreshedIdToken,
+		StandardClaims: jwt.StandardClaims{Subject: "jdoe", ExpiresAt: clockTime.Add(time.Hour).Unix()},
+	}))
+	request = httptest.NewRequest("GET", "http://kiali/api/namespaces", nil)
+	for _, cookie := range sessionRecorder.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	responseRecorder = httptest.NewRecorder()
+	handler.ServeHTTP(responseRecorder, request)
+	assert.Equal(t, http.StatusUnauthorized, responseRecorder.Code)
+}
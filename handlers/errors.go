@@ -19,10 +19,12 @@ func handleErrorResponse(w http.ResponseWriter, err error, extraMesg ...string)
 		errorMsg = strings.Join(extraMesg, ";")
 	}
 	log.Error(errorMsg)
-	if business.IsAccessibleError(err) {
+	if business.IsAccessibleError(err) || errors.IsForbidden(err) {
 		RespondWithError(w, http.StatusForbidden, errorMsg)
 	} else if errors.IsNotFound(err) {
 		RespondWithError(w, http.StatusNotFound, errorMsg)
+	} else if errors.IsBadRequest(err) {
+		RespondWithError(w, http.StatusBadRequest, errorMsg)
 	} else if errors.IsServiceUnavailable(err) {
 		RespondWithError(w, http.StatusServiceUnavailable, errorMsg)
 	} else if statusError, isStatus := err.(*errors.StatusError); isStatus {
@@ -21,10 +21,11 @@ package handlers
 //   configVendor:    default: cytoscape
 //   duration:        time.Duration indicating desired query range duration, (default: 10m)
 //   graphType:       Determines how to present the telemetry data. app | service | versionedApp | workload (default: workload)
+//   includeTraces:   Add the edges only known from traces to an istio app or versionedApp graph (default: false)
 //   boxBy:           If supported by vendor, visually box by a specified node attribute (default: none)
 //   namespaces:      Comma-separated list of namespace names to use in the graph. Will override namespace path param
 //   queryTime:       Unix time (seconds) for query such that range is queryTime-duration..queryTime (default now)
-//   TelemetryVendor: default: istio
+//   TelemetryVendor: istio | tracing (default: istio)
 //
 //  Note: some handlers may ignore some query parameters.
 //  Note: vendors may support additional, vendor-specific query parameters.
@@ -48,7 +49,7 @@ func GraphNamespaces(w http.ResponseWriter, r *http.Request) {
 	business, err := getBusiness(r)
 	graph.CheckError(err)
 
-	code, payload := api.GraphNamespaces(business, o)
+	code, payload := api.GraphNamespaces(r.Context(), business, o)
 	respond(w, code, payload)
 }
 
@@ -61,7 +62,7 @@ func GraphNode(w http.ResponseWriter, r *http.Request) {
 	business, err := getBusiness(r)
 	graph.CheckError(err)
 
-	code, payload := api.GraphNode(business, o)
+	code, payload := api.GraphNode(r.Context(), business, o)
 	respond(w, code, payload)
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/util"
 )
 
@@ -37,36 +38,60 @@ func NamespaceHealth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Bound the Prometheus queries to the request, health is returned without rates if they time out
+	ctx, cancel := prometheus.NewQueryBudgetContext(r.Context())
+	defer cancel()
+	healthService := business.Health.WithContext(ctx)
+
 	switch p.Type {
 	case "app":
-		health, err := business.Health.GetNamespaceAppHealth(p.Namespace, rateInterval, p.QueryTime)
+		health, err := healthService.GetNamespaceAppHealth(p.Namespace, rateInterval, p.QueryTime)
 		if err != nil {
 			handleErrorResponse(w, err, "Error while fetching app health: "+err.Error())
 			return
 		}
-		RespondWithJSON(w, http.StatusOK, health)
+		respondNamespaceHealth(w, p, health, prometheus.GetQueryBudget(ctx).Warnings())
 	case "service":
-		health, err := business.Health.GetNamespaceServiceHealth(p.Namespace, rateInterval, p.QueryTime)
+		health, err := healthService.GetNamespaceServiceHealth(p.Namespace, rateInterval, p.QueryTime)
 		if err != nil {
 			handleErrorResponse(w, err, "Error while fetching service health: "+err.Error())
 			return
 		}
-		RespondWithJSON(w, http.StatusOK, health)
+		respondNamespaceHealth(w, p, health, prometheus.GetQueryBudget(ctx).Warnings())
 	case "workload":
-		health, err := business.Health.GetNamespaceWorkloadHealth(p.Namespace, rateInterval, p.QueryTime)
+		health, err := healthService.GetNamespaceWorkloadHealth(p.Namespace, rateInterval, p.QueryTime)
 		if err != nil {
 			handleErrorResponse(w, err, "Error while fetching workload health: "+err.Error())
 			return
 		}
-		RespondWithJSON(w, http.StatusOK, health)
+		respondNamespaceHealth(w, p, health, prometheus.GetQueryBudget(ctx).Warnings())
 	case "pod":
-		health, err := business.Health.GetNamespacePodHealth(p.Namespace, rateInterval, p.QueryTime)
+		health, err := healthService.GetNamespacePodHealth(p.Namespace, rateInterval, p.QueryTime)
 		if err != nil {
 			handleErrorResponse(w, err, "Error while fetching workload health: "+err.Error())
 			return
 		}
+		respondNamespaceHealth(w, p, health, prometheus.GetQueryBudget(ctx).Warnings())
+	}
+}
+
+// namespaceHealthWithWarnings is the body of NamespaceHealth when the warnings are requested. Namespace health
+// is a map keyed by item name, there is no room for the warnings in the plain body.
+type namespaceHealthWithWarnings struct {
+	Health interface{} `json:"health"`
+	// Set when the health is partial, e.g. when some Prometheus queries timed out
+	Warnings []prometheus.QueryWarning `json:"warnings"`
+}
+
+func respondNamespaceHealth(w http.ResponseWriter, p namespaceHealthParams, health interface{}, warnings []prometheus.QueryWarning) {
+	if !p.Warnings {
 		RespondWithJSON(w, http.StatusOK, health)
+		return
 	}
+	if warnings == nil {
+		warnings = []prometheus.QueryWarning{}
+	}
+	RespondWithJSON(w, http.StatusOK, namespaceHealthWithWarnings{Health: health, Warnings: warnings})
 }
 
 // AppHealth is the API handler to get health of a single app
@@ -180,6 +205,12 @@ type namespaceHealthParams struct {
 	// pattern: ^(app|service|workload)$
 	// default: app
 	Type string `json:"type"`
+	// When true, the health is returned under "health" along with a "warnings" array telling which
+	// namespaces/metrics are incomplete.
+	//
+	// in: query
+	// default: false
+	Warnings bool `json:"warnings"`
 }
 
 func (p *namespaceHealthParams) extract(r *http.Request) (bool, string) {
@@ -193,6 +224,12 @@ func (p *namespaceHealthParams) extract(r *http.Request) (bool, string) {
 		}
 		p.Type = healthType
 	}
+	if warnings := queryParams.Get("warnings"); warnings != "" {
+		var err error
+		if p.Warnings, err = strconv.ParseBool(warnings); err != nil {
+			return false, "Bad request, query parameter 'warnings' must be a boolean"
+		}
+	}
 	return true, ""
 }
 
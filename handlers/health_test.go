@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -56,6 +57,33 @@ func TestNamespaceAppHealth(t *testing.T) {
 	prom.AssertNumberOfCalls(t, "GetAllRequestRates", 1)
 }
 
+func TestNamespaceAppHealthWithWarnings(t *testing.T) {
+	conf := config.NewConfig()
+	conf.KubernetesConfig.CacheEnabled = false
+	config.Set(conf)
+	ts, k8s, prom := setupNamespaceHealthEndpoint(t)
+	defer ts.Close()
+
+	url := ts.URL + "/api/namespaces/ns/health?warnings=true"
+
+	k8s.MockServices("ns", []string{"reviews", "httpbin"})
+	k8s.On("GetPods", "ns", mock.AnythingOfType("string")).Return(kubetest.FakePodList(), nil)
+	k8s.MockEmptyWorkloads("ns")
+	prom.On("GetAllRequestRates", "ns", "17s", util.Clock.Now()).Return(model.Vector{}, nil)
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, _ := ioutil.ReadAll(resp.Body)
+
+	assert.Equal(t, 200, resp.StatusCode, string(actual))
+	var body map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(actual, &body))
+	assert.Contains(t, body, "health")
+	assert.JSONEq(t, "[]", string(body["warnings"]))
+}
+
 func setupNamespaceHealthEndpoint(t *testing.T) (*httptest.Server, *kubetest.K8SClientMock, *prometheustest.PromClientMock) {
 	k8s := kubetest.NewK8SClientMock()
 	prom := new(prometheustest.PromClientMock)
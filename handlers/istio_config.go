@@ -7,10 +7,10 @@ import (
 	"sync"
 
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/api/meta"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
-	"github.com/kiali/kiali/config"
-	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 )
 
@@ -161,12 +161,16 @@ func IstioConfigDelete(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
 		return
 	}
+	before := getIstioObjectBefore(business, namespace, objectType, object)
 	err = business.IstioConfig.DeleteIstioConfigDetail(namespace, objectType, object)
+	auditRecord(r, audit.Event{Verb: audit.VerbDelete, Resource: objectType, Namespace: namespace, Name: object, Before: audit.Object(before)}, err)
+	if err == nil {
+		business.IstioHistory.RecordChange(namespace, objectType, object, models.IstioConfigDeleted, r.Header.Get("Kiali-User"), before, nil)
+	}
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	} else {
-		audit(r, "DELETE on Namespace: "+namespace+" Type: "+objectType+" Name: "+object)
 		RespondWithCode(w, http.StatusOK)
 	}
 }
@@ -194,14 +198,20 @@ func IstioConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Update request with bad update patch: "+err.Error())
 	}
 	jsonPatch := string(body)
+	before := getIstioObjectBefore(business, namespace, objectType, object)
 	updatedConfigDetails, err := business.IstioConfig.UpdateIstioConfigDetail(namespace, objectType, object, jsonPatch)
+	event := audit.Event{Verb: audit.VerbUpdate, Resource: objectType, Namespace: namespace, Name: object, Before: audit.Object(before), Patch: audit.Patch(body)}
+	if err == nil {
+		event.After = audit.Object(updatedConfigDetails.GetObject())
+		business.IstioHistory.RecordChange(namespace, objectType, object, models.IstioConfigUpdated, r.Header.Get("Kiali-User"), before, updatedConfigDetails.GetObject())
+	}
+	auditRecord(r, event, err)
 
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
 
-	audit(r, "UPDATE on Namespace: "+namespace+" Type: "+objectType+" Name: "+object+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, updatedConfigDetails)
 }
 
@@ -229,24 +239,40 @@ func IstioConfigCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createdConfigDetails, err := business.IstioConfig.CreateIstioConfigDetail(namespace, objectType, body)
+	event := audit.Event{Verb: audit.VerbCreate, Resource: objectType, Namespace: namespace}
+	if created := createdConfigDetails.GetObject(); err == nil && created != nil {
+		event.After = audit.Object(created)
+		if m, errMeta := meta.Accessor(created); errMeta == nil {
+			event.Name = m.GetName()
+			business.IstioHistory.RecordChange(namespace, objectType, event.Name, models.IstioConfigCreated, r.Header.Get("Kiali-User"), nil, created)
+		}
+	} else {
+		// The object may not be created, record what was sent
+		event.After = audit.Patch(body)
+	}
+	auditRecord(r, event, err)
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
 
-	audit(r, "CREATE on Namespace: "+namespace+" Type: "+objectType+" Object: "+string(body))
 	RespondWithJSON(w, http.StatusOK, createdConfigDetails)
 }
 
-func checkObjectType(objectType string) bool {
-	return business.GetIstioAPI(objectType)
+// getIstioObjectBefore returns an Istio object about to be changed, when it has to be recorded in the audit log or
+// in the history of the object
+func getIstioObjectBefore(layer *business.Layer, namespace, objectType, object string) interface{} {
+	if !audit.Enabled() && !business.IsIstioConfigHistoryEnabled() {
+		return nil
+	}
+	if before, err := layer.IstioConfig.GetIstioConfigDetails(namespace, objectType, object); err == nil {
+		return before.GetObject()
+	}
+	return nil
 }
 
-func audit(r *http.Request, message string) {
-	if config.Get().Server.AuditLog {
-		user := r.Header.Get("Kiali-User")
-		log.Infof("AUDIT User [%s] Msg [%s]", user, message)
-	}
+func checkObjectType(objectType string) bool {
+	return business.GetIstioAPI(objectType)
 }
 
 func IstioConfigPermissions(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
+)
+
+// IstioConfigHistory lists the revisions kept for an Istio object modified through Kiali
+func IstioConfigHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	if !checkIstioConfigHistory(w, params["object_type"]) {
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	history, err := business.IstioHistory.GetHistory(params["namespace"], params["object_type"], params["object"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, history)
+}
+
+// IstioConfigRevisionDiff compares two revisions of an Istio object
+func IstioConfigRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query := r.URL.Query()
+	if !checkIstioConfigHistory(w, params["object_type"]) {
+		return
+	}
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Cannot parse parameter 'from': "+err.Error())
+		return
+	}
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Cannot parse parameter 'to': "+err.Error())
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	diff, err := business.IstioHistory.DiffRevisions(params["namespace"], params["object_type"], params["object"], from, to)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, diff)
+}
+
+// IstioConfigRevisionRestore brings an Istio object back to a previous revision, recreating it when it was deleted
+func IstioConfigRevisionRestore(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	namespace := params["namespace"]
+	objectType := params["object_type"]
+	object := params["object"]
+	if !checkIstioConfigHistory(w, objectType) {
+		return
+	}
+	revision, err := strconv.Atoi(params["revision"])
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Cannot parse parameter 'revision': "+err.Error())
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	before := getIstioObjectBefore(business, namespace, objectType, object)
+	restored, err := business.IstioHistory.RestoreRevision(namespace, objectType, object, revision, r.Header.Get("Kiali-User"))
+	event := audit.Event{Verb: audit.VerbRestore, Resource: objectType, Namespace: namespace, Name: object, Before: audit.Object(before), Message: "Revision: " + params["revision"]}
+	if err == nil {
+		event.After = audit.Object(restored.GetObject())
+	}
+	auditRecord(r, event, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, restored)
+}
+
+func checkIstioConfigHistory(w http.ResponseWriter, objectType string) bool {
+	if !business.GetIstioAPI(objectType) {
+		RespondWithError(w, http.StatusBadRequest, "Object type not managed: "+objectType)
+		return false
+	}
+	if !business.IsIstioConfigHistoryEnabled() {
+		RespondWithError(w, http.StatusNotFound, "Istio config history is disabled")
+		return false
+	}
+	return true
+}
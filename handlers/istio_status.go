@@ -21,3 +21,21 @@ func IstioStatus(w http.ResponseWriter, r *http.Request) {
 
 	RespondWithJSON(w, http.StatusOK, istioStatus)
 }
+
+// IstioControlPlanes lists the control planes of the mesh by revision, with their health, version and namespaces
+func IstioControlPlanes(w http.ResponseWriter, r *http.Request) {
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	controlPlanes, err := business.IstioStatus.GetControlPlanes()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, controlPlanes)
+}
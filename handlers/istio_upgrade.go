@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
+)
+
+// IstioUpgradeMigrationRequest is the body of a migration of namespaces to the upgrade revision
+type IstioUpgradeMigrationRequest struct {
+	Namespaces       []string `json:"namespaces"`
+	BatchSize        int      `json:"batchSize"`
+	RestartWorkloads bool     `json:"restartWorkloads"`
+}
+
+// checkIstioUpgradeEnabled responds with an error when the canary upgrade isn't enabled or configured
+func checkIstioUpgradeEnabled(w http.ResponseWriter) bool {
+	if !business.IsIstioUpgradeEnabled() {
+		RespondWithError(w, http.StatusForbidden, "Istio upgrade action is disabled, or the current and upgrade revisions are not configured")
+		return false
+	}
+	return true
+}
+
+// IstioUpgradeStatus lists the namespaces and workloads of each revision, and the outdated proxies
+func IstioUpgradeStatus(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	status, err := business.IstioUpgrade.GetUpgradeStatus()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, status)
+}
+
+// IstioUpgradePreCheck checks whether namespaces can be migrated to the upgrade revision
+func IstioUpgradePreCheck(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+	namespaces := r.URL.Query().Get("namespaces")
+	if namespaces == "" {
+		RespondWithError(w, http.StatusBadRequest, "namespaces query param is not set")
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	check, err := business.IstioUpgrade.PreCheck(strings.Split(namespaces, ","))
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, check)
+}
+
+// IstioUpgradeMigrationCreate starts the migration of namespaces to the upgrade revision
+func IstioUpgradeMigrationCreate(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Migration request with bad body: "+err.Error())
+		return
+	}
+	request := IstioUpgradeMigrationRequest{}
+	if err := json.Unmarshal(body, &request); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Migration request with bad body: "+err.Error())
+		return
+	}
+	if len(request.Namespaces) == 0 || request.BatchSize < 0 {
+		RespondWithError(w, http.StatusBadRequest, "Migration request must list namespaces, with a positive batch size if set")
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	migration, err := business.IstioUpgrade.StartMigration(request.Namespaces, request.BatchSize, request.RestartWorkloads)
+	event := audit.Event{Verb: audit.VerbCreate, Resource: "istioupgrademigrations", Patch: audit.Patch(body), Message: "Namespaces: " + strings.Join(request.Namespaces, ",")}
+	if err == nil {
+		event.Name = migration.ID
+		event.Message += " Revision: " + migration.To
+	}
+	auditRecord(r, event, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusAccepted, migration)
+}
+
+// IstioUpgradeMigrationList lists the migrations, the latest first
+func IstioUpgradeMigrationList(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	migrations, err := business.IstioUpgrade.GetMigrations()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, migrations)
+}
+
+// IstioUpgradeMigrationDetails returns the progress of a migration
+func IstioUpgradeMigrationDetails(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	migration, err := business.IstioUpgrade.GetMigration(params["migration"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, migration)
+}
+
+// IstioUpgradeMigrationNamespaces returns the namespaces of the migration of a request, to authorize it
+func IstioUpgradeMigrationNamespaces(r *http.Request) ([]string, error) {
+	return business.GetMigrationNamespaces(mux.Vars(r)["migration"])
+}
+
+// IstioUpgradeMigrationRollback moves the namespaces of a migration back to their previous revision
+func IstioUpgradeMigrationRollback(w http.ResponseWriter, r *http.Request) {
+	if !checkIstioUpgradeEnabled(w) {
+		return
+	}
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	migration, err := business.IstioUpgrade.RollbackMigration(params["migration"])
+	auditRecord(r, audit.Event{Verb: audit.VerbRollback, Resource: "istioupgrademigrations", Name: params["migration"]}, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, migration)
+}
@@ -6,9 +6,11 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/models"
@@ -59,6 +61,64 @@ func AppTraces(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, http.StatusOK, traces)
 }
 
+// AppTracesAnalytics is the API handler to summarize the traces of a specific app: latency per operation,
+// critical path contribution per hop and error hotspots
+func AppTracesAnalytics(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "AppTracesAnalytics initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	namespace := params["namespace"]
+	app := params["app"]
+	queryParams := r.URL.Query()
+	q, err := readQuery(queryParams)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var errorTags []string
+	if v := queryParams.Get("errorTags"); v != "" {
+		errorTags = strings.Split(v, ",")
+	}
+	analytics, err := business.Jaeger.GetAppTracesAnalytics(namespace, app, q, errorTags)
+	if err != nil {
+		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, analytics)
+}
+
+// AppTracesComparison is the API handler to compare the traces of a specific app in two time windows. The
+// usual query parameters select the candidate traces, baselineStartMicros and baselineEndMicros the baseline.
+func AppTracesComparison(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "AppTracesComparison initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	namespace := params["namespace"]
+	app := params["app"]
+	candidate, err := readQuery(r.URL.Query())
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	baseline, err := readBaselineQuery(r.URL.Query(), candidate)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	comparison, err := business.Jaeger.CompareAppTraces(namespace, app, baseline, candidate)
+	if err != nil {
+		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, comparison)
+}
+
 func ServiceTraces(w http.ResponseWriter, r *http.Request) {
 	business, err := getBusiness(r)
 	if err != nil {
@@ -135,6 +195,19 @@ func TraceDetails(w http.ResponseWriter, r *http.Request) {
 	}
 	params := mux.Vars(r)
 	traceID := params["traceID"]
+	if candidateID := r.URL.Query().Get("compareWith"); candidateID != "" {
+		comparison, err := business.Jaeger.CompareTraces(traceID, candidateID)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				RespondWithError(w, http.StatusNotFound, err.Error())
+			} else {
+				RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+			}
+			return
+		}
+		RespondWithJSON(w, http.StatusOK, comparison)
+		return
+	}
 	trace, err := business.Jaeger.GetJaegerTraceDetail(traceID)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
@@ -268,5 +341,53 @@ func readQuery(values url.Values) (models.TracingQuery, error) {
 			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'minDuration': " + err.Error())
 		}
 	}
+	if strMaxD := values.Get("maxDuration"); strMaxD != "" {
+		if num, err := strconv.Atoi(strMaxD); err == nil {
+			q.MaxDuration = time.Duration(num) * time.Microsecond
+		} else {
+			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'maxDuration': " + err.Error())
+		}
+	}
+	q.Operation = values.Get("operation")
+	q.HTTPStatusCode = values.Get("httpStatusCode")
+	q.GRPCStatusCode = values.Get("grpcStatusCode")
+	if v := values.Get("errorOnly"); v != "" {
+		if errorOnly, err := strconv.ParseBool(v); err == nil {
+			q.ErrorOnly = errorOnly
+		} else {
+			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'errorOnly': " + err.Error())
+		}
+	}
+	if v := values.Get("paged"); v != "" {
+		if paged, err := strconv.ParseBool(v); err == nil {
+			q.Paged = paged
+		} else {
+			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'paged': " + err.Error())
+		}
+	}
+	if v := values.Get("cursor"); v != "" {
+		if cursor, err := models.DecodeTracesCursor(v); err == nil {
+			q.Cursor = cursor
+			q.Paged = true
+		} else {
+			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'cursor': " + err.Error())
+		}
+	}
+	return q, nil
+}
+
+// readBaselineQuery reads the baseline window of a comparison, other criteria are the candidate ones
+func readBaselineQuery(values url.Values, candidate models.TracingQuery) (models.TracingQuery, error) {
+	q := candidate
+	start, err := strconv.ParseInt(values.Get("baselineStartMicros"), 10, 64)
+	if err != nil {
+		return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'baselineStartMicros': " + err.Error())
+	}
+	end, err := strconv.ParseInt(values.Get("baselineEndMicros"), 10, 64)
+	if err != nil {
+		return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'baselineEndMicros': " + err.Error())
+	}
+	q.Start = time.Unix(0, start*int64(time.Microsecond))
+	q.End = time.Unix(0, end*int64(time.Microsecond))
 	return q, nil
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -42,13 +43,18 @@ func getAppMetrics(w http.ResponseWriter, r *http.Request, promSupplier promClie
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	metrics, err := metricsService.GetMetrics(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 
 // WorkloadMetrics is the API handler to fetch metrics to be displayed, related to a single workload
@@ -78,13 +84,18 @@ func getPodMetrics(w http.ResponseWriter, r *http.Request, promSupplier promClie
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	metrics, err := metricsService.GetMetrics(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 // getWorkloadMetrics (mock-friendly version)
 func getWorkloadMetrics(w http.ResponseWriter, r *http.Request, promSupplier promClientSupplier) {
@@ -104,13 +115,18 @@ func getWorkloadMetrics(w http.ResponseWriter, r *http.Request, promSupplier pro
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	metrics, err := metricsService.GetMetrics(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 
 // ServiceMetrics is the API handler to fetch metrics to be displayed, related to a single service
@@ -136,13 +152,18 @@ func getServiceMetrics(w http.ResponseWriter, r *http.Request, promSupplier prom
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	metrics, err := metricsService.GetMetrics(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 
 // AggregateMetrics is the API handler to fetch metrics to be displayed, related to a single aggregate
@@ -169,6 +190,11 @@ func getAggregateMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if params.Direction != "inbound" {
 		RespondWithError(w, http.StatusBadRequest, "AggregateMetrics 'direction' must be 'inbound' as the metrics are associated with inbound traffic to the destination workload.")
 		return
@@ -183,7 +209,7 @@ func getAggregateMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 
 // NamespaceMetrics is the API handler to fetch metrics to be displayed, related to all
@@ -209,13 +235,18 @@ func getNamespaceMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 		RespondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	format, err := extractMetricsFormat(r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	metrics, err := metricsService.GetMetrics(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
-	RespondWithJSON(w, http.StatusOK, metrics)
+	respondWithMetrics(w, format, metrics)
 }
 
 func extractIstioMetricsQueryParams(r *http.Request, q *models.IstioMetricsQuery, namespaceInfo *models.Namespace) error {
@@ -243,9 +274,61 @@ func extractIstioMetricsQueryParams(r *http.Request, q *models.IstioMetricsQuery
 		}
 		q.Reporter = reporter
 	}
+	if includeExemplars := queryParams.Get("includeExemplars"); includeExemplars != "" {
+		include, err := strconv.ParseBool(includeExemplars)
+		if err != nil {
+			return errors.New("bad request, cannot parse query parameter 'includeExemplars'")
+		}
+		q.IncludeExemplars = include
+	}
 	return extractBaseMetricsQueryParams(queryParams, &q.RangeQuery, namespaceInfo)
 }
 
+// extractMetricsFormat reads the optional "format" query parameter of the metrics endpoints. Besides the
+// default JSON, metrics can be exported flattened as CSV, OpenMetrics or Parquet, for offline analysis.
+func extractMetricsFormat(r *http.Request) (string, error) {
+	format := r.URL.Query().Get("format")
+	switch format {
+	case "", models.MetricsFormatJSON:
+		return models.MetricsFormatJSON, nil
+	case models.MetricsFormatCSV, models.MetricsFormatOpenMetrics, models.MetricsFormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("bad request, query parameter 'format' must be one of '%s', '%s', '%s' or '%s'", models.MetricsFormatJSON, models.MetricsFormatCSV, models.MetricsFormatOpenMetrics, models.MetricsFormatParquet)
+	}
+}
+
+func respondWithMetrics(w http.ResponseWriter, format string, metrics models.MetricsMap) {
+	var err error
+	switch format {
+	case models.MetricsFormatCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="metrics.csv"`)
+		w.WriteHeader(http.StatusOK)
+		err = models.WriteMetricsCSV(w, metrics)
+	case models.MetricsFormatOpenMetrics:
+		// The series are checked before anything is written, such that a rejected export is a proper error
+		var buf bytes.Buffer
+		if err := models.WriteMetricsOpenMetrics(&buf, metrics); err != nil {
+			RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err = buf.WriteTo(w)
+	case models.MetricsFormatParquet:
+		w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+		w.Header().Set("Content-Disposition", `attachment; filename="metrics.parquet"`)
+		w.WriteHeader(http.StatusOK)
+		err = models.WriteMetricsParquet(w, metrics)
+	default:
+		RespondWithJSON(w, http.StatusOK, metrics)
+	}
+	if err != nil {
+		log.Errorf("Failed to export metrics as %s: %v", format, err)
+	}
+}
+
 func extractBaseMetricsQueryParams(queryParams url.Values, q *prometheus.RangeQuery, namespaceInfo *models.Namespace) error {
 	if ri := queryParams.Get("rateInterval"); ri != "" {
 		q.RateInterval = ri
@@ -479,3 +479,65 @@ func TestValidateBadRequest(t *testing.T) {
 	assert.Contains(errs.Error(), "bad request")
 	assert.Len(errs.Strings(), 2)
 }
+
+func TestExtractMetricsFormat(t *testing.T) {
+	assert := assert.New(t)
+
+	for query, expected := range map[string]string{"": "json", "?format=json": "json", "?format=csv": "csv", "?format=openmetrics": "openmetrics", "?format=parquet": "parquet"} {
+		req := httptest.NewRequest("GET", "http://host/api/namespaces/ns/apps/app/metrics"+query, nil)
+		format, err := extractMetricsFormat(req)
+		assert.NoError(err)
+		assert.Equal(expected, format)
+	}
+
+	req := httptest.NewRequest("GET", "http://host/api/namespaces/ns/apps/app/metrics?format=xml", nil)
+	_, err := extractMetricsFormat(req)
+	assert.Error(err)
+}
+
+func TestRespondWithMetricsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := models.MetricsMap{
+		"request_count": []models.Metric{
+			{Name: "request_count", Labels: map[string]string{"response_code": "200"}, Datapoints: []models.Datapoint{{Timestamp: 1000000, Value: 10}}},
+		},
+	}
+	rr := httptest.NewRecorder()
+	respondWithMetrics(rr, models.MetricsFormatCSV, metrics)
+
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.Equal("text/csv; charset=utf-8", rr.Header().Get("Content-Type"))
+	assert.Equal("metric,stat,timestamp,value,label_response_code\nrequest_count,,1000,10,200\n", rr.Body.String())
+}
+
+func TestRespondWithMetricsParquet(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := models.MetricsMap{
+		"request_count": []models.Metric{
+			{Name: "request_count", Labels: map[string]string{"response_code": "200"}, Datapoints: []models.Datapoint{{Timestamp: 1000000, Value: 10}}},
+		},
+	}
+	rr := httptest.NewRecorder()
+	respondWithMetrics(rr, models.MetricsFormatParquet, metrics)
+
+	assert.Equal(http.StatusOK, rr.Code)
+	assert.Equal("application/vnd.apache.parquet", rr.Header().Get("Content-Type"))
+	assert.True(strings.HasPrefix(rr.Body.String(), "PAR1"))
+	assert.True(strings.HasSuffix(rr.Body.String(), "PAR1"))
+}
+
+func TestRespondWithMetricsOpenMetricsStatCollision(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := models.MetricsMap{
+		"request_count": []models.Metric{
+			{Name: "request_count", Labels: map[string]string{"kiali_stat": "x"}, Datapoints: []models.Datapoint{{Timestamp: 1000000, Value: 10}}},
+		},
+	}
+	rr := httptest.NewRecorder()
+	respondWithMetrics(rr, models.MetricsFormatOpenMetrics, metrics)
+
+	assert.Equal(http.StatusBadRequest, rr.Code)
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 )
@@ -70,10 +71,10 @@ func NamespaceUpdate(w http.ResponseWriter, r *http.Request) {
 	jsonPatch := string(body)
 
 	ns, err := business.Namespace.UpdateNamespace(namespace, jsonPatch)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "namespaces", Namespace: namespace, Name: namespace, Patch: audit.Patch(body)}, err)
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
-	audit(r, "UPDATE on Namespace: "+namespace+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, ns)
 }
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/models"
 )
 
 func LoggingUpdate(w http.ResponseWriter, r *http.Request) {
@@ -22,21 +25,131 @@ func LoggingUpdate(w http.ResponseWriter, r *http.Request) {
 
 	namespace := params["namespace"]
 	pod := params["pod"]
-	level := r.URL.Query().Get("level")
-	switch {
-	case level == "":
-		RespondWithError(w, 400, "level query param is not set")
+	levels, ttl, err := parseProxyLogLevels(r)
+	if err != nil {
+		RespondWithError(w, 400, err.Error())
+		return
+	}
+
+	err = businessLayer.ProxyLogging.SetLogLevels(namespace, pod, levels, ttl)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "proxylogging", Namespace: namespace, Name: pod, Message: "Log level:" + describeProxyLogLevels(levels, ttl)}, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithCode(w, 200)
+}
+
+// LoggingBulkUpdate sets the proxy log levels of the pods of a workload, of an app, or matching a label selector
+func LoggingBulkUpdate(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query := r.URL.Query()
+
+	// Get business layer
+	businessLayer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
 		return
-	case !business.IsValidProxyLogLevel(level):
-		msg := fmt.Sprintf("%s is an invalid log level. Valid log levels are: %s", level, strings.Join(business.ValidProxyLogLevels, ", "))
-		RespondWithError(w, 400, msg)
+	}
+
+	namespace := params["namespace"]
+	selector := business.ProxyLoggingSelector{
+		Workload:      query.Get("workload"),
+		App:           query.Get("app"),
+		LabelSelector: query.Get("labelSelector"),
+	}
+	if selector.Workload == "" && selector.App == "" && selector.LabelSelector == "" {
+		RespondWithError(w, 400, "one of the workload, app or labelSelector query params must be set")
+		return
+	}
+	levels, ttl, err := parseProxyLogLevels(r)
+	if err != nil {
+		RespondWithError(w, 400, err.Error())
 		return
 	}
 
-	if err := businessLayer.ProxyLogging.SetLogLevel(namespace, pod, level); err != nil {
+	results, err := businessLayer.ProxyLogging.SetPodsLogLevels(namespace, selector, levels, ttl)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "proxylogging", Namespace: namespace, Message: fmt.Sprintf("Selector: %+v Log level:%s", selector, describeProxyLogLevels(levels, ttl))}, err)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, results)
+}
+
+// LoggingRevert restores the proxy log levels of a pod as they were before being raised through Kiali
+func LoggingRevert(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	businessLayer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	namespace := params["namespace"]
+	pod := params["pod"]
+	err = businessLayer.ProxyLogging.RevertLogLevels(namespace, pod)
+	auditRecord(r, audit.Event{Verb: audit.VerbRevert, Resource: "proxylogging", Namespace: namespace, Name: pod}, err)
+	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
-	audit(r, "UPDATE Envoy log. Namespace: "+namespace+" Pod: "+pod+" Log level:"+level)
 	RespondWithCode(w, 200)
 }
+
+// LoggingList lists the proxies whose log levels were raised through Kiali
+func LoggingList(w http.ResponseWriter, r *http.Request) {
+	// Get business layer
+	businessLayer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	proxies, err := businessLayer.ProxyLogging.GetElevatedProxies()
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, proxies)
+}
+
+func parseProxyLogLevels(r *http.Request) (models.ProxyLogLevels, time.Duration, error) {
+	query := r.URL.Query()
+	levels := models.ProxyLogLevels{Level: query.Get("level")}
+	loggers := query.Get("loggers")
+	switch {
+	case levels.Level == "" && loggers == "":
+		return levels, 0, fmt.Errorf("level query param is not set")
+	case levels.Level != "" && !business.IsValidProxyLogLevel(levels.Level):
+		return levels, 0, fmt.Errorf("%s is an invalid log level. Valid log levels are: %s", levels.Level, strings.Join(business.ValidProxyLogLevels, ", "))
+	}
+	if loggers != "" {
+		var err error
+		if levels.Loggers, err = business.ParseProxyLoggerLevels(loggers); err != nil {
+			return levels, 0, err
+		}
+	}
+
+	var ttl time.Duration
+	if ttlParam := query.Get("ttl"); ttlParam != "" {
+		var err error
+		if ttl, err = time.ParseDuration(ttlParam); err != nil || ttl <= 0 {
+			return levels, 0, fmt.Errorf("%s is an invalid ttl, it must be a positive duration, i.e. 15m", ttlParam)
+		}
+	}
+	return levels, ttl, nil
+}
+
+func describeProxyLogLevels(levels models.ProxyLogLevels, ttl time.Duration) string {
+	description := levels.Level
+	if len(levels.Loggers) > 0 {
+		description += fmt.Sprintf(" Loggers: %v", levels.Loggers)
+	}
+	if ttl > 0 {
+		description += " TTL: " + ttl.String()
+	}
+	return description
+}
@@ -1,11 +1,22 @@
 package handlers
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/models"
 )
 
+// Proxies out of sync for less than this are usually just waiting for a push in progress
+const defaultStaleProxyThreshold = time.Minute
+
 func ConfigDump(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 
@@ -50,3 +61,213 @@ func ConfigDumpResourceEntries(w http.ResponseWriter, r *http.Request) {
 
 	RespondWithJSON(w, http.StatusOK, dump)
 }
+
+// ConfigDumpDiff compares the proxy config of a pod with the one of another pod, or with an earlier snapshot
+func ConfigDumpDiff(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	query := r.URL.Query()
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	namespace := params["namespace"]
+	pod := params["pod"]
+
+	var diff *models.EnvoyConfigDiff
+	if snapshot := query.Get("snapshot"); snapshot != "" {
+		diff, err = business.ProxyStatus.DiffConfigDumpSnapshot(namespace, pod, snapshot)
+	} else if comparePod := query.Get("comparePod"); comparePod != "" {
+		compareNamespace := query.Get("compareNamespace")
+		if compareNamespace == "" {
+			compareNamespace = namespace
+		}
+		diff, err = business.ProxyStatus.DiffConfigDumps(namespace, pod, compareNamespace, comparePod)
+	} else {
+		RespondWithError(w, http.StatusBadRequest, "Either a snapshot or a pod to compare with is required")
+		return
+	}
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, diff)
+}
+
+// ConfigDumpSnapshot keeps the current proxy config of a pod, to be compared later
+func ConfigDumpSnapshot(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	snapshot, err := business.ProxyStatus.SnapshotConfigDump(params["namespace"], params["pod"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusCreated, snapshot)
+}
+
+// RouteSimulation tells where the proxy of a pod would send a synthetic request
+func RouteSimulation(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var request models.RouteSimulationRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if request.Port == 0 {
+		// The port can be part of the host, as in the Host header
+		if _, port, err := net.SplitHostPort(request.Host); err == nil {
+			request.Port, _ = strconv.Atoi(port)
+		}
+	}
+	if request.Host == "" || request.Port == 0 {
+		RespondWithError(w, http.StatusBadRequest, "The host and port of the request are required")
+		return
+	}
+
+	simulation, err := business.ProxyStatus.SimulateRoute(params["namespace"], params["pod"], request)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, simulation)
+}
+
+// ProxyStats returns the stats of the proxy of a pod, optionally filtered by a regex on their names
+func ProxyStats(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	filter := r.URL.Query().Get("filter")
+	if _, err := regexp.Compile(filter); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid filter: "+err.Error())
+		return
+	}
+
+	stats, err := business.ProxyStatus.GetProxyStats(params["namespace"], params["pod"], filter)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, stats)
+}
+
+// ProxyClusters returns the circuit breakers, outlier detection and endpoints health of the proxy of a pod
+func ProxyClusters(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	clusters, err := business.ProxyStatus.GetProxyClusters(params["namespace"], params["pod"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, clusters)
+}
+
+// ProxyServerInfo returns the state, version and uptime of the proxy of a pod
+func ProxyServerInfo(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	info, err := business.ProxyStatus.GetProxyServerInfo(params["namespace"], params["pod"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, info)
+}
+
+// ProxySyncHistory returns how the proxy of a pod synchronized with the control plane over time
+func ProxySyncHistory(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	history, err := business.ProxyStatus.GetPodProxySyncHistory(params["namespace"], params["pod"])
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, history)
+}
+
+// StaleProxies lists the proxies of the mesh out of sync for longer than a threshold, by istiod revision
+func StaleProxies(w http.ResponseWriter, r *http.Request) {
+	threshold := defaultStaleProxyThreshold
+	if param := r.URL.Query().Get("threshold"); param != "" {
+		var err error
+		if threshold, err = time.ParseDuration(param); err != nil || threshold < 0 {
+			RespondWithError(w, http.StatusBadRequest, "Invalid threshold, it must be a duration, i.e. 5m: "+param)
+			return
+		}
+	}
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return
+	}
+
+	proxies, err := business.ProxyStatus.GetStaleProxies(threshold)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, proxies)
+}
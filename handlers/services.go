@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/util"
@@ -140,6 +141,7 @@ func ServiceUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	serviceDetails, err := business.Svc.UpdateService(namespace, service, rateInterval, queryTime, jsonPatch)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "services", Namespace: namespace, Name: service, Patch: audit.Patch(body)}, err)
 
 	if includeValidations && err == nil {
 		wg.Wait()
@@ -152,6 +154,5 @@ func ServiceUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	audit(r, "UPDATE on Namespace: "+namespace+" Service name: "+service+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, serviceDetails)
 }
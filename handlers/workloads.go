@@ -6,6 +6,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 )
 
@@ -80,12 +81,12 @@ func WorkloadUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	jsonPatch := string(body)
 	workloadDetails, err := business.Workload.UpdateWorkload(namespace, workload, workloadType, true, jsonPatch)
+	auditRecord(r, audit.Event{Verb: audit.VerbUpdate, Resource: "workloads", Namespace: namespace, Name: workload, Patch: audit.Patch(body), Message: "Type: " + workloadType}, err)
 
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
-	audit(r, "UPDATE on Namespace: "+namespace+" Workload name: "+workload+" Type: "+workloadType+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, workloadDetails)
 }
 
@@ -27,7 +27,8 @@ import (
 	"github.com/kiali/kiali/util/httputil"
 )
 
-// ClientInterface for mocks (only mocked function are necessary here)
+// ClientInterface is the tracing backend used by the JaegerService. Besides the Jaeger Client, it is
+// implemented by the Tempo client (package tempo), which converts its traces to the Jaeger JSON model.
 type ClientInterface interface {
 	GetAppTraces(ns, app string, query models.TracingQuery) (traces *JaegerResponse, err error)
 	GetTraceDetail(traceId string) (*JaegerSingleTrace, error)
@@ -120,15 +121,17 @@ func (in *Client) GetAppTraces(namespace, app string, q models.TracingQuery) (*J
 	if in.grpcClient == nil {
 		return getAppTracesHTTP(in.httpClient, in.baseURL, namespace, app, q)
 	}
-	jaegerServiceName := buildJaegerServiceName(namespace, app)
+	jaegerServiceName := BuildServiceName(namespace, app)
 	findTracesRQ := &jaegerModel.FindTracesRequest{
 		Query: &jaegerModel.TraceQueryParameters{
-			ServiceName:  jaegerServiceName,
-			StartTimeMin: timestamppb.New(q.Start),
-			StartTimeMax: timestamppb.New(q.End),
-			Tags:         q.Tags,
-			DurationMin:  durationpb.New(q.MinDuration),
-			SearchDepth:  int32(q.Limit),
+			ServiceName:   jaegerServiceName,
+			OperationName: q.Operation,
+			StartTimeMin:  timestamppb.New(q.Start),
+			StartTimeMax:  timestamppb.New(q.End),
+			Tags:          q.SearchTags(),
+			DurationMin:   durationpb.New(q.MinDuration),
+			DurationMax:   durationpb.New(q.MaxDuration),
+			SearchDepth:   int32(q.Limit),
 		},
 	}
 	ctx, cancel := context.WithTimeout(in.ctx, 4*time.Second)
@@ -256,7 +259,8 @@ func readSpansStream(stream SpansStreamer) (map[jaegerModel.TraceID]*jaegerModel
 	return tracesMap, nil
 }
 
-func buildJaegerServiceName(namespace, app string) string {
+// BuildServiceName returns the name under which the app reports its traces
+func BuildServiceName(namespace, app string) string {
 	conf := config.Get()
 	if conf.ExternalServices.Tracing.NamespaceSelector && namespace != conf.IstioNamespace {
 		return app + "." + namespace
@@ -19,7 +19,7 @@ import (
 func getAppTracesHTTP(client http.Client, baseURL *url.URL, namespace, app string, q models.TracingQuery) (response *JaegerResponse, err error) {
 	url := *baseURL
 	url.Path = path.Join(url.Path, "/api/traces")
-	jaegerServiceName := buildJaegerServiceName(namespace, app)
+	jaegerServiceName := BuildServiceName(namespace, app)
 	prepareQuery(&url, jaegerServiceName, q)
 	r, err := queryTracesHTTP(client, &url)
 	if r != nil {
@@ -89,11 +89,15 @@ func unmarshal(r []byte, u *url.URL) (*JaegerResponse, error) {
 func prepareQuery(u *url.URL, jaegerServiceName string, query models.TracingQuery) {
 	q := url.Values{}
 	q.Set("service", jaegerServiceName)
-	q.Set("start", fmt.Sprintf("%d", query.Start.Unix()*time.Second.Microseconds()))
-	q.Set("end", fmt.Sprintf("%d", query.End.Unix()*time.Second.Microseconds()))
-	if len(query.Tags) > 0 {
+	// Microseconds precision, so that paging cursors don't skip traces
+	q.Set("start", fmt.Sprintf("%d", query.Start.UnixNano()/int64(time.Microsecond)))
+	q.Set("end", fmt.Sprintf("%d", query.End.UnixNano()/int64(time.Microsecond)))
+	if query.Operation != "" {
+		q.Set("operation", query.Operation)
+	}
+	if searchTags := query.SearchTags(); len(searchTags) > 0 {
 		// Tags must be json encoded
-		tags, err := json.Marshal(query.Tags)
+		tags, err := json.Marshal(searchTags)
 		if err != nil {
 			log.Errorf("Jager query: error while marshalling tags to json: %v", err)
 		}
@@ -102,6 +106,10 @@ func prepareQuery(u *url.URL, jaegerServiceName string, query models.TracingQuer
 	if query.MinDuration > 0 {
 		q.Set("minDuration", fmt.Sprintf("%d", query.MinDuration.Microseconds()))
 	}
+	if query.MaxDuration > 0 {
+		// Jaeger parses it as a Go duration, e.g. "1.5s"
+		q.Set("maxDuration", query.MaxDuration.String())
+	}
 	if query.Limit > 0 {
 		q.Set("limit", strconv.Itoa(query.Limit))
 	}
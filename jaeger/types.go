@@ -18,6 +18,8 @@ type JaegerResponse struct {
 	Data              []jaegerModels.Trace `json:"data"`
 	Errors            []structuredError    `json:"errors"`
 	JaegerServiceName string               `json:"jaegerServiceName"`
+	// Cursor of the next page of traces, for paged queries, empty on the last page
+	Cursor string `json:"cursor,omitempty"`
 }
 
 type JaegerSingleTrace struct {
@@ -26,6 +26,8 @@ import (
 
 	_ "go.uber.org/automaxprocs"
 
+	"github.com/kiali/kiali/authorization"
+	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
@@ -155,16 +157,42 @@ func validateConfig() error {
 	} else if auth.Strategy != config.AuthStrategyOpenId &&
 		auth.Strategy != config.AuthStrategyOpenshift &&
 		auth.Strategy != config.AuthStrategyToken &&
-		auth.Strategy != config.AuthStrategyHeader {
+		auth.Strategy != config.AuthStrategyHeader &&
+		auth.Strategy != config.AuthStrategyX509 {
 		return fmt.Errorf("Invalid authentication strategy [%v]", auth.Strategy)
 	}
 
+	// Client certificates are only requested over TLS, and can only be verified with the CAs issuing them
+	if auth.Strategy == config.AuthStrategyX509 {
+		if cfg.Identity.CertFile == "" || cfg.Identity.PrivateKeyFile == "" {
+			return fmt.Errorf("auth strategy [%v] requires the server to be configured with a certificate", auth.Strategy)
+		}
+		if _, err := business.LoadX509ClientCAs(); err != nil {
+			return err
+		}
+		if auth.X509.UsernameSource != business.X509UsernameSourceSpiffe && auth.X509.UsernameSource != business.X509UsernameSourceSubject {
+			return fmt.Errorf("Invalid x509 username source [%v]", auth.X509.UsernameSource)
+		}
+	}
+
 	// Check the signing key for the JWT token is valid
 	signingKey := cfg.LoginToken.SigningKey
 	if err := config.ValidateSigningKey(signingKey, auth.Strategy); err != nil {
 		return err
 	}
 
+	// Check the Kiali policy, when configured, is valid
+	if auth.PolicyFile != "" {
+		if _, err := authorization.GetPolicy(); err != nil {
+			return err
+		}
+		log.Infof("Restricting the features available to users with the Kiali policy [%v]", auth.PolicyFile)
+	}
+
+	if auth.ApiKeys.Enabled {
+		log.Infof("Accepting API keys, stored in [%v]", auth.ApiKeys.Store)
+	}
+
 	// log a warning if the user is ignoring some validations
 	if len(cfg.KialiFeatureFlags.Validations.Ignore) > 0 {
 		log.Warningf("Some validation errors will be ignored %v. If these errors do occur, they will still be logged. If you think the validation errors you see are incorrect, please report them to the Kiali team if you have not done so already and provide the details of your scenario. This will keep Kiali validations strong for the whole community.", cfg.KialiFeatureFlags.Validations.Ignore)
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
@@ -38,6 +39,7 @@ type (
 		NamespacesCache
 		ProxyStatusCache
 		RegistryStatusCache
+		RevisionCache
 	}
 
 	// This map will store Informers per specific types
@@ -63,8 +65,13 @@ type (
 		refreshDuration        time.Duration
 		cacheNamespacesRegexps []regexp.Regexp
 		cacheIstioTypes        map[string]bool
+		clusterScoped          bool
+		labelSelector          string
+		fieldSelector          string
 		stopChan               map[string]chan struct{}
 		nsCache                map[string]typeCache
+		clusterCache           typeCache
+		clusterStopChan        chan struct{}
 		cacheLock              sync.RWMutex
 		tokenLock              sync.RWMutex
 		tokenNamespaces        map[string]namespaceCache
@@ -72,10 +79,16 @@ type (
 		proxyStatusLock        sync.RWMutex
 		proxyStatusCreated     *time.Time
 		proxyStatusNamespaces  map[string]map[string]podProxyStatus
-		registryRefreshHandler RegistryRefreshHandler
+		proxySyncHistory       map[string]*models.ProxySyncHistory
 		registryStatusLock     sync.RWMutex
 		registryStatusCreated  *time.Time
+		registryStatusStale    bool
 		registryStatus         *kubernetes.RegistryStatus
+		revisionLock           sync.Mutex
+		revision               uint64
+		typeRevisions          map[string]uint64
+		allTypeRevisions       map[string]uint64
+		revisionChange         chan struct{}
 	}
 )
 
@@ -136,16 +149,24 @@ func NewKialiCache() (KialiCache, error) {
 		refreshDuration:        refreshDuration,
 		cacheNamespacesRegexps: cacheNamespacesRegexps,
 		cacheIstioTypes:        cacheIstioTypes,
+		clusterScoped:          kConfig.KubernetesConfig.CacheClusterScoped,
+		labelSelector:          kConfig.KubernetesConfig.CacheLabelSelector,
+		fieldSelector:          kConfig.KubernetesConfig.CacheFieldSelector,
 		stopChan:               stopChan,
 		nsCache:                make(map[string]typeCache),
 		tokenNamespaces:        make(map[string]namespaceCache),
 		tokenNamespaceDuration: tokenNamespaceDuration,
 		proxyStatusNamespaces:  make(map[string]map[string]podProxyStatus),
+		proxySyncHistory:       make(map[string]*models.ProxySyncHistory),
 	}
 
 	kialiCacheImpl.k8sApi = istioClient.GetK8sApi()
 	kialiCacheImpl.istioApi = istioClient.Istio()
 
+	if kialiCacheImpl.clusterScoped {
+		log.Infof("Kiali Cache is active for namespaces %v with cluster scoped informers", cacheNamespaces)
+		return &kialiCacheImpl, nil
+	}
 	log.Infof("Kiali Cache is active for namespaces %v", cacheNamespaces)
 	return &kialiCacheImpl, nil
 }
@@ -160,14 +181,47 @@ func (c *kialiCacheImpl) isCached(namespace string) bool {
 	return false
 }
 
+// tweakListOptions limits the resources listed and watched by the informers to the configured selectors
+func (c *kialiCacheImpl) tweakListOptions(options *meta_v1.ListOptions) {
+	if c.labelSelector != "" {
+		options.LabelSelector = c.labelSelector
+	}
+	if c.fieldSelector != "" {
+		options.FieldSelector = c.fieldSelector
+	}
+}
+
+// listObjects lists the objects of a namespace held by an informer. Per namespace informers only hold the
+// objects of their namespace, cluster scoped informers are looked up through their namespace index.
+func (c *kialiCacheImpl) listObjects(informer cache.SharedIndexInformer, namespace string) []interface{} {
+	if !c.clusterScoped {
+		return informer.GetStore().List()
+	}
+	objects, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Errorf("Kiali cache lookup failure for [namespace: %s]: %v", namespace, err)
+		return nil
+	}
+	return objects
+}
+
 func (c *kialiCacheImpl) createCache(namespace string) bool {
 	if _, exist := c.nsCache[namespace]; exist {
 		return true
 	}
+	if c.clusterScoped {
+		if !c.createClusterCache() {
+			return false
+		}
+		c.nsCache[namespace] = c.clusterCache
+		return true
+	}
 	informer := make(typeCache)
-	c.registryRefreshHandler = NewRegistryHandler(c, namespace)
 	c.createKubernetesInformers(namespace, &informer)
 	c.createIstioInformers(namespace, &informer)
+	for resourceType, typeInformer := range informer {
+		typeInformer.AddEventHandler(newChangeHandler(c, namespace, resourceType))
+	}
 	c.nsCache[namespace] = informer
 
 	if _, exist := c.stopChan[namespace]; !exist {
@@ -200,6 +254,43 @@ func (c *kialiCacheImpl) createCache(namespace string) bool {
 	return true
 }
 
+// createClusterCache creates the informers of all the namespaces, shared by the cached namespaces
+func (c *kialiCacheImpl) createClusterCache() bool {
+	if c.clusterCache != nil {
+		return true
+	}
+	informer := make(typeCache)
+	c.createKubernetesInformers(meta_v1.NamespaceAll, &informer)
+	c.createIstioInformers(meta_v1.NamespaceAll, &informer)
+	for resourceType, typeInformer := range informer {
+		typeInformer.AddEventHandler(newChangeHandler(c, meta_v1.NamespaceAll, resourceType))
+	}
+
+	stopCh := make(chan struct{})
+	for _, typeInformer := range informer {
+		go typeInformer.Run(stopCh)
+	}
+
+	log.Infof("Waiting for cluster scoped Kiali cache to sync")
+	isSynced := func() bool {
+		hasSynced := true
+		for _, typeInformer := range informer {
+			hasSynced = hasSynced && typeInformer.HasSynced()
+		}
+		return hasSynced
+	}
+	if synced := cache.WaitForCacheSync(stopCh, isSynced); !synced {
+		close(stopCh)
+		log.Errorf("Cluster scoped Kiali cache sync failure")
+		return false
+	}
+	log.Infof("Cluster scoped Kiali cache started")
+
+	c.clusterCache = informer
+	c.clusterStopChan = stopCh
+	return true
+}
+
 // CheckNamespace will
 // - Validate if a namespace is included in the cache
 // - Create and initialize a cache
@@ -222,9 +313,15 @@ func (c *kialiCacheImpl) CheckNamespace(namespace string) bool {
 }
 
 // RefreshNamespace will delete the specific namespace's cache and create a new one.
+// Cluster scoped informers are shared by all the namespaces, they are all recreated.
 func (c *kialiCacheImpl) RefreshNamespace(namespace string) {
 	defer c.cacheLock.Unlock()
 	c.cacheLock.Lock()
+	if c.clusterScoped {
+		c.stopClusterCache()
+		c.createCache(namespace)
+		return
+	}
 	if nsChan, exist := c.stopChan[namespace]; exist {
 		close(nsChan)
 		delete(c.stopChan, namespace)
@@ -241,8 +338,20 @@ func (c *kialiCacheImpl) Stop() {
 		close(nsChan)
 		delete(c.stopChan, namespace)
 	}
+	c.stopClusterCache()
 	log.Infof("Clearing Kiali Cache")
 	for ns := range c.nsCache {
 		delete(c.nsCache, ns)
 	}
 }
+
+func (c *kialiCacheImpl) stopClusterCache() {
+	if c.clusterStopChan != nil {
+		close(c.clusterStopChan)
+		c.clusterStopChan = nil
+	}
+	c.clusterCache = nil
+	for ns := range c.nsCache {
+		delete(c.nsCache, ns)
+	}
+}
@@ -1,148 +1,108 @@
 package cache
 
 import (
-	"reflect"
-	"strings"
+	"time"
 
-	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
-	security_v1beta1 "istio.io/client-go/pkg/apis/security/v1beta1"
-	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/kiali/kiali/kubernetes"
-	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
 
-type RegistryRefreshHandler struct {
-	Cache     KialiCache
-	Namespace string
-	cache.ResourceEventHandler
+// ChangeHandler is notified of the changes observed by the informer of a resource type in a namespace, or in
+// all the namespaces for cluster scoped informers. Every change bumps the revision of the cache, invalidating the results computed from it, and the changes
+// of the resources known by the Istio registry make its status stale.
+type ChangeHandler struct {
+	cache        *kialiCacheImpl
+	namespace    string
+	resourceType string
+	registry     bool
+	// Objects changed before the handler was created come from the initial list, they don't measure any lag
+	started time.Time
 }
 
-func NewRegistryHandler(cache KialiCache, namespace string) RegistryRefreshHandler {
-	log.Infof("Adding a RegistryRefreshHandler for [%s]", namespace)
-	return RegistryRefreshHandler{Cache: cache, Namespace: namespace}
+func newChangeHandler(c *kialiCacheImpl, namespace, resourceType string) ChangeHandler {
+	registry := resourceType == kubernetes.ServiceType || resourceType == kubernetes.EndpointsType
+	for _, istioType := range kubernetes.PluralType {
+		registry = registry || resourceType == istioType
+	}
+	return ChangeHandler{cache: c, namespace: namespace, resourceType: resourceType, registry: registry, started: time.Now()}
 }
 
-func (sh RegistryRefreshHandler) OnAdd(obj interface{}) {
-	sh.Cache.RefreshRegistryStatus()
+func (ch ChangeHandler) OnAdd(obj interface{}) {
+	ch.observeLag(obj)
+	ch.changed(obj)
 }
 
-func (sh RegistryRefreshHandler) OnUpdate(oldObj, newObj interface{}) {
-	resourceVersion1, resourceVersion2 := sh.parseResourceVersion(oldObj, newObj)
-	if resourceVersion1 != resourceVersion2 {
-		sh.Cache.RefreshRegistryStatus()
+func (ch ChangeHandler) OnUpdate(oldObj, newObj interface{}) {
+	// Informers notify periodic resyncs as updates without changes
+	if resourceVersion(oldObj) != resourceVersion(newObj) {
+		ch.observeLag(newObj)
+		ch.changed(newObj)
 	}
 }
 
-func (sh RegistryRefreshHandler) OnDelete(obj interface{}) {
-	sh.Cache.RefreshRegistryStatus()
+func (ch ChangeHandler) OnDelete(obj interface{}) {
+	ch.changed(obj)
 }
 
-func (sh RegistryRefreshHandler) parseResourceVersion(oldObj, newObj interface{}) (string, string) {
-	oldResourceVersion := ""
-	newResourceVersion := ""
-	if oldObj == nil {
-		return oldResourceVersion, newResourceVersion
+func (ch ChangeHandler) changed(obj interface{}) {
+	namespace := ch.namespace
+	if namespace == "" {
+		namespace = objectNamespace(obj)
 	}
-	oldType := reflect.TypeOf(oldObj).String()
-	resourceType := ""
-	split := strings.Split(oldType, ".")
-	if len(split) == 2 {
-		resourceType = split[1]
+	ch.cache.recordChange(namespace, ch.resourceType)
+	if ch.registry {
+		ch.cache.RefreshRegistryStatus()
 	}
-	switch resourceType {
-	case kubernetes.ServiceType:
-		oldConv, ok1 := oldObj.(*core_v1.Service)
-		newConv, ok2 := newObj.(*core_v1.Service)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.EndpointsType:
-		oldConv, ok1 := oldObj.(*core_v1.Endpoints)
-		newConv, ok2 := newObj.(*core_v1.Endpoints)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.DestinationRuleType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.DestinationRule)
-		newConv, ok2 := newObj.(*networking_v1alpha3.DestinationRule)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.EnvoyFilterType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.EnvoyFilter)
-		newConv, ok2 := newObj.(*networking_v1alpha3.EnvoyFilter)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.GatewayType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.Gateway)
-		newConv, ok2 := newObj.(*networking_v1alpha3.Gateway)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.ServiceEntryType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.ServiceEntry)
-		newConv, ok2 := newObj.(*networking_v1alpha3.ServiceEntry)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.Sidecars:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.Sidecar)
-		newConv, ok2 := newObj.(*networking_v1alpha3.Sidecar)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.VirtualServiceType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.VirtualService)
-		newConv, ok2 := newObj.(*networking_v1alpha3.VirtualService)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.WorkloadEntryType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.WorkloadEntry)
-		newConv, ok2 := newObj.(*networking_v1alpha3.WorkloadEntry)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.WorkloadGroupType:
-		oldConv, ok1 := oldObj.(*networking_v1alpha3.WorkloadGroup)
-		newConv, ok2 := newObj.(*networking_v1alpha3.WorkloadGroup)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.AuthorizationPoliciesType:
-		oldConv, ok1 := oldObj.(*security_v1beta1.AuthorizationPolicy)
-		newConv, ok2 := newObj.(*security_v1beta1.AuthorizationPolicy)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.PeerAuthenticationsType:
-		oldConv, ok1 := oldObj.(*security_v1beta1.PeerAuthentication)
-		newConv, ok2 := newObj.(*security_v1beta1.PeerAuthentication)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
-		}
-	case kubernetes.RequestAuthenticationsType:
-		oldConv, ok1 := oldObj.(*security_v1beta1.RequestAuthentication)
-		newConv, ok2 := newObj.(*security_v1beta1.RequestAuthentication)
-		if ok1 && ok2 {
-			oldResourceVersion = oldConv.ResourceVersion
-			newResourceVersion = newConv.ResourceVersion
+}
+
+// observeLag measures the time between the last write of an object, as recorded by the API server, and its
+// notification. Timestamps have a resolution of one second and depend on the clock of the API server.
+func (ch ChangeHandler) observeLag(obj interface{}) {
+	changed, found := lastChange(obj)
+	if !found || changed.Before(ch.started) {
+		return
+	}
+	lag := time.Since(changed)
+	if lag < 0 {
+		lag = 0
+	}
+	internalmetrics.GetKialiCacheSyncLagMetric(ch.resourceType).Observe(lag.Seconds())
+}
+
+func lastChange(obj interface{}) (time.Time, bool) {
+	if _, isTombstone := obj.(cache.DeletedFinalStateUnknown); isTombstone {
+		return time.Time{}, false
+	}
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return time.Time{}, false
+	}
+	changed := m.GetCreationTimestamp().Time
+	for _, field := range m.GetManagedFields() {
+		if field.Time != nil && field.Time.After(changed) {
+			changed = field.Time.Time
 		}
 	}
-	return oldResourceVersion, newResourceVersion
+	return changed, !changed.IsZero()
+}
+
+func objectNamespace(obj interface{}) string {
+	if tombstone, isTombstone := obj.(cache.DeletedFinalStateUnknown); isTombstone {
+		namespace, _, _ := cache.SplitMetaNamespaceKey(tombstone.Key)
+		return namespace
+	}
+	if m, err := meta.Accessor(obj); err == nil {
+		return m.GetNamespace()
+	}
+	return ""
+}
+
+func resourceVersion(obj interface{}) string {
+	if m, err := meta.Accessor(obj); err == nil {
+		return m.GetResourceVersion()
+	}
+	return ""
 }
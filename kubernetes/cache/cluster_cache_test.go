@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	istio_fake "istio.io/client-go/pkg/clientset/versioned/fake"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kube_fake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func newFakeKialiCache(clusterScoped bool, labelSelector string, k8sObjects, istioObjects []runtime.Object) *kialiCacheImpl {
+	cacheIstioTypes := map[string]bool{}
+	for _, istioType := range kubernetes.PluralType {
+		cacheIstioTypes[istioType] = true
+	}
+	return &kialiCacheImpl{
+		k8sApi:                 kube_fake.NewSimpleClientset(k8sObjects...),
+		istioApi:               istio_fake.NewSimpleClientset(istioObjects...),
+		cacheNamespacesRegexps: []regexp.Regexp{*regexp.MustCompile(".*")},
+		cacheIstioTypes:        cacheIstioTypes,
+		clusterScoped:          clusterScoped,
+		labelSelector:          labelSelector,
+		stopChan:               make(map[string]chan struct{}),
+		nsCache:                make(map[string]typeCache),
+	}
+}
+
+func fakeCacheObjects(namespaces, podsPerNamespace int) ([]runtime.Object, []runtime.Object) {
+	k8sObjects := []runtime.Object{}
+	istioObjects := []runtime.Object{}
+	for i := 0; i < namespaces; i++ {
+		namespace := fmt.Sprintf("bookinfo-%d", i)
+		for j := 0; j < podsPerNamespace; j++ {
+			k8sObjects = append(k8sObjects, &core_v1.Pod{ObjectMeta: meta_v1.ObjectMeta{
+				Name:      fmt.Sprintf("reviews-%d", j),
+				Namespace: namespace,
+				Labels:    map[string]string{"app": "reviews", "cached": fmt.Sprintf("%t", j%2 == 0)},
+			}})
+		}
+		istioObjects = append(istioObjects, &networking_v1alpha3.VirtualService{ObjectMeta: meta_v1.ObjectMeta{
+			Name:      "reviews",
+			Namespace: namespace,
+			Labels:    map[string]string{"cached": "true"},
+		}})
+	}
+	return k8sObjects, istioObjects
+}
+
+func TestClusterScopedCache(t *testing.T) {
+	assert := assert.New(t)
+
+	k8sObjects, istioObjects := fakeCacheObjects(2, 4)
+	kialiCache := newFakeKialiCache(true, "", k8sObjects, istioObjects)
+	defer kialiCache.Stop()
+
+	assert.True(kialiCache.CheckNamespace("bookinfo-0"))
+	assert.True(kialiCache.CheckNamespace("bookinfo-1"))
+	// A single set of informers is shared by the namespaces
+	assert.Same(kialiCache.nsCache["bookinfo-0"][kubernetes.PodType], kialiCache.nsCache["bookinfo-1"][kubernetes.PodType])
+
+	pods, err := kialiCache.GetPods("bookinfo-1", "app=reviews")
+	assert.NoError(err)
+	assert.Len(pods, 4)
+	for _, pod := range pods {
+		assert.Equal("bookinfo-1", pod.Namespace)
+	}
+	vs, err := kialiCache.GetVirtualService("bookinfo-0", "reviews")
+	assert.NoError(err)
+	assert.Equal("bookinfo-0", vs.Namespace)
+
+	vss, err := kialiCache.GetVirtualServices("bookinfo-0", "")
+	assert.NoError(err)
+	assert.Len(vss, 1)
+	assert.Equal("bookinfo-0", vss[0].Namespace)
+}
+
+func TestCacheLabelSelector(t *testing.T) {
+	assert := assert.New(t)
+
+	k8sObjects, istioObjects := fakeCacheObjects(1, 4)
+	for _, clusterScoped := range []bool{true, false} {
+		kialiCache := newFakeKialiCache(clusterScoped, "cached=true", k8sObjects, istioObjects)
+
+		assert.True(kialiCache.CheckNamespace("bookinfo-0"))
+		pods, err := kialiCache.GetPods("bookinfo-0", "")
+		assert.NoError(err)
+		assert.Len(pods, 2)
+		vss, err := kialiCache.GetVirtualServices("bookinfo-0", "")
+		assert.NoError(err)
+		assert.Len(vss, 1)
+
+		kialiCache.Stop()
+	}
+}
+
+// BenchmarkCache compares the namespace scoped and cluster scoped informers, caching and querying the pods of
+// all the namespaces. Every informer opens a watch against the API server.
+func BenchmarkCache(b *testing.B) {
+	namespaces := 20
+	k8sObjects, istioObjects := fakeCacheObjects(namespaces, 10)
+
+	for _, clusterScoped := range []bool{false, true} {
+		name := "NamespaceScoped"
+		if clusterScoped {
+			name = "ClusterScoped"
+		}
+		b.Run(name, func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				kialiCache := newFakeKialiCache(clusterScoped, "", k8sObjects, istioObjects)
+				for i := 0; i < namespaces; i++ {
+					namespace := fmt.Sprintf("bookinfo-%d", i)
+					if !kialiCache.CheckNamespace(namespace) {
+						b.Fatalf("namespace %s not cached", namespace)
+					}
+					if pods, _ := kialiCache.GetPods(namespace, ""); len(pods) != 10 {
+						b.Fatalf("namespace %s has %d pods", namespace, len(pods))
+					}
+				}
+
+				informers := map[interface{}]bool{}
+				for _, nsCache := range kialiCache.nsCache {
+					for _, informer := range nsCache {
+						informers[informer] = true
+					}
+				}
+				b.ReportMetric(float64(len(informers)), "informers/op")
+				kialiCache.Stop()
+			}
+		})
+	}
+}
@@ -50,51 +50,40 @@ func (c *kialiCacheImpl) CheckIstioResource(resourceType string) bool {
 }
 
 func (c *kialiCacheImpl) createIstioInformers(namespace string, informer *typeCache) {
-	sharedInformers := istio.NewSharedInformerFactoryWithOptions(c.istioApi, c.refreshDuration, istio.WithNamespace(namespace))
+	sharedInformers := istio.NewSharedInformerFactoryWithOptions(c.istioApi, c.refreshDuration, istio.WithNamespace(namespace), istio.WithTweakListOptions(c.tweakListOptions))
 	if c.CheckIstioResource(kubernetes.DestinationRules) {
 		(*informer)[kubernetes.DestinationRuleType] = sharedInformers.Networking().V1alpha3().DestinationRules().Informer()
-		(*informer)[kubernetes.DestinationRuleType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.EnvoyFilters) {
 		(*informer)[kubernetes.EnvoyFilterType] = sharedInformers.Networking().V1alpha3().EnvoyFilters().Informer()
-		(*informer)[kubernetes.EnvoyFilterType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.Gateways) {
 		(*informer)[kubernetes.GatewayType] = sharedInformers.Networking().V1alpha3().Gateways().Informer()
-		(*informer)[kubernetes.GatewayType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.ServiceEntries) {
 		(*informer)[kubernetes.ServiceEntryType] = sharedInformers.Networking().V1alpha3().ServiceEntries().Informer()
-		(*informer)[kubernetes.ServiceEntryType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.Sidecars) {
 		(*informer)[kubernetes.SidecarType] = sharedInformers.Networking().V1alpha3().Sidecars().Informer()
-		(*informer)[kubernetes.SidecarType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.VirtualServices) {
 		(*informer)[kubernetes.VirtualServiceType] = sharedInformers.Networking().V1alpha3().VirtualServices().Informer()
-		(*informer)[kubernetes.VirtualServiceType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.WorkloadEntries) {
 		(*informer)[kubernetes.WorkloadEntryType] = sharedInformers.Networking().V1alpha3().WorkloadEntries().Informer()
-		(*informer)[kubernetes.WorkloadEntryType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.WorkloadGroups) {
 		(*informer)[kubernetes.WorkloadGroupType] = sharedInformers.Networking().V1alpha3().WorkloadGroups().Informer()
-		(*informer)[kubernetes.WorkloadGroupType].AddEventHandler(c.registryRefreshHandler)
 	}
 
 	if c.CheckIstioResource(kubernetes.AuthorizationPolicies) {
 		(*informer)[kubernetes.AuthorizationPoliciesType] = sharedInformers.Security().V1beta1().AuthorizationPolicies().Informer()
-		(*informer)[kubernetes.AuthorizationPoliciesType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.PeerAuthentications) {
 		(*informer)[kubernetes.PeerAuthenticationsType] = sharedInformers.Security().V1beta1().PeerAuthentications().Informer()
-		(*informer)[kubernetes.PeerAuthenticationsType].AddEventHandler(c.registryRefreshHandler)
 	}
 	if c.CheckIstioResource(kubernetes.RequestAuthentications) {
 		(*informer)[kubernetes.RequestAuthenticationsType] = sharedInformers.Security().V1beta1().RequestAuthentications().Informer()
-		(*informer)[kubernetes.RequestAuthenticationsType].AddEventHandler(c.registryRefreshHandler)
 	}
 }
 
@@ -172,7 +161,7 @@ func (c *kialiCacheImpl) GetDestinationRules(namespace, labelSelector string) ([
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.DestinationRuleType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.DestinationRuleType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.DestinationRuleType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.DestinationRule)
@@ -234,7 +223,7 @@ func (c *kialiCacheImpl) GetEnvoyFilters(namespace, labelSelector string) ([]net
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.EnvoyFilterType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.EnvoyFilterType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.EnvoyFilterType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.EnvoyFilter)
@@ -295,7 +284,7 @@ func (c *kialiCacheImpl) GetGateways(namespace, labelSelector string) ([]network
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.Gateways)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.GatewayType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.GatewayType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.Gateway)
@@ -356,7 +345,7 @@ func (c *kialiCacheImpl) GetServiceEntries(namespace, labelSelector string) ([]n
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.ServiceEntryType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.ServiceEntryType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.ServiceEntryType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.ServiceEntry)
@@ -417,7 +406,7 @@ func (c *kialiCacheImpl) GetSidecars(namespace, labelSelector string) ([]network
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.SidecarType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.SidecarType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.SidecarType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.Sidecar)
@@ -478,7 +467,7 @@ func (c *kialiCacheImpl) GetVirtualServices(namespace, labelSelector string) ([]
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.VirtualServiceType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.VirtualServiceType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.VirtualServiceType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.VirtualService)
@@ -539,7 +528,7 @@ func (c *kialiCacheImpl) GetWorkloadEntries(namespace, labelSelector string) ([]
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.WorkloadEntryType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.WorkloadEntryType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.WorkloadEntryType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.WorkloadEntry)
@@ -600,7 +589,7 @@ func (c *kialiCacheImpl) GetWorkloadGroups(namespace, labelSelector string) ([]n
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.WorkloadGroups)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.WorkloadGroupType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.WorkloadGroupType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*networking_v1alpha3.WorkloadGroup)
@@ -661,7 +650,7 @@ func (c *kialiCacheImpl) GetAuthorizationPolicies(namespace, labelSelector strin
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.AuthorizationPolicies)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.AuthorizationPoliciesType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.AuthorizationPoliciesType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*security_v1beta1.AuthorizationPolicy)
@@ -722,7 +711,7 @@ func (c *kialiCacheImpl) GetPeerAuthentications(namespace, labelSelector string)
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.PeerAuthenticationsType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.PeerAuthenticationsType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.PeerAuthenticationsType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*security_v1beta1.PeerAuthentication)
@@ -783,7 +772,7 @@ func (c *kialiCacheImpl) GetRequestAuthentications(namespace, labelSelector stri
 		return nil, fmt.Errorf("Kiali cache doesn't support [resourceType: %s]", kubernetes.RequestAuthenticationsType)
 	}
 	if nsCache, nsOk := c.nsCache[namespace]; nsOk {
-		l := nsCache[kubernetes.RequestAuthenticationsType].GetStore().List()
+		l := c.listObjects(nsCache[kubernetes.RequestAuthenticationsType], namespace)
 		lenL := len(l)
 		if lenL > 0 {
 			_, ok := l[0].(*security_v1beta1.RequestAuthentication)
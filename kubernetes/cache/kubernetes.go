@@ -31,17 +31,15 @@ type (
 )
 
 func (c *kialiCacheImpl) createKubernetesInformers(namespace string, informer *typeCache) {
-	sharedInformers := informers.NewSharedInformerFactoryWithOptions(c.k8sApi, c.refreshDuration, informers.WithNamespace(namespace))
+	sharedInformers := informers.NewSharedInformerFactoryWithOptions(c.k8sApi, c.refreshDuration, informers.WithNamespace(namespace), informers.WithTweakListOptions(c.tweakListOptions))
 	(*informer)[kubernetes.DeploymentType] = sharedInformers.Apps().V1().Deployments().Informer()
 	(*informer)[kubernetes.StatefulSetType] = sharedInformers.Apps().V1().StatefulSets().Informer()
 	(*informer)[kubernetes.ReplicaSetType] = sharedInformers.Apps().V1().ReplicaSets().Informer()
 	(*informer)[kubernetes.DaemonSetType] = sharedInformers.Apps().V1().DaemonSets().Informer()
 	(*informer)[kubernetes.ServiceType] = sharedInformers.Core().V1().Services().Informer()
-	(*informer)[kubernetes.ServiceType].AddEventHandler(c.registryRefreshHandler)
 	(*informer)[kubernetes.PodType] = sharedInformers.Core().V1().Pods().Informer()
 	(*informer)[kubernetes.ConfigMapType] = sharedInformers.Core().V1().ConfigMaps().Informer()
 	(*informer)[kubernetes.EndpointsType] = sharedInformers.Core().V1().Endpoints().Informer()
-	(*informer)[kubernetes.EndpointsType].AddEventHandler(c.registryRefreshHandler)
 }
 
 func (c *kialiCacheImpl) isKubernetesSynced(namespace string) bool {
@@ -83,7 +81,7 @@ func (c *kialiCacheImpl) GetConfigMap(namespace, name string) (*core_v1.ConfigMa
 
 func (c *kialiCacheImpl) GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		daeset := nsCache[kubernetes.DaemonSetType].GetStore().List()
+		daeset := c.listObjects(nsCache[kubernetes.DaemonSetType], namespace)
 		lenDaeSet := len(daeset)
 		if lenDaeSet > 0 {
 			_, ok := daeset[0].(*apps_v1.DaemonSet)
@@ -123,7 +121,7 @@ func (c *kialiCacheImpl) GetDaemonSet(namespace, name string) (*apps_v1.DaemonSe
 
 func (c *kialiCacheImpl) GetDeployments(namespace string) ([]apps_v1.Deployment, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		deps := nsCache[kubernetes.DeploymentType].GetStore().List()
+		deps := c.listObjects(nsCache[kubernetes.DeploymentType], namespace)
 		lenDeps := len(deps)
 		if lenDeps > 0 {
 			_, ok := deps[0].(*apps_v1.Deployment)
@@ -183,7 +181,7 @@ func (c *kialiCacheImpl) GetEndpoints(namespace, name string) (*core_v1.Endpoint
 
 func (c *kialiCacheImpl) GetStatefulSets(namespace string) ([]apps_v1.StatefulSet, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		ss := nsCache[kubernetes.StatefulSetType].GetStore().List()
+		ss := c.listObjects(nsCache[kubernetes.StatefulSetType], namespace)
 		lenSs := len(ss)
 		if lenSs > 0 {
 			_, ok := ss[0].(*apps_v1.StatefulSet)
@@ -223,7 +221,7 @@ func (c *kialiCacheImpl) GetStatefulSet(namespace, name string) (*apps_v1.Statef
 
 func (c *kialiCacheImpl) GetServices(namespace string, selectorLabels map[string]string) ([]core_v1.Service, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		services := nsCache[kubernetes.ServiceType].GetStore().List()
+		services := c.listObjects(nsCache[kubernetes.ServiceType], namespace)
 		lenServices := len(services)
 		if lenServices > 0 {
 			_, ok := services[0].(*core_v1.Service)
@@ -274,7 +272,7 @@ func (c *kialiCacheImpl) GetService(namespace, name string) (*core_v1.Service, e
 
 func (c *kialiCacheImpl) GetPods(namespace, labelSelector string) ([]core_v1.Pod, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		pods := nsCache[kubernetes.PodType].GetStore().List()
+		pods := c.listObjects(nsCache[kubernetes.PodType], namespace)
 		lenPods := len(pods)
 		if lenPods > 0 {
 			_, ok := pods[0].(*core_v1.Pod)
@@ -312,7 +310,7 @@ func (c *kialiCacheImpl) GetPods(namespace, labelSelector string) ([]core_v1.Pod
 // see also: ../kubernetes.go
 func (c *kialiCacheImpl) GetReplicaSets(namespace string) ([]apps_v1.ReplicaSet, error) {
 	if nsCache, ok := c.nsCache[namespace]; ok {
-		reps := nsCache[kubernetes.ReplicaSetType].GetStore().List()
+		reps := c.listObjects(nsCache[kubernetes.ReplicaSetType], namespace)
 		if len(reps) > 0 {
 			_, ok := reps[0].(*apps_v1.ReplicaSet)
 			if !ok {
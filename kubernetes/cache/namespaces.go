@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/kiali/kiali/models"
@@ -22,6 +23,10 @@ func (c *kialiCacheImpl) SetNamespaces(token string, namespaces []models.Namespa
 	for _, ns := range namespaces {
 		nameNamespace[ns.Name] = ns
 	}
+	// Namespaces have no informer, their changes are observed when they are listed again
+	if previous, found := c.tokenNamespaces[token]; !found || !reflect.DeepEqual(previous.namespaces, namespaces) {
+		c.recordChange("", NamespaceType)
+	}
 	c.tokenNamespaces[token] = namespaceCache{
 		created:       time.Now(),
 		namespaces:    namespaces,
@@ -5,14 +5,20 @@ import (
 	"time"
 
 	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
 )
 
+// Histories of the proxies missing from syncz, i.e. deleted pods, are dropped after this
+const proxySyncHistoryRetention = time.Hour
+
 type (
 	ProxyStatusCache interface {
 		CheckProxyStatus() bool
 		GetPodProxyStatus(namespace, pod string) *kubernetes.ProxyStatus
 		SetProxyStatus(proxyStatus []*kubernetes.ProxyStatus)
 		RefreshProxyStatus()
+		GetProxySyncHistory(namespace, pod string) *models.ProxySyncHistory
+		GetProxySyncHistories() []models.ProxySyncHistory
 	}
 )
 
@@ -61,10 +67,56 @@ func (c *kialiCacheImpl) SetProxyStatus(proxyStatus []*kubernetes.ProxyStatus) {
 						pod:         pod,
 						proxyStatus: ps,
 					}
+					c.recordProxySync(ns, pod, ps, timeNow)
 				}
 			}
 		}
+		for key, history := range c.proxySyncHistory {
+			if timeNow.Sub(history.LastSeen) > proxySyncHistoryRetention {
+				delete(c.proxySyncHistory, key)
+			}
+		}
+	}
+}
+
+func (c *kialiCacheImpl) recordProxySync(namespace, pod string, ps *kubernetes.ProxyStatus, observed time.Time) {
+	if c.proxySyncHistory == nil {
+		c.proxySyncHistory = make(map[string]*models.ProxySyncHistory)
+	}
+	key := namespace + "/" + pod
+	history, ok := c.proxySyncHistory[key]
+	if !ok {
+		history = &models.ProxySyncHistory{Namespace: namespace, Pod: pod}
+		c.proxySyncHistory[key] = history
+	}
+	history.Record(ps.Pilot(), ps.SyncStatus, observed)
+}
+
+// GetProxySyncHistory returns the sync history of the proxy of a pod, kept across the proxy status refreshes
+func (c *kialiCacheImpl) GetProxySyncHistory(namespace, pod string) *models.ProxySyncHistory {
+	defer c.proxyStatusLock.RUnlock()
+	c.proxyStatusLock.RLock()
+	if history, ok := c.proxySyncHistory[namespace+"/"+pod]; ok {
+		return history.DeepCopy()
+	}
+	return nil
+}
+
+// GetProxySyncHistories returns the sync histories of the proxies found by the latest proxy status refresh. The
+// histories of the proxies gone since, i.e. deleted pods, are only kept for GetProxySyncHistory.
+func (c *kialiCacheImpl) GetProxySyncHistories() []models.ProxySyncHistory {
+	defer c.proxyStatusLock.RUnlock()
+	c.proxyStatusLock.RLock()
+	histories := make([]models.ProxySyncHistory, 0, len(c.proxySyncHistory))
+	if c.proxyStatusCreated == nil {
+		return histories
+	}
+	for _, history := range c.proxySyncHistory {
+		if history.LastSeen.Equal(*c.proxyStatusCreated) {
+			histories = append(histories, *history.DeepCopy())
+		}
 	}
+	return histories
 }
 
 func (c *kialiCacheImpl) RefreshProxyStatus() {
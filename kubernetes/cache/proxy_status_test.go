@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func TestProxySyncHistoryKeptAcrossRefreshes(t *testing.T) {
+	assert := assert.New(t)
+
+	kialiCache := &kialiCacheImpl{proxyStatusNamespaces: make(map[string]map[string]podProxyStatus)}
+	kialiCache.SetProxyStatus([]*kubernetes.ProxyStatus{
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "reviews-v1.bookinfo", ClusterSent: "1", ClusterAcked: "1"}},
+	})
+	kialiCache.RefreshProxyStatus()
+	kialiCache.SetProxyStatus([]*kubernetes.ProxyStatus{
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "reviews-v1.bookinfo", ClusterSent: "2", ClusterAcked: "1"}},
+	})
+
+	history := kialiCache.GetProxySyncHistory("bookinfo", "reviews-v1")
+	assert.NotNil(history)
+	assert.Equal("Stale", history.Xds["CDS"].Status)
+	assert.Len(history.Events, 1)
+	assert.Nil(kialiCache.GetProxySyncHistory("bookinfo", "ratings-v1"))
+
+	// Histories of the proxies gone for a while are dropped
+	kialiCache.proxySyncHistory["bookinfo/reviews-v1"].LastSeen = time.Now().Add(-2 * proxySyncHistoryRetention)
+	kialiCache.SetProxyStatus([]*kubernetes.ProxyStatus{
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "ratings-v1.bookinfo", ClusterSent: "1", ClusterAcked: "1"}},
+	})
+	assert.Len(kialiCache.GetProxySyncHistories(), 1)
+	assert.Nil(kialiCache.GetProxySyncHistory("bookinfo", "reviews-v1"))
+}
+
+func TestProxySyncHistoriesOnlyListsCurrentProxies(t *testing.T) {
+	assert := assert.New(t)
+
+	kialiCache := &kialiCacheImpl{proxyStatusNamespaces: make(map[string]map[string]podProxyStatus)}
+	kialiCache.SetProxyStatus([]*kubernetes.ProxyStatus{
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "reviews-v1.bookinfo", ClusterSent: "2", ClusterAcked: "1"}},
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "ratings-v1.bookinfo", ClusterSent: "1", ClusterAcked: "1"}},
+	})
+	assert.Len(kialiCache.GetProxySyncHistories(), 2)
+
+	// reviews-v1 is deleted: its history is kept, but it's not a current proxy anymore
+	kialiCache.RefreshProxyStatus()
+	kialiCache.SetProxyStatus([]*kubernetes.ProxyStatus{
+		{SyncStatus: kubernetes.SyncStatus{ProxyID: "ratings-v1.bookinfo", ClusterSent: "1", ClusterAcked: "1"}},
+	})
+	histories := kialiCache.GetProxySyncHistories()
+	assert.Len(histories, 1)
+	assert.Equal("ratings-v1", histories[0].Pod)
+	assert.NotNil(kialiCache.GetProxySyncHistory("bookinfo", "reviews-v1"))
+}
@@ -1,11 +1,16 @@
 package cache
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/kiali/kiali/kubernetes"
 )
 
+// Changes of the resources known by the registry make the registry status stale, it is fetched again once it is at
+// least this old, so a burst of changes fetches it once
+const minRegistryStatusAge = 10 * time.Second
+
 type (
 	RegistryStatusCache interface {
 		CheckRegistryStatus() bool
@@ -21,10 +26,13 @@ func (c *kialiCacheImpl) CheckRegistryStatus() bool {
 	if c.registryStatusCreated == nil {
 		return false
 	}
-	if time.Since(*c.registryStatusCreated) > c.refreshDuration {
+	// Changes of the cached resources refresh the registry status, but istiod also registers services
+	// and endpoints from other sources
+	age := time.Since(*c.registryStatusCreated)
+	if age > c.refreshDuration {
 		return false
 	}
-	return true
+	return !c.registryStatusStale || age < minRegistryStatusAge
 }
 
 func (c *kialiCacheImpl) GetRegistryStatus() *kubernetes.RegistryStatus {
@@ -38,12 +46,17 @@ func (c *kialiCacheImpl) SetRegistryStatus(registryStatus *kubernetes.RegistrySt
 	c.registryStatusLock.Lock()
 	timeNow := time.Now()
 	c.registryStatusCreated = &timeNow
+	c.registryStatusStale = false
+	// Results computed from the previous registry status are outdated, unless it didn't change
+	if !reflect.DeepEqual(c.registryStatus, registryStatus) {
+		c.recordChange("", RegistryStatusType)
+	}
 	c.registryStatus = registryStatus
 }
 
+// RefreshRegistryStatus marks the registry status as stale, it is kept until it is minRegistryStatusAge old
 func (c *kialiCacheImpl) RefreshRegistryStatus() {
 	defer c.registryStatusLock.Unlock()
 	c.registryStatusLock.Lock()
-	c.registryStatusCreated = nil
-	c.registryStatus = nil
+	c.registryStatusStale = true
 }
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"time"
+)
+
+const (
+	// RegistryStatusType is the resource type of the changes of the registry status
+	RegistryStatusType = "RegistryStatus"
+	// NamespaceType is the resource type of the changes of the namespaces, observed when they are listed again
+	NamespaceType = "Namespace"
+)
+
+type (
+	RevisionCache interface {
+		// GetRevision returns a counter of the changes observed by the cache.
+		// Results computed from the cache remain valid as long as it doesn't change.
+		GetRevision() uint64
+		// GetTypeRevision returns a counter of the changes observed for a resource type in a namespace
+		GetTypeRevision(namespace, resourceType string) uint64
+		// GetTypesRevision returns a counter of the changes observed for the resource types in a namespace, or in
+		// all the namespaces when namespace is empty. Results computed from these types only remain valid as long
+		// as it doesn't change.
+		GetTypesRevision(namespace string, resourceTypes ...string) uint64
+		// WaitForTypeChange waits until a change of a resource type in a namespace is observed after the given
+		// revision. It returns false when the timeout expires first, or when the type isn't cached in the namespace.
+		WaitForTypeChange(namespace, resourceType string, revision uint64, timeout time.Duration) bool
+	}
+)
+
+func (c *kialiCacheImpl) GetRevision() uint64 {
+	defer c.revisionLock.Unlock()
+	c.revisionLock.Lock()
+	return c.revision
+}
+
+func (c *kialiCacheImpl) GetTypeRevision(namespace, resourceType string) uint64 {
+	defer c.revisionLock.Unlock()
+	c.revisionLock.Lock()
+	return c.typeRevisions[namespace+"/"+resourceType]
+}
+
+func (c *kialiCacheImpl) GetTypesRevision(namespace string, resourceTypes ...string) uint64 {
+	defer c.revisionLock.Unlock()
+	c.revisionLock.Lock()
+	// Counters only grow, their sum changes with any of them
+	var revision uint64
+	for _, resourceType := range resourceTypes {
+		if namespace == "" {
+			revision += c.allTypeRevisions[resourceType]
+		} else {
+			revision += c.typeRevisions[namespace+"/"+resourceType]
+		}
+	}
+	return revision
+}
+
+func (c *kialiCacheImpl) WaitForTypeChange(namespace, resourceType string, revision uint64, timeout time.Duration) bool {
+	c.cacheLock.RLock()
+	_, isCached := c.nsCache[namespace][resourceType]
+	c.cacheLock.RUnlock()
+	if !isCached {
+		return false
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		c.revisionLock.Lock()
+		if c.typeRevisions[namespace+"/"+resourceType] != revision {
+			c.revisionLock.Unlock()
+			return true
+		}
+		if c.revisionChange == nil {
+			c.revisionChange = make(chan struct{})
+		}
+		changed := c.revisionChange
+		c.revisionLock.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return false
+		}
+	}
+}
+
+// recordChange bumps the revision of the cache, the one of the resource type in all the namespaces and, unless
+// namespace is empty, the one of the resource type in the namespace. Waiters are woken up to check the revision
+// they wait for.
+func (c *kialiCacheImpl) recordChange(namespace, resourceType string) {
+	defer c.revisionLock.Unlock()
+	c.revisionLock.Lock()
+	c.revision++
+	if c.allTypeRevisions == nil {
+		c.allTypeRevisions = make(map[string]uint64)
+	}
+	c.allTypeRevisions[resourceType]++
+	if namespace != "" {
+		if c.typeRevisions == nil {
+			c.typeRevisions = make(map[string]uint64)
+		}
+		c.typeRevisions[namespace+"/"+resourceType]++
+	}
+	if c.revisionChange != nil {
+		close(c.revisionChange)
+		c.revisionChange = nil
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func TestWaitForTypeChange(t *testing.T) {
+	assert := assert.New(t)
+
+	kialiCacheImpl := kialiCacheImpl{
+		nsCache: map[string]typeCache{
+			"bookinfo": {kubernetes.VirtualServiceType: nil},
+		},
+	}
+
+	revision := kialiCacheImpl.GetTypeRevision("bookinfo", kubernetes.VirtualServiceType)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		// Changes of other types don't end the wait
+		kialiCacheImpl.recordChange("bookinfo", kubernetes.DestinationRuleType)
+		kialiCacheImpl.recordChange("bookinfo", kubernetes.VirtualServiceType)
+	}()
+	assert.True(kialiCacheImpl.WaitForTypeChange("bookinfo", kubernetes.VirtualServiceType, revision, time.Minute))
+	assert.Equal(uint64(2), kialiCacheImpl.GetRevision())
+
+	revision = kialiCacheImpl.GetTypeRevision("bookinfo", kubernetes.VirtualServiceType)
+	assert.False(kialiCacheImpl.WaitForTypeChange("bookinfo", kubernetes.VirtualServiceType, revision, 10*time.Millisecond))
+
+	// Types without informer are never notified
+	start := time.Now()
+	assert.False(kialiCacheImpl.WaitForTypeChange("bookinfo", kubernetes.GatewayType, 0, time.Minute))
+	assert.True(time.Since(start) < time.Minute)
+}
+
+func TestChangeHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	kialiCacheImpl := kialiCacheImpl{refreshDuration: time.Hour}
+	kialiCacheImpl.SetRegistryStatus(&kubernetes.RegistryStatus{})
+	revision := kialiCacheImpl.GetRevision()
+
+	vs := &networking_v1alpha3.VirtualService{}
+	vs.ResourceVersion = "1"
+	handler := newChangeHandler(&kialiCacheImpl, "bookinfo", kubernetes.VirtualServiceType)
+
+	// Resyncs don't change anything
+	handler.OnUpdate(vs, vs)
+	assert.Equal(revision, kialiCacheImpl.GetRevision())
+	assert.True(kialiCacheImpl.CheckRegistryStatus())
+
+	updated := vs.DeepCopy()
+	updated.ResourceVersion = "2"
+	handler.OnUpdate(vs, updated)
+	assert.Equal(revision+1, kialiCacheImpl.GetRevision())
+	assert.Equal(uint64(1), kialiCacheImpl.GetTypeRevision("bookinfo", kubernetes.VirtualServiceType))
+	// The stale registry status is kept for a while, so a burst of changes refreshes it once
+	assert.True(kialiCacheImpl.CheckRegistryStatus())
+	created := time.Now().Add(-minRegistryStatusAge)
+	kialiCacheImpl.registryStatusCreated = &created
+	assert.False(kialiCacheImpl.CheckRegistryStatus())
+
+	// A refreshed registry status that didn't change doesn't change the revision
+	revision = kialiCacheImpl.GetRevision()
+	kialiCacheImpl.SetRegistryStatus(&kubernetes.RegistryStatus{})
+	assert.True(kialiCacheImpl.CheckRegistryStatus())
+	assert.Equal(revision, kialiCacheImpl.GetRevision())
+
+	// Pods are not known by the registry
+	newChangeHandler(&kialiCacheImpl, "bookinfo", kubernetes.PodType).OnDelete(vs)
+	kialiCacheImpl.registryStatusCreated = &created
+	assert.True(kialiCacheImpl.CheckRegistryStatus())
+}
+
+func TestGetTypesRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	kialiCacheImpl := kialiCacheImpl{}
+	kialiCacheImpl.recordChange("bookinfo", kubernetes.VirtualServiceType)
+	kialiCacheImpl.recordChange("travels", kubernetes.ServiceType)
+	kialiCacheImpl.recordChange("", RegistryStatusType)
+
+	assert.Equal(uint64(2), kialiCacheImpl.GetTypesRevision("", kubernetes.VirtualServiceType, RegistryStatusType))
+	assert.Equal(uint64(0), kialiCacheImpl.GetTypesRevision("bookinfo", kubernetes.ServiceType))
+	assert.Equal(uint64(1), kialiCacheImpl.GetTypesRevision("travels", kubernetes.ServiceType))
+
+	// Pods are not read, their changes don't change the revision
+	kialiCacheImpl.recordChange("bookinfo", kubernetes.PodType)
+	assert.Equal(uint64(2), kialiCacheImpl.GetTypesRevision("", kubernetes.VirtualServiceType, RegistryStatusType))
+}
@@ -39,6 +39,8 @@ type PodLogs struct {
 type ClientInterface interface {
 	GetServerVersion() (*version.Info, error)
 	GetToken() string
+	// GetTokenHash returns a hash of the token and of the impersonated identity, it identifies the privileges of the client
+	GetTokenHash() string
 	GetAuthInfo() *api.AuthInfo
 	IsOpenShift() bool
 	K8SClientInterface
@@ -52,6 +54,7 @@ type ClientInterface interface {
 type K8SClient struct {
 	ClientInterface
 	token          string
+	tokenHash      string
 	k8s            *kube.Clientset
 	iter8Api       *rest.RESTClient
 	istioClientset *istio.Clientset
@@ -78,6 +81,11 @@ func (client *K8SClient) GetToken() string {
 	return client.token
 }
 
+// GetTokenHash returns the hash of the BearerToken and of the impersonated identity used from the config
+func (client *K8SClient) GetTokenHash() string {
+	return client.tokenHash
+}
+
 // Point the k8s client to a remote cluster's API server
 func UseRemoteCreds(remoteSecret *RemoteSecret) (*rest.Config, error) {
 	caData := remoteSecret.Clusters[0].Cluster.CertificateAuthorityData
@@ -122,7 +130,7 @@ func ConfigClient() (*rest.Config, error) {
 		var err error
 		if remoteSecret, readErr := GetRemoteSecret(RemoteSecretData); readErr == nil {
 			incluster, err = UseRemoteCreds(remoteSecret)
-		} else if _,err:= os.Stat("D:\\mesh\\kiali\\config\\config"); err==nil{
+		} else if _, statErr := os.Stat("D:\\mesh\\kiali\\config\\config"); statErr == nil {
 			incluster, err = LoadsKubeConfigFromFile("D:\\mesh\\kiali\\config\\config")
 		} else {
 			incluster, err = rest.InClusterConfig()
@@ -163,6 +171,12 @@ func LoadsKubeConfigFromFile(fileName string) (*rest.Config, error) {
 func NewClientFromConfig(config *rest.Config) (*K8SClient, error) {
 	client := K8SClient{
 		token: config.BearerToken,
+		tokenHash: getTokenHash(&api.AuthInfo{
+			Token:                config.BearerToken,
+			Impersonate:          config.Impersonate.UserName,
+			ImpersonateGroups:    config.Impersonate.Groups,
+			ImpersonateUserExtra: config.Impersonate.Extra,
+		}),
 	}
 
 	log.Debugf("Rest perf config QPS: %f Burst: %d", config.QPS, config.Burst)
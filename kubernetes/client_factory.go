@@ -127,8 +127,10 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo) (ClientInterface, err
 		}
 	}
 
-	// Impersonation is valid only for header authentication strategy
-	if cfg.Auth.Strategy == kialiConfig.AuthStrategyHeader && authInfo.Impersonate != "" {
+	// Impersonation is valid only for header authentication strategy, and for the identities of the client
+	// certificates and the ServiceAccounts of the API keys, which are impersonated by Kiali
+	impersonatedByKiali := (cfg.Auth.Strategy == kialiConfig.AuthStrategyX509 || cfg.Auth.ApiKeys.Enabled) && KialiToken != "" && authInfo.Token == KialiToken
+	if (cfg.Auth.Strategy == kialiConfig.AuthStrategyHeader || impersonatedByKiali) && authInfo.Impersonate != "" {
 		config.Impersonate.UserName = authInfo.Impersonate
 		config.Impersonate.Groups = authInfo.ImpersonateGroups
 		config.Impersonate.Extra = authInfo.ImpersonateUserExtra
@@ -67,3 +67,14 @@ func TestClientExpiry(t *testing.T) {
 	assert.Equal(t, 0, len(clientEntries))
 	mutex.RUnlock()
 }
+
+// TestClientTokenHashCoversImpersonation verifies that clients of a token impersonating different users don't share their token hash
+func TestClientTokenHashCoversImpersonation(t *testing.T) {
+	alice, err := NewClientFromConfig(&rest.Config{BearerToken: "kiali", Impersonate: rest.ImpersonationConfig{UserName: "alice"}})
+	assert.NoError(t, err)
+	admins, err := NewClientFromConfig(&rest.Config{BearerToken: "kiali", Impersonate: rest.ImpersonationConfig{UserName: "alice", Groups: []string{"admins"}}})
+	assert.NoError(t, err)
+
+	assert.Equal(t, alice.GetToken(), admins.GetToken())
+	assert.NotEqual(t, alice.GetTokenHash(), admins.GetTokenHash())
+}
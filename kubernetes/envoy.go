@@ -1,6 +1,10 @@
 package kubernetes
 
-import "github.com/mitchellh/mapstructure"
+import (
+	"encoding/json"
+
+	"github.com/mitchellh/mapstructure"
+)
 
 // Root of ConfigDump
 type ConfigDump struct {
@@ -56,6 +60,7 @@ type StaticListener struct {
 }
 
 type EnvoyListener struct {
+	Name    string `mapstructure:"name"`
 	Address struct {
 		SocketAddress struct {
 			Address   string  `mapstructure:"address"`
@@ -96,8 +101,16 @@ type VirtualHostFilter struct {
 		Match    map[string]interface{} `mapstructure:"match"`
 		Metadata *EnvoyMetadata         `mapstructure:"metadata,omitempty"`
 		Route    *struct {
-			Cluster string `mapstructure:"cluster,omitempty"`
+			Cluster          string `mapstructure:"cluster,omitempty"`
+			WeightedClusters *struct {
+				Clusters []struct {
+					Name   string  `mapstructure:"name"`
+					Weight float64 `mapstructure:"weight"`
+				} `mapstructure:"clusters"`
+			} `mapstructure:"weighted_clusters,omitempty"`
 		} `mapstructure:"route,omitempty"`
+		Redirect       map[string]interface{} `mapstructure:"redirect,omitempty"`
+		DirectResponse map[string]interface{} `mapstructure:"direct_response,omitempty"`
 	} `mapstructure:"routes,omitempty"`
 }
 
@@ -112,6 +125,127 @@ type FilterChainMatch struct {
 	} `mapstructure:"prefix_ranges"`
 }
 
+type SecretDump struct {
+	StaticSecrets         []EnvoySecretWrapper `mapstructure:"static_secrets"`
+	DynamicActiveSecrets  []EnvoySecretWrapper `mapstructure:"dynamic_active_secrets"`
+	DynamicWarmingSecrets []EnvoySecretWrapper `mapstructure:"dynamic_warming_secrets"`
+}
+
+type EnvoySecretWrapper struct {
+	Name        string      `mapstructure:"name"`
+	VersionInfo string      `mapstructure:"version_info"`
+	LastUpdated string      `mapstructure:"last_updated"`
+	Secret      EnvoySecret `mapstructure:"secret"`
+}
+
+// EnvoySecret only maps the public parts of a secret: the private key is never decoded
+type EnvoySecret struct {
+	Name           string `mapstructure:"name"`
+	TLSCertificate *struct {
+		CertificateChain EnvoyDataSource `mapstructure:"certificate_chain"`
+	} `mapstructure:"tls_certificate,omitempty"`
+	ValidationContext *struct {
+		TrustedCA            EnvoyDataSource          `mapstructure:"trusted_ca"`
+		MatchSubjectAltNames []map[string]interface{} `mapstructure:"match_subject_alt_names,omitempty"`
+	} `mapstructure:"validation_context,omitempty"`
+}
+
+type EnvoyDataSource struct {
+	Filename     string `mapstructure:"filename,omitempty"`
+	InlineBytes  string `mapstructure:"inline_bytes,omitempty"`
+	InlineString string `mapstructure:"inline_string,omitempty"`
+}
+
+type EcdsDump struct {
+	EcdsFilters []EcdsFilter `mapstructure:"ecds_filters"`
+}
+
+type EcdsFilter struct {
+	VersionInfo string `mapstructure:"version_info"`
+	LastUpdated string `mapstructure:"last_updated"`
+	EcdsFilter  struct {
+		Name        string                 `mapstructure:"name"`
+		TypedConfig map[string]interface{} `mapstructure:"typed_config"`
+	} `mapstructure:"ecds_filter"`
+}
+
+// ClustersStatus is the response of the Envoy admin /clusters?format=json endpoint
+type ClustersStatus struct {
+	ClusterStatuses []ClusterStatus `json:"cluster_statuses"`
+}
+
+type ClusterStatus struct {
+	Name                         string       `json:"name"`
+	AddedViaAPI                  bool         `json:"added_via_api"`
+	HostStatuses                 []HostStatus `json:"host_statuses"`
+	SuccessRateEjectionThreshold *DoubleValue `json:"success_rate_ejection_threshold,omitempty"`
+	CircuitBreakers              *struct {
+		Thresholds []CircuitBreakerThresholds `json:"thresholds"`
+	} `json:"circuit_breakers,omitempty"`
+}
+
+type CircuitBreakerThresholds struct {
+	Priority           string `json:"priority"`
+	MaxConnections     int64  `json:"max_connections"`
+	MaxPendingRequests int64  `json:"max_pending_requests"`
+	MaxRequests        int64  `json:"max_requests"`
+	MaxRetries         int64  `json:"max_retries"`
+}
+
+type HostStatus struct {
+	Address struct {
+		SocketAddress struct {
+			Address   string  `json:"address"`
+			PortValue float64 `json:"port_value"`
+		} `json:"socket_address"`
+	} `json:"address"`
+	Stats []struct {
+		Name  string      `json:"name"`
+		Type  string      `json:"type"`
+		Value json.Number `json:"value"`
+	} `json:"stats"`
+	HealthStatus HostHealthStatus `json:"health_status"`
+	SuccessRate  *DoubleValue     `json:"success_rate,omitempty"`
+	Weight       int              `json:"weight"`
+	Priority     int              `json:"priority"`
+	Locality     struct {
+		Region  string `json:"region"`
+		Zone    string `json:"zone"`
+		SubZone string `json:"sub_zone"`
+	} `json:"locality"`
+}
+
+type HostHealthStatus struct {
+	EdsHealthStatus            string `json:"eds_health_status"`
+	FailedOutlierCheck         bool   `json:"failed_outlier_check"`
+	FailedActiveHealthCheck    bool   `json:"failed_active_health_check"`
+	FailedActiveDegradedCheck  bool   `json:"failed_active_degraded_check"`
+	PendingDynamicRemoval      bool   `json:"pending_dynamic_removal"`
+	PendingActiveHc            bool   `json:"pending_active_hc"`
+	ExcludedViaImmediateHcFail bool   `json:"excluded_via_immediate_hc_fail"`
+	ActiveHcTimeout            bool   `json:"active_hc_timeout"`
+}
+
+type DoubleValue struct {
+	Value float64 `json:"value"`
+}
+
+// ServerInfo is the response of the Envoy admin /server_info endpoint
+type ServerInfo struct {
+	Version            string `json:"version"`
+	State              string `json:"state"`
+	HotRestartVersion  string `json:"hot_restart_version"`
+	UptimeCurrentEpoch string `json:"uptime_current_epoch"`
+	UptimeAllEpochs    string `json:"uptime_all_epochs"`
+	CommandLineOptions struct {
+		RestartEpoch int `json:"restart_epoch"`
+	} `json:"command_line_options"`
+	Node struct {
+		ID      string `json:"id"`
+		Cluster string `json:"cluster"`
+	} `json:"node"`
+}
+
 func (cd *ConfigDump) GetListeners() (*ListenerDump, error) {
 	listenersDumpRaw := cd.GetConfig("type.googleapis.com/envoy.admin.v3.ListenersConfigDump")
 	var listenersDump ListenerDump
@@ -130,6 +264,18 @@ func (cd *ConfigDump) GetRoutes() (*RouteDump, error) {
 	return &routeDump, mapstructure.Decode(routeDumpRaw, &routeDump)
 }
 
+func (cd *ConfigDump) GetSecrets() (*SecretDump, error) {
+	secretDumpRaw := cd.GetConfig("type.googleapis.com/envoy.admin.v3.SecretsConfigDump")
+	var secretDump SecretDump
+	return &secretDump, mapstructure.Decode(secretDumpRaw, &secretDump)
+}
+
+func (cd *ConfigDump) GetEcds() (*EcdsDump, error) {
+	ecdsDumpRaw := cd.GetConfig("type.googleapis.com/envoy.admin.v3.EcdsConfigDump")
+	var ecdsDump EcdsDump
+	return &ecdsDump, mapstructure.Decode(ecdsDumpRaw, &ecdsDump)
+}
+
 func (cd *ConfigDump) GetConfig(objectType string) map[string]interface{} {
 	for _, configRaw := range cd.Configs {
 		conf, ok := configRaw.(map[string]interface{})
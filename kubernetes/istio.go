@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"gopkg.in/yaml.v2"
 	api_networking_v1alpha3 "istio.io/api/networking/v1alpha3"
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
@@ -37,7 +41,12 @@ type IstioClientInterface interface {
 
 	GetProxyStatus() ([]*ProxyStatus, error)
 	GetConfigDump(namespace, podName string) (*ConfigDump, error)
+	GetClustersStatus(namespace, podName string) (*ClustersStatus, error)
+	GetProxyStats(namespace, podName, filter string) (map[string]*dto.MetricFamily, error)
+	GetProxyServerInfo(namespace, podName string) (*ServerInfo, error)
 	SetProxyLogLevel(namespace, podName, level string) error
+	SetProxyLoggerLevels(namespace, podName string, levels map[string]string) error
+	GetProxyLogLevels(namespace, podName string) (map[string]string, error)
 	GetRegistryConfiguration() (*RegistryConfiguration, error)
 	GetRegistryEndpoints() ([]*RegistryEndpoint, error)
 	GetRegistryServices() ([]*RegistryService, error)
@@ -363,33 +372,156 @@ func (in *K8SClient) GetConfigDump(namespace, podName string) (*ConfigDump, erro
 	return cd, err
 }
 
+// GetClustersStatus fetches the clusters of the pod's Envoy, with the health and stats of their endpoints
+func (in *K8SClient) GetClustersStatus(namespace, podName string) (*ClustersStatus, error) {
+	resp, err := in.envoyAdminGet(namespace, podName, "/clusters?format=json")
+	if err != nil {
+		log.Errorf("Error forwarding the /clusters request: %v", err)
+		return nil, err
+	}
+
+	cs := &ClustersStatus{}
+	err = json.Unmarshal(resp, cs)
+	if err != nil {
+		log.Errorf("Error Unmarshalling the clusters status: %v", err)
+	}
+
+	return cs, err
+}
+
+// GetProxyStats fetches the stats of the pod's Envoy whose name matches the filter regex, if any.
+// The Prometheus format is used since it is the only one telling counters, gauges and histograms apart.
+func (in *K8SClient) GetProxyStats(namespace, podName, filter string) (map[string]*dto.MetricFamily, error) {
+	params := url.Values{}
+	params.Set("format", "prometheus")
+	if filter != "" {
+		params.Set("filter", filter)
+	}
+	resp, err := in.envoyAdminGet(namespace, podName, "/stats?"+params.Encode())
+	if err != nil {
+		log.Errorf("Error forwarding the /stats request: %v", err)
+		return nil, err
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(bytes.NewReader(resp))
+	if err != nil {
+		log.Errorf("Error parsing the proxy stats: %v", err)
+	}
+
+	return families, err
+}
+
+// GetProxyServerInfo fetches the state, version and uptime of the pod's Envoy
+func (in *K8SClient) GetProxyServerInfo(namespace, podName string) (*ServerInfo, error) {
+	resp, err := in.envoyAdminGet(namespace, podName, "/server_info")
+	if err != nil {
+		log.Errorf("Error forwarding the /server_info request: %v", err)
+		return nil, err
+	}
+
+	si := &ServerInfo{}
+	err = json.Unmarshal(resp, si)
+	if err != nil {
+		log.Errorf("Error Unmarshalling the server info: %v", err)
+	}
+
+	return si, err
+}
+
+func (in *K8SClient) envoyAdminGet(namespace, podName, path string) ([]byte, error) {
+	freePort := httputil.Pool.GetFreePort()
+	defer httputil.Pool.FreePort(freePort)
+	return in.ForwardGetRequest(namespace, podName, freePort, envoyAdminLocalPort(), path)
+}
+
+func envoyAdminLocalPort() int {
+	if port := config.Get().ExternalServices.Istio.EnvoyAdminLocalPort; port != 0 {
+		return port
+	}
+	return envoyAdminPort
+}
+
 func (in *K8SClient) SetProxyLogLevel(namespace, pod, level string) error {
-	path := fmt.Sprintf("/logging?level=%s", level)
+	_, err := in.envoyAdminPost(namespace, pod, fmt.Sprintf("/logging?level=%s", level))
+	return err
+}
+
+// SetProxyLoggerLevels sets the level of some loggers of the pod's Envoy, i.e. {"rbac": "debug"}
+func (in *K8SClient) SetProxyLoggerLevels(namespace, pod string, levels map[string]string) error {
+	loggers := make([]string, 0, len(levels))
+	for logger := range levels {
+		loggers = append(loggers, logger)
+	}
+	sort.Strings(loggers)
+
+	paths := make([]string, 0, len(loggers))
+	for _, logger := range loggers {
+		paths = append(paths, fmt.Sprintf("/logging?%s=%s", url.QueryEscape(logger), url.QueryEscape(levels[logger])))
+	}
+	_, err := in.envoyAdminPost(namespace, pod, paths...)
+	return err
+}
+
+// GetProxyLogLevels fetches the level of each logger of the pod's Envoy
+func (in *K8SClient) GetProxyLogLevels(namespace, pod string) (map[string]string, error) {
+	// Envoy lists its loggers when none is set
+	body, err := in.envoyAdminPost(namespace, pod, "/logging")
+	if err != nil {
+		return nil, err
+	}
+	return parseProxyLogLevels(body), nil
+}
+
+// parseProxyLogLevels parses the list of loggers returned by Envoy, one indented "name: level" line per logger
+func parseProxyLogLevels(body []byte) map[string]string {
+	levels := map[string]string{}
+	for _, line := range strings.Split(string(body), "\n") {
+		if !strings.HasPrefix(line, " ") {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(parts) == 2 {
+			levels[parts[0]] = strings.TrimSpace(parts[1])
+		}
+	}
+	return levels
+}
 
+// envoyAdminPost sends POST requests to the Envoy admin interface of a pod, through a single port forwarding.
+// It returns the body of the last response.
+func (in *K8SClient) envoyAdminPost(namespace, pod string, paths ...string) ([]byte, error) {
 	localPort := httputil.Pool.GetFreePort()
 	defer httputil.Pool.FreePort(localPort)
-	f, err := in.GetPodPortForwarder(namespace, pod, fmt.Sprintf("%d:%d", localPort, envoyAdminPort))
+	f, err := in.GetPodPortForwarder(namespace, pod, fmt.Sprintf("%d:%d", localPort, envoyAdminLocalPort()))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Start the forwarding
 	if err := (*f).Start(); err != nil {
-		return err
+		return nil, err
 	}
 
 	// Defering the finish of the port-forwarding
 	defer (*f).Stop()
 
-	// Ready to create a request
-	url := fmt.Sprintf("http://localhost:%d%s", localPort, path)
-	body, code, err := httputil.HttpPost(url, nil, nil, time.Second*10, nil)
-	if code >= 400 {
-		log.Errorf("Error whilst posting. Error: %s. Body: %s", err, string(body))
-		return fmt.Errorf("error sending post request %s from %s/%s. Response code: %d", path, namespace, pod, code)
+	var body []byte
+	for _, path := range paths {
+		// Ready to create a request
+		endpoint := fmt.Sprintf("http://localhost:%d%s", localPort, path)
+		var code int
+		body, code, err = httputil.HttpPost(endpoint, nil, nil, time.Second*10, nil)
+		if code >= 400 {
+			log.Errorf("Error whilst posting. Error: %s. Body: %s", err, string(body))
+			return nil, fmt.Errorf("error sending post request %s from %s/%s. Response code: %d", path, namespace, pod, code)
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	return err
+	return body, nil
 }
 
 func GetIstioConfigMap(istioConfig *core_v1.ConfigMap) (*IstioMeshConfig, error) {
@@ -213,3 +213,8 @@ func TestRegistryServices(t *testing.T) {
 	assert.Equal(79, len(registry))
 	assert.Equal("*.msn.com", registry[0].Attributes.Name)
 }
+
+func TestParseProxyLogLevels(t *testing.T) {
+	levels := parseProxyLogLevels([]byte("active loggers:\n  admin: warning\n  rbac: debug\n"))
+	assert.Equal(t, map[string]string{"admin": "warning", "rbac": "debug"}, levels)
+}
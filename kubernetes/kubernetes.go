@@ -9,6 +9,7 @@ import (
 	osapps_v1 "github.com/openshift/api/apps/v1"
 	osproject_v1 "github.com/openshift/api/project/v1"
 	osroutes_v1 "github.com/openshift/api/route/v1"
+	admission_v1 "k8s.io/api/admissionregistration/v1"
 	apps_v1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/authentication/v1"
 	auth_v1 "k8s.io/api/authorization/v1"
@@ -29,6 +30,7 @@ import (
 )
 
 type K8SClientInterface interface {
+	CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error)
 	ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error)
 	GetClusterServicesByLabels(labelsSelector string) ([]core_v1.Service, error)
 	GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error)
@@ -41,6 +43,7 @@ type K8SClientInterface interface {
 	GetDeploymentConfigs(namespace string) ([]osapps_v1.DeploymentConfig, error)
 	GetEndpoints(namespace string, name string) (*core_v1.Endpoints, error)
 	GetJobs(namespace string) ([]batch_v1.Job, error)
+	GetMutatingWebhookConfigurations(labelSelector string) ([]admission_v1.MutatingWebhookConfiguration, error)
 	GetNamespace(namespace string) (*core_v1.Namespace, error)
 	GetNamespaces(labelSelector string) ([]core_v1.Namespace, error)
 	GetPod(namespace, name string) (*core_v1.Pod, error)
@@ -58,6 +61,7 @@ type K8SClientInterface interface {
 	GetStatefulSet(namespace string, name string) (*apps_v1.StatefulSet, error)
 	GetStatefulSets(namespace string) ([]apps_v1.StatefulSet, error)
 	GetTokenSubject(authInfo *api.AuthInfo) (string, error)
+	UpdateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error)
 	UpdateNamespace(namespace string, jsonPatch string) (*core_v1.Namespace, error)
 	UpdateService(namespace string, name string, jsonPatch string) error
 	UpdateWorkload(namespace string, name string, workloadType string, jsonPatch string) error
@@ -117,6 +121,17 @@ func (in *K8SClient) GetConfigMap(namespace, name string) (*core_v1.ConfigMap, e
 	return configMap, nil
 }
 
+// CreateConfigMap creates the given ConfigMap in the cluster
+func (in *K8SClient) CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Create(in.ctx, configMap, meta_v1.CreateOptions{})
+}
+
+// UpdateConfigMap replaces the given ConfigMap in the cluster. The update is rejected when the ConfigMap
+// changed since its resource version was read.
+func (in *K8SClient) UpdateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	return in.k8s.CoreV1().ConfigMaps(namespace).Update(in.ctx, configMap, meta_v1.UpdateOptions{})
+}
+
 // GetNamespace fetches and returns the specified namespace definition
 // from the cluster
 func (in *K8SClient) GetNamespace(namespace string) (*core_v1.Namespace, error) {
@@ -276,6 +291,18 @@ func (in *K8SClient) GetDeployments(namespace string) ([]apps_v1.Deployment, err
 	}
 }
 
+// GetMutatingWebhookConfigurations returns the mutating webhook configurations matching a label selector, i.e. the
+// sidecar injectors of the Istio control planes.
+// It returns an error on any problem.
+func (in *K8SClient) GetMutatingWebhookConfigurations(labelSelector string) ([]admission_v1.MutatingWebhookConfiguration, error) {
+	listOptions := meta_v1.ListOptions{LabelSelector: labelSelector}
+	if webhookList, err := in.k8s.AdmissionregistrationV1().MutatingWebhookConfigurations().List(in.ctx, listOptions); err == nil {
+		return webhookList.Items, nil
+	} else {
+		return []admission_v1.MutatingWebhookConfiguration{}, err
+	}
+}
+
 // GetDeployment returns the definition of a specific deployment.
 // It returns an error on any problem.
 func (in *K8SClient) GetDeploymentConfig(namespace, name string) (*osapps_v1.DeploymentConfig, error) {
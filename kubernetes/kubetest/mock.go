@@ -104,6 +104,11 @@ func (o *K8SClientMock) GetToken() string {
 	return args.Get(0).(string)
 }
 
+func (o *K8SClientMock) GetTokenHash() string {
+	args := o.Called()
+	return args.Get(0).(string)
+}
+
 // GetAuthInfo returns the AuthInfo struct for the client
 func (o *K8SClientMock) GetAuthInfo() *api.AuthInfo {
 	args := o.Called()
@@ -3,6 +3,7 @@ package kubetest
 import (
 	"context"
 
+	dto "github.com/prometheus/client_model/go"
 	networking_v1alpha3 "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istio "istio.io/client-go/pkg/clientset/versioned"
 	istio_fake "istio.io/client-go/pkg/clientset/versioned/fake"
@@ -42,6 +43,21 @@ func (o *K8SClientMock) GetConfigDump(namespace string, podName string) (*kubern
 	return args.Get(0).(*kubernetes.ConfigDump), args.Error(1)
 }
 
+func (o *K8SClientMock) GetClustersStatus(namespace string, podName string) (*kubernetes.ClustersStatus, error) {
+	args := o.Called(namespace, podName)
+	return args.Get(0).(*kubernetes.ClustersStatus), args.Error(1)
+}
+
+func (o *K8SClientMock) GetProxyStats(namespace, podName, filter string) (map[string]*dto.MetricFamily, error) {
+	args := o.Called(namespace, podName, filter)
+	return args.Get(0).(map[string]*dto.MetricFamily), args.Error(1)
+}
+
+func (o *K8SClientMock) GetProxyServerInfo(namespace string, podName string) (*kubernetes.ServerInfo, error) {
+	args := o.Called(namespace, podName)
+	return args.Get(0).(*kubernetes.ServerInfo), args.Error(1)
+}
+
 func (o *K8SClientMock) GetRegistryConfiguration() (*kubernetes.RegistryConfiguration, error) {
 	args := o.Called()
 	return args.Get(0).(*kubernetes.RegistryConfiguration), args.Error(1)
@@ -57,6 +73,16 @@ func (o *K8SClientMock) GetRegistryEndpoints() ([]*kubernetes.RegistryEndpoint,
 	return args.Get(0).([]*kubernetes.RegistryEndpoint), args.Error(1)
 }
 
+func (o *K8SClientMock) SetProxyLoggerLevels(namespace, podName string, levels map[string]string) error {
+	args := o.Called(namespace, podName, levels)
+	return args.Error(0)
+}
+
+func (o *K8SClientMock) GetProxyLogLevels(namespace, podName string) (map[string]string, error) {
+	args := o.Called(namespace, podName)
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (o *K8SClientMock) SetProxyLogLevel(namespace, podName, level string) error {
 	args := o.Called()
 	return args.Error(0)
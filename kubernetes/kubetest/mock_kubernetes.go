@@ -1,6 +1,7 @@
 package kubetest
 
 import (
+	admission_v1 "k8s.io/api/admissionregistration/v1"
 	apps_v1 "k8s.io/api/apps/v1"
 	auth_v1 "k8s.io/api/authorization/v1"
 	batch_v1 "k8s.io/api/batch/v1"
@@ -11,6 +12,11 @@ import (
 	"github.com/kiali/kiali/util/httputil"
 )
 
+func (o *K8SClientMock) CreateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	args := o.Called(namespace, configMap)
+	return args.Get(0).(*core_v1.ConfigMap), args.Error(1)
+}
+
 func (o *K8SClientMock) ForwardGetRequest(namespace, podName string, localPort, destinationPort int, path string) ([]byte, error) {
 	args := o.Called(namespace, podName, localPort, destinationPort, path)
 	return args.Get(0).([]byte), args.Error(1)
@@ -71,6 +77,11 @@ func (o *K8SClientMock) GetNamespace(namespace string) (*core_v1.Namespace, erro
 	return args.Get(0).(*core_v1.Namespace), args.Error(1)
 }
 
+func (o *K8SClientMock) GetMutatingWebhookConfigurations(labelSelector string) ([]admission_v1.MutatingWebhookConfiguration, error) {
+	args := o.Called(labelSelector)
+	return args.Get(0).([]admission_v1.MutatingWebhookConfiguration), args.Error(1)
+}
+
 func (o *K8SClientMock) GetNamespaces(labelSelector string) ([]core_v1.Namespace, error) {
 	args := o.Called(labelSelector)
 	return args.Get(0).([]core_v1.Namespace), args.Error(1)
@@ -146,6 +157,11 @@ func (o *K8SClientMock) GetStatefulSets(namespace string) ([]apps_v1.StatefulSet
 	return args.Get(0).([]apps_v1.StatefulSet), args.Error(1)
 }
 
+func (o *K8SClientMock) UpdateConfigMap(namespace string, configMap *core_v1.ConfigMap) (*core_v1.ConfigMap, error) {
+	args := o.Called(namespace, configMap)
+	return args.Get(0).(*core_v1.ConfigMap), args.Error(1)
+}
+
 func (o *K8SClientMock) UpdateNamespace(namespace string, jsonPatch string) (*core_v1.Namespace, error) {
 	args := o.Called(namespace, jsonPatch)
 	return args.Get(0).(*core_v1.Namespace), args.Error(1)
@@ -7,6 +7,8 @@ import (
 	security_v1beta "istio.io/client-go/pkg/apis/security/v1beta1"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/config"
 )
 
 const (
@@ -143,8 +145,9 @@ var (
 )
 
 type IstioMeshConfig struct {
-	DisableMixerHttpReports bool  `yaml:"disableMixerHttpReports,omitempty"`
-	EnableAutoMtls          *bool `yaml:"enableAutoMtls,omitempty"`
+	DisableMixerHttpReports bool   `yaml:"disableMixerHttpReports,omitempty"`
+	EnableAutoMtls          *bool  `yaml:"enableAutoMtls,omitempty"`
+	RootNamespace           string `yaml:"rootNamespace,omitempty"`
 }
 
 // MTLSDetails is a wrapper to group all Istio objects related to non-local mTLS configurations
@@ -153,6 +156,8 @@ type MTLSDetails struct {
 	MeshPeerAuthentications []security_v1beta.PeerAuthentication  `json:"meshpeerauthentications"`
 	PeerAuthentications     []security_v1beta.PeerAuthentication  `json:"peerauthentications"`
 	EnabledAutoMtls         bool                                  `json:"enabledautomtls"`
+	// The root namespaces of the control planes, where the PeerAuthentications are mesh-wide
+	RootNamespaces []string `json:"rootnamespaces"`
 }
 
 // RBACDetails is a wrapper for objects related to Istio RBAC (Role Based Access Control)
@@ -174,6 +179,11 @@ type ProxyStatus struct {
 	SyncStatus
 }
 
+// Pilot returns the name of the istiod instance the proxy is connected to
+func (ps *ProxyStatus) Pilot() string {
+	return ps.pilot
+}
+
 // SyncStatus is the synchronization status between Pilot and a given Envoy
 type SyncStatus struct {
 	ProxyID       string `json:"proxy,omitempty"`
@@ -313,3 +323,11 @@ func (imc IstioMeshConfig) GetEnableAutoMtls() bool {
 	}
 	return *imc.EnableAutoMtls
 }
+
+// GetRootNamespace returns the root namespace of the mesh, the one of the Kiali config when not set in the mesh config
+func (imc IstioMeshConfig) GetRootNamespace() string {
+	if imc.RootNamespace == "" {
+		return config.Get().ExternalServices.Istio.RootNamespace
+	}
+	return imc.RootNamespace
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	ApiKeyScopeRead  = "read"
+	ApiKeyScopeWrite = "write"
+)
+
+// ApiKey is a key issued by Kiali to an automation client. Requests made with the key use the privileges of a
+// ServiceAccount, restricted to the namespaces and the scope of the key.
+//
+// swagger:model ApiKey
+type ApiKey struct {
+	// required: true
+	ID string `json:"id"`
+	// Description of the client using the key
+	//
+	// required: true
+	Name string `json:"name"`
+	// Namespaces the key can access
+	//
+	// required: true
+	Namespaces []string `json:"namespaces"`
+	// read or write
+	//
+	// required: true
+	// example: read
+	Scope string `json:"scope"`
+	// ServiceAccount impersonated for the calls to the Kubernetes API
+	//
+	// required: true
+	ServiceAccount ApiKeyServiceAccount `json:"serviceAccount"`
+	// required: true
+	CreatedAt time.Time `json:"createdAt"`
+	// Subject of the user that created the key
+	CreatedBy string `json:"createdBy,omitempty"`
+	// required: true
+	ExpiresAt time.Time `json:"expiresAt"`
+	// Last time the key was used, empty when it was never used
+	LastUsed *time.Time `json:"lastUsed,omitempty"`
+}
+
+// ApiKeyServiceAccount identifies the ServiceAccount of an API key
+type ApiKeyServiceAccount struct {
+	// required: true
+	Namespace string `json:"namespace"`
+	// required: true
+	Name string `json:"name"`
+}
+
+// ApiKeyRequest describes the key to create
+//
+// swagger:model ApiKeyRequest
+type ApiKeyRequest struct {
+	// required: true
+	Name string `json:"name"`
+	// required: true
+	Namespaces []string `json:"namespaces"`
+	// read or write
+	//
+	// required: true
+	Scope string `json:"scope"`
+	// required: true
+	ServiceAccount ApiKeyServiceAccount `json:"serviceAccount"`
+	// Expiration of the key, the longest expiration allowed when empty
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+// ApiKeyCreated is the key just created, with its secret value
+//
+// swagger:model ApiKeyCreated
+type ApiKeyCreated struct {
+	ApiKey
+	// Value of the key, to send as a bearer token. It can't be read again.
+	//
+	// required: true
+	Key string `json:"key"`
+}
+
+// Username is the user of the requests made with the key, for audit and authorization purposes
+func (k ApiKey) Username() string {
+	return "apikey:" + k.Name
+}
+
+// ServiceAccountUser is the Kubernetes user of the ServiceAccount of the key
+func (k ApiKey) ServiceAccountUser() string {
+	return "system:serviceaccount:" + k.ServiceAccount.Namespace + ":" + k.ServiceAccount.Name
+}
+
+// AllowsNamespace returns true when the key can access the namespace
+func (k ApiKey) AllowsNamespace(namespace string) bool {
+	for _, ns := range k.Namespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
+}
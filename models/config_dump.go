@@ -14,6 +14,9 @@ type EnvoyProxyDump struct {
 	Clusters   *Clusters              `json:"clusters,omitempty"`
 	Listeners  *Listeners             `json:"listeners,omitempty"`
 	Routes     *Routes                `json:"routes,omitempty"`
+	Secrets    *Secrets               `json:"secrets,omitempty"`
+	Endpoints  *ClusterEndpoints      `json:"endpoints,omitempty"`
+	Ecds       *ExtensionConfigs      `json:"ecds,omitempty"`
 }
 
 type Listeners []*Listener
@@ -42,6 +45,28 @@ type Route struct {
 	VirtualService string          `json:"virtual_service"`
 }
 
+type ClusterEndpoints []*ClusterEndpoint
+type ClusterEndpoint struct {
+	Cluster     string          `json:"cluster"`
+	ServiceFQDN kubernetes.Host `json:"service_fqdn"`
+	Subset      string          `json:"subset"`
+	Address     string          `json:"address"`
+	Port        int             `json:"port"`
+	Health      string          `json:"health"`
+	Weight      int             `json:"weight"`
+	Priority    int             `json:"priority"`
+	Locality    string          `json:"locality"`
+}
+
+type ExtensionConfigs []*ExtensionConfig
+type ExtensionConfig struct {
+	Name        string                 `json:"name"`
+	Type        string                 `json:"type"`
+	VersionInfo string                 `json:"version_info"`
+	LastUpdated string                 `json:"last_updated"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+}
+
 type Bootstrap struct {
 	Bootstrap map[string]interface{} `json:"bootstrap,inline"`
 }
@@ -323,6 +348,63 @@ func (bd *Bootstrap) Parse(dump *kubernetes.ConfigDump) error {
 	return nil
 }
 
+func (es *ClusterEndpoints) Parse(status *kubernetes.ClustersStatus) {
+	for _, cluster := range status.ClusterStatuses {
+		cs := &Cluster{}
+		cs.Parse(kubernetes.EnvoyCluster{Name: cluster.Name})
+
+		for _, host := range cluster.HostStatuses {
+			locality := []string{}
+			for _, l := range []string{host.Locality.Region, host.Locality.Zone, host.Locality.SubZone} {
+				if l != "" {
+					locality = append(locality, l)
+				}
+			}
+			*es = append(*es, &ClusterEndpoint{
+				Cluster:     cluster.Name,
+				ServiceFQDN: cs.ServiceFQDN,
+				Subset:      cs.Subset,
+				Address:     host.Address.SocketAddress.Address,
+				Port:        int(host.Address.SocketAddress.PortValue),
+				Health:      endpointHealth(host.HealthStatus),
+				Weight:      host.Weight,
+				Priority:    host.Priority,
+				Locality:    strings.Join(locality, "/"),
+			})
+		}
+	}
+}
+
+// endpointHealth returns the health reported by EDS, unless the proxy itself ejected the endpoint
+func endpointHealth(status kubernetes.HostHealthStatus) string {
+	if status.FailedOutlierCheck || status.FailedActiveHealthCheck {
+		return "UNHEALTHY"
+	}
+	if status.EdsHealthStatus == "" {
+		return "UNKNOWN"
+	}
+	return status.EdsHealthStatus
+}
+
+func (ecs *ExtensionConfigs) Parse(dump *kubernetes.ConfigDump) error {
+	ecdsDump, err := dump.GetEcds()
+	if err != nil {
+		return err
+	}
+
+	for _, filter := range ecdsDump.EcdsFilters {
+		configType, _ := filter.EcdsFilter.TypedConfig["@type"].(string)
+		*ecs = append(*ecs, &ExtensionConfig{
+			Name:        filter.EcdsFilter.Name,
+			Type:        configType,
+			VersionInfo: filter.VersionInfo,
+			LastUpdated: filter.LastUpdated,
+			Config:      filter.EcdsFilter.TypedConfig,
+		})
+	}
+	return nil
+}
+
 func matchSummary(match map[string]interface{}) string {
 	conds := []string{}
 	if prefixRaw, found := match["prefix"]; found && prefixRaw.(string) != "" {
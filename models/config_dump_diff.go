@@ -0,0 +1,184 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+const (
+	ConfigResourceAdded   = "added"
+	ConfigResourceRemoved = "removed"
+	ConfigResourceChanged = "changed"
+)
+
+// EnvoyConfigDiff lists the listeners, routes and clusters that differ from one proxy config dump to another
+type EnvoyConfigDiff struct {
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Listeners []ConfigResourceDiff `json:"listeners"`
+	Routes    []ConfigResourceDiff `json:"routes"`
+	Clusters  []ConfigResourceDiff `json:"clusters"`
+}
+
+type ConfigResourceDiff struct {
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Fields []ConfigFieldDiff `json:"fields,omitempty"`
+}
+
+type ConfigFieldDiff struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ConfigDumpSnapshot identifies a config dump kept to be compared later with the live config of the pod
+type ConfigDumpSnapshot struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Fields changing on every push, regardless of the content of the resource
+var diffIgnoredFields = map[string]bool{
+	"version_info": true,
+	"last_updated": true,
+}
+
+// The sections of each dump holding named resources, with the key of the resource in their entries
+var (
+	listenerSections = map[string][]string{
+		"static_listeners":  {"listener"},
+		"dynamic_listeners": {"active_state", "listener"},
+	}
+	routeSections = map[string][]string{
+		"static_route_configs":  {"route_config"},
+		"dynamic_route_configs": {"route_config"},
+	}
+	clusterSections = map[string][]string{
+		"static_clusters":         {"cluster"},
+		"dynamic_active_clusters": {"cluster"},
+	}
+)
+
+// ConfigDumpResources are the named listeners, routes and clusters of a config dump, the only parts of it the diff needs
+type ConfigDumpResources struct {
+	Listeners map[string]interface{}
+	Routes    map[string]interface{}
+	Clusters  map[string]interface{}
+}
+
+func NewConfigDumpResources(dump *kubernetes.ConfigDump) *ConfigDumpResources {
+	return &ConfigDumpResources{
+		Listeners: namedResources(dump, "type.googleapis.com/envoy.admin.v3.ListenersConfigDump", listenerSections),
+		Routes:    namedResources(dump, "type.googleapis.com/envoy.admin.v3.RoutesConfigDump", routeSections),
+		Clusters:  namedResources(dump, "type.googleapis.com/envoy.admin.v3.ClustersConfigDump", clusterSections),
+	}
+}
+
+// DiffConfigDumps compares the listeners, routes and clusters of two config dumps, keyed by name
+func DiffConfigDumps(from, to *kubernetes.ConfigDump) *EnvoyConfigDiff {
+	return DiffConfigDumpResources(NewConfigDumpResources(from), NewConfigDumpResources(to))
+}
+
+// DiffConfigDumpResources compares the resources of two config dumps, keyed by name
+func DiffConfigDumpResources(from, to *ConfigDumpResources) *EnvoyConfigDiff {
+	return &EnvoyConfigDiff{
+		Listeners: diffResources(from.Listeners, to.Listeners),
+		Routes:    diffResources(from.Routes, to.Routes),
+		Clusters:  diffResources(from.Clusters, to.Clusters),
+	}
+}
+
+func namedResources(dump *kubernetes.ConfigDump, configType string, sections map[string][]string) map[string]interface{} {
+	resources := map[string]interface{}{}
+	config := dump.GetConfig(configType)
+	for section, keys := range sections {
+		entries, _ := config[section].([]interface{})
+		for _, entry := range entries {
+			resource := entry
+			for _, key := range keys {
+				m, _ := resource.(map[string]interface{})
+				resource = m[key]
+			}
+			if m, ok := resource.(map[string]interface{}); ok {
+				if name, ok := m["name"].(string); ok {
+					resources[name] = m
+				}
+			}
+		}
+	}
+	return resources
+}
+
+func diffResources(from, to map[string]interface{}) []ConfigResourceDiff {
+	diffs := []ConfigResourceDiff{}
+	for name, resource := range from {
+		other, found := to[name]
+		if !found {
+			diffs = append(diffs, ConfigResourceDiff{Name: name, Status: ConfigResourceRemoved})
+			continue
+		}
+		fields := []ConfigFieldDiff{}
+		diffValues("", resource, other, &fields)
+		if len(fields) > 0 {
+			diffs = append(diffs, ConfigResourceDiff{Name: name, Status: ConfigResourceChanged, Fields: fields})
+		}
+	}
+	for name := range to {
+		if _, found := from[name]; !found {
+			diffs = append(diffs, ConfigResourceDiff{Name: name, Status: ConfigResourceAdded})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
+
+// diffValues walks both values and reports the paths where they differ. Lists of different lengths are
+// reported as a whole, since their items can't be matched reliably.
+func diffValues(path string, from, to interface{}, diffs *[]ConfigFieldDiff) {
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := map[string]bool{}
+		for k := range fromMap {
+			keys[k] = true
+		}
+		for k := range toMap {
+			keys[k] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			if !diffIgnoredFields[k] {
+				sorted = append(sorted, k)
+			}
+		}
+		sort.Strings(sorted)
+		for _, k := range sorted {
+			subPath := k
+			if path != "" {
+				subPath = path + "." + k
+			}
+			diffValues(subPath, fromMap[k], toMap[k], diffs)
+		}
+		return
+	}
+
+	fromList, fromIsList := from.([]interface{})
+	toList, toIsList := to.([]interface{})
+	if fromIsList && toIsList && len(fromList) == len(toList) {
+		for i := range fromList {
+			diffValues(fmt.Sprintf("%s[%d]", path, i), fromList[i], toList[i], diffs)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*diffs = append(*diffs, ConfigFieldDiff{Path: path, From: from, To: to})
+	}
+}
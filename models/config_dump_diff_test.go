@@ -0,0 +1,74 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func fakeConfigDump(t *testing.T, raw string) *kubernetes.ConfigDump {
+	dump := &kubernetes.ConfigDump{}
+	assert.NoError(t, json.Unmarshal([]byte(raw), dump))
+	return dump
+}
+
+func TestDiffConfigDumps(t *testing.T) {
+	assert := assert.New(t)
+
+	from := fakeConfigDump(t, `{"configs": [
+		{"@type": "type.googleapis.com/envoy.admin.v3.ClustersConfigDump", "dynamic_active_clusters": [
+			{"version_info": "1", "last_updated": "2021-01-01T00:00:00Z", "cluster": {"name": "outbound|9080||reviews", "connect_timeout": "10s"}},
+			{"version_info": "1", "cluster": {"name": "outbound|9080||ratings", "connect_timeout": "10s"}}
+		]},
+		{"@type": "type.googleapis.com/envoy.admin.v3.ListenersConfigDump", "dynamic_listeners": [
+			{"name": "0.0.0.0_9080", "active_state": {"version_info": "1", "listener": {"name": "0.0.0.0_9080", "filter_chains": [{"filters": [{"name": "http"}]}]}}}
+		]},
+		{"@type": "type.googleapis.com/envoy.admin.v3.RoutesConfigDump", "dynamic_route_configs": [
+			{"version_info": "1", "route_config": {"name": "9080", "virtual_hosts": [{"name": "reviews", "routes": [{"match": {"prefix": "/"}}]}]}}
+		]}
+	]}`)
+	to := fakeConfigDump(t, `{"configs": [
+		{"@type": "type.googleapis.com/envoy.admin.v3.ClustersConfigDump", "dynamic_active_clusters": [
+			{"version_info": "2", "last_updated": "2021-01-02T00:00:00Z", "cluster": {"name": "outbound|9080||reviews", "connect_timeout": "10s"}},
+			{"version_info": "2", "cluster": {"name": "outbound|9080|v2|reviews", "connect_timeout": "10s"}}
+		]},
+		{"@type": "type.googleapis.com/envoy.admin.v3.ListenersConfigDump", "dynamic_listeners": [
+			{"name": "0.0.0.0_9080", "active_state": {"version_info": "2", "listener": {"name": "0.0.0.0_9080", "filter_chains": [{"filters": [{"name": "tcp"}]}]}}}
+		]},
+		{"@type": "type.googleapis.com/envoy.admin.v3.RoutesConfigDump", "dynamic_route_configs": [
+			{"version_info": "2", "route_config": {"name": "9080", "virtual_hosts": [{"name": "reviews", "routes": [{"match": {"prefix": "/"}}, {"match": {"prefix": "/v2"}}]}]}}
+		]}
+	]}`)
+
+	diff := DiffConfigDumps(from, to)
+
+	// Version and update time don't make a difference
+	assert.Equal([]ConfigResourceDiff{
+		{Name: "outbound|9080|v2|reviews", Status: ConfigResourceAdded},
+		{Name: "outbound|9080||ratings", Status: ConfigResourceRemoved},
+	}, diff.Clusters)
+
+	assert.Equal([]ConfigResourceDiff{{
+		Name:   "0.0.0.0_9080",
+		Status: ConfigResourceChanged,
+		Fields: []ConfigFieldDiff{{Path: "filter_chains[0].filters[0].name", From: "http", To: "tcp"}},
+	}}, diff.Listeners)
+
+	// Lists of different lengths are reported as a whole
+	assert.Len(diff.Routes, 1)
+	assert.Equal("virtual_hosts[0].routes", diff.Routes[0].Fields[0].Path)
+}
+
+func TestDiffConfigDumpsIdentical(t *testing.T) {
+	dump := fakeConfigDump(t, `{"configs": [
+		{"@type": "type.googleapis.com/envoy.admin.v3.ClustersConfigDump", "static_clusters": [{"cluster": {"name": "prometheus_stats"}}]}
+	]}`)
+
+	diff := DiffConfigDumps(dump, dump)
+	assert.Empty(t, diff.Clusters)
+	assert.Empty(t, diff.Listeners)
+	assert.Empty(t, diff.Routes)
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+type Secrets []*Secret
+type Secret struct {
+	Name              string             `json:"name"`
+	State             string             `json:"state"`
+	VersionInfo       string             `json:"version_info"`
+	LastUpdated       string             `json:"last_updated"`
+	CertificateChain  []*Certificate     `json:"certificate_chain,omitempty"`
+	ValidationContext *ValidationContext `json:"validation_context,omitempty"`
+}
+
+// Certificate summarizes a x509 certificate sent to the proxy
+type Certificate struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	URIs         []string  `json:"uris,omitempty"`
+	IsCA         bool      `json:"is_ca"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+}
+
+type ValidationContext struct {
+	TrustedCA            []*Certificate `json:"trusted_ca,omitempty"`
+	MatchSubjectAltNames []string       `json:"match_subject_alt_names,omitempty"`
+}
+
+// Parse summarizes the secrets of the proxy. Private keys are never part of the summary.
+func (ss *Secrets) Parse(dump *kubernetes.ConfigDump) error {
+	secretDump, err := dump.GetSecrets()
+	if err != nil {
+		return err
+	}
+
+	states := []struct {
+		state   string
+		secrets []kubernetes.EnvoySecretWrapper
+	}{
+		{"ACTIVE", secretDump.DynamicActiveSecrets},
+		{"WARMING", secretDump.DynamicWarmingSecrets},
+		{"STATIC", secretDump.StaticSecrets},
+	}
+	for _, s := range states {
+		for _, secret := range s.secrets {
+			sc := &Secret{State: s.state}
+			if err := sc.Parse(secret); err != nil {
+				return err
+			}
+			*ss = append(*ss, sc)
+		}
+	}
+	return nil
+}
+
+func (sc *Secret) Parse(wrapper kubernetes.EnvoySecretWrapper) error {
+	secret := wrapper.Secret
+	sc.Name = wrapper.Name
+	if sc.Name == "" {
+		sc.Name = secret.Name
+	}
+	sc.VersionInfo = wrapper.VersionInfo
+	sc.LastUpdated = wrapper.LastUpdated
+
+	var err error
+	if secret.TLSCertificate != nil {
+		if sc.CertificateChain, err = parseCertificates(secret.TLSCertificate.CertificateChain); err != nil {
+			return fmt.Errorf("invalid certificate chain in secret %s: %v", sc.Name, err)
+		}
+	}
+	if vc := secret.ValidationContext; vc != nil {
+		sc.ValidationContext = &ValidationContext{}
+		if sc.ValidationContext.TrustedCA, err = parseCertificates(vc.TrustedCA); err != nil {
+			return fmt.Errorf("invalid trusted CA in secret %s: %v", sc.Name, err)
+		}
+		for _, matcher := range vc.MatchSubjectAltNames {
+			sc.ValidationContext.MatchSubjectAltNames = append(sc.ValidationContext.MatchSubjectAltNames, stringMatcherSummary(matcher))
+		}
+	}
+	return nil
+}
+
+// parseCertificates decodes the PEM certificates of an inline data source. Certificates read from files
+// by the proxy aren't part of the dump.
+func parseCertificates(source kubernetes.EnvoyDataSource) ([]*Certificate, error) {
+	data := []byte(source.InlineString)
+	if source.InlineBytes != "" {
+		decoded, err := base64.StdEncoding.DecodeString(source.InlineBytes)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+
+	certs := []*Certificate{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		uris := make([]string, 0, len(cert.URIs))
+		for _, uri := range cert.URIs {
+			uris = append(uris, uri.String())
+		}
+		certs = append(certs, &Certificate{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SerialNumber: cert.SerialNumber.String(),
+			DNSNames:     cert.DNSNames,
+			URIs:         uris,
+			IsCA:         cert.IsCA,
+			NotBefore:    cert.NotBefore,
+			NotAfter:     cert.NotAfter,
+		})
+	}
+	return certs, nil
+}
+
+// stringMatcherSummary renders an Envoy StringMatcher, i.e. {"exact": "spiffe://cluster.local/ns/default/sa/foo"}
+func stringMatcherSummary(matcher map[string]interface{}) string {
+	keys := make([]string, 0, len(matcher))
+	for k := range matcher {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "ignore_case" {
+			continue
+		}
+		if k == "safe_regex" {
+			if regex, ok := matcher[k].(map[string]interface{}); ok {
+				return fmt.Sprintf("regex %v", regex["regex"])
+			}
+		}
+		return fmt.Sprintf("%s %v", k, matcher[k])
+	}
+	return ""
+}
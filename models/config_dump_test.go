@@ -0,0 +1,180 @@
+package models
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func fakeCertificatePEM(t *testing.T, cn string, isCA bool, spiffe string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(42),
+		Subject:               pkix.Name{Organization: []string{cn}},
+		NotBefore:             time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+		NotAfter:              time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	if spiffe != "" {
+		uri, _ := url.Parse(spiffe)
+		template.URIs = []*url.URL{uri}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestSecretsParse(t *testing.T) {
+	assert := assert.New(t)
+
+	workloadCert := fakeCertificatePEM(t, "workload", false, "spiffe://cluster.local/ns/bookinfo/sa/reviews")
+	rootCert := fakeCertificatePEM(t, "root", true, "")
+	dump := &kubernetes.ConfigDump{Configs: []interface{}{
+		map[string]interface{}{
+			"@type": "type.googleapis.com/envoy.admin.v3.SecretsConfigDump",
+			"dynamic_active_secrets": []interface{}{
+				map[string]interface{}{
+					"name":         "default",
+					"version_info": "2021-01-01T00:00:00Z",
+					"last_updated": "2021-01-01T00:00:01Z",
+					"secret": map[string]interface{}{
+						"name": "default",
+						"tls_certificate": map[string]interface{}{
+							"certificate_chain": map[string]interface{}{"inline_bytes": base64.StdEncoding.EncodeToString(workloadCert)},
+							"private_key":       map[string]interface{}{"inline_bytes": "W3JlZGFjdGVkXQ=="},
+						},
+					},
+				},
+				map[string]interface{}{
+					"name": "ROOTCA",
+					"secret": map[string]interface{}{
+						"name": "ROOTCA",
+						"validation_context": map[string]interface{}{
+							"trusted_ca":              map[string]interface{}{"inline_string": string(rootCert)},
+							"match_subject_alt_names": []interface{}{map[string]interface{}{"exact": "spiffe://cluster.local/ns/bookinfo/sa/ratings"}},
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	secrets := &Secrets{}
+	assert.NoError(secrets.Parse(dump))
+	assert.Len(*secrets, 2)
+
+	sc := (*secrets)[0]
+	assert.Equal("default", sc.Name)
+	assert.Equal("ACTIVE", sc.State)
+	assert.Equal("2021-01-01T00:00:01Z", sc.LastUpdated)
+	assert.Len(sc.CertificateChain, 1)
+	assert.Equal("O=workload", sc.CertificateChain[0].Subject)
+	assert.Equal([]string{"spiffe://cluster.local/ns/bookinfo/sa/reviews"}, sc.CertificateChain[0].URIs)
+	assert.Equal(time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), sc.CertificateChain[0].NotAfter)
+	assert.False(sc.CertificateChain[0].IsCA)
+	assert.Nil(sc.ValidationContext)
+
+	root := (*secrets)[1]
+	assert.Empty(root.CertificateChain)
+	assert.Len(root.ValidationContext.TrustedCA, 1)
+	assert.True(root.ValidationContext.TrustedCA[0].IsCA)
+	assert.Equal([]string{"exact spiffe://cluster.local/ns/bookinfo/sa/ratings"}, root.ValidationContext.MatchSubjectAltNames)
+
+	// The private key is never sent back
+	out, _ := json.Marshal(secrets)
+	assert.NotContains(string(out), "W3JlZGFjdGVkXQ")
+}
+
+func TestSecretsParseInvalidCertificate(t *testing.T) {
+	dump := &kubernetes.ConfigDump{Configs: []interface{}{
+		map[string]interface{}{
+			"@type": "type.googleapis.com/envoy.admin.v3.SecretsConfigDump",
+			"static_secrets": []interface{}{
+				map[string]interface{}{
+					"name": "default",
+					"secret": map[string]interface{}{"tls_certificate": map[string]interface{}{
+						"certificate_chain": map[string]interface{}{"inline_string": "-----BEGIN CERTIFICATE-----\nZm9v\n-----END CERTIFICATE-----\n"},
+					}},
+				},
+			},
+		},
+	}}
+
+	secrets := &Secrets{}
+	assert.Error(t, secrets.Parse(dump))
+}
+
+func TestClusterEndpointsParse(t *testing.T) {
+	assert := assert.New(t)
+
+	status := &kubernetes.ClustersStatus{}
+	assert.NoError(json.Unmarshal([]byte(`{"cluster_statuses": [{
+		"name": "outbound|9080|v2|reviews.bookinfo.svc.cluster.local",
+		"added_via_api": true,
+		"host_statuses": [
+			{"address": {"socket_address": {"address": "10.0.0.1", "port_value": 9080}}, "health_status": {"eds_health_status": "HEALTHY"}, "weight": 3, "locality": {"region": "us", "zone": "us-1"}},
+			{"address": {"socket_address": {"address": "10.0.0.2", "port_value": 9080}}, "health_status": {"eds_health_status": "HEALTHY", "failed_outlier_check": true}, "weight": 1, "priority": 1}
+		]
+	}, {"name": "BlackHoleCluster"}]}`), status))
+
+	endpoints := &ClusterEndpoints{}
+	endpoints.Parse(status)
+	assert.Len(*endpoints, 2)
+
+	ep := (*endpoints)[0]
+	assert.Equal("outbound|9080|v2|reviews.bookinfo.svc.cluster.local", ep.Cluster)
+	assert.Equal("reviews.bookinfo.svc.cluster.local", ep.ServiceFQDN.Service)
+	assert.Equal("v2", ep.Subset)
+	assert.Equal("10.0.0.1", ep.Address)
+	assert.Equal(9080, ep.Port)
+	assert.Equal("HEALTHY", ep.Health)
+	assert.Equal(3, ep.Weight)
+	assert.Equal("us/us-1", ep.Locality)
+
+	assert.Equal("UNHEALTHY", (*endpoints)[1].Health)
+	assert.Equal(1, (*endpoints)[1].Priority)
+}
+
+func TestExtensionConfigsParse(t *testing.T) {
+	assert := assert.New(t)
+
+	dump := &kubernetes.ConfigDump{Configs: []interface{}{
+		map[string]interface{}{
+			"@type": "type.googleapis.com/envoy.admin.v3.EcdsConfigDump",
+			"ecds_filters": []interface{}{
+				map[string]interface{}{
+					"version_info": "1",
+					"last_updated": "2021-01-01T00:00:00Z",
+					"ecds_filter": map[string]interface{}{
+						"name": "bookinfo.stats-filter",
+						"typed_config": map[string]interface{}{
+							"@type": "type.googleapis.com/envoy.extensions.filters.http.wasm.v3.Wasm",
+						},
+					},
+				},
+			},
+		},
+	}}
+
+	ecds := &ExtensionConfigs{}
+	assert.NoError(ecds.Parse(dump))
+	assert.Len(*ecds, 1)
+	assert.Equal("bookinfo.stats-filter", (*ecds)[0].Name)
+	assert.Equal("type.googleapis.com/envoy.extensions.filters.http.wasm.v3.Wasm", (*ecds)[0].Type)
+	assert.Equal("1", (*ecds)[0].VersionInfo)
+}
@@ -53,6 +53,35 @@ type IstioConfigDetails struct {
 	IstioValidation *IstioValidation    `json:"validation"`
 }
 
+// GetObject returns the Istio object of the details, or nil when there isn't any
+func (icd IstioConfigDetails) GetObject() interface{} {
+	switch {
+	case icd.DestinationRule != nil:
+		return icd.DestinationRule
+	case icd.EnvoyFilter != nil:
+		return icd.EnvoyFilter
+	case icd.Gateway != nil:
+		return icd.Gateway
+	case icd.ServiceEntry != nil:
+		return icd.ServiceEntry
+	case icd.Sidecar != nil:
+		return icd.Sidecar
+	case icd.VirtualService != nil:
+		return icd.VirtualService
+	case icd.WorkloadEntry != nil:
+		return icd.WorkloadEntry
+	case icd.WorkloadGroup != nil:
+		return icd.WorkloadGroup
+	case icd.AuthorizationPolicy != nil:
+		return icd.AuthorizationPolicy
+	case icd.PeerAuthentication != nil:
+		return icd.PeerAuthentication
+	case icd.RequestAuthentication != nil:
+		return icd.RequestAuthentication
+	}
+	return nil
+}
+
 // ResourcePermissions holds permission flags for an object type
 // True means allowed.
 type ResourcePermissions struct {
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	IstioConfigCreated  = "create"
+	IstioConfigDeleted  = "delete"
+	IstioConfigObserved = "observed"
+	IstioConfigRestored = "restore"
+	IstioConfigUpdated  = "update"
+)
+
+// IstioConfigHistory lists the revisions of an Istio object modified through Kiali
+//
+// swagger:model IstioConfigHistory
+type IstioConfigHistory struct {
+	Namespace  string `json:"namespace"`
+	ObjectType string `json:"objectType"`
+	Name       string `json:"name"`
+	// Revisions of the object, the oldest first
+	Revisions []IstioConfigRevision `json:"revisions"`
+}
+
+// IstioConfigRevision is the state of an Istio object after an operation
+type IstioConfigRevision struct {
+	// Increasing number of the revision, unique for the object
+	//
+	// required: true
+	Revision int `json:"revision"`
+	// required: true
+	Timestamp time.Time `json:"timestamp"`
+	// Operation that produced the revision: create, update, delete, restore, or observed for a state of the
+	// object found before a change made through Kiali, i.e. the original object or a change made outside Kiali
+	//
+	// required: true
+	Operation string `json:"operation"`
+	// Subject of the user that made the operation
+	User string `json:"user,omitempty"`
+	// The revision restored by a restore operation
+	RestoredFrom int `json:"restoredFrom,omitempty"`
+	// The object, without its status and server generated metadata. Empty when the object was deleted.
+	Object json.RawMessage `json:"object,omitempty"`
+}
+
+// IstioConfigRevisionDiff lists the fields that differ from a revision of an Istio object to another
+//
+// swagger:model IstioConfigRevisionDiff
+type IstioConfigRevisionDiff struct {
+	From   int               `json:"from"`
+	To     int               `json:"to"`
+	Fields []ConfigFieldDiff `json:"fields"`
+}
+
+// GetRevision returns a revision of the history, or nil when it isn't kept
+func (h *IstioConfigHistory) GetRevision(revision int) *IstioConfigRevision {
+	for i := range h.Revisions {
+		if h.Revisions[i].Revision == revision {
+			return &h.Revisions[i]
+		}
+	}
+	return nil
+}
+
+// LastRevision returns the latest revision of the history, or nil when it's empty
+func (h *IstioConfigHistory) LastRevision() *IstioConfigRevision {
+	if len(h.Revisions) == 0 {
+		return nil
+	}
+	return &h.Revisions[len(h.Revisions)-1]
+}
+
+// DiffIstioConfigRevisions compares the objects of two revisions. A deleted object compares as empty.
+func DiffIstioConfigRevisions(from, to IstioConfigRevision) (*IstioConfigRevisionDiff, error) {
+	var fromObject, toObject interface{}
+	if len(from.Object) > 0 {
+		if err := json.Unmarshal(from.Object, &fromObject); err != nil {
+			return nil, err
+		}
+	}
+	if len(to.Object) > 0 {
+		if err := json.Unmarshal(to.Object, &toObject); err != nil {
+			return nil, err
+		}
+	}
+	diff := &IstioConfigRevisionDiff{From: from.Revision, To: to.Revision, Fields: []ConfigFieldDiff{}}
+	diffValues("", fromObject, toObject, &diff.Fields)
+	return diff, nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import "time"
+
+// Status of a canary upgrade migration and of its batches
+const (
+	MigrationPending    = "Pending"
+	MigrationRunning    = "Running"
+	MigrationCompleted  = "Completed"
+	MigrationFailed     = "Failed"
+	MigrationRolledBack = "RolledBack"
+)
+
+// IstioUpgradeStatus shows how far the mesh is in the canary upgrade from the current to the upgrade revision
+type IstioUpgradeStatus struct {
+	Current         string              `json:"current"`
+	Upgrade         string              `json:"upgrade"`
+	Revisions       []RevisionWorkloads `json:"revisions"`
+	OutdatedProxies []OutdatedProxy     `json:"outdatedProxies"`
+}
+
+// RevisionWorkloads lists the namespaces injected by a revision and their workloads
+type RevisionWorkloads struct {
+	Revision   string             `json:"revision"`
+	Namespaces []string           `json:"namespaces"`
+	Workloads  []RevisionWorkload `json:"workloads"`
+}
+
+type RevisionWorkload struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+}
+
+// OutdatedProxy is a proxy injected by, or connected to, another revision than the one of its namespace.
+// It moves to the namespace revision once its workload is restarted.
+type OutdatedProxy struct {
+	Namespace        string `json:"namespace"`
+	Workload         string `json:"workload"`
+	Pod              string `json:"pod"`
+	Revision         string `json:"revision"`
+	ExpectedRevision string `json:"expectedRevision"`
+	Istiod           string `json:"istiod,omitempty"`
+}
+
+// UpgradePreCheck tells whether namespaces can be migrated to the upgrade revision
+type UpgradePreCheck struct {
+	Passed bool `json:"passed"`
+	// Healthy istiod instances of the upgrade revision
+	Istiods []string `json:"istiods"`
+	// Number of validation errors by namespace
+	ValidationErrors map[string]int `json:"validationErrors"`
+	Messages         []string       `json:"messages"`
+}
+
+// IstioUpgradeMigration moves namespaces from a revision to another, in batches
+type IstioUpgradeMigration struct {
+	ID               string           `json:"id"`
+	From             string           `json:"from"`
+	To               string           `json:"to"`
+	RestartWorkloads bool             `json:"restartWorkloads"`
+	Status           string           `json:"status"`
+	Created          time.Time        `json:"created"`
+	PreCheck         UpgradePreCheck  `json:"preCheck"`
+	Batches          []MigrationBatch `json:"batches"`
+	Error            string           `json:"error,omitempty"`
+}
+
+type MigrationBatch struct {
+	Namespaces []string   `json:"namespaces"`
+	Status     string     `json:"status"`
+	Started    *time.Time `json:"started,omitempty"`
+	Completed  *time.Time `json:"completed,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// NamespaceRevision returns the revision injecting the sidecars of a namespace: the one of its revision label,
+// "default" with the injection label enabled, or an empty string when the namespace isn't injected
+func NamespaceRevision(ns Namespace, injectionLabel, revisionLabel string) string {
+	if revision, ok := ns.Labels[revisionLabel]; ok {
+		return revision
+	}
+	if ns.Labels[injectionLabel] == "enabled" {
+		return "default"
+	}
+	return ""
+}
+
+// PodRevision returns the revision which injected the sidecar of a pod
+func PodRevision(pod *Pod, revisionLabel string) string {
+	if revision, ok := pod.Labels[revisionLabel]; ok {
+		return revision
+	}
+	return "default"
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceRevision(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("canary", NamespaceRevision(Namespace{Labels: map[string]string{"istio.io/rev": "canary"}}, "istio-injection", "istio.io/rev"))
+	assert.Equal("default", NamespaceRevision(Namespace{Labels: map[string]string{"istio-injection": "enabled"}}, "istio-injection", "istio.io/rev"))
+	assert.Equal("", NamespaceRevision(Namespace{Labels: map[string]string{"istio-injection": "disabled"}}, "istio-injection", "istio.io/rev"))
+
+	assert.Equal("canary", PodRevision(&Pod{Labels: map[string]string{"istio.io/rev": "canary"}}, "istio.io/rev"))
+	assert.Equal("default", PodRevision(&Pod{}, "istio.io/rev"))
+}
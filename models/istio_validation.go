@@ -389,6 +389,26 @@ func (iv IstioValidations) FilterByTypes(objectTypes []string) IstioValidations
 	return fiv
 }
 
+// DeepCopy copies the validations, so the copy can be merged or stripped without affecting the original
+func (iv IstioValidations) DeepCopy() IstioValidations {
+	civ := make(IstioValidations, len(iv))
+	for k, v := range iv {
+		cv := *v
+		if v.Checks != nil {
+			cv.Checks = make([]*IstioCheck, len(v.Checks))
+			for i, check := range v.Checks {
+				cc := *check
+				cv.Checks[i] = &cc
+			}
+		}
+		if v.References != nil {
+			cv.References = append([]IstioValidationKey{}, v.References...)
+		}
+		civ[k] = &cv
+	}
+	return civ
+}
+
 func (iv IstioValidations) MergeValidations(validations IstioValidations) IstioValidations {
 	for key, validation := range validations {
 		v, ok := iv[key]
@@ -84,3 +84,28 @@ func TestSummarizeValidations(t *testing.T) {
 	assert.Equal(1, summary.Warnings)
 	assert.Equal(1, summary.Errors)
 }
+
+func TestIstioValidationsDeepCopy(t *testing.T) {
+	assert := assert.New(t)
+
+	key := IstioValidationKey{ObjectType: "virtualservice", Name: "foo", Namespace: "bookinfo"}
+	validations := IstioValidations{
+		key: &IstioValidation{
+			Name:       "foo",
+			ObjectType: "virtualservice",
+			Valid:      false,
+			Checks:     []*IstioCheck{{Code: "FOO1", Severity: ErrorSeverity, Message: "Message 1"}},
+		},
+	}
+
+	copied := validations.DeepCopy()
+	assert.Equal(validations, copied)
+
+	copied.MergeValidations(IstioValidations{
+		key: &IstioValidation{Name: "foo", ObjectType: "virtualservice", Checks: []*IstioCheck{{Code: "FOO2", Severity: WarningSeverity, Message: "Message 2"}}},
+	})
+	copied[key].Checks[0].Message = "Changed"
+	assert.Len(validations[key].Checks, 1)
+	assert.Equal("Message 1", validations[key].Checks[0].Message)
+	assert.Len(copied[key].Checks, 2)
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	pmod "github.com/prometheus/common/model"
@@ -29,6 +31,8 @@ type IstioMetricsQuery struct {
 	Aggregate       string
 	AggregateValue  string
 	Pod             string
+	// IncludeExemplars adds the exemplars of the request duration histograms, linking the series to traces
+	IncludeExemplars bool
 }
 
 // FillDefaults fills the struct with default parameters
@@ -119,6 +123,20 @@ type Metric struct {
 	Datapoints []Datapoint       `json:"datapoints"`
 	Stat       string            `json:"stat,omitempty"`
 	Name       string            `json:"name"`
+	Exemplars  []Exemplar        `json:"exemplars,omitempty"`
+}
+
+// Exemplar is a datapoint linked to the trace it was sampled from. The trace can be fetched
+// from the traces API.
+type Exemplar struct {
+	TraceID   string            `json:"traceId"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Timestamp int64             `json:"timestamp"`
+	Value     float64           `json:"value"`
+	// Bucket is the upper bound of the histogram bucket the exemplar was sampled from
+	Bucket string `json:"bucket,omitempty"`
+	// Datapoint is the timestamp of the datapoint of the series covering the exemplar, if any
+	Datapoint int64 `json:"datapoint,omitempty"`
 }
 
 type Datapoint struct {
@@ -238,3 +256,101 @@ func convertSamplePair(from *pmod.SamplePair, scale float64) Datapoint {
 		Value:     scale * float64(from.Value),
 	}
 }
+
+// exemplarTraceIDLabels are the exemplar labels that may hold a trace ID, by order of preference
+var exemplarTraceIDLabels = []pmod.LabelName{"trace_id", "traceID", "traceId"}
+
+// AttachExemplars adds the exemplars to the series whose labels they match, with the bucket they were sampled
+// from and the datapoint covering them. The stats of an histogram are series with the same labels, the exemplars
+// are only added to the first of them. Exemplars without a trace ID are ignored.
+func AttachExemplars(series []Metric, exemplars []prometheus.ExemplarQueryResult, scale float64) {
+	attached := map[string]bool{}
+	for i := range series {
+		key := labelsKey(series[i].Labels)
+		if attached[key] {
+			continue
+		}
+		attached[key] = true
+		for _, result := range exemplars {
+			if !labelsMatch(series[i].Labels, result.SeriesLabels) {
+				continue
+			}
+			bucket := scaleBucket(string(result.SeriesLabels["le"]), scale)
+			for _, e := range result.Exemplars {
+				if exemplar, ok := convertExemplar(e, scale); ok {
+					exemplar.Bucket = bucket
+					exemplar.Datapoint = coveringDatapoint(series[i].Datapoints, exemplar.Timestamp)
+					series[i].Exemplars = append(series[i].Exemplars, exemplar)
+				}
+			}
+		}
+	}
+}
+
+func labelsKey(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for k := range labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var key strings.Builder
+	for _, k := range names {
+		key.WriteString(strconv.Quote(k) + "=" + strconv.Quote(labels[k]) + ",")
+	}
+	return key.String()
+}
+
+// scaleBucket scales the upper bound of an histogram bucket like the values of the histogram
+func scaleBucket(le string, scale float64) string {
+	bound, err := strconv.ParseFloat(le, 64)
+	if err != nil || math.IsInf(bound, 0) || scale == 1 {
+		return le
+	}
+	return strconv.FormatFloat(bound*scale, 'g', -1, 64)
+}
+
+// coveringDatapoint returns the timestamp of the first datapoint at or after a timestamp: datapoints of range
+// queries aggregate the samples preceding them
+func coveringDatapoint(datapoints []Datapoint, timestamp int64) int64 {
+	i := sort.Search(len(datapoints), func(i int) bool { return datapoints[i].Timestamp >= timestamp })
+	if i == len(datapoints) {
+		return 0
+	}
+	return datapoints[i].Timestamp
+}
+
+func labelsMatch(seriesLabels map[string]string, exemplarLabels pmod.LabelSet) bool {
+	for k, v := range seriesLabels {
+		if string(exemplarLabels[pmod.LabelName(k)]) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func convertExemplar(from prometheus.Exemplar, scale float64) (Exemplar, bool) {
+	exemplar := Exemplar{
+		Timestamp: int64(from.Timestamp),
+		Value:     scale * float64(from.Value),
+	}
+	var traceIDLabel pmod.LabelName
+	for _, name := range exemplarTraceIDLabels {
+		if traceID, ok := from.Labels[name]; ok {
+			exemplar.TraceID = string(traceID)
+			traceIDLabel = name
+			break
+		}
+	}
+	if exemplar.TraceID == "" {
+		return exemplar, false
+	}
+	for k, v := range from.Labels {
+		if k != traceIDLabel {
+			if exemplar.Labels == nil {
+				exemplar.Labels = map[string]string{}
+			}
+			exemplar.Labels[string(k)] = string(v)
+		}
+	}
+	return exemplar, true
+}
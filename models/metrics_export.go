@@ -0,0 +1,183 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metrics export formats, as accepted by the "format" query parameter of the metrics endpoints
+const (
+	MetricsFormatJSON        = "json"
+	MetricsFormatCSV         = "csv"
+	MetricsFormatOpenMetrics = "openmetrics"
+	MetricsFormatParquet     = "parquet"
+)
+
+// labelValueEscaper escapes label values as required by the OpenMetrics text format
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// statColumn is the CSV column holding the stat (avg, quantile) of histogram series
+const statColumn = "stat"
+
+// labelColumnPrefix prefixes the CSV columns of the labels, so that they don't collide with the other columns
+const labelColumnPrefix = "label_"
+
+// statLabel is the OpenMetrics label holding the stat of histogram series. It's prefixed so as not to be
+// mistaken for a label of the series, series having a label of this name can't be exported.
+const statLabel = "kiali_stat"
+
+// WriteMetricsCSV writes the metrics as CSV, one row per datapoint. Columns are the metric name, the stat
+// (empty for simple metrics), the unix timestamp in seconds, the value, then one column per label found
+// in any of the series, named after the label prefixed by "label_".
+func WriteMetricsCSV(w io.Writer, metrics MetricsMap) error {
+	names := sortedMetricNames(metrics)
+	labels := sortedLabelNames(metrics)
+
+	header := []string{"metric", statColumn, "timestamp", "value"}
+	for _, l := range labels {
+		header = append(header, labelColumnPrefix+l)
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, name := range names {
+		for _, m := range sortedSeries(metrics[name]) {
+			row := make([]string, 4+len(labels))
+			row[0] = name
+			row[1] = m.Stat
+			for i, l := range labels {
+				row[4+i] = m.Labels[l]
+			}
+			for _, dp := range m.Datapoints {
+				row[2] = formatTimestamp(dp.Timestamp)
+				row[3] = strconv.FormatFloat(dp.Value, 'f', -1, 64)
+				if err := writer.Write(row); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteMetricsOpenMetrics writes the metrics in the OpenMetrics text format. Metric names are prefixed
+// with "kiali_", and histogram stats are exposed through the "kiali_stat" label. Nothing is written when
+// a series already has a "kiali_stat" label.
+func WriteMetricsOpenMetrics(w io.Writer, metrics MetricsMap) error {
+	names := sortedMetricNames(metrics)
+	for _, name := range names {
+		for _, m := range metrics[name] {
+			if _, found := m.Labels[statLabel]; found {
+				return fmt.Errorf("metric %s can't be exported as OpenMetrics, its label %s collides with the label of the stats", name, statLabel)
+			}
+		}
+	}
+
+	for _, name := range names {
+		family := "kiali_" + sanitizeMetricName(name)
+		if _, err := fmt.Fprintf(w, "# TYPE %s unknown\n", family); err != nil {
+			return err
+		}
+		for _, m := range sortedSeries(metrics[name]) {
+			labelSet := openMetricsLabels(m)
+			for _, dp := range m.Datapoints {
+				if _, err := fmt.Fprintf(w, "%s%s %s %s\n", family, labelSet, strconv.FormatFloat(dp.Value, 'g', -1, 64), formatTimestamp(dp.Timestamp)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	_, err := io.WriteString(w, "# EOF\n")
+	return err
+}
+
+func sortedMetricNames(metrics MetricsMap) []string {
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sortedLabelNames returns the names of the labels found in any of the series
+func sortedLabelNames(metrics MetricsMap) []string {
+	labelNames := map[string]bool{}
+	for _, series := range metrics {
+		for _, m := range series {
+			for k := range m.Labels {
+				labelNames[k] = true
+			}
+		}
+	}
+	labels := make([]string, 0, len(labelNames))
+	for k := range labelNames {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// sortedSeries returns the series ordered by stat then labels, for a stable output
+func sortedSeries(series []Metric) []Metric {
+	sorted := make([]Metric, len(series))
+	copy(sorted, series)
+	keys := make([]string, len(sorted))
+	for i, m := range sorted {
+		keys[i] = m.Stat + openMetricsLabels(m)
+	}
+	sort.Sort(seriesByKey{series: sorted, keys: keys})
+	return sorted
+}
+
+type seriesByKey struct {
+	series []Metric
+	keys   []string
+}
+
+func (s seriesByKey) Len() int           { return len(s.series) }
+func (s seriesByKey) Less(i, j int) bool { return s.keys[i] < s.keys[j] }
+func (s seriesByKey) Swap(i, j int) {
+	s.series[i], s.series[j] = s.series[j], s.series[i]
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+}
+
+func openMetricsLabels(m Metric) string {
+	names := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names)+1)
+	for _, k := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, k, labelValueEscaper.Replace(m.Labels[k])))
+	}
+	if m.Stat != "" {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, statLabel, labelValueEscaper.Replace(m.Stat)))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func sanitizeMetricName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// formatTimestamp converts a datapoint timestamp (milliseconds) to seconds
+func formatTimestamp(ms int64) string {
+	return strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64)
+}
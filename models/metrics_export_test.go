@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeMetricsMap() MetricsMap {
+	return MetricsMap{
+		"request_count": []Metric{
+			{
+				Name:       "request_count",
+				Labels:     map[string]string{"response_code": "500"},
+				Datapoints: []Datapoint{{Timestamp: 1000000, Value: 0.5}},
+			},
+			{
+				Name:       "request_count",
+				Labels:     map[string]string{"response_code": "200"},
+				Datapoints: []Datapoint{{Timestamp: 1000000, Value: 10}, {Timestamp: 1015000, Value: 12.25}},
+			},
+		},
+		"request_duration_millis": []Metric{
+			{
+				Name:       "request_duration_millis",
+				Stat:       "0.99",
+				Labels:     map[string]string{"source_app": "productpage"},
+				Datapoints: []Datapoint{{Timestamp: 1000500, Value: 42}},
+			},
+		},
+	}
+}
+
+func TestWriteMetricsCSV(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteMetricsCSV(&buf, fakeMetricsMap()))
+	assert.Equal(`metric,stat,timestamp,value,label_response_code,label_source_app
+request_count,,1000,10,200,
+request_count,,1015,12.25,200,
+request_count,,1000,0.5,500,
+request_duration_millis,0.99,1000.5,42,,productpage
+`, buf.String())
+}
+
+func TestWriteMetricsOpenMetrics(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteMetricsOpenMetrics(&buf, fakeMetricsMap()))
+	assert.Equal(`# TYPE kiali_request_count unknown
+kiali_request_count{response_code="200"} 10 1000
+kiali_request_count{response_code="200"} 12.25 1015
+kiali_request_count{response_code="500"} 0.5 1000
+# TYPE kiali_request_duration_millis unknown
+kiali_request_duration_millis{source_app="productpage",kiali_stat="0.99"} 42 1000.5
+# EOF
+`, buf.String())
+}
+
+func TestWriteMetricsOpenMetricsRejectsStatLabel(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := MetricsMap{
+		"request_count": []Metric{
+			{Name: "request_count", Labels: map[string]string{"kiali_stat": "x"}, Datapoints: []Datapoint{{Timestamp: 1000000, Value: 1}}},
+		},
+	}
+	var buf bytes.Buffer
+	assert.Error(WriteMetricsOpenMetrics(&buf, metrics))
+	assert.Empty(buf.String())
+}
+
+func TestWriteMetricsCSVPrefixesLabels(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := MetricsMap{
+		"request_count": []Metric{
+			{Name: "request_count", Labels: map[string]string{"value": "x"}, Datapoints: []Datapoint{{Timestamp: 1000000, Value: 1}}},
+		},
+	}
+	var buf bytes.Buffer
+	assert.NoError(WriteMetricsCSV(&buf, metrics))
+	assert.Equal("metric,stat,timestamp,value,label_value\nrequest_count,,1000,1,x\n", buf.String())
+}
+
+func TestWriteMetricsParquet(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteMetricsParquet(&buf, fakeMetricsMap()))
+	file := buf.Bytes()
+	assert.Equal(parquetMagic, string(file[:4]))
+	assert.Equal(parquetMagic, string(file[len(file)-4:]))
+
+	// The footer ends with its length, it describes the columns
+	footerSize := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footer := string(file[len(file)-8-footerSize : len(file)-8])
+	for _, column := range []string{"metric", "stat", "timestamp", "value", "label_response_code", "label_source_app"} {
+		assert.Contains(footer, column)
+	}
+
+	// The first column chunk is the metric names, PLAIN encoded in the data page
+	assert.Contains(string(file[4:len(file)-8-footerSize]), "\x0d\x00\x00\x00request_count")
+}
+
+func TestWriteMetricsEmpty(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	assert.NoError(WriteMetricsOpenMetrics(&buf, MetricsMap{}))
+	assert.Equal("# EOF\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(WriteMetricsCSV(&buf, MetricsMap{}))
+	assert.Equal("metric,stat,timestamp,value\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(WriteMetricsParquet(&buf, MetricsMap{}))
+	assert.Equal(parquetMagic, buf.String()[:4])
+}
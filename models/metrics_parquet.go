@@ -0,0 +1,318 @@
+package models
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// Parquet is written without a dependency: a single row group with a single uncompressed data page per column,
+// values being PLAIN encoded. See https://github.com/apache/parquet-format for the format.
+const parquetMagic = "PAR1"
+
+// Parquet physical types, converted types and encodings, as numbered by parquet.thrift
+const (
+	parquetTypeInt64     = 2
+	parquetTypeDouble    = 5
+	parquetTypeByteArray = 6
+
+	parquetConvertedNone            = -1
+	parquetConvertedUTF8            = 0
+	parquetConvertedTimestampMillis = 9
+
+	parquetEncodingPlain = 0
+	parquetEncodingRLE   = 3
+)
+
+// parquetColumn holds the PLAIN encoded values of a column. Optional columns also hold whether each row has a value.
+type parquetColumn struct {
+	name      string
+	kind      int32
+	converted int32
+	optional  bool
+	values    bytes.Buffer
+	defined   []bool
+	rows      int
+}
+
+func (c *parquetColumn) appendString(value string, defined bool) {
+	c.rows++
+	if c.optional {
+		c.defined = append(c.defined, defined)
+		if !defined {
+			return
+		}
+	}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(value)))
+	c.values.Write(size[:])
+	c.values.WriteString(value)
+}
+
+func (c *parquetColumn) appendUint64(value uint64) {
+	c.rows++
+	var data [8]byte
+	binary.LittleEndian.PutUint64(data[:], value)
+	c.values.Write(data[:])
+}
+
+// pageData returns the content of the data page: the definition levels of an optional column, then the values
+func (c *parquetColumn) pageData() []byte {
+	var page bytes.Buffer
+	if c.optional {
+		// Definition levels use the RLE hybrid encoding with a bit width of 1, as runs of the same level
+		var levels bytes.Buffer
+		for i := 0; i < len(c.defined); {
+			run := 1
+			for i+run < len(c.defined) && c.defined[i+run] == c.defined[i] {
+				run++
+			}
+			writeUvarint(&levels, uint64(run)<<1)
+			if c.defined[i] {
+				levels.WriteByte(1)
+			} else {
+				levels.WriteByte(0)
+			}
+			i += run
+		}
+		var size [4]byte
+		binary.LittleEndian.PutUint32(size[:], uint32(levels.Len()))
+		page.Write(size[:])
+		page.Write(levels.Bytes())
+	}
+	page.Write(c.values.Bytes())
+	return page.Bytes()
+}
+
+// WriteMetricsParquet writes the metrics as a Parquet file, with the rows and columns of WriteMetricsCSV. The
+// timestamp is a timestamp in milliseconds, the stat and the labels are null when a series doesn't have them.
+func WriteMetricsParquet(w io.Writer, metrics MetricsMap) error {
+	names := sortedMetricNames(metrics)
+	labels := sortedLabelNames(metrics)
+
+	metricColumn := &parquetColumn{name: "metric", kind: parquetTypeByteArray, converted: parquetConvertedUTF8}
+	statsColumn := &parquetColumn{name: statColumn, kind: parquetTypeByteArray, converted: parquetConvertedUTF8, optional: true}
+	timestampColumn := &parquetColumn{name: "timestamp", kind: parquetTypeInt64, converted: parquetConvertedTimestampMillis}
+	valueColumn := &parquetColumn{name: "value", kind: parquetTypeDouble, converted: parquetConvertedNone}
+	columns := []*parquetColumn{metricColumn, statsColumn, timestampColumn, valueColumn}
+	for _, l := range labels {
+		columns = append(columns, &parquetColumn{name: labelColumnPrefix + l, kind: parquetTypeByteArray, converted: parquetConvertedUTF8, optional: true})
+	}
+
+	for _, name := range names {
+		for _, m := range sortedSeries(metrics[name]) {
+			for _, dp := range m.Datapoints {
+				metricColumn.appendString(name, true)
+				statsColumn.appendString(m.Stat, m.Stat != "")
+				timestampColumn.appendUint64(uint64(dp.Timestamp))
+				valueColumn.appendUint64(math.Float64bits(dp.Value))
+				for i, l := range labels {
+					value, found := m.Labels[l]
+					columns[4+i].appendString(value, found)
+				}
+			}
+		}
+	}
+	rows := metricColumn.rows
+
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	// Each column chunk is a data page, the offsets and sizes go into the footer
+	type chunk struct {
+		offset int64
+		size   int64
+	}
+	chunks := make([]chunk, len(columns))
+	if rows > 0 {
+		for i, c := range columns {
+			data := c.pageData()
+			header := newThriftCompactWriter()
+			header.i32(1, 0) // DATA_PAGE
+			header.i32(2, int32(len(data)))
+			header.i32(3, int32(len(data)))
+			header.beginStruct(5)
+			header.i32(1, int32(rows))
+			header.i32(2, parquetEncodingPlain)
+			header.i32(3, parquetEncodingRLE)
+			header.i32(4, parquetEncodingRLE)
+			header.endStruct()
+			header.end()
+
+			chunks[i].offset = int64(file.Len())
+			file.Write(header.bytes())
+			file.Write(data)
+			chunks[i].size = int64(file.Len()) - chunks[i].offset
+		}
+	}
+
+	footer := newThriftCompactWriter()
+	footer.i32(1, 1)
+	footer.list(2, thriftStruct, len(columns)+1)
+	footer.beginListStruct()
+	footer.binary(4, "schema")
+	footer.i32(5, int32(len(columns)))
+	footer.endStruct()
+	for _, c := range columns {
+		footer.beginListStruct()
+		footer.i32(1, c.kind)
+		if c.optional {
+			footer.i32(3, 1) // OPTIONAL
+		} else {
+			footer.i32(3, 0) // REQUIRED
+		}
+		footer.binary(4, c.name)
+		if c.converted != parquetConvertedNone {
+			footer.i32(6, c.converted)
+		}
+		footer.endStruct()
+	}
+	footer.i64(3, int64(rows))
+	if rows > 0 {
+		footer.list(4, thriftStruct, 1)
+		footer.beginListStruct()
+		footer.list(1, thriftStruct, len(columns))
+		totalSize := int64(0)
+		for i, c := range columns {
+			totalSize += chunks[i].size
+			footer.beginListStruct()
+			footer.i64(2, chunks[i].offset)
+			footer.beginStruct(3)
+			footer.i32(1, c.kind)
+			if c.optional {
+				footer.list(2, thriftI32, 2)
+				footer.listI32(parquetEncodingPlain)
+				footer.listI32(parquetEncodingRLE)
+			} else {
+				footer.list(2, thriftI32, 1)
+				footer.listI32(parquetEncodingPlain)
+			}
+			footer.list(3, thriftBinary, 1)
+			footer.listBinary(c.name)
+			footer.i32(4, 0) // UNCOMPRESSED
+			footer.i64(5, int64(rows))
+			footer.i64(6, chunks[i].size)
+			footer.i64(7, chunks[i].size)
+			footer.i64(9, chunks[i].offset)
+			footer.endStruct()
+			footer.endStruct()
+		}
+		footer.i64(2, totalSize)
+		footer.i64(3, int64(rows))
+		footer.endStruct()
+	} else {
+		footer.list(4, thriftStruct, 0)
+	}
+	footer.binary(6, "kiali")
+	footer.end()
+
+	file.Write(footer.bytes())
+	var footerSize [4]byte
+	binary.LittleEndian.PutUint32(footerSize[:], uint32(len(footer.bytes())))
+	file.Write(footerSize[:])
+	file.WriteString(parquetMagic)
+
+	_, err := file.WriteTo(w)
+	return err
+}
+
+// Thrift compact protocol types, used by the Parquet headers and footer
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftCompactWriter encodes Thrift structs with the compact protocol, fields being written in increasing order
+type thriftCompactWriter struct {
+	buf     bytes.Buffer
+	lastId  int16
+	lastIds []int16
+}
+
+func newThriftCompactWriter() *thriftCompactWriter {
+	return &thriftCompactWriter{}
+}
+
+func (t *thriftCompactWriter) field(id int16, kind byte) {
+	if delta := id - t.lastId; delta > 0 && delta <= 15 {
+		t.buf.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		t.buf.WriteByte(kind)
+		writeUvarint(&t.buf, zigzag(int64(id)))
+	}
+	t.lastId = id
+}
+
+func (t *thriftCompactWriter) i32(id int16, value int32) {
+	t.field(id, thriftI32)
+	writeUvarint(&t.buf, zigzag(int64(value)))
+}
+
+func (t *thriftCompactWriter) i64(id int16, value int64) {
+	t.field(id, thriftI64)
+	writeUvarint(&t.buf, zigzag(value))
+}
+
+func (t *thriftCompactWriter) binary(id int16, value string) {
+	t.field(id, thriftBinary)
+	t.listBinary(value)
+}
+
+func (t *thriftCompactWriter) beginStruct(id int16) {
+	t.field(id, thriftStruct)
+	t.beginListStruct()
+}
+
+// beginListStruct starts a struct element of a list, which has no field header
+func (t *thriftCompactWriter) beginListStruct() {
+	t.lastIds = append(t.lastIds, t.lastId)
+	t.lastId = 0
+}
+
+func (t *thriftCompactWriter) endStruct() {
+	t.buf.WriteByte(0)
+	t.lastId = t.lastIds[len(t.lastIds)-1]
+	t.lastIds = t.lastIds[:len(t.lastIds)-1]
+}
+
+// list writes the header of a list, its elements are written next
+func (t *thriftCompactWriter) list(id int16, kind byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf.WriteByte(byte(size)<<4 | kind)
+	} else {
+		t.buf.WriteByte(0xf0 | kind)
+		writeUvarint(&t.buf, uint64(size))
+	}
+}
+
+func (t *thriftCompactWriter) listI32(value int32) {
+	writeUvarint(&t.buf, zigzag(int64(value)))
+}
+
+func (t *thriftCompactWriter) listBinary(value string) {
+	writeUvarint(&t.buf, uint64(len(value)))
+	t.buf.WriteString(value)
+}
+
+// end ends the top level struct
+func (t *thriftCompactWriter) end() {
+	t.buf.WriteByte(0)
+}
+
+func (t *thriftCompactWriter) bytes() []byte {
+	return t.buf.Bytes()
+}
+
+func zigzag(value int64) uint64 {
+	return uint64(value<<1) ^ uint64(value>>63)
+}
+
+func writeUvarint(buf *bytes.Buffer, value uint64) {
+	var data [binary.MaxVarintLen64]byte
+	buf.Write(data[:binary.PutUvarint(data[:], value)])
+}
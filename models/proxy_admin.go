@@ -0,0 +1,212 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// ProxyStats holds the stats of an Envoy proxy, by type
+type ProxyStats struct {
+	Counters   []ProxyStat      `json:"counters"`
+	Gauges     []ProxyStat      `json:"gauges"`
+	Histograms []ProxyHistogram `json:"histograms"`
+}
+
+type ProxyStat struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  float64           `json:"value"`
+}
+
+type ProxyHistogram struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Count   uint64            `json:"count"`
+	Sum     float64           `json:"sum"`
+	Buckets []HistogramBucket `json:"buckets"`
+}
+
+type HistogramBucket struct {
+	UpperBound float64 `json:"upper_bound"`
+	Count      uint64  `json:"count"`
+}
+
+// ProxyClusters holds the runtime state of the clusters of an Envoy proxy: circuit breakers, outlier
+// detection and health of the endpoints
+type ProxyClusters []*ProxyCluster
+type ProxyCluster struct {
+	Name                         string                   `json:"name"`
+	ServiceFQDN                  kubernetes.Host          `json:"service_fqdn"`
+	Subset                       string                   `json:"subset"`
+	Direction                    string                   `json:"direction"`
+	CircuitBreakers              []CircuitBreakerSettings `json:"circuit_breakers,omitempty"`
+	SuccessRateEjectionThreshold *float64                 `json:"success_rate_ejection_threshold,omitempty"`
+	Hosts                        []ProxyClusterHost       `json:"hosts"`
+}
+
+type CircuitBreakerSettings struct {
+	Priority           string `json:"priority"`
+	MaxConnections     int64  `json:"max_connections"`
+	MaxPendingRequests int64  `json:"max_pending_requests"`
+	MaxRequests        int64  `json:"max_requests"`
+	MaxRetries         int64  `json:"max_retries"`
+}
+
+type ProxyClusterHost struct {
+	Address     string           `json:"address"`
+	Port        int              `json:"port"`
+	Health      string           `json:"health"`
+	HealthFlags []string         `json:"health_flags,omitempty"`
+	Weight      int              `json:"weight"`
+	SuccessRate *float64         `json:"success_rate,omitempty"`
+	Stats       map[string]int64 `json:"stats"`
+}
+
+// ProxyServerInfo holds the state, version and uptime of an Envoy proxy. Uptimes are in seconds.
+type ProxyServerInfo struct {
+	Version            string  `json:"version"`
+	State              string  `json:"state"`
+	HotRestartVersion  string  `json:"hot_restart_version"`
+	RestartEpoch       int     `json:"restart_epoch"`
+	UptimeCurrentEpoch float64 `json:"uptime_current_epoch"`
+	UptimeAllEpochs    float64 `json:"uptime_all_epochs"`
+	NodeID             string  `json:"node_id"`
+	Cluster            string  `json:"cluster"`
+}
+
+func (ps *ProxyStats) Parse(families map[string]*dto.MetricFamily) {
+	ps.Counters = []ProxyStat{}
+	ps.Gauges = []ProxyStat{}
+	ps.Histograms = []ProxyHistogram{}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		family := families[name]
+		for _, m := range family.Metric {
+			labels := map[string]string{}
+			for _, l := range m.Label {
+				labels[l.GetName()] = l.GetValue()
+			}
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				ps.Counters = append(ps.Counters, ProxyStat{Name: name, Labels: labels, Value: m.GetCounter().GetValue()})
+			case dto.MetricType_GAUGE:
+				ps.Gauges = append(ps.Gauges, ProxyStat{Name: name, Labels: labels, Value: m.GetGauge().GetValue()})
+			case dto.MetricType_UNTYPED:
+				ps.Gauges = append(ps.Gauges, ProxyStat{Name: name, Labels: labels, Value: m.GetUntyped().GetValue()})
+			case dto.MetricType_HISTOGRAM:
+				h := m.GetHistogram()
+				histogram := ProxyHistogram{Name: name, Labels: labels, Count: h.GetSampleCount(), Sum: h.GetSampleSum(), Buckets: []HistogramBucket{}}
+				for _, b := range h.Bucket {
+					histogram.Buckets = append(histogram.Buckets, HistogramBucket{UpperBound: b.GetUpperBound(), Count: b.GetCumulativeCount()})
+				}
+				ps.Histograms = append(ps.Histograms, histogram)
+			}
+		}
+	}
+}
+
+func (pcs *ProxyClusters) Parse(status *kubernetes.ClustersStatus) {
+	for _, cluster := range status.ClusterStatuses {
+		cs := &Cluster{}
+		cs.Parse(kubernetes.EnvoyCluster{Name: cluster.Name})
+		pc := &ProxyCluster{
+			Name:        cluster.Name,
+			ServiceFQDN: cs.ServiceFQDN,
+			Subset:      cs.Subset,
+			Direction:   cs.Direction,
+			Hosts:       []ProxyClusterHost{},
+		}
+		if cluster.CircuitBreakers != nil {
+			for _, t := range cluster.CircuitBreakers.Thresholds {
+				priority := t.Priority
+				if priority == "" {
+					priority = "DEFAULT"
+				}
+				pc.CircuitBreakers = append(pc.CircuitBreakers, CircuitBreakerSettings{
+					Priority:           priority,
+					MaxConnections:     t.MaxConnections,
+					MaxPendingRequests: t.MaxPendingRequests,
+					MaxRequests:        t.MaxRequests,
+					MaxRetries:         t.MaxRetries,
+				})
+			}
+		}
+		if cluster.SuccessRateEjectionThreshold != nil {
+			pc.SuccessRateEjectionThreshold = &cluster.SuccessRateEjectionThreshold.Value
+		}
+
+		for _, host := range cluster.HostStatuses {
+			h := ProxyClusterHost{
+				Address:     host.Address.SocketAddress.Address,
+				Port:        int(host.Address.SocketAddress.PortValue),
+				Health:      endpointHealth(host.HealthStatus),
+				HealthFlags: healthFlags(host.HealthStatus),
+				Weight:      host.Weight,
+				Stats:       map[string]int64{},
+			}
+			if host.SuccessRate != nil {
+				rate := host.SuccessRate.Value
+				h.SuccessRate = &rate
+			}
+			for _, stat := range host.Stats {
+				if v, err := stat.Value.Int64(); err == nil {
+					h.Stats[stat.Name] = v
+				}
+			}
+			pc.Hosts = append(pc.Hosts, h)
+		}
+		*pcs = append(*pcs, pc)
+	}
+}
+
+// healthFlags lists the reasons why a host is excluded from the load balancing, as Envoy names them
+func healthFlags(status kubernetes.HostHealthStatus) []string {
+	flags := []string{}
+	for flag, set := range map[string]bool{
+		"failed_outlier_check":           status.FailedOutlierCheck,
+		"failed_active_hc":               status.FailedActiveHealthCheck,
+		"degraded_active_hc":             status.FailedActiveDegradedCheck,
+		"pending_dynamic_removal":        status.PendingDynamicRemoval,
+		"pending_active_hc":              status.PendingActiveHc,
+		"excluded_via_immediate_hc_fail": status.ExcludedViaImmediateHcFail,
+		"active_hc_timeout":              status.ActiveHcTimeout,
+	} {
+		if set {
+			flags = append(flags, flag)
+		}
+	}
+	if status.EdsHealthStatus == "UNHEALTHY" || status.EdsHealthStatus == "DRAINING" || status.EdsHealthStatus == "TIMEOUT" {
+		flags = append(flags, "failed_eds_health")
+	}
+	if status.EdsHealthStatus == "DEGRADED" {
+		flags = append(flags, "degraded_eds_health")
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+func (si *ProxyServerInfo) Parse(info *kubernetes.ServerInfo) {
+	si.Version = info.Version
+	si.State = info.State
+	si.HotRestartVersion = info.HotRestartVersion
+	si.RestartEpoch = info.CommandLineOptions.RestartEpoch
+	si.NodeID = info.Node.ID
+	si.Cluster = info.Node.Cluster
+	// Uptimes are formatted as protobuf durations, i.e. "3600s"
+	if d, err := time.ParseDuration(info.UptimeCurrentEpoch); err == nil {
+		si.UptimeCurrentEpoch = d.Seconds()
+	}
+	if d, err := time.ParseDuration(info.UptimeAllEpochs); err == nil {
+		si.UptimeAllEpochs = d.Seconds()
+	}
+}
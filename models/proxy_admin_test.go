@@ -0,0 +1,106 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func TestProxyStatsParse(t *testing.T) {
+	assert := assert.New(t)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(`# TYPE envoy_cluster_upstream_rq_pending_overflow counter
+envoy_cluster_upstream_rq_pending_overflow{cluster_name="outbound|9080||reviews.bookinfo.svc.cluster.local"} 12
+# TYPE envoy_cluster_outlier_detection_ejections_active gauge
+envoy_cluster_outlier_detection_ejections_active{cluster_name="outbound|9080||reviews.bookinfo.svc.cluster.local"} 1
+# TYPE envoy_cluster_upstream_rq_time histogram
+envoy_cluster_upstream_rq_time_bucket{cluster_name="xds-grpc",le="0.5"} 1
+envoy_cluster_upstream_rq_time_bucket{cluster_name="xds-grpc",le="+Inf"} 3
+envoy_cluster_upstream_rq_time_sum{cluster_name="xds-grpc"} 25.5
+envoy_cluster_upstream_rq_time_count{cluster_name="xds-grpc"} 3
+`))
+	assert.NoError(err)
+
+	stats := &ProxyStats{}
+	stats.Parse(families)
+	assert.Equal([]ProxyStat{{
+		Name:   "envoy_cluster_upstream_rq_pending_overflow",
+		Labels: map[string]string{"cluster_name": "outbound|9080||reviews.bookinfo.svc.cluster.local"},
+		Value:  12,
+	}}, stats.Counters)
+	assert.Len(stats.Gauges, 1)
+	assert.Equal(float64(1), stats.Gauges[0].Value)
+	assert.Len(stats.Histograms, 1)
+	assert.Equal(uint64(3), stats.Histograms[0].Count)
+	assert.Equal(25.5, stats.Histograms[0].Sum)
+	assert.Equal(HistogramBucket{UpperBound: 0.5, Count: 1}, stats.Histograms[0].Buckets[0])
+}
+
+func TestProxyClustersParse(t *testing.T) {
+	assert := assert.New(t)
+
+	status := &kubernetes.ClustersStatus{}
+	assert.NoError(json.Unmarshal([]byte(`{"cluster_statuses": [{
+		"name": "outbound|9080|v1|reviews.bookinfo.svc.cluster.local",
+		"success_rate_ejection_threshold": {"value": 85.5},
+		"circuit_breakers": {"thresholds": [
+			{"max_connections": 1, "max_pending_requests": 1, "max_requests": 4294967295, "max_retries": 4294967295},
+			{"priority": "HIGH", "max_connections": 1024, "max_pending_requests": 1024, "max_requests": 1024, "max_retries": 3}
+		]},
+		"host_statuses": [{
+			"address": {"socket_address": {"address": "10.0.0.1", "port_value": 9080}},
+			"stats": [{"name": "cx_connect_fail", "value": "2"}, {"name": "rq_error", "value": "7"}, {"name": "cx_active", "type": "GAUGE", "value": "1"}],
+			"health_status": {"eds_health_status": "HEALTHY", "failed_outlier_check": true},
+			"success_rate": {"value": 42},
+			"weight": 1
+		}]
+	}]}`), status))
+
+	clusters := &ProxyClusters{}
+	clusters.Parse(status)
+	assert.Len(*clusters, 1)
+
+	cluster := (*clusters)[0]
+	assert.Equal("v1", cluster.Subset)
+	assert.Equal("outbound", cluster.Direction)
+	assert.Equal(85.5, *cluster.SuccessRateEjectionThreshold)
+	assert.Equal([]CircuitBreakerSettings{
+		{Priority: "DEFAULT", MaxConnections: 1, MaxPendingRequests: 1, MaxRequests: 4294967295, MaxRetries: 4294967295},
+		{Priority: "HIGH", MaxConnections: 1024, MaxPendingRequests: 1024, MaxRequests: 1024, MaxRetries: 3},
+	}, cluster.CircuitBreakers)
+
+	host := cluster.Hosts[0]
+	assert.Equal("UNHEALTHY", host.Health)
+	assert.Equal([]string{"failed_outlier_check"}, host.HealthFlags)
+	assert.Equal(42.0, *host.SuccessRate)
+	assert.Equal(map[string]int64{"cx_connect_fail": 2, "rq_error": 7, "cx_active": 1}, host.Stats)
+}
+
+func TestProxyServerInfoParse(t *testing.T) {
+	assert := assert.New(t)
+
+	info := &kubernetes.ServerInfo{}
+	assert.NoError(json.Unmarshal([]byte(`{
+		"version": "a1b2/1.17.1/Clean/RELEASE/BoringSSL",
+		"state": "LIVE",
+		"hot_restart_version": "11.104",
+		"command_line_options": {"restart_epoch": 1},
+		"node": {"id": "sidecar~10.0.0.1~reviews-v1.bookinfo~bookinfo.svc.cluster.local", "cluster": "reviews.bookinfo"},
+		"uptime_current_epoch": "60s",
+		"uptime_all_epochs": "3600.5s"
+	}`), info))
+
+	serverInfo := &ProxyServerInfo{}
+	serverInfo.Parse(info)
+	assert.Equal("LIVE", serverInfo.State)
+	assert.Equal(1, serverInfo.RestartEpoch)
+	assert.Equal(60.0, serverInfo.UptimeCurrentEpoch)
+	assert.Equal(3600.5, serverInfo.UptimeAllEpochs)
+	assert.Equal("reviews.bookinfo", serverInfo.Cluster)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ProxyLogLevels is a change of the log levels of a proxy: one level for all its loggers, and/or levels per logger
+type ProxyLogLevels struct {
+	Level   string            `json:"level,omitempty"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+// ProxyLogging records a proxy whose log levels were raised through Kiali
+type ProxyLogging struct {
+	ProxyLogLevels
+	Namespace string     `json:"namespace"`
+	Pod       string     `json:"pod"`
+	Since     time.Time  `json:"since"`
+	RevertAt  *time.Time `json:"revertAt,omitempty"`
+}
+
+// ProxyLoggingResult is the outcome of a log level change for one of the pods of a bulk update
+type ProxyLoggingResult struct {
+	Pod   string `json:"pod"`
+	Error string `json:"error,omitempty"`
+}
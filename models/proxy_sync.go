@@ -0,0 +1,207 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// The xDS types reported by istiod's syncz
+var xdsTypes = []string{"CDS", "EDS", "LDS", "RDS"}
+
+// Number of status changes kept by proxy
+const maxProxySyncEvents = 20
+
+// ProxySyncHistory tracks the synchronization of a proxy with the control plane across the syncz refreshes
+type ProxySyncHistory struct {
+	Namespace    string                     `json:"namespace"`
+	Pod          string                     `json:"pod"`
+	Istiod       string                     `json:"istiod"`
+	ProxyVersion string                     `json:"proxyVersion,omitempty"`
+	IstioVersion string                     `json:"istioVersion,omitempty"`
+	LastSeen     time.Time                  `json:"lastSeen"`
+	Xds          map[string]*XdsSyncHistory `json:"xds"`
+	Events       []ProxySyncEvent           `json:"events"`
+}
+
+// XdsSyncHistory is the synchronization of one xDS type of a proxy. Times are the ones when Kiali observed the changes.
+type XdsSyncHistory struct {
+	Status     string     `json:"status"`
+	LastAcked  *time.Time `json:"lastAcked,omitempty"`
+	StaleSince *time.Time `json:"staleSince,omitempty"`
+	ackedNonce string
+}
+
+// ProxySyncEvent is a change of the status of an xDS type, or of the istiod instance the proxy is connected to
+type ProxySyncEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+}
+
+// StaleProxies lists the proxies out of sync for longer than a threshold, for one istiod revision
+type StaleProxies struct {
+	Revision string       `json:"revision"`
+	Istiods  []string     `json:"istiods"`
+	Proxies  []StaleProxy `json:"proxies"`
+}
+
+type StaleProxy struct {
+	Namespace  string    `json:"namespace"`
+	Pod        string    `json:"pod"`
+	Istiod     string    `json:"istiod"`
+	StaleTypes []string  `json:"staleTypes"`
+	StaleSince time.Time `json:"staleSince"`
+	// How long the proxy has been stale, in seconds
+	StaleFor float64 `json:"staleFor"`
+}
+
+// XdsStatus tells whether an xDS type is synced from the nonces sent by istiod and acknowledged by the proxy
+func XdsStatus(sent, acked string) string {
+	if sent == "" {
+		return "NOT_SENT"
+	}
+	if sent == acked {
+		return "Synced"
+	}
+	// acked will be empty string when there is never Acknowledged
+	if acked == "" {
+		return "Stale (Never Acknowledged)"
+	}
+	// Since the Nonce changes to uuid, so there is no more any time diff info
+	return "Stale"
+}
+
+// Record adds a syncz observation of the proxy to its history
+func (h *ProxySyncHistory) Record(istiod string, status kubernetes.SyncStatus, observed time.Time) {
+	if h.Xds == nil {
+		h.Xds = map[string]*XdsSyncHistory{}
+	}
+	if h.Istiod != "" && h.Istiod != istiod {
+		h.addEvent(ProxySyncEvent{Timestamp: observed, Type: "istiod", From: h.Istiod, To: istiod})
+	}
+	h.Istiod = istiod
+	h.ProxyVersion = status.ProxyVersion
+	h.IstioVersion = status.IstioVersion
+	h.LastSeen = observed
+
+	nonces := map[string][2]string{
+		"CDS": {status.ClusterSent, status.ClusterAcked},
+		"EDS": {status.EndpointSent, status.EndpointAcked},
+		"LDS": {status.ListenerSent, status.ListenerAcked},
+		"RDS": {status.RouteSent, status.RouteAcked},
+	}
+	for _, xdsType := range xdsTypes {
+		sent, acked := nonces[xdsType][0], nonces[xdsType][1]
+		xds, found := h.Xds[xdsType]
+		if !found {
+			xds = &XdsSyncHistory{}
+			h.Xds[xdsType] = xds
+		}
+		if acked != "" && acked != xds.ackedNonce {
+			at := observed
+			xds.LastAcked = &at
+			xds.ackedNonce = acked
+		}
+
+		xdsStatus := XdsStatus(sent, acked)
+		if found && xds.Status != xdsStatus {
+			h.addEvent(ProxySyncEvent{Timestamp: observed, Type: xdsType, From: xds.Status, To: xdsStatus})
+		}
+		xds.Status = xdsStatus
+		switch {
+		case xdsStatus == "Synced" || xdsStatus == "NOT_SENT":
+			xds.StaleSince = nil
+		case xds.StaleSince == nil:
+			since := observed
+			xds.StaleSince = &since
+		}
+	}
+}
+
+// DeepCopy copies the history, so it can be read while the original is updated
+func (h *ProxySyncHistory) DeepCopy() *ProxySyncHistory {
+	c := *h
+	c.Xds = make(map[string]*XdsSyncHistory, len(h.Xds))
+	for xdsType, xds := range h.Xds {
+		x := *xds
+		c.Xds[xdsType] = &x
+	}
+	c.Events = append([]ProxySyncEvent{}, h.Events...)
+	return &c
+}
+
+func (h *ProxySyncHistory) addEvent(event ProxySyncEvent) {
+	h.Events = append(h.Events, event)
+	if len(h.Events) > maxProxySyncEvents {
+		h.Events = h.Events[len(h.Events)-maxProxySyncEvents:]
+	}
+}
+
+// Stale returns the xDS types out of sync and since when the earliest of them is, or nil when the proxy is synced
+func (h *ProxySyncHistory) Stale() ([]string, *time.Time) {
+	var staleTypes []string
+	var since *time.Time
+	for _, xdsType := range xdsTypes {
+		if xds, ok := h.Xds[xdsType]; ok && xds.StaleSince != nil {
+			staleTypes = append(staleTypes, xdsType)
+			if since == nil || xds.StaleSince.Before(*since) {
+				since = xds.StaleSince
+			}
+		}
+	}
+	return staleTypes, since
+}
+
+// GroupStaleProxies lists the proxies stale for longer than the threshold, grouped by the revision of their istiod.
+// Revisions are given by istiod name, istiods not found there are grouped under the default revision.
+func GroupStaleProxies(histories []ProxySyncHistory, revisions map[string]string, threshold time.Duration, now time.Time) []StaleProxies {
+	byRevision := map[string]*StaleProxies{}
+	for _, h := range histories {
+		staleTypes, since := h.Stale()
+		if since == nil || now.Sub(*since) < threshold {
+			continue
+		}
+		revision, ok := revisions[h.Istiod]
+		if !ok {
+			revision = "default"
+		}
+		group, ok := byRevision[revision]
+		if !ok {
+			group = &StaleProxies{Revision: revision, Istiods: []string{}, Proxies: []StaleProxy{}}
+			byRevision[revision] = group
+		}
+		group.Proxies = append(group.Proxies, StaleProxy{
+			Namespace:  h.Namespace,
+			Pod:        h.Pod,
+			Istiod:     h.Istiod,
+			StaleTypes: staleTypes,
+			StaleSince: *since,
+			StaleFor:   now.Sub(*since).Seconds(),
+		})
+	}
+
+	groups := []StaleProxies{}
+	for _, group := range byRevision {
+		istiods := map[string]bool{}
+		for _, p := range group.Proxies {
+			if !istiods[p.Istiod] {
+				istiods[p.Istiod] = true
+				group.Istiods = append(group.Istiods, p.Istiod)
+			}
+		}
+		sort.Strings(group.Istiods)
+		// The longest stale first
+		sort.Slice(group.Proxies, func(i, j int) bool {
+			if group.Proxies[i].StaleFor != group.Proxies[j].StaleFor {
+				return group.Proxies[i].StaleFor > group.Proxies[j].StaleFor
+			}
+			return group.Proxies[i].Namespace+"/"+group.Proxies[i].Pod < group.Proxies[j].Namespace+"/"+group.Proxies[j].Pod
+		})
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Revision < groups[j].Revision })
+	return groups
+}
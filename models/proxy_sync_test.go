@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func TestProxySyncHistoryRecord(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	h := &ProxySyncHistory{Namespace: "bookinfo", Pod: "reviews-v1"}
+	h.Record("istiod-a", kubernetes.SyncStatus{ClusterSent: "1", ClusterAcked: "1", ListenerSent: "1", ListenerAcked: "1"}, start)
+	assert.Equal("Synced", h.Xds["CDS"].Status)
+	assert.Equal("NOT_SENT", h.Xds["EDS"].Status)
+	assert.Equal(start, *h.Xds["CDS"].LastAcked)
+	assert.Empty(h.Events)
+
+	// A push not acknowledged yet
+	h.Record("istiod-a", kubernetes.SyncStatus{ClusterSent: "2", ClusterAcked: "1", ListenerSent: "1", ListenerAcked: "1"}, start.Add(time.Minute))
+	assert.Equal("Stale", h.Xds["CDS"].Status)
+	assert.Equal(start.Add(time.Minute), *h.Xds["CDS"].StaleSince)
+	assert.Equal(start, *h.Xds["CDS"].LastAcked)
+
+	// Still stale on a new istiod: the stale time is kept
+	h.Record("istiod-b", kubernetes.SyncStatus{ClusterSent: "3", ClusterAcked: "1", ListenerSent: "1", ListenerAcked: "1"}, start.Add(2*time.Minute))
+	staleTypes, since := h.Stale()
+	assert.Equal([]string{"CDS"}, staleTypes)
+	assert.Equal(start.Add(time.Minute), *since)
+
+	h.Record("istiod-b", kubernetes.SyncStatus{ClusterSent: "3", ClusterAcked: "3", ListenerSent: "1", ListenerAcked: "1"}, start.Add(3*time.Minute))
+	assert.Nil(h.Xds["CDS"].StaleSince)
+	assert.Equal(start.Add(3*time.Minute), *h.Xds["CDS"].LastAcked)
+	assert.Equal([]ProxySyncEvent{
+		{Timestamp: start.Add(time.Minute), Type: "CDS", From: "Synced", To: "Stale"},
+		{Timestamp: start.Add(2 * time.Minute), Type: "istiod", From: "istiod-a", To: "istiod-b"},
+		{Timestamp: start.Add(3 * time.Minute), Type: "CDS", From: "Stale", To: "Synced"},
+	}, h.Events)
+}
+
+func TestGroupStaleProxies(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	stale := func(ns, pod, istiod string, d time.Duration) ProxySyncHistory {
+		h := ProxySyncHistory{Namespace: ns, Pod: pod}
+		h.Record(istiod, kubernetes.SyncStatus{RouteSent: "2", RouteAcked: "1"}, now.Add(-d))
+		return h
+	}
+	synced := ProxySyncHistory{Namespace: "bookinfo", Pod: "ratings-v1"}
+	synced.Record("istiod-canary", kubernetes.SyncStatus{RouteSent: "1", RouteAcked: "1"}, now.Add(-time.Hour))
+
+	groups := GroupStaleProxies([]ProxySyncHistory{
+		stale("bookinfo", "reviews-v1", "istiod-canary", 5*time.Minute),
+		stale("bookinfo", "reviews-v2", "istiod-canary", 10*time.Minute),
+		stale("bookinfo", "details-v1", "istiod-1", 30*time.Second),
+		stale("travel", "cars-v1", "istiod-1", 2*time.Minute),
+		synced,
+	}, map[string]string{"istiod-canary": "canary"}, time.Minute, now)
+
+	assert.Len(groups, 2)
+	assert.Equal("canary", groups[0].Revision)
+	assert.Equal([]string{"istiod-canary"}, groups[0].Istiods)
+	assert.Equal("reviews-v2", groups[0].Proxies[0].Pod)
+	assert.Equal(600.0, groups[0].Proxies[0].StaleFor)
+	assert.Equal([]string{"RDS"}, groups[0].Proxies[0].StaleTypes)
+	assert.Equal("reviews-v1", groups[0].Proxies[1].Pod)
+	assert.Equal("default", groups[1].Revision)
+	assert.Len(groups[1].Proxies, 1)
+	assert.Equal("cars-v1", groups[1].Proxies[0].Pod)
+}
@@ -0,0 +1,493 @@
+package models
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+const (
+	RouteActionRoute          = "route"
+	RouteActionRedirect       = "redirect"
+	RouteActionDirectResponse = "direct_response"
+	RouteActionTCPProxy       = "tcp_proxy"
+)
+
+// RouteSimulationRequest describes a plain text request sent by the application to its proxy
+type RouteSimulationRequest struct {
+	Host    string            `json:"host"`
+	Port    int               `json:"port"`
+	Path    string            `json:"path"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+}
+
+// RouteSimulation tells where the proxy would send a request, and why. When the request doesn't match,
+// Reason tells at which step the matching stopped.
+type RouteSimulation struct {
+	Matched        bool               `json:"matched"`
+	Reason         string             `json:"reason,omitempty"`
+	Listener       string             `json:"listener,omitempty"`
+	FilterChain    string             `json:"filter_chain,omitempty"`
+	RouteConfig    string             `json:"route_config,omitempty"`
+	VirtualHost    string             `json:"virtual_host,omitempty"`
+	Route          string             `json:"route,omitempty"`
+	RouteMatch     string             `json:"route_match,omitempty"`
+	VirtualService string             `json:"virtual_service,omitempty"`
+	Action         string             `json:"action,omitempty"`
+	Destinations   []RouteDestination `json:"destinations,omitempty"`
+}
+
+type RouteDestination struct {
+	Cluster         string          `json:"cluster"`
+	ServiceFQDN     kubernetes.Host `json:"service_fqdn"`
+	Port            int             `json:"port"`
+	Subset          string          `json:"subset"`
+	DestinationRule string          `json:"destination_rule"`
+	Weight          int             `json:"weight"`
+}
+
+// SimulateRoute evaluates offline the matching of a request against the listeners, routes and clusters of
+// a proxy: listener and filter chain, virtual host, then the first matching route.
+func SimulateRoute(dump *kubernetes.ConfigDump, req RouteSimulationRequest) (*RouteSimulation, error) {
+	listenersDump, err := dump.GetListeners()
+	if err != nil {
+		return nil, err
+	}
+	routesDump, err := dump.GetRoutes()
+	if err != nil {
+		return nil, err
+	}
+	clustersDump, err := dump.GetClusters()
+	if err != nil {
+		return nil, err
+	}
+	if req.Method == "" {
+		req.Method = "GET"
+	}
+	if req.Path == "" {
+		req.Path = "/"
+	}
+
+	sim := &RouteSimulation{}
+	listener := matchListener(listenersDump, req)
+	if listener == nil {
+		sim.Reason = fmt.Sprintf("No listener on port %d", req.Port)
+		return sim, nil
+	}
+	sim.Listener = listener.Name
+
+	chain := matchFilterChain(listener, req)
+	if chain == nil {
+		sim.Reason = "No filter chain of the listener matches the request"
+		return sim, nil
+	}
+	sim.FilterChain = listenerMatches(kubernetes.EnvoyListener{FilterChains: []kubernetes.EnvoyFilterChain{*chain}})[0]["match"].(string)
+
+	for _, filter := range chain.Filters {
+		typedConfig := filter.TypedConfig
+		switch filter.Name {
+		case "envoy.filters.network.tcp_proxy":
+			sim.Matched = true
+			sim.Action = RouteActionTCPProxy
+			sim.Destinations = []RouteDestination{routeDestination(typedConfig.Cluster, 100, clustersDump)}
+			return sim, nil
+		case "envoy.filters.network.http_connection_manager":
+			rc := typedConfig.RouteConfig
+			if rc == nil && typedConfig.Rds != nil {
+				sim.RouteConfig = typedConfig.Rds.RouteConfigName
+				rc = findRouteConfig(routesDump, typedConfig.Rds.RouteConfigName)
+			}
+			if rc == nil {
+				sim.Reason = "The route configuration of the listener is not found"
+				return sim, nil
+			}
+			sim.RouteConfig = rc.Name
+			simulateHTTPRoute(sim, rc, req, clustersDump)
+			return sim, nil
+		}
+	}
+	sim.Reason = "The filter chain is neither HTTP nor TCP"
+	return sim, nil
+}
+
+func simulateHTTPRoute(sim *RouteSimulation, rc *kubernetes.RouteConfig, req RouteSimulationRequest, clusters *kubernetes.ClusterDump) {
+	vh := matchVirtualHost(rc.VirtualHosts, req.Host)
+	if vh == nil {
+		sim.Reason = fmt.Sprintf("No virtual host matches the host %s", req.Host)
+		return
+	}
+	sim.VirtualHost = vh.Name
+
+	// Routes are evaluated in order, the first match wins
+	for _, r := range vh.Routes {
+		if !routeMatches(r.Match, req) {
+			continue
+		}
+		sim.Matched = true
+		sim.Route = r.Name
+		sim.RouteMatch = matchSummary(r.Match)
+		sim.VirtualService = istioMetadata(r.Metadata)
+		switch {
+		case r.Route != nil && r.Route.WeightedClusters != nil:
+			sim.Action = RouteActionRoute
+			for _, wc := range r.Route.WeightedClusters.Clusters {
+				sim.Destinations = append(sim.Destinations, routeDestination(wc.Name, int(wc.Weight), clusters))
+			}
+		case r.Route != nil:
+			sim.Action = RouteActionRoute
+			sim.Destinations = []RouteDestination{routeDestination(r.Route.Cluster, 100, clusters)}
+		case r.Redirect != nil:
+			sim.Action = RouteActionRedirect
+		case r.DirectResponse != nil:
+			sim.Action = RouteActionDirectResponse
+		}
+		return
+	}
+	sim.Reason = fmt.Sprintf("No route of the virtual host %s matches the request", vh.Name)
+}
+
+// matchListener prefers a listener bound to the requested IP over a wildcard one
+func matchListener(dump *kubernetes.ListenerDump, req RouteSimulationRequest) *kubernetes.EnvoyListener {
+	listeners := make([]kubernetes.EnvoyListener, 0, len(dump.DynamicListeners)+len(dump.StaticListeners))
+	for _, dynamicListener := range dump.DynamicListeners {
+		listener := dynamicListener.ActiveState.Listener
+		if listener.Name == "" {
+			listener.Name = dynamicListener.Name
+		}
+		listeners = append(listeners, listener)
+	}
+	for _, staticListener := range dump.StaticListeners {
+		listeners = append(listeners, staticListener.Listener)
+	}
+
+	host := hostWithoutPort(req.Host)
+	var wildcard *kubernetes.EnvoyListener
+	for i := range listeners {
+		listener := &listeners[i]
+		if int(listener.Address.SocketAddress.PortValue) != req.Port {
+			continue
+		}
+		switch listener.Address.SocketAddress.Address {
+		case host:
+			return listener
+		case "0.0.0.0", "::":
+			if wildcard == nil {
+				wildcard = listener
+			}
+		}
+	}
+	return wildcard
+}
+
+// chainSpecificity ranks filter chains by the criteria Envoy uses, in the same order:
+// destination port, destination IP prefix length, transport protocol and application protocols
+type chainSpecificity [4]int
+
+func (cs chainSpecificity) moreSpecificThan(other chainSpecificity) bool {
+	for i := range cs {
+		if cs[i] != other[i] {
+			return cs[i] > other[i]
+		}
+	}
+	return false
+}
+
+func matchFilterChain(listener *kubernetes.EnvoyListener, req RouteSimulationRequest) *kubernetes.EnvoyFilterChain {
+	ip := net.ParseIP(hostWithoutPort(req.Host))
+	var best *kubernetes.EnvoyFilterChain
+	var bestSpecificity chainSpecificity
+	for i := range listener.FilterChains {
+		chain := &listener.FilterChains[i]
+		specificity, ok := filterChainSpecificity(chain.FilterChainMatch, req.Port, ip)
+		if ok && (best == nil || specificity.moreSpecificThan(bestSpecificity)) {
+			best, bestSpecificity = chain, specificity
+		}
+	}
+	if best == nil {
+		return listener.DefaultFilterChain
+	}
+	return best
+}
+
+// filterChainSpecificity tells if a filter chain matches a plain text HTTP request, and how specific the match is
+func filterChainSpecificity(match *kubernetes.FilterChainMatch, port int, ip net.IP) (chainSpecificity, bool) {
+	var specificity chainSpecificity
+	if match == nil {
+		return specificity, true
+	}
+	if match.DestinationPort != nil {
+		if int(*match.DestinationPort) != port {
+			return specificity, false
+		}
+		specificity[0] = 1
+	}
+	if len(match.PrefixRanges) > 0 {
+		for _, p := range match.PrefixRanges {
+			_, cidr, err := net.ParseCIDR(fmt.Sprintf("%s/%d", p.AddressPrefix, p.PrefixLen))
+			if err == nil && ip != nil && cidr.Contains(ip) && p.PrefixLen+1 > specificity[1] {
+				specificity[1] = p.PrefixLen + 1
+			}
+		}
+		if specificity[1] == 0 {
+			return specificity, false
+		}
+	}
+	// A plain text request has no SNI
+	if len(match.ServerNames) > 0 {
+		return specificity, false
+	}
+	if match.TransportProtocol != "" {
+		if match.TransportProtocol != "raw_buffer" {
+			return specificity, false
+		}
+		specificity[2] = 1
+	}
+	if len(match.ApplicationProtocols) > 0 {
+		if !containsString(match.ApplicationProtocols, "http/1.1") {
+			return specificity, false
+		}
+		specificity[3] = 1
+	}
+	return specificity, true
+}
+
+func findRouteConfig(dump *kubernetes.RouteDump, name string) *kubernetes.RouteConfig {
+	for _, routeSet := range [][]kubernetes.EnvoyRouteConfig{dump.DynamicRouteConfigs, dump.StaticRouteConfigs} {
+		for _, route := range routeSet {
+			if route.RouteConfig != nil && route.RouteConfig.Name == name {
+				return route.RouteConfig
+			}
+		}
+	}
+	return nil
+}
+
+// matchVirtualHost follows the Envoy domain matching: exact domains first, then the longest suffix
+// wildcard, the longest prefix wildcard and finally the catch-all domain.
+func matchVirtualHost(vhs []kubernetes.VirtualHostFilter, host string) *kubernetes.VirtualHostFilter {
+	host = strings.ToLower(host)
+	var suffixMatch, prefixMatch, catchAll *kubernetes.VirtualHostFilter
+	suffixLen, prefixLen := 0, 0
+	for i := range vhs {
+		for _, domain := range vhs[i].Domains {
+			domain = strings.ToLower(domain)
+			switch {
+			case domain == host:
+				return &vhs[i]
+			case domain == "*":
+				if catchAll == nil {
+					catchAll = &vhs[i]
+				}
+			case strings.HasPrefix(domain, "*"):
+				if len(host) >= len(domain) && strings.HasSuffix(host, domain[1:]) && len(domain) > suffixLen {
+					suffixMatch, suffixLen = &vhs[i], len(domain)
+				}
+			case strings.HasSuffix(domain, "*"):
+				if len(host) >= len(domain) && strings.HasPrefix(host, domain[:len(domain)-1]) && len(domain) > prefixLen {
+					prefixMatch, prefixLen = &vhs[i], len(domain)
+				}
+			}
+		}
+	}
+	if suffixMatch != nil {
+		return suffixMatch
+	}
+	if prefixMatch != nil {
+		return prefixMatch
+	}
+	return catchAll
+}
+
+func routeMatches(match map[string]interface{}, req RouteSimulationRequest) bool {
+	rawPath, rawQuery := req.Path, ""
+	if i := strings.Index(rawPath, "?"); i >= 0 {
+		rawPath, rawQuery = rawPath[:i], rawPath[i+1:]
+	}
+	path := rawPath
+	caseSensitive := true
+	if cs, ok := match["case_sensitive"].(bool); ok {
+		caseSensitive = cs
+	}
+	if !caseSensitive {
+		path = strings.ToLower(path)
+	}
+
+	if prefix, ok := match["prefix"].(string); ok {
+		if !caseSensitive {
+			prefix = strings.ToLower(prefix)
+		}
+		if !strings.HasPrefix(path, prefix) {
+			return false
+		}
+	} else if exact, ok := match["path"].(string); ok {
+		if !caseSensitive {
+			exact = strings.ToLower(exact)
+		}
+		if path != exact {
+			return false
+		}
+	} else if safeRegex, ok := match["safe_regex"].(map[string]interface{}); ok {
+		if !regexMatches(safeRegex["regex"], rawPath) {
+			return false
+		}
+	} else {
+		// i.e. CONNECT matchers
+		return false
+	}
+
+	headers, _ := match["headers"].([]interface{})
+	for _, h := range headers {
+		headerMatch, _ := h.(map[string]interface{})
+		name, _ := headerMatch["name"].(string)
+		value, present := requestHeader(req, name)
+		if !headerMatches(headerMatch, value, present) {
+			return false
+		}
+	}
+
+	queryParameters, _ := match["query_parameters"].([]interface{})
+	if len(queryParameters) > 0 {
+		query, _ := url.ParseQuery(rawQuery)
+		for _, q := range queryParameters {
+			queryMatch, _ := q.(map[string]interface{})
+			name, _ := queryMatch["name"].(string)
+			_, present := query[name]
+			if presentMatch, ok := queryMatch["present_match"].(bool); ok {
+				if present != presentMatch {
+					return false
+				}
+				continue
+			}
+			stringMatch, _ := queryMatch["string_match"].(map[string]interface{})
+			if !present || (stringMatch != nil && !stringMatches(stringMatch, query.Get(name))) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func requestHeader(req RouteSimulationRequest, name string) (string, bool) {
+	switch strings.ToLower(name) {
+	case ":method":
+		return req.Method, true
+	case ":path":
+		return req.Path, true
+	case ":authority", "host":
+		return req.Host, true
+	case ":scheme":
+		return "http", true
+	}
+	for k, v := range req.Headers {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func headerMatches(match map[string]interface{}, value string, present bool) bool {
+	var matched bool
+	if presentMatch, ok := match["present_match"].(bool); ok {
+		matched = present == presentMatch
+	} else if !present {
+		matched = false
+	} else if exact, ok := match["exact_match"].(string); ok {
+		matched = value == exact
+	} else if prefix, ok := match["prefix_match"].(string); ok {
+		matched = strings.HasPrefix(value, prefix)
+	} else if suffix, ok := match["suffix_match"].(string); ok {
+		matched = strings.HasSuffix(value, suffix)
+	} else if contains, ok := match["contains_match"].(string); ok {
+		matched = strings.Contains(value, contains)
+	} else if safeRegex, ok := match["safe_regex_match"].(map[string]interface{}); ok {
+		matched = regexMatches(safeRegex["regex"], value)
+	} else if stringMatch, ok := match["string_match"].(map[string]interface{}); ok {
+		matched = stringMatches(stringMatch, value)
+	} else {
+		// Only the name: the header must be present
+		matched = true
+	}
+	if invert, _ := match["invert_match"].(bool); invert {
+		return !matched
+	}
+	return matched
+}
+
+func stringMatches(match map[string]interface{}, value string) bool {
+	ignoreCase, _ := match["ignore_case"].(bool)
+	normalize := func(s string) string {
+		if ignoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	if exact, ok := match["exact"].(string); ok {
+		return normalize(value) == normalize(exact)
+	}
+	if prefix, ok := match["prefix"].(string); ok {
+		return strings.HasPrefix(normalize(value), normalize(prefix))
+	}
+	if suffix, ok := match["suffix"].(string); ok {
+		return strings.HasSuffix(normalize(value), normalize(suffix))
+	}
+	if contains, ok := match["contains"].(string); ok {
+		return strings.Contains(normalize(value), normalize(contains))
+	}
+	if safeRegex, ok := match["safe_regex"].(map[string]interface{}); ok {
+		return regexMatches(safeRegex["regex"], value)
+	}
+	return false
+}
+
+// regexMatches matches the whole value, as Envoy does. Envoy uses RE2, like Go.
+func regexMatches(regex interface{}, value string) bool {
+	expr, ok := regex.(string)
+	if !ok {
+		return false
+	}
+	re, err := regexp.Compile("^(?:" + expr + ")$")
+	return err == nil && re.MatchString(value)
+}
+
+func routeDestination(cluster string, weight int, clusters *kubernetes.ClusterDump) RouteDestination {
+	cs := &Cluster{}
+	cs.Parse(kubernetes.EnvoyCluster{Name: cluster})
+	// The cluster itself holds the DestinationRule metadata
+	for _, clusterSet := range [][]kubernetes.EnvoyClusterWrapper{clusters.DynamicClusters, clusters.StaticClusters} {
+		for _, c := range clusterSet {
+			if c.Cluster.Name == cluster {
+				cs.Parse(c.Cluster)
+			}
+		}
+	}
+	return RouteDestination{
+		Cluster:         cluster,
+		ServiceFQDN:     cs.ServiceFQDN,
+		Port:            cs.Port,
+		Subset:          cs.Subset,
+		DestinationRule: cs.DestinationRule,
+		Weight:          weight,
+	}
+}
+
+func hostWithoutPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const routeSimulationDump = `{"configs": [
+	{"@type": "type.googleapis.com/envoy.admin.v3.ListenersConfigDump", "dynamic_listeners": [
+		{"name": "0.0.0.0_9080", "active_state": {"listener": {
+			"name": "0.0.0.0_9080",
+			"address": {"socket_address": {"address": "0.0.0.0", "port_value": 9080}},
+			"filter_chains": [
+				{"filter_chain_match": {"transport_protocol": "tls", "application_protocols": ["istio-peer-exchange", "istio"]},
+				 "filters": [{"name": "envoy.filters.network.tcp_proxy", "typed_config": {"cluster": "BlackHoleCluster"}}]},
+				{"filter_chain_match": {"application_protocols": ["http/1.0", "http/1.1", "h2c"]},
+				 "filters": [{"name": "envoy.filters.network.http_connection_manager", "typed_config": {"rds": {"route_config_name": "9080"}}}]}
+			]
+		}}},
+		{"name": "10.96.0.5_3306", "active_state": {"listener": {
+			"name": "10.96.0.5_3306",
+			"address": {"socket_address": {"address": "10.96.0.5", "port_value": 3306}},
+			"filter_chains": [{"filters": [{"name": "envoy.filters.network.tcp_proxy", "typed_config": {"cluster": "outbound|3306||mysqldb.bookinfo.svc.cluster.local"}}]}]
+		}}}
+	]},
+	{"@type": "type.googleapis.com/envoy.admin.v3.RoutesConfigDump", "dynamic_route_configs": [
+		{"route_config": {"name": "9080", "virtual_hosts": [
+			{"name": "allow_any", "domains": ["*"], "routes": [{"name": "allow_any", "match": {"prefix": "/"}, "route": {"cluster": "PassthroughCluster"}}]},
+			{"name": "reviews.bookinfo.svc.cluster.local:9080", "domains": ["reviews.bookinfo.svc.cluster.local", "reviews", "reviews:9080"], "routes": [
+				{"name": "jason", "match": {"prefix": "/", "headers": [{"name": "end-user", "string_match": {"exact": "jason"}}]},
+				 "metadata": {"filter_metadata": {"istio": {"config": "/apis/networking.istio.io/v1alpha3/namespaces/bookinfo/virtual-service/reviews"}}},
+				 "route": {"cluster": "outbound|9080|v2|reviews.bookinfo.svc.cluster.local"}},
+				{"name": "canary", "match": {"prefix": "/"},
+				 "metadata": {"filter_metadata": {"istio": {"config": "/apis/networking.istio.io/v1alpha3/namespaces/bookinfo/virtual-service/reviews"}}},
+				 "route": {"weighted_clusters": {"clusters": [
+					{"name": "outbound|9080|v1|reviews.bookinfo.svc.cluster.local", "weight": 80},
+					{"name": "outbound|9080|v3|reviews.bookinfo.svc.cluster.local", "weight": 20}
+				 ]}}}
+			]},
+			{"name": "ratings.bookinfo.svc.cluster.local:9080", "domains": ["ratings.bookinfo.svc.cluster.local", "ratings"], "routes": [
+				{"name": "ratings", "match": {"path": "/health", "headers": [{"name": ":method", "exact_match": "GET"}]}, "route": {"cluster": "outbound|9080||ratings.bookinfo.svc.cluster.local"}}
+			]}
+		]}}
+	]},
+	{"@type": "type.googleapis.com/envoy.admin.v3.ClustersConfigDump", "dynamic_active_clusters": [
+		{"cluster": {"name": "outbound|9080|v2|reviews.bookinfo.svc.cluster.local",
+		 "metadata": {"filter_metadata": {"istio": {"config": "/apis/networking.istio.io/v1alpha3/namespaces/bookinfo/destination-rule/reviews"}}}}}
+	]}
+]}`
+
+func TestSimulateRouteHeaderMatch(t *testing.T) {
+	assert := assert.New(t)
+	dump := fakeConfigDump(t, routeSimulationDump)
+
+	sim, err := SimulateRoute(dump, RouteSimulationRequest{Host: "reviews:9080", Port: 9080, Path: "/reviews/0", Headers: map[string]string{"End-User": "jason"}})
+	assert.NoError(err)
+	assert.True(sim.Matched)
+	assert.Equal("0.0.0.0_9080", sim.Listener)
+	assert.Equal("App: HTTP", sim.FilterChain)
+	assert.Equal("9080", sim.RouteConfig)
+	assert.Equal("reviews.bookinfo.svc.cluster.local:9080", sim.VirtualHost)
+	assert.Equal("jason", sim.Route)
+	assert.Equal("reviews.bookinfo", sim.VirtualService)
+	assert.Equal(RouteActionRoute, sim.Action)
+	assert.Equal([]RouteDestination{{
+		Cluster:         "outbound|9080|v2|reviews.bookinfo.svc.cluster.local",
+		ServiceFQDN:     sim.Destinations[0].ServiceFQDN,
+		Port:            9080,
+		Subset:          "v2",
+		DestinationRule: "reviews.bookinfo",
+		Weight:          100,
+	}}, sim.Destinations)
+}
+
+func TestSimulateRouteWeightedClusters(t *testing.T) {
+	assert := assert.New(t)
+	dump := fakeConfigDump(t, routeSimulationDump)
+
+	sim, err := SimulateRoute(dump, RouteSimulationRequest{Host: "reviews", Port: 9080, Headers: map[string]string{"end-user": "mike"}})
+	assert.NoError(err)
+	assert.Equal("canary", sim.Route)
+	assert.Len(sim.Destinations, 2)
+	assert.Equal("v1", sim.Destinations[0].Subset)
+	assert.Equal(80, sim.Destinations[0].Weight)
+	assert.Equal("v3", sim.Destinations[1].Subset)
+	assert.Equal(20, sim.Destinations[1].Weight)
+
+	// Unknown hosts fall back on the catch-all virtual host
+	sim, err = SimulateRoute(dump, RouteSimulationRequest{Host: "www.example.com", Port: 9080})
+	assert.NoError(err)
+	assert.Equal("allow_any", sim.VirtualHost)
+	assert.Equal("PassthroughCluster", sim.Destinations[0].Cluster)
+}
+
+func TestSimulateRouteNoMatch(t *testing.T) {
+	assert := assert.New(t)
+	dump := fakeConfigDump(t, routeSimulationDump)
+
+	sim, err := SimulateRoute(dump, RouteSimulationRequest{Host: "ratings", Port: 9080, Path: "/health", Method: "POST"})
+	assert.NoError(err)
+	assert.False(sim.Matched)
+	assert.Equal("ratings.bookinfo.svc.cluster.local:9080", sim.VirtualHost)
+	assert.Equal("No route of the virtual host ratings.bookinfo.svc.cluster.local:9080 matches the request", sim.Reason)
+
+	sim, err = SimulateRoute(dump, RouteSimulationRequest{Host: "ratings", Port: 9090})
+	assert.NoError(err)
+	assert.False(sim.Matched)
+	assert.Equal("No listener on port 9090", sim.Reason)
+}
+
+func TestSimulateRouteTCP(t *testing.T) {
+	assert := assert.New(t)
+	dump := fakeConfigDump(t, routeSimulationDump)
+
+	sim, err := SimulateRoute(dump, RouteSimulationRequest{Host: "10.96.0.5", Port: 3306})
+	assert.NoError(err)
+	assert.True(sim.Matched)
+	assert.Equal("10.96.0.5_3306", sim.Listener)
+	assert.Equal(RouteActionTCPProxy, sim.Action)
+	assert.Equal("outbound|3306||mysqldb.bookinfo.svc.cluster.local", sim.Destinations[0].Cluster)
+}
+
+func TestRouteMatchesRegexOnCaseInsensitivePath(t *testing.T) {
+	assert := assert.New(t)
+
+	// Lowercasing "Ⱥ" makes the path longer, the regex is still matched against the original path
+	match := map[string]interface{}{"case_sensitive": false, "safe_regex": map[string]interface{}{"regex": "/Ⱥ.*"}}
+	assert.True(routeMatches(match, RouteSimulationRequest{Path: "/Ⱥ"}))
+	assert.True(routeMatches(match, RouteSimulationRequest{Path: "/Ⱥ?q=1"}))
+	assert.False(routeMatches(match, RouteSimulationRequest{Path: "/other"}))
+}
@@ -0,0 +1,97 @@
+package models
+
+// TraceAnalytics summarizes a set of traces. All durations are in microseconds, like the trace spans.
+type TraceAnalytics struct {
+	// Number of traces analyzed
+	TracesCount int `json:"tracesCount"`
+	// End-to-end latency of the analyzed traces, measured on their root spans
+	Latency DurationStats `json:"latency"`
+	// Duration and self-time (excluding children) stats per operation
+	Operations []OperationStats `json:"operations"`
+	// Hops sorted by contribution to the end-to-end latency, most contributing first
+	Hops []HopContribution `json:"hops"`
+	// Error hotspots, by span tag, most frequent first
+	ErrorHotspots []ErrorHotspot `json:"errorHotspots"`
+}
+
+// DurationStats holds the average and percentiles of a set of durations, in microseconds
+type DurationStats struct {
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+type OperationStats struct {
+	Service    string        `json:"service"`
+	Operation  string        `json:"operation"`
+	Count      int           `json:"count"`
+	ErrorCount int           `json:"errorCount"`
+	Duration   DurationStats `json:"duration"`
+	SelfTime   DurationStats `json:"selfTime"`
+}
+
+// HopContribution is the time spent on the critical path of the traces by a hop, i.e. the operation of
+// a service called by another service (empty for the trace entry point), excluding the time spent waiting
+// for its own critical downstream calls.
+type HopContribution struct {
+	Source    string `json:"source"`
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+	// Number of traces where the hop appears on the critical path
+	Traces int `json:"traces"`
+	// Average time on the critical path, per trace where the hop appears
+	AvgCriticalTime float64 `json:"avgCriticalTime"`
+	// Share of the total end-to-end latency, between 0 and 1
+	LatencyShare float64 `json:"latencyShare"`
+}
+
+// ErrorHotspot counts the spans in error of a service, for a given value of a span tag
+type ErrorHotspot struct {
+	Service    string  `json:"service"`
+	Tag        string  `json:"tag"`
+	Value      string  `json:"value"`
+	Count      int     `json:"count"`
+	ErrorCount int     `json:"errorCount"`
+	ErrorRate  float64 `json:"errorRate"`
+}
+
+// Status of a call in a trace comparison
+const (
+	CallAdded     = "added"
+	CallMissing   = "missing"
+	CallFanOut    = "fanOut"
+	CallUnchanged = "unchanged"
+)
+
+// TraceComparison compares two traces, or two populations of traces, call by call. Calls are aligned by
+// the service and operation of the span and of its parent. Durations are in microseconds.
+type TraceComparison struct {
+	Baseline  TracesSummary `json:"baseline"`
+	Candidate TracesSummary `json:"candidate"`
+	// Average end-to-end latency of the candidate minus the baseline one
+	LatencyDelta float64 `json:"latencyDelta"`
+	// Compared calls, structural differences first then by decreasing duration delta
+	Calls []CallComparison `json:"calls"`
+}
+
+type TracesSummary struct {
+	Traces     int     `json:"traces"`
+	AvgLatency float64 `json:"avgLatency"`
+}
+
+type CallComparison struct {
+	ParentService   string `json:"parentService"`
+	ParentOperation string `json:"parentOperation"`
+	Service         string `json:"service"`
+	Operation       string `json:"operation"`
+	// One of added, missing, fanOut (the number of calls per trace changed) or unchanged
+	Status string `json:"status"`
+	// Average number of calls per trace
+	BaselineCalls  float64 `json:"baselineCalls"`
+	CandidateCalls float64 `json:"candidateCalls"`
+	// Average duration per call
+	BaselineDuration  float64 `json:"baselineDuration"`
+	CandidateDuration float64 `json:"candidateDuration"`
+	DurationDelta     float64 `json:"durationDelta"`
+}
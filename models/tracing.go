@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+)
 
 type JaegerInfo struct {
 	Enabled              bool     `json:"enabled"`
@@ -11,9 +15,63 @@ type JaegerInfo struct {
 }
 
 type TracingQuery struct {
-	Start       time.Time
-	End         time.Time
-	Tags        map[string]string
-	MinDuration time.Duration
-	Limit       int
+	Start          time.Time
+	End            time.Time
+	Tags           map[string]string
+	MinDuration    time.Duration
+	MaxDuration    time.Duration
+	Operation      string
+	HTTPStatusCode string
+	GRPCStatusCode string
+	ErrorOnly      bool
+	Limit          int
+	// Paged enables cursor-based paging: traces are returned from the most recent, with a cursor to fetch the
+	// next page, instead of being spread over the time range
+	Paged  bool
+	Cursor *TracesCursor
+}
+
+// TracesCursor is the position of a page of traces. Traces are walked from the most recent: the next page
+// ends at the start time of the oldest trace of the previous one, skipping the traces already returned
+// that started at that same time.
+type TracesCursor struct {
+	StartMicros uint64   `json:"t"`
+	TraceIDs    []string `json:"ids,omitempty"`
+}
+
+// Encode returns the cursor as an opaque token
+func (c TracesCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeTracesCursor parses a token returned by TracesCursor.Encode
+func DecodeTracesCursor(token string) (*TracesCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	var cursor TracesCursor
+	if err := json.Unmarshal(b, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
+
+// SearchTags returns the span tags to search for, including the status and error filters
+func (q TracingQuery) SearchTags() map[string]string {
+	tags := make(map[string]string, len(q.Tags)+3)
+	for k, v := range q.Tags {
+		tags[k] = v
+	}
+	if q.HTTPStatusCode != "" {
+		tags["http.status_code"] = q.HTTPStatusCode
+	}
+	if q.GRPCStatusCode != "" {
+		tags["grpc.status_code"] = q.GRPCStatusCode
+	}
+	if q.ErrorOnly {
+		tags["error"] = "true"
+	}
+	return tags
 }
@@ -0,0 +1,188 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+)
+
+// ErrQueryBudgetExceeded is returned for queries issued after the request's QueryBudget is exhausted
+var ErrQueryBudgetExceeded = errors.New("prometheus query budget exceeded")
+
+type queryBudgetKey struct{}
+
+// QueryWarning tells the caller that part of a response is incomplete because some queries
+// timed out or were skipped.
+type QueryWarning struct {
+	Namespace string `json:"namespace,omitempty"`
+	Metric    string `json:"metric,omitempty"`
+	Message   string `json:"message"`
+}
+
+// QueryBudget limits the number of queries and series processed on behalf of a single API request.
+// It travels with the request context and collects warnings for the queries that could not complete.
+// All methods are safe for concurrent use and can be called on a nil budget, which means unlimited.
+type QueryBudget struct {
+	maxQueries int
+	maxSeries  int
+
+	lock     sync.Mutex
+	queries  int
+	series   int
+	warnings []QueryWarning
+}
+
+// NewQueryBudget creates a budget with the given limits. Zero (or negative) values mean no limit.
+func NewQueryBudget(maxQueries, maxSeries int) *QueryBudget {
+	return &QueryBudget{maxQueries: maxQueries, maxSeries: maxSeries}
+}
+
+// NewQueryBudgetContext returns a child of parent carrying a QueryBudget and the overall deadline
+// configured in ExternalServices.Prometheus.QueryBudget. The cancel func must always be called.
+func NewQueryBudgetContext(parent context.Context) (context.Context, context.CancelFunc) {
+	cfg := config.Get().ExternalServices.Prometheus.QueryBudget
+	ctx := WithQueryBudget(parent, NewQueryBudget(cfg.MaxQueries, cfg.MaxSeries))
+	if cfg.Timeout > 0 {
+		return context.WithTimeout(ctx, time.Duration(cfg.Timeout)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WithQueryBudget returns a child of parent carrying the budget
+func WithQueryBudget(parent context.Context, budget *QueryBudget) context.Context {
+	return context.WithValue(parent, queryBudgetKey{}, budget)
+}
+
+// GetQueryBudget returns the budget carried by ctx, or nil if there is none
+func GetQueryBudget(ctx context.Context) *QueryBudget {
+	if ctx == nil {
+		return nil
+	}
+	if budget, ok := ctx.Value(queryBudgetKey{}).(*QueryBudget); ok {
+		return budget
+	}
+	return nil
+}
+
+// Reserve accounts for a new query. It returns ErrQueryBudgetExceeded if the query must not be issued.
+func (b *QueryBudget) Reserve() error {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if (b.maxQueries > 0 && b.queries >= b.maxQueries) || (b.maxSeries > 0 && b.series >= b.maxSeries) {
+		return ErrQueryBudgetExceeded
+	}
+	b.queries++
+	return nil
+}
+
+// Consume accounts for the series returned by a query. The result of the query is still usable, but
+// once the series limit is reached any further Reserve fails.
+func (b *QueryBudget) Consume(series int) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.series += series
+}
+
+// Warn records that the results for the given namespace and metric are incomplete. Either may be empty.
+func (b *QueryBudget) Warn(namespace, metric string, err error) {
+	log.Warningf("Partial results [namespace: %s] [metric: %s]: %v", namespace, metric, err)
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	message := err.Error()
+	for _, w := range b.warnings {
+		if w.Namespace == namespace && w.Metric == metric && w.Message == message {
+			return
+		}
+	}
+	b.warnings = append(b.warnings, QueryWarning{Namespace: namespace, Metric: metric, Message: message})
+}
+
+// Warnings returns a copy of the warnings recorded so far
+func (b *QueryBudget) Warnings() []QueryWarning {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.warnings) == 0 {
+		return nil
+	}
+	warnings := make([]QueryWarning, len(b.warnings))
+	copy(warnings, b.warnings)
+	return warnings
+}
+
+// IsPartialError returns true when err means that a query did not complete because of the request's
+// budget or deadline, as opposed to Prometheus being unavailable. Such errors should produce partial
+// results rather than failing the whole request.
+func IsPartialError(ctx context.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrQueryBudgetExceeded) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	var promErr *prom_v1.Error
+	if errors.As(err, &promErr) && (promErr.Type == prom_v1.ErrTimeout || promErr.Type == prom_v1.ErrCanceled) {
+		return true
+	}
+	return ctx != nil && ctx.Err() != nil
+}
+
+// BudgetedQuery performs an instant query, accounting for the QueryBudget carried by ctx, if any.
+func BudgetedQuery(ctx context.Context, api prom_v1.API, query string, queryTime time.Time) (model.Value, prom_v1.Warnings, error) {
+	budget := GetQueryBudget(ctx)
+	if err := budget.Reserve(); err != nil {
+		return nil, nil, err
+	}
+	result, warnings, err := api.Query(ctx, query, queryTime)
+	if err == nil {
+		budget.Consume(seriesCount(result))
+	}
+	return result, warnings, err
+}
+
+// BudgetedQueryRange performs a range query, accounting for the QueryBudget carried by ctx, if any.
+func BudgetedQueryRange(ctx context.Context, api prom_v1.API, query string, bounds prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	budget := GetQueryBudget(ctx)
+	if err := budget.Reserve(); err != nil {
+		return nil, nil, err
+	}
+	result, warnings, err := api.QueryRange(ctx, query, bounds)
+	if err == nil {
+		budget.Consume(seriesCount(result))
+	}
+	return result, warnings, err
+}
+
+func seriesCount(value model.Value) int {
+	switch v := value.(type) {
+	case model.Vector:
+		return len(v)
+	case model.Matrix:
+		return len(v)
+	case nil:
+		return 0
+	default:
+		return 1
+	}
+}
@@ -1,270 +1,293 @@
 package prometheus
 
 import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
-	kialiConfig "github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus/internalmetrics"
+)
+
+// Query kinds, used to label the cache metrics
+const (
+	cacheKindInstant = "instant"
+	cacheKindRange   = "range"
 )
 
 type (
-	timeInResult struct {
-		queryTime time.Time
-		inResult  model.Vector
+	// PromCache caches the results of instant and range queries. Results are keyed by scope (the Prometheus
+	// server and credentials queried, see scopedAPI), by normalized query, and by query time (or range bounds)
+	// aligned on the cache duration, so that requests issued within the same cache duration window share
+	// their results.
+	PromCache interface {
+		GetInstant(scope, query string, queryTime time.Time) (model.Value, bool)
+		GetRange(scope, query string, bounds prom_v1.Range) (model.Value, bool)
+		SetInstant(scope, query string, queryTime time.Time, value model.Value)
+		SetRange(scope, query string, bounds prom_v1.Range, value model.Value)
 	}
 
-	timeInOutResult struct {
-		queryTime time.Time
-		inResult  model.Vector
-		outResult model.Vector
+	// scopedAPI is the API of a Client along with the identity of the Prometheus server and credentials it
+	// queries, so that clients with different configs (i.e. custom dashboards) don't share cached results
+	scopedAPI struct {
+		prom_v1.API
+		scope string
 	}
 
-	PromCache interface {
-		GetAllRequestRates(namespace string, ratesInterval string, queryTime time.Time) (bool, model.Vector)
-		GetAppRequestRates(namespace, app, ratesInterval string, queryTime time.Time) (bool, model.Vector, model.Vector)
-		GetNamespaceServicesRequestRates(namespace string, ratesInterval string, queryTime time.Time) (bool, model.Vector)
-		GetServiceRequestRates(namespace, service, ratesInterval string, queryTime time.Time) (bool, model.Vector)
-		GetWorkloadRequestRates(namespace, workload, ratesInterval string, queryTime time.Time) (bool, model.Vector, model.Vector)
-		SetAllRequestRates(namespace string, ratesInterval string, queryTime time.Time, inResult model.Vector)
-		SetAppRequestRates(namespace, app, ratesInterval string, queryTime time.Time, inResult model.Vector, outResult model.Vector)
-		SetNamespaceServicesRequestRates(namespace string, ratesInterval string, queryTime time.Time, inResult model.Vector)
-		SetServiceRequestRates(namespace, service, ratesInterval string, queryTime time.Time, inResult model.Vector)
-		SetWorkloadRequestRates(namespace, workload, ratesInterval string, queryTime time.Time, inResult model.Vector, outResult model.Vector)
+	cacheEntry struct {
+		key   string
+		value model.Value
+		size  int
 	}
 
+	// promCacheImpl is a LRU cache bounded by the estimated size of the cached results
 	promCacheImpl struct {
-		cacheDuration          time.Duration
-		cacheExpiration        time.Duration
-		cacheAllRequestRates   map[string]map[string]timeInResult
-		cacheAppRequestRates   map[string]map[string]map[string]timeInOutResult
-		cacheNsSvcRequestRates map[string]map[string]timeInResult
-		cacheSvcRequestRates   map[string]map[string]map[string]timeInResult
-		cacheWkRequestRates    map[string]map[string]map[string]timeInOutResult
-		allRequestRatesLock    sync.RWMutex
-		appRequestRatesLock    sync.RWMutex
-		nsSvcRequestRatesLock  sync.RWMutex
-		svcRequestRatesLock    sync.RWMutex
-		wkRequestRatesLock     sync.RWMutex
+		cacheDuration   time.Duration
+		cacheExpiration time.Duration
+		maxSize         int
+
+		lock    sync.Mutex
+		entries map[string]*list.Element
+		lru     *list.List // front is the most recently used
+		size    int
 	}
 )
 
 func NewPromCache() PromCache {
-	kConfig := kialiConfig.Get()
+	kConfig := config.Get()
 
 	cacheDuration := time.Duration(kConfig.ExternalServices.Prometheus.CacheDuration) * time.Second
 	cacheExpiration := time.Duration(kConfig.ExternalServices.Prometheus.CacheExpiration) * time.Second
-	promCacheImpl := promCacheImpl{
-		cacheDuration:          cacheDuration,
-		cacheExpiration:        cacheExpiration,
-		cacheAllRequestRates:   make(map[string]map[string]timeInResult),
-		cacheAppRequestRates:   make(map[string]map[string]map[string]timeInOutResult),
-		cacheNsSvcRequestRates: make(map[string]map[string]timeInResult),
-		cacheSvcRequestRates:   make(map[string]map[string]map[string]timeInResult),
-		cacheWkRequestRates:    make(map[string]map[string]map[string]timeInOutResult),
-	}
+	promCacheImpl := newPromCache(cacheDuration, cacheExpiration, kConfig.ExternalServices.Prometheus.CacheMaxSize)
 
 	go promCacheImpl.watchExpiration()
 
-	return &promCacheImpl
+	return promCacheImpl
 }
 
-func (c *promCacheImpl) GetAllRequestRates(namespace string, ratesInterval string, queryTime time.Time) (bool, model.Vector) {
-	defer c.allRequestRatesLock.RUnlock()
-	c.allRequestRatesLock.RLock()
-
-	if nsRates, okNs := c.cacheAllRequestRates[namespace]; okNs {
-		if rtInterval, okRt := nsRates[ratesInterval]; okRt {
-			if !queryTime.Before(rtInterval.queryTime) && queryTime.Sub(rtInterval.queryTime) < c.cacheDuration {
-				log.Tracef("[Prom Cache] GetAllRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
-				return true, rtInterval.inResult
-			}
-		}
+func newPromCache(cacheDuration, cacheExpiration time.Duration, maxSize int) *promCacheImpl {
+	return &promCacheImpl{
+		cacheDuration:   cacheDuration,
+		cacheExpiration: cacheExpiration,
+		maxSize:         maxSize,
+		entries:         make(map[string]*list.Element),
+		lru:             list.New(),
 	}
-	return false, nil
 }
 
-func (c *promCacheImpl) SetAllRequestRates(namespace string, ratesInterval string, queryTime time.Time, inResult model.Vector) {
-	defer c.allRequestRatesLock.Unlock()
-	c.allRequestRatesLock.Lock()
-
-	if _, okNs := c.cacheAllRequestRates[namespace]; !okNs {
-		c.cacheAllRequestRates[namespace] = make(map[string]timeInResult)
-	}
+func (c *promCacheImpl) GetInstant(scope, query string, queryTime time.Time) (model.Value, bool) {
+	return c.get(cacheKindInstant, c.instantKey(scope, query, queryTime))
+}
 
-	c.cacheAllRequestRates[namespace][ratesInterval] = timeInResult{
-		queryTime: queryTime,
-		inResult:  inResult,
-	}
-	log.Tracef("[Prom Cache] SetAllRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
+func (c *promCacheImpl) GetRange(scope, query string, bounds prom_v1.Range) (model.Value, bool) {
+	return c.get(cacheKindRange, c.rangeKey(scope, query, bounds))
 }
 
-func (c *promCacheImpl) GetAppRequestRates(namespace string, app string, ratesInterval string, queryTime time.Time) (bool, model.Vector, model.Vector) {
-	defer c.appRequestRatesLock.RUnlock()
-	c.appRequestRatesLock.RLock()
-
-	if nsRates, okNs := c.cacheAppRequestRates[namespace]; okNs {
-		if appInterval, okApp := nsRates[app]; okApp {
-			if rtInterval, okRt := appInterval[ratesInterval]; okRt {
-				if !queryTime.Before(rtInterval.queryTime) && queryTime.Sub(rtInterval.queryTime) < c.cacheDuration {
-					log.Tracef("[Prom Cache] GetAppRequestRates [namespace: %s] [app: %s] [ratesInterval: %s] [queryTime: %s]", namespace, app, ratesInterval, queryTime.String())
-					return true, rtInterval.inResult, rtInterval.outResult
-				}
-			}
-		}
-	}
-	return false, nil, nil
+func (c *promCacheImpl) SetInstant(scope, query string, queryTime time.Time, value model.Value) {
+	c.set(c.instantKey(scope, query, queryTime), value)
 }
 
-func (c *promCacheImpl) SetAppRequestRates(namespace string, app string, ratesInterval string, queryTime time.Time, inResult model.Vector, outResult model.Vector) {
-	defer c.appRequestRatesLock.Unlock()
-	c.appRequestRatesLock.Lock()
+func (c *promCacheImpl) SetRange(scope, query string, bounds prom_v1.Range, value model.Value) {
+	c.set(c.rangeKey(scope, query, bounds), value)
+}
 
-	if _, okNs := c.cacheAppRequestRates[namespace]; !okNs {
-		c.cacheAppRequestRates[namespace] = make(map[string]map[string]timeInOutResult)
-	}
+func (c *promCacheImpl) instantKey(scope, query string, queryTime time.Time) string {
+	return fmt.Sprintf("%s/%s@%d", scope, normalizeQuery(query), c.align(queryTime))
+}
 
-	if _, okApp := c.cacheAppRequestRates[namespace][app]; !okApp {
-		c.cacheAppRequestRates[namespace][app] = make(map[string]timeInOutResult)
-	}
+func (c *promCacheImpl) rangeKey(scope, query string, bounds prom_v1.Range) string {
+	return fmt.Sprintf("%s/%s@%d:%d:%d", scope, normalizeQuery(query), c.align(bounds.Start), c.align(bounds.End), bounds.Step)
+}
 
-	c.cacheAppRequestRates[namespace][app][ratesInterval] = timeInOutResult{
-		queryTime: queryTime,
-		inResult:  inResult,
-		outResult: outResult,
+func (c *promCacheImpl) align(t time.Time) int64 {
+	if c.cacheDuration <= 0 {
+		return t.UnixNano()
 	}
-	log.Tracef("[Prom Cache] SetAppRequestRates [namespace: %s] [app: %s] [ratesInterval: %s] [queryTime: %s]", namespace, app, ratesInterval, queryTime.String())
+	return t.Truncate(c.cacheDuration).Unix()
 }
 
-func (c *promCacheImpl) GetNamespaceServicesRequestRates(namespace string, ratesInterval string, queryTime time.Time) (bool, model.Vector) {
-	defer c.nsSvcRequestRatesLock.RUnlock()
-	c.nsSvcRequestRatesLock.RLock()
+func (c *promCacheImpl) get(kind, key string) (model.Value, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
 
-	if nsRates, okNs := c.cacheNsSvcRequestRates[namespace]; okNs {
-		if rtInterval, okRt := nsRates[ratesInterval]; okRt {
-			if !queryTime.Before(rtInterval.queryTime) && queryTime.Sub(rtInterval.queryTime) < c.cacheDuration {
-				log.Tracef("[Prom Cache] GetNamespaceServicesRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
-				return true, rtInterval.inResult
-			}
-		}
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		internalmetrics.GetPrometheusCacheHitsMetric(kind).Inc()
+		log.Tracef("[Prom Cache] Hit [%s]", key)
+		return elem.Value.(*cacheEntry).value, true
 	}
-	return false, nil
+	internalmetrics.GetPrometheusCacheMissesMetric(kind).Inc()
+	return nil, false
 }
 
-func (c *promCacheImpl) SetNamespaceServicesRequestRates(namespace string, ratesInterval string, queryTime time.Time, inResult model.Vector) {
-	defer c.nsSvcRequestRatesLock.Unlock()
-	c.nsSvcRequestRatesLock.Lock()
-
-	if _, okNs := c.cacheNsSvcRequestRates[namespace]; !okNs {
-		c.cacheNsSvcRequestRates[namespace] = make(map[string]timeInResult)
+func (c *promCacheImpl) set(key string, value model.Value) {
+	size := len(key) + valueSize(value)
+	if c.maxSize > 0 && size > c.maxSize {
+		log.Tracef("[Prom Cache] Result too large to be cached [%s] [size: %d]", key, size)
+		return
 	}
 
-	c.cacheNsSvcRequestRates[namespace][ratesInterval] = timeInResult{
-		queryTime: queryTime,
-		inResult:  inResult,
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
 	}
-	log.Tracef("[Prom Cache] SetNamespaceServicesRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
-}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, value: value, size: size})
+	c.size += size
 
-func (c *promCacheImpl) GetServiceRequestRates(namespace string, service string, ratesInterval string, queryTime time.Time) (bool, model.Vector) {
-	defer c.svcRequestRatesLock.RUnlock()
-	c.svcRequestRatesLock.RLock()
-
-	if nsRates, okNs := c.cacheSvcRequestRates[namespace]; okNs {
-		if svcInterval, okSvc := nsRates[service]; okSvc {
-			if rtInterval, okRt := svcInterval[ratesInterval]; okRt {
-				if !queryTime.Before(rtInterval.queryTime) && queryTime.Sub(rtInterval.queryTime) < c.cacheDuration {
-					log.Tracef("[Prom Cache] GetServiceRequestRates [namespace: %s] [service: %s] [ratesInterval: %s] [queryTime: %s]", namespace, service, ratesInterval, queryTime.String())
-					return true, rtInterval.inResult
-				}
-			}
-		}
+	for c.maxSize > 0 && c.size > c.maxSize {
+		c.removeElement(c.lru.Back())
 	}
-	return false, nil
+	internalmetrics.SetPrometheusCacheSize(c.size)
+	log.Tracef("[Prom Cache] Set [%s] [size: %d]", key, size)
 }
 
-func (c *promCacheImpl) SetServiceRequestRates(namespace string, service string, ratesInterval string, queryTime time.Time, inResult model.Vector) {
-	defer c.svcRequestRatesLock.Unlock()
-	c.svcRequestRatesLock.Lock()
+func (c *promCacheImpl) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*cacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
 
-	if _, okNs := c.cacheSvcRequestRates[namespace]; !okNs {
-		c.cacheSvcRequestRates[namespace] = make(map[string]map[string]timeInResult)
+// Expiration is done globally, this cache is designed as short term, so in the worst case it would populated the queries
+// Doing an expiration check per item is costly and it's not necessary in this particular context
+func (c *promCacheImpl) watchExpiration() {
+	for {
+		time.Sleep(c.cacheExpiration)
+		c.lock.Lock()
+		c.entries = make(map[string]*list.Element)
+		c.lru.Init()
+		c.size = 0
+		internalmetrics.SetPrometheusCacheSize(c.size)
+		c.lock.Unlock()
+		log.Tracef("[Prom Cache] Expired")
 	}
+}
 
-	if _, okSvc := c.cacheSvcRequestRates[namespace][service]; !okSvc {
-		c.cacheSvcRequestRates[namespace][service] = make(map[string]timeInResult)
+// normalizeQuery collapses the whitespaces found outside of string literals, so that queries
+// differing only in formatting share their cache entries.
+func normalizeQuery(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+	var quote rune
+	escaped := false
+	pendingSpace := false
+	for _, r := range query {
+		if quote != 0 {
+			sb.WriteRune(r)
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+			continue
+		}
+		if r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			pendingSpace = sb.Len() > 0
+			continue
+		}
+		if pendingSpace {
+			sb.WriteByte(' ')
+			pendingSpace = false
+		}
+		if r == '"' || r == '\'' || r == '`' {
+			quote = r
+		}
+		sb.WriteRune(r)
 	}
+	return sb.String()
+}
 
-	c.cacheSvcRequestRates[namespace][service][ratesInterval] = timeInResult{
-		queryTime: queryTime,
-		inResult:  inResult,
+// valueSize estimates the memory used by a query result, in bytes
+func valueSize(value model.Value) int {
+	const sampleSize = 16 // timestamp + value
+	metricSize := func(m model.Metric) int {
+		size := 0
+		for k, v := range m {
+			size += len(k) + len(v)
+		}
+		return size
 	}
-	log.Tracef("[Prom Cache] SetServiceRequestRates [namespace: %s] [service: %s] [ratesInterval: %s] [queryTime: %s]", namespace, service, ratesInterval, queryTime.String())
-}
 
-func (c *promCacheImpl) GetWorkloadRequestRates(namespace string, workload string, ratesInterval string, queryTime time.Time) (bool, model.Vector, model.Vector) {
-	defer c.wkRequestRatesLock.RUnlock()
-	c.wkRequestRatesLock.RLock()
-
-	if nsRates, okNs := c.cacheWkRequestRates[namespace]; okNs {
-		if wkInterval, okWk := nsRates[workload]; okWk {
-			if rtInterval, okRt := wkInterval[ratesInterval]; okRt {
-				if !queryTime.Before(rtInterval.queryTime) && queryTime.Sub(rtInterval.queryTime) < c.cacheDuration {
-					log.Tracef("[Prom Cache] GetWorkloadRequestRates [namespace: %s] [workload: %s] [ratesInterval: %s] [queryTime: %s]", namespace, workload, ratesInterval, queryTime.String())
-					return true, rtInterval.inResult, rtInterval.outResult
-				}
-			}
+	switch v := value.(type) {
+	case model.Vector:
+		size := 0
+		for _, s := range v {
+			size += metricSize(s.Metric) + sampleSize
+		}
+		return size
+	case model.Matrix:
+		size := 0
+		for _, s := range v {
+			size += metricSize(s.Metric) + sampleSize*len(s.Values)
 		}
+		return size
+	case *model.Scalar:
+		return sampleSize
+	case *model.String:
+		return sampleSize + len(v.Value)
+	default:
+		return 0
 	}
-	return false, nil, nil
 }
 
-func (c *promCacheImpl) SetWorkloadRequestRates(namespace string, workload string, ratesInterval string, queryTime time.Time, inResult model.Vector, outResult model.Vector) {
-	defer c.wkRequestRatesLock.Unlock()
-	c.wkRequestRatesLock.Lock()
+// cacheScope returns the identity of a Prometheus server and credentials, hashed to keep the credentials
+// out of the cache keys
+func cacheScope(cfg config.PrometheusConfig, auth config.Auth) string {
+	identity := fmt.Sprintf("%s|%s|%s|%s|%s|%v", cfg.URL, auth.Type, auth.Username, auth.Password, auth.Token, cfg.CustomHeaders)
+	hash := sha256.Sum256([]byte(identity))
+	return hex.EncodeToString(hash[:8])
+}
 
-	if _, okNs := c.cacheWkRequestRates[namespace]; !okNs {
-		c.cacheWkRequestRates[namespace] = make(map[string]map[string]timeInOutResult)
+// getCache returns the cache and the scope of the API's results, or a nil cache when they must not be
+// cached: the cache is disabled, or the API doesn't come from a Client (i.e. an injected mock)
+func getCache(api prom_v1.API) (PromCache, string) {
+	if scoped, ok := api.(scopedAPI); ok && promCache != nil {
+		return promCache, scoped.scope
 	}
+	return nil, ""
+}
 
-	if _, okApp := c.cacheWkRequestRates[namespace][workload]; !okApp {
-		c.cacheWkRequestRates[namespace][workload] = make(map[string]timeInOutResult)
+// CachedQuery performs an instant query, serving it from the Prometheus cache when enabled. Queries that
+// are not in the cache are accounted for in the QueryBudget carried by ctx, if any.
+func CachedQuery(ctx context.Context, api prom_v1.API, query string, queryTime time.Time) (model.Value, prom_v1.Warnings, error) {
+	cache, scope := getCache(api)
+	if cache != nil {
+		if result, ok := cache.GetInstant(scope, query, queryTime); ok {
+			return result, nil, nil
+		}
 	}
-
-	c.cacheWkRequestRates[namespace][workload][ratesInterval] = timeInOutResult{
-		queryTime: queryTime,
-		inResult:  inResult,
-		outResult: outResult,
+	result, warnings, err := BudgetedQuery(ctx, api, query, queryTime)
+	if err == nil && cache != nil {
+		cache.SetInstant(scope, query, queryTime, result)
 	}
-	log.Tracef("[Prom Cache] SetAppRequestRates [namespace: %s] [workload: %s] [ratesInterval: %s] [queryTime: %s]", namespace, workload, ratesInterval, queryTime.String())
+	return result, warnings, err
 }
 
-// Expiration is done globally, this cache is designed as short term, so in the worst case it would populated the queries
-// Doing an expiration check per item is costly and it's not necessary in this particular context
-func (c *promCacheImpl) watchExpiration() {
-	for {
-		time.Sleep(c.cacheExpiration)
-		c.allRequestRatesLock.Lock()
-		c.cacheAllRequestRates = make(map[string]map[string]timeInResult)
-		c.allRequestRatesLock.Unlock()
-
-		c.appRequestRatesLock.Lock()
-		c.cacheAppRequestRates = make(map[string]map[string]map[string]timeInOutResult)
-		c.appRequestRatesLock.Unlock()
-
-		c.nsSvcRequestRatesLock.Lock()
-		c.cacheNsSvcRequestRates = make(map[string]map[string]timeInResult)
-		c.nsSvcRequestRatesLock.Unlock()
-
-		c.svcRequestRatesLock.Lock()
-		c.cacheSvcRequestRates = make(map[string]map[string]map[string]timeInResult)
-		c.svcRequestRatesLock.Unlock()
-
-		c.wkRequestRatesLock.Lock()
-		c.cacheWkRequestRates = make(map[string]map[string]map[string]timeInOutResult)
-		c.wkRequestRatesLock.Unlock()
-		log.Tracef("[Prom Cache] Expired")
+// CachedQueryRange performs a range query, serving it from the Prometheus cache when enabled. Queries that
+// are not in the cache are accounted for in the QueryBudget carried by ctx, if any.
+func CachedQueryRange(ctx context.Context, api prom_v1.API, query string, bounds prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	cache, scope := getCache(api)
+	if cache != nil {
+		if result, ok := cache.GetRange(scope, query, bounds); ok {
+			return result, nil, nil
+		}
+	}
+	result, warnings, err := BudgetedQueryRange(ctx, api, query, bounds)
+	if err == nil && cache != nil {
+		cache.SetRange(scope, query, bounds, result)
 	}
+	return result, warnings, err
 }
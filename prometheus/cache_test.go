@@ -0,0 +1,111 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+)
+
+func fakeVector(value float64) model.Vector {
+	return model.Vector{
+		&model.Sample{Metric: model.Metric{"destination_service_name": "reviews"}, Value: model.SampleValue(value)},
+	}
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`sum(rate(foo{a="b"}[1m])) by (x)`, normalizeQuery("  sum(rate(foo{a=\"b\"}[1m]))\n\t  by   (x) "))
+	// whitespaces within string literals are significant
+	assert.Equal(`foo{a="b  c"}`, normalizeQuery(`foo{a="b  c"}`))
+	assert.Equal(`foo{a="b \"  c"} or bar`, normalizeQuery(`foo{a="b \"  c"}   or bar`))
+}
+
+func TestPromCacheInstantAlignedOnDuration(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newPromCache(10*time.Second, time.Minute, 0)
+	queryTime := time.Unix(1000, 0)
+	cache.SetInstant("scope", "sum(foo)", queryTime, fakeVector(1))
+
+	result, ok := cache.GetInstant("scope", " sum(foo)\n", queryTime.Add(5*time.Second))
+	assert.True(ok)
+	assert.Equal(fakeVector(1), result)
+
+	_, ok = cache.GetInstant("scope", "sum(foo)", queryTime.Add(10*time.Second))
+	assert.False(ok)
+}
+
+func TestPromCacheRangeKeyedOnStep(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newPromCache(10*time.Second, time.Minute, 0)
+	bounds := prom_v1.Range{Start: time.Unix(1000, 0), End: time.Unix(1600, 0), Step: 15 * time.Second}
+	matrix := model.Matrix{&model.SampleStream{Metric: model.Metric{"a": "b"}, Values: []model.SamplePair{{Timestamp: 1000, Value: 1}}}}
+	cache.SetRange("scope", "foo", bounds, matrix)
+
+	result, ok := cache.GetRange("scope", "foo", bounds)
+	assert.True(ok)
+	assert.Equal(matrix, result)
+
+	bounds.Step = 30 * time.Second
+	_, ok = cache.GetRange("scope", "foo", bounds)
+	assert.False(ok)
+}
+
+func TestPromCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	assert := assert.New(t)
+
+	queryTime := time.Unix(1000, 0)
+	entrySize := len(newPromCache(time.Second, time.Minute, 0).instantKey("scope", "q1", queryTime)) + valueSize(fakeVector(1))
+	cache := newPromCache(time.Second, time.Minute, 2*entrySize)
+
+	cache.SetInstant("scope", "q1", queryTime, fakeVector(1))
+	cache.SetInstant("scope", "q2", queryTime, fakeVector(2))
+	// q1 becomes the most recently used
+	_, ok := cache.GetInstant("scope", "q1", queryTime)
+	assert.True(ok)
+
+	cache.SetInstant("scope", "q3", queryTime, fakeVector(3))
+	_, ok = cache.GetInstant("scope", "q2", queryTime)
+	assert.False(ok)
+	_, ok = cache.GetInstant("scope", "q1", queryTime)
+	assert.True(ok)
+	_, ok = cache.GetInstant("scope", "q3", queryTime)
+	assert.True(ok)
+	assert.Equal(2*entrySize, cache.size)
+
+	// results larger than the whole cache are not cached
+	cache.SetInstant("scope", "q4", queryTime, append(fakeVector(1), append(fakeVector(2), fakeVector(3)...)...))
+	_, ok = cache.GetInstant("scope", "q4", queryTime)
+	assert.False(ok)
+	assert.Equal(2*entrySize, cache.size)
+}
+
+func TestPromCacheKeyedOnScope(t *testing.T) {
+	assert := assert.New(t)
+
+	cache := newPromCache(10*time.Second, time.Minute, 0)
+	queryTime := time.Unix(1000, 0)
+	cache.SetInstant("main", "sum(foo)", queryTime, fakeVector(1))
+
+	_, ok := cache.GetInstant("dashboards", "sum(foo)", queryTime)
+	assert.False(ok)
+}
+
+func TestCacheScopeDependsOnServerAndCredentials(t *testing.T) {
+	assert := assert.New(t)
+
+	cfg := config.PrometheusConfig{URL: "http://prometheus:9090"}
+	scope := cacheScope(cfg, config.Auth{Type: config.AuthTypeBearer, Token: "a"})
+	assert.Equal(scope, cacheScope(cfg, config.Auth{Type: config.AuthTypeBearer, Token: "a"}))
+	assert.NotEqual(scope, cacheScope(cfg, config.Auth{Type: config.AuthTypeBearer, Token: "b"}))
+
+	other := config.PrometheusConfig{URL: "http://thanos:9090"}
+	assert.NotEqual(scope, cacheScope(other, config.Auth{Type: config.AuthTypeBearer, Token: "a"}))
+}
@@ -27,6 +27,7 @@ var (
 
 // ClientInterface for mocks (only mocked function are necessary here)
 type ClientInterface interface {
+	FetchExemplars(metricName, labels string, q *RangeQuery) ([]ExemplarQueryResult, error)
 	FetchHistogramRange(metricName, labels, grouping string, q *RangeQuery) Histogram
 	FetchHistogramValues(metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, error)
 	FetchRange(metricName, labels, grouping, aggregator string, q *RangeQuery) Metric
@@ -111,10 +112,18 @@ func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
 	if err != nil {
 		return nil, errors.NewServiceUnavailable(err.Error())
 	}
-	client := Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}
+	client := Client{p8s: p8s, api: scopedAPI{API: prom_v1.NewAPI(p8s), scope: cacheScope(cfg, auth)}, ctx: context.Background()}
 	return &client, nil
 }
 
+// WithContext returns a shallow copy of the client whose queries are bound to ctx, so that the request
+// deadline and the QueryBudget carried by ctx, if any, apply to them.
+func (in *Client) WithContext(ctx context.Context) *Client {
+	client := *in
+	client.ctx = ctx
+	return &client
+}
+
 // Inject allows for replacing the API with a mock For testing
 func (in *Client) Inject(api prom_v1.API) {
 	in.api = api
@@ -127,19 +136,7 @@ func (in *Client) Inject(api prom_v1.API) {
 // Returns (rates, error)
 func (in *Client) GetAllRequestRates(namespace string, ratesInterval string, queryTime time.Time) (model.Vector, error) {
 	log.Tracef("GetAllRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
-	if promCache != nil {
-		if isCached, result := promCache.GetAllRequestRates(namespace, ratesInterval, queryTime); isCached {
-			return result, nil
-		}
-	}
-	result, err := getAllRequestRates(in.ctx, in.api, namespace, queryTime, ratesInterval)
-	if err != nil {
-		return result, err
-	}
-	if promCache != nil {
-		promCache.SetAllRequestRates(namespace, ratesInterval, queryTime, result)
-	}
-	return result, nil
+	return getAllRequestRates(in.ctx, in.api, namespace, queryTime, ratesInterval)
 }
 
 // GetNamespaceServicesRequestRates queries Prometheus to fetch request counter rates, over a time interval, limited to
@@ -149,19 +146,7 @@ func (in *Client) GetAllRequestRates(namespace string, ratesInterval string, que
 // Returns (rates, error)
 func (in *Client) GetNamespaceServicesRequestRates(namespace string, ratesInterval string, queryTime time.Time) (model.Vector, error) {
 	log.Tracef("GetNamespaceServicesRequestRates [namespace: %s] [ratesInterval: %s] [queryTime: %s]", namespace, ratesInterval, queryTime.String())
-	if promCache != nil {
-		if isCached, result := promCache.GetNamespaceServicesRequestRates(namespace, ratesInterval, queryTime); isCached {
-			return result, nil
-		}
-	}
-	result, err := getNamespaceServicesRequestRates(in.ctx, in.api, namespace, queryTime, ratesInterval)
-	if err != nil {
-		return result, err
-	}
-	if promCache != nil {
-		promCache.SetNamespaceServicesRequestRates(namespace, ratesInterval, queryTime, result)
-	}
-	return result, nil
+	return getNamespaceServicesRequestRates(in.ctx, in.api, namespace, queryTime, ratesInterval)
 }
 
 // GetServiceRequestRates queries Prometheus to fetch request counters rates over a time interval
@@ -171,19 +156,7 @@ func (in *Client) GetNamespaceServicesRequestRates(namespace string, ratesInterv
 // Returns (in, error)
 func (in *Client) GetServiceRequestRates(namespace, service, ratesInterval string, queryTime time.Time) (model.Vector, error) {
 	log.Tracef("GetServiceRequestRates [namespace: %s] [service: %s] [ratesInterval: %s] [queryTime: %s]", namespace, service, ratesInterval, queryTime.String())
-	if promCache != nil {
-		if isCached, result := promCache.GetServiceRequestRates(namespace, service, ratesInterval, queryTime); isCached {
-			return result, nil
-		}
-	}
-	result, err := getServiceRequestRates(in.ctx, in.api, namespace, service, queryTime, ratesInterval)
-	if err != nil {
-		return result, err
-	}
-	if promCache != nil {
-		promCache.SetServiceRequestRates(namespace, service, ratesInterval, queryTime, result)
-	}
-	return result, nil
+	return getServiceRequestRates(in.ctx, in.api, namespace, service, queryTime, ratesInterval)
 }
 
 // GetAppRequestRates queries Prometheus to fetch request counters rates over a time interval
@@ -193,19 +166,7 @@ func (in *Client) GetServiceRequestRates(namespace, service, ratesInterval strin
 // Returns (in, out, error)
 func (in *Client) GetAppRequestRates(namespace, app, ratesInterval string, queryTime time.Time) (model.Vector, model.Vector, error) {
 	log.Tracef("GetAppRequestRates [namespace: %s] [app: %s] [ratesInterval: %s] [queryTime: %s]", namespace, app, ratesInterval, queryTime.String())
-	if promCache != nil {
-		if isCached, inResult, outResult := promCache.GetAppRequestRates(namespace, app, ratesInterval, queryTime); isCached {
-			return inResult, outResult, nil
-		}
-	}
-	inResult, outResult, err := getItemRequestRates(in.ctx, in.api, namespace, app, "app", queryTime, ratesInterval)
-	if err != nil {
-		return inResult, outResult, err
-	}
-	if promCache != nil {
-		promCache.SetAppRequestRates(namespace, app, ratesInterval, queryTime, inResult, outResult)
-	}
-	return inResult, outResult, nil
+	return getItemRequestRates(in.ctx, in.api, namespace, app, "app", queryTime, ratesInterval)
 }
 
 // GetWorkloadRequestRates queries Prometheus to fetch request counters rates over a time interval
@@ -215,19 +176,7 @@ func (in *Client) GetAppRequestRates(namespace, app, ratesInterval string, query
 // Returns (in, out, error)
 func (in *Client) GetWorkloadRequestRates(namespace, workload, ratesInterval string, queryTime time.Time) (model.Vector, model.Vector, error) {
 	log.Tracef("GetWorkloadRequestRates [namespace: %s] [workload: %s] [ratesInterval: %s] [queryTime: %s]", namespace, workload, ratesInterval, queryTime.String())
-	if promCache != nil {
-		if isCached, inResult, outResult := promCache.GetWorkloadRequestRates(namespace, workload, ratesInterval, queryTime); isCached {
-			return inResult, outResult, nil
-		}
-	}
-	inResult, outResult, err := getItemRequestRates(in.ctx, in.api, namespace, workload, "workload", queryTime, ratesInterval)
-	if err != nil {
-		return inResult, outResult, err
-	}
-	if promCache != nil {
-		promCache.SetWorkloadRequestRates(namespace, workload, ratesInterval, queryTime, inResult, outResult)
-	}
-	return inResult, outResult, nil
+	return getItemRequestRates(in.ctx, in.api, namespace, workload, "workload", queryTime, ratesInterval)
 }
 
 // FetchRange fetches a simple metric (gauge or counter) in given range
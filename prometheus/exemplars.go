@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/log"
+)
+
+// Exemplar is a sample of an histogram bucket, usually labelled with the ID of the trace it comes from
+type Exemplar struct {
+	Labels    model.LabelSet    `json:"labels"`
+	Value     model.SampleValue `json:"value"`
+	Timestamp model.Time        `json:"timestamp"`
+}
+
+// ExemplarQueryResult holds the exemplars of a series
+type ExemplarQueryResult struct {
+	SeriesLabels model.LabelSet `json:"seriesLabels"`
+	Exemplars    []Exemplar     `json:"exemplars"`
+}
+
+type exemplarsResponse struct {
+	Status    string                `json:"status"`
+	Data      []ExemplarQueryResult `json:"data"`
+	ErrorType string                `json:"errorType"`
+	Error     string                `json:"error"`
+}
+
+// FetchExemplars fetches the exemplars stored on the buckets of an histogram, in given range. It requires
+// Prometheus 2.26+ with the exemplar storage enabled. The client_golang API doesn't support this endpoint
+// yet, so it is queried directly.
+func (in *Client) FetchExemplars(metricName, labels string, q *RangeQuery) ([]ExemplarQueryResult, error) {
+	if in.p8s == nil {
+		return []ExemplarQueryResult{}, nil
+	}
+	budget := GetQueryBudget(in.ctx)
+	if err := budget.Reserve(); err != nil {
+		return nil, err
+	}
+
+	u := in.p8s.URL("/api/v1/query_exemplars", nil)
+	params := u.Query()
+	params.Set("query", fmt.Sprintf("%s_bucket%s", metricName, labels))
+	params.Set("start", formatTime(q.Start))
+	params.Set("end", formatTime(q.End))
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, body, err := in.p8s.Do(in.ctx, req)
+	if err != nil {
+		return nil, errors.NewServiceUnavailable(err.Error())
+	}
+	var result exemplarsResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("cannot read exemplars (status %d): %v", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("exemplars query failed: %s: %s", result.ErrorType, result.Error)
+	}
+	budget.Consume(len(result.Data))
+	log.Tracef("[Prom] FetchExemplars: %d series with exemplars for %s", len(result.Data), metricName)
+	return result.Data, nil
+}
+
+func formatTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', -1, 64)
+}
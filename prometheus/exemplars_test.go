@@ -0,0 +1,57 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/api"
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeExemplarsClient(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	p8s, err := api.NewClient(api.Config{Address: server.URL})
+	assert.NoError(t, err)
+	return &Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}, server.Close
+}
+
+func TestFetchExemplars(t *testing.T) {
+	assert := assert.New(t)
+
+	client, closeServer := fakeExemplarsClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/api/v1/query_exemplars", r.URL.Path)
+		assert.Equal(`istio_request_duration_milliseconds_bucket{app="reviews"}`, r.URL.Query().Get("query"))
+		assert.Equal("1000", r.URL.Query().Get("start"))
+		assert.Equal("1600.5", r.URL.Query().Get("end"))
+		_, _ = w.Write([]byte(`{"status": "success", "data": [{
+			"seriesLabels": {"app": "reviews", "le": "25"},
+			"exemplars": [{"labels": {"trace_id": "0af7651916cd43dd8448eb211c80319c"}, "value": "12", "timestamp": 1500.25}]
+		}]}`))
+	})
+	defer closeServer()
+
+	q := RangeQuery{Range: prom_v1.Range{Start: time.Unix(1000, 0), End: time.Unix(1600, 500000000)}}
+	exemplars, err := client.FetchExemplars("istio_request_duration_milliseconds", `{app="reviews"}`, &q)
+	assert.NoError(err)
+	assert.Equal([]ExemplarQueryResult{{
+		SeriesLabels: model.LabelSet{"app": "reviews", "le": "25"},
+		Exemplars:    []Exemplar{{Labels: model.LabelSet{"trace_id": "0af7651916cd43dd8448eb211c80319c"}, Value: 12, Timestamp: 1500250}},
+	}}, exemplars)
+}
+
+func TestFetchExemplarsError(t *testing.T) {
+	client, closeServer := fakeExemplarsClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": "error", "errorType": "bad_data", "error": "exemplar storage is disabled"}`))
+	})
+	defer closeServer()
+
+	q := RangeQuery{Range: prom_v1.Range{Start: time.Unix(1000, 0), End: time.Unix(1600, 0)}}
+	_, err := client.FetchExemplars("istio_request_duration_milliseconds", "", &q)
+	assert.EqualError(t, err, "exemplars query failed: bad_data: exemplar storage is disabled")
+}
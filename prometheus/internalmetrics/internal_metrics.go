@@ -23,6 +23,8 @@ const (
 	labelService          = "service"
 	labelType             = "type"
 	labelName             = "name"
+	labelQueryKind        = "query_kind"
+	labelCache            = "cache"
 )
 
 // MetricsType defines all of Kiali's own internal metrics.
@@ -38,6 +40,12 @@ type MetricsType struct {
 	CheckerProcessingTime          *prometheus.HistogramVec
 	ValidationProcessingTime       *prometheus.HistogramVec
 	SingleValidationProcessingTime *prometheus.HistogramVec
+	PrometheusCacheHits            *prometheus.CounterVec
+	PrometheusCacheMisses          *prometheus.CounterVec
+	PrometheusCacheSize            *prometheus.GaugeVec
+	KialiCacheHits                 *prometheus.CounterVec
+	KialiCacheMisses               *prometheus.CounterVec
+	KialiCacheSyncLag              *prometheus.HistogramVec
 }
 
 // Metrics contains all of Kiali's own internal metrics.
@@ -121,6 +129,49 @@ var Metrics = MetricsType{
 		},
 		[]string{labelNamespace, labelType, labelName},
 	),
+	PrometheusCacheHits: prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kiali_prometheus_cache_hits_total",
+			Help: "Counts the total number of Prometheus queries served from the cache.",
+		},
+		[]string{labelQueryKind},
+	),
+	PrometheusCacheMisses: prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kiali_prometheus_cache_misses_total",
+			Help: "Counts the total number of Prometheus queries not found in the cache.",
+		},
+		[]string{labelQueryKind},
+	),
+	PrometheusCacheSize: prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kiali_prometheus_cache_size_bytes",
+			Help: "The estimated size of the Prometheus query results held in the cache.",
+		},
+		[]string{},
+	),
+	KialiCacheHits: prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kiali_cache_hits_total",
+			Help: "Counts the total number of lookups served from the Kiali cache.",
+		},
+		[]string{labelCache},
+	),
+	KialiCacheMisses: prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kiali_cache_misses_total",
+			Help: "Counts the total number of lookups not served from the Kiali cache.",
+		},
+		[]string{labelCache},
+	),
+	KialiCacheSyncLag: prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "kiali_cache_sync_lag_seconds",
+			Help:    "The time between a change of a resource and its notification to the Kiali cache.",
+			Buckets: []float64{0.5, 1, 2, 5, 10, 30, 60, 300},
+		},
+		[]string{labelType},
+	),
 }
 
 // SuccessOrFailureMetricType let's you capture metrics for both successes and failures,
@@ -180,6 +231,12 @@ func RegisterInternalMetrics() {
 		Metrics.CheckerProcessingTime,
 		Metrics.ValidationProcessingTime,
 		Metrics.SingleValidationProcessingTime,
+		Metrics.PrometheusCacheHits,
+		Metrics.PrometheusCacheMisses,
+		Metrics.PrometheusCacheSize,
+		Metrics.KialiCacheHits,
+		Metrics.KialiCacheMisses,
+		Metrics.KialiCacheSyncLag,
 	)
 }
 
@@ -346,3 +403,44 @@ func GetAPIFailureMetric(route string) prometheus.Counter {
 func SetKubernetesClients(clientCount int) {
 	Metrics.KubernetesClients.With(prometheus.Labels{}).Set(float64(clientCount))
 }
+
+// GetPrometheusCacheHitsMetric returns the counter of cache hits for the given kind of query (instant or range)
+func GetPrometheusCacheHitsMetric(queryKind string) prometheus.Counter {
+	return Metrics.PrometheusCacheHits.With(prometheus.Labels{
+		labelQueryKind: queryKind,
+	})
+}
+
+// GetPrometheusCacheMissesMetric returns the counter of cache misses for the given kind of query (instant or range)
+func GetPrometheusCacheMissesMetric(queryKind string) prometheus.Counter {
+	return Metrics.PrometheusCacheMisses.With(prometheus.Labels{
+		labelQueryKind: queryKind,
+	})
+}
+
+// SetPrometheusCacheSize sets the estimated size of the Prometheus cache, in bytes
+func SetPrometheusCacheSize(size int) {
+	Metrics.PrometheusCacheSize.With(prometheus.Labels{}).Set(float64(size))
+}
+
+// GetKialiCacheHitsMetric returns the counter of lookups served from the given cache of Kiali
+func GetKialiCacheHitsMetric(cache string) prometheus.Counter {
+	return Metrics.KialiCacheHits.With(prometheus.Labels{
+		labelCache: cache,
+	})
+}
+
+// GetKialiCacheMissesMetric returns the counter of lookups not served from the given cache of Kiali
+func GetKialiCacheMissesMetric(cache string) prometheus.Counter {
+	return Metrics.KialiCacheMisses.With(prometheus.Labels{
+		labelCache: cache,
+	})
+}
+
+// GetKialiCacheSyncLagMetric returns the histogram of the time taken by the changes of the given resource type
+// to reach the Kiali cache
+func GetKialiCacheSyncLagMetric(resourceType string) prometheus.Observer {
+	return Metrics.KialiCacheSyncLag.With(prometheus.Labels{
+		labelType: resourceType,
+	})
+}
@@ -52,10 +52,13 @@ func fetchHistogramValues(ctx context.Context, api prom_v1.API, metricName, labe
 	histogram := make(map[string]model.Vector, len(queries))
 	for k, query := range queries {
 		log.Tracef("[Prom] fetchHistogramValues: %s", query)
-		result, warnings, err := api.Query(ctx, query, queryTime)
+		result, warnings, err := CachedQuery(ctx, api, query, queryTime)
 		if warnings != nil && len(warnings) > 0 {
 			log.Warningf("fetchHistogramValues. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 		}
+		if IsPartialError(ctx, err) {
+			return nil, err
+		}
 		if err != nil {
 			return nil, errors.NewServiceUnavailable(err.Error())
 		}
@@ -96,7 +99,7 @@ func buildHistogramQueries(metricName, labels, grouping, rateInterval string, av
 
 func fetchRange(ctx context.Context, api prom_v1.API, query string, bounds prom_v1.Range) Metric {
 	log.Tracef("[Prom] fetchRange: %s", query)
-	result, warnings, err := api.QueryRange(ctx, query, bounds)
+	result, warnings, err := CachedQueryRange(ctx, api, query, bounds)
 	if warnings != nil && len(warnings) > 0 {
 		log.Warningf("fetchRange. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
@@ -177,10 +180,13 @@ func getRequestRatesForLabel(ctx context.Context, api prom_v1.API, time time.Tim
 	query := fmt.Sprintf("rate(istio_requests_total{%s}[%s]) > 0", labels, ratesInterval)
 	log.Tracef("[Prom] getRequestRatesForLabel: %s", query)
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Metrics-GetRequestRates")
-	result, warnings, err := api.Query(ctx, query, time)
+	result, warnings, err := CachedQuery(ctx, api, query, time)
 	if warnings != nil && len(warnings) > 0 {
 		log.Warningf("fetchHistogramValues. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
+	if IsPartialError(ctx, err) {
+		return model.Vector{}, err
+	}
 	if err != nil {
 		return model.Vector{}, errors.NewServiceUnavailable(err.Error())
 	}
@@ -0,0 +1,113 @@
+package prometheustest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kiali/kiali/prometheus"
+)
+
+func TestQueryBudgetLimitsQueries(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := prometheus.NewQueryBudget(2, 0)
+	assert.NoError(budget.Reserve())
+	assert.NoError(budget.Reserve())
+	assert.Equal(prometheus.ErrQueryBudgetExceeded, budget.Reserve())
+}
+
+func TestQueryBudgetLimitsSeries(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := prometheus.NewQueryBudget(0, 10)
+	assert.NoError(budget.Reserve())
+	budget.Consume(12)
+	assert.Equal(prometheus.ErrQueryBudgetExceeded, budget.Reserve())
+}
+
+func TestNilQueryBudgetIsUnlimited(t *testing.T) {
+	assert := assert.New(t)
+
+	var budget *prometheus.QueryBudget
+	assert.NoError(budget.Reserve())
+	budget.Consume(100)
+	budget.Warn("ns", "metric", errors.New("ignored"))
+	assert.Nil(budget.Warnings())
+	assert.Nil(prometheus.GetQueryBudget(context.Background()))
+}
+
+func TestQueryBudgetWarningsAreDeduplicated(t *testing.T) {
+	assert := assert.New(t)
+
+	budget := prometheus.NewQueryBudget(0, 0)
+	budget.Warn("bookinfo", "istio_requests_total", context.DeadlineExceeded)
+	budget.Warn("bookinfo", "istio_requests_total", context.DeadlineExceeded)
+	budget.Warn("tutorial", "istio_requests_total", context.DeadlineExceeded)
+
+	warnings := budget.Warnings()
+	assert.Len(warnings, 2)
+	assert.Equal("bookinfo", warnings[0].Namespace)
+	assert.Equal("istio_requests_total", warnings[0].Metric)
+	assert.Equal(context.DeadlineExceeded.Error(), warnings[0].Message)
+	assert.Equal("tutorial", warnings[1].Namespace)
+}
+
+func TestIsPartialError(t *testing.T) {
+	assert := assert.New(t)
+
+	ctx := context.Background()
+	assert.False(prometheus.IsPartialError(ctx, nil))
+	assert.False(prometheus.IsPartialError(ctx, errors.New("connection refused")))
+	assert.True(prometheus.IsPartialError(ctx, prometheus.ErrQueryBudgetExceeded))
+	assert.True(prometheus.IsPartialError(ctx, context.DeadlineExceeded))
+	assert.True(prometheus.IsPartialError(ctx, &prom_v1.Error{Type: prom_v1.ErrTimeout, Msg: "query timed out"}))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.True(prometheus.IsPartialError(cancelled, errors.New("some transport error")))
+}
+
+func TestBudgetedQuerySkipsQueriesOverBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	api := new(PromAPIMock)
+	vector := model.Vector{
+		&model.Sample{Metric: model.Metric{"foo": "bar"}, Value: 1},
+		&model.Sample{Metric: model.Metric{"foo": "baz"}, Value: 2},
+	}
+	api.On("Query", mock.Anything, "up", mock.AnythingOfType("time.Time")).Return(vector, nil)
+
+	ctx := prometheus.WithQueryBudget(context.Background(), prometheus.NewQueryBudget(0, 2))
+	result, _, err := prometheus.BudgetedQuery(ctx, api, "up", time.Now())
+	assert.NoError(err)
+	assert.Equal(vector, result)
+
+	// The series limit is reached, the next query is not even sent
+	_, _, err = prometheus.BudgetedQuery(ctx, api, "up", time.Now())
+	assert.Equal(prometheus.ErrQueryBudgetExceeded, err)
+	api.AssertNumberOfCalls(t, "Query", 1)
+}
+
+func TestClientWithContextAppliesBudget(t *testing.T) {
+	assert := assert.New(t)
+
+	client, api, err := setupMocked()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	budget := prometheus.NewQueryBudget(1, 0)
+	budget.Reserve()
+	ctx := prometheus.WithQueryBudget(context.Background(), budget)
+
+	_, err = client.WithContext(ctx).GetNamespaceServicesRequestRates("ns", "5m", time.Now())
+	assert.True(prometheus.IsPartialError(ctx, err))
+	api.AssertNotCalled(t, "Query", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -316,6 +316,11 @@ func (o *PromClientMock) FetchHistogramRange(metricName, labels, grouping string
 	return args.Get(0).(prometheus.Histogram)
 }
 
+func (o *PromClientMock) FetchExemplars(metricName, labels string, q *prometheus.RangeQuery) ([]prometheus.ExemplarQueryResult, error) {
+	args := o.Called(metricName, labels, q)
+	return args.Get(0).([]prometheus.ExemplarQueryResult), args.Error(1)
+}
+
 func (o *PromClientMock) FetchHistogramValues(metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, error) {
 	args := o.Called(metricName, labels, grouping, rateInterval, avg, quantiles, queryTime)
 	return args.Get(0).(map[string]model.Vector), args.Error((1))
@@ -0,0 +1,223 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/authorization"
+	"github.com/kiali/kiali/handlers"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+)
+
+// routeFeatures maps routes to the features of the Kiali policy. Other routes are the read feature when they use
+// the GET method, and the write feature otherwise.
+var routeFeatures = map[string]string{
+	"ApiKeyCreate":               authorization.FeatureApiKeys,
+	"ApiKeyRevoke":               authorization.FeatureApiKeys,
+	"ApiKeys":                    authorization.FeatureApiKeys,
+	"AuditEvents":                authorization.FeatureAudit,
+	"GraphAggregate":             authorization.FeatureGraph,
+	"GraphAggregateByService":    authorization.FeatureGraph,
+	"GraphApp":                   authorization.FeatureGraph,
+	"GraphAppVersion":            authorization.FeatureGraph,
+	"GraphNamespaces":            authorization.FeatureGraph,
+	"GraphService":               authorization.FeatureGraph,
+	"GraphWorkload":              authorization.FeatureGraph,
+	"IstioConfigCreate":          authorization.FeatureIstioWrite,
+	"IstioConfigDelete":          authorization.FeatureIstioWrite,
+	"IstioConfigRevisionRestore": authorization.FeatureIstioWrite,
+	"IstioConfigUpdate":          authorization.FeatureIstioWrite,
+	"IstioUpgradePreCheck":       authorization.FeatureRead,
+	"MetricsStats":               authorization.FeatureRead,
+	"PodConfigDump":              authorization.FeatureConfigDump,
+	"PodConfigDumpDiff":          authorization.FeatureConfigDump,
+	"PodConfigDumpSnapshot":      authorization.FeatureConfigDump,
+	"PodLogs":                    authorization.FeaturePodLogs,
+	"PodProxyClusters":           authorization.FeatureConfigDump,
+	"PodProxyServerInfo":         authorization.FeatureConfigDump,
+	"PodProxyStats":              authorization.FeatureConfigDump,
+	"PodRouteSimulation":         authorization.FeatureConfigDump,
+}
+
+// routeBodyNamespaces reads the namespaces of the routes which take them from the body of the request
+var routeBodyNamespaces = map[string]func(body []byte) []string{
+	"IstioConfigCreate":           objectNamespaces,
+	"IstioConfigUpdate":           objectNamespaces,
+	"IstioUpgradeMigrationCreate": istioUpgradeMigrationNamespaces,
+	"MetricsStats":                metricsStatsNamespaces,
+}
+
+// routeQueryNamespaces reads the namespaces of the routes which take them from other query parameters than namespaces
+var routeQueryNamespaces = map[string]func(query url.Values) []string{
+	"PodConfigDumpDiff": compareNamespaces,
+}
+
+// routeResolvedNamespaces finds the namespaces of the routes which are about a resource of Kiali covering namespaces
+var routeResolvedNamespaces = map[string]func(r *http.Request) ([]string, error){
+	"IstioUpgradeMigrationDetails":  handlers.IstioUpgradeMigrationNamespaces,
+	"IstioUpgradeMigrationRollback": handlers.IstioUpgradeMigrationNamespaces,
+}
+
+func routeFeature(route Route) string {
+	if feature, ok := routeFeatures[route.Name]; ok {
+		return feature
+	}
+	if route.Method == http.MethodGet {
+		return authorization.FeatureRead
+	}
+	return authorization.FeatureWrite
+}
+
+// authorizationHandler rejects the requests for features that the Kiali policy doesn't make available to the
+// authenticated user, and the requests out of the scope of the API key that authenticated them. It must run after
+// the authentication handler, which sets the user and groups headers.
+func authorizationHandler(next http.Handler, route Route) http.Handler {
+	feature := routeFeature(route)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		namespaces, err := requestNamespaces(r, route)
+		if err != nil {
+			handlers.RespondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if resolveNamespaces, ok := routeResolvedNamespaces[route.Name]; ok {
+			resolved, err := resolveNamespaces(r)
+			if err != nil {
+				log.Errorf("Namespaces of the request can't be resolved: %v", err)
+				handlers.RespondWithError(w, http.StatusInternalServerError, "Namespaces of the request can't be resolved")
+				return
+			}
+			namespaces = append(namespaces, resolved...)
+		}
+
+		if apiKey := handlers.GetApiKey(r); apiKey != nil {
+			if reason := apiKeyForbiddenReason(apiKey, route, namespaces); reason != "" {
+				handlers.RespondWithError(w, http.StatusForbidden, "Forbidden: "+reason)
+				return
+			}
+		}
+
+		policy, err := authorization.GetPolicy()
+		if err != nil {
+			log.Errorf("Kiali policy can't be loaded: %v", err)
+			handlers.RespondWithError(w, http.StatusInternalServerError, "Kiali policy can't be loaded")
+			return
+		}
+		if policy == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		permissions := policy.Permissions(r.Header.Get("Kiali-User"), r.Header.Values("Kiali-Groups"))
+		if decision := permissions.Authorize(feature, namespaces); !decision.Allowed {
+			handlers.RespondWithError(w, http.StatusForbidden, "Forbidden: "+decision.Reason)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiKeyForbiddenReason explains why an API key can't use a route in some namespaces, empty when it can.
+// API keys can't manage keys, and only keys with the write scope can make changes, whatever the feature of the
+// route. Keys limited to namespaces can't make changes that are not for a namespace, since their scope can't be
+// checked.
+func apiKeyForbiddenReason(apiKey *models.ApiKey, route Route, namespaces []string) string {
+	if routeFeature(route) == authorization.FeatureApiKeys {
+		return "API keys can't be managed with an API key"
+	}
+	if route.Method != http.MethodGet {
+		if apiKey.Scope != models.ApiKeyScopeWrite {
+			return fmt.Sprintf("API key [%s] has the %s scope", apiKey.Name, apiKey.Scope)
+		}
+		if len(namespaces) == 0 && len(apiKey.Namespaces) > 0 {
+			return fmt.Sprintf("API key [%s] is limited to namespaces, it can't make changes which are not for a namespace", apiKey.Name)
+		}
+	}
+	for _, namespace := range namespaces {
+		if !apiKey.AllowsNamespace(namespace) {
+			return fmt.Sprintf("API key [%s] can't access namespace [%s]", apiKey.Name, namespace)
+		}
+	}
+	return ""
+}
+
+// requestNamespaces returns the namespaces of the path, of the namespaces query parameter and, for the routes
+// of routeQueryNamespaces and routeBodyNamespaces, of the query and of the body. The body is left readable for
+// the handler of the route.
+func requestNamespaces(r *http.Request, route Route) ([]string, error) {
+	namespaces := []string{}
+	if namespace := mux.Vars(r)["namespace"]; namespace != "" {
+		namespaces = append(namespaces, namespace)
+	}
+	for _, namespace := range strings.Split(r.URL.Query().Get("namespaces"), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if queryNamespaces, ok := routeQueryNamespaces[route.Name]; ok {
+		namespaces = append(namespaces, queryNamespaces(r.URL.Query())...)
+	}
+	if bodyNamespaces, ok := routeBodyNamespaces[route.Name]; ok && r.Body != nil {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		namespaces = append(namespaces, bodyNamespaces(body)...)
+	}
+	return namespaces, nil
+}
+
+// compareNamespaces returns the namespace of the pod a config dump is compared with, when it isn't the one of the path
+func compareNamespaces(query url.Values) []string {
+	if namespace := query.Get("compareNamespace"); namespace != "" {
+		return []string{namespace}
+	}
+	return nil
+}
+
+// metricsStatsNamespaces returns the namespaces of the targets of the stats queries. A body which can't be parsed
+// has no namespace, the handler rejects it.
+func metricsStatsNamespaces(body []byte) []string {
+	var queries models.MetricsStatsQueries
+	if err := json.Unmarshal(body, &queries); err != nil {
+		return nil
+	}
+	namespaces := []string{}
+	for _, query := range queries.Queries {
+		namespaces = append(namespaces, query.Target.Namespace)
+		if query.PeerTarget != nil {
+			namespaces = append(namespaces, query.PeerTarget.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// objectNamespaces returns the namespace of the metadata of an object, or of the patch of an object
+func objectNamespaces(body []byte) []string {
+	var object struct {
+		Metadata struct {
+			Namespace string `json:"namespace"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &object); err != nil || object.Metadata.Namespace == "" {
+		return nil
+	}
+	return []string{object.Metadata.Namespace}
+}
+
+// istioUpgradeMigrationNamespaces returns the namespaces to migrate
+func istioUpgradeMigrationNamespaces(body []byte) []string {
+	var request handlers.IstioUpgradeMigrationRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil
+	}
+	return request.Namespaces
+}
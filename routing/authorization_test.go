@@ -0,0 +1,148 @@
+package routing
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+)
+
+func TestAuthorizationHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kiali-policy")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	policyFile := filepath.Join(dir, "policy.yaml")
+	policy := `
+default_role: viewer
+roles:
+- name: viewer
+  allow:
+  - features: [read, graph]
+  deny:
+  - features: [pod_logs]
+- name: editor
+  allow:
+  - features: ["*"]
+  deny:
+  - features: [istio_write, config_dump]
+    namespaces: [prod]
+bindings:
+- role: editor
+  groups: [editors]
+`
+	require.NoError(t, ioutil.WriteFile(policyFile, []byte(policy), 0600))
+
+	conf := config.NewConfig()
+	conf.Auth.PolicyFile = policyFile
+	config.Set(conf)
+	defer config.Set(config.NewConfig())
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	router := mux.NewRouter()
+	for _, route := range []Route{
+		{Name: "PodLogs", Method: "GET", Pattern: "/api/namespaces/{namespace}/pods/{pod}/logs"},
+		{Name: "GraphNamespaces", Method: "GET", Pattern: "/api/namespaces/graph"},
+		{Name: "IstioConfigUpdate", Method: "PATCH", Pattern: "/api/namespaces/{namespace}/istio/{object_type}/{object}"},
+		{Name: "PodConfigDumpDiff", Method: "GET", Pattern: "/api/namespaces/{namespace}/pods/{pod}/config_dump_diff"},
+	} {
+		router.Methods(route.Method).Path(route.Pattern).Handler(authorizationHandler(ok, route))
+	}
+
+	cases := []struct {
+		method string
+		url    string
+		groups []string
+		status int
+	}{
+		{"GET", "/api/namespaces/bookinfo/pods/reviews/logs", nil, http.StatusForbidden},
+		{"GET", "/api/namespaces/graph?namespaces=bookinfo,prod", nil, http.StatusOK},
+		{"PATCH", "/api/namespaces/bookinfo/istio/virtualservices/reviews", nil, http.StatusForbidden},
+		{"PATCH", "/api/namespaces/bookinfo/istio/virtualservices/reviews", []string{"editors"}, http.StatusOK},
+		{"PATCH", "/api/namespaces/prod/istio/virtualservices/reviews", []string{"editors"}, http.StatusForbidden},
+		{"GET", "/api/namespaces/bookinfo/pods/reviews/config_dump_diff?comparePod=reviews-v2", []string{"editors"}, http.StatusOK},
+		// The pod compared with is in a namespace where config dumps are denied
+		{"GET", "/api/namespaces/bookinfo/pods/reviews/config_dump_diff?comparePod=reviews&compareNamespace=prod", []string{"editors"}, http.StatusForbidden},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest(c.method, c.url, nil)
+		r.Header.Set("Kiali-User", "bob")
+		for _, group := range c.groups {
+			r.Header.Add("Kiali-Groups", group)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, c.status, w.Code, c.method+" "+c.url)
+	}
+
+	r := httptest.NewRequest("GET", "/api/namespaces/bookinfo/pods/reviews/logs", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	assert.Contains(t, w.Body.String(), "Forbidden: feature [pod_logs] is denied by the Kiali policy in namespace [bookinfo]")
+}
+
+func TestRequestNamespacesOfBody(t *testing.T) {
+	body := `{"queries":[{"target":{"namespace":"bookinfo","name":"reviews","kind":"app"},"peerTarget":{"namespace":"prod","name":"ratings","kind":"app"}}]}`
+	r := httptest.NewRequest("POST", "/api/stats/metrics?namespaces=travels", strings.NewReader(body))
+	namespaces, err := requestNamespaces(r, Route{Name: "MetricsStats"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"travels", "bookinfo", "prod"}, namespaces)
+
+	// The handler still reads the body
+	read, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(read))
+
+	r = httptest.NewRequest("POST", "/api/mesh/upgrade/migrations", strings.NewReader(`{"namespaces":["bookinfo","prod"],"batchSize":1}`))
+	namespaces, err = requestNamespaces(r, Route{Name: "IstioUpgradeMigrationCreate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bookinfo", "prod"}, namespaces)
+
+	r = httptest.NewRequest("POST", "/api/namespaces/bookinfo/istio/virtualservices", strings.NewReader(`{"metadata":{"name":"reviews","namespace":"prod"}}`))
+	r = mux.SetURLVars(r, map[string]string{"namespace": "bookinfo"})
+	namespaces, err = requestNamespaces(r, Route{Name: "IstioConfigCreate"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bookinfo", "prod"}, namespaces)
+}
+
+func TestRequestNamespacesOfQuery(t *testing.T) {
+	r := httptest.NewRequest("GET", "/api/namespaces/bookinfo/pods/reviews/config_dump_diff?comparePod=reviews&compareNamespace=prod", nil)
+	r = mux.SetURLVars(r, map[string]string{"namespace": "bookinfo"})
+	namespaces, err := requestNamespaces(r, Route{Name: "PodConfigDumpDiff"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bookinfo", "prod"}, namespaces)
+}
+
+func TestApiKeyForbiddenReason(t *testing.T) {
+	assert := assert.New(t)
+	apiKey := &models.ApiKey{Name: "pipeline", Namespaces: []string{"bookinfo"}, Scope: models.ApiKeyScopeRead}
+	read := Route{Name: "ServiceList", Method: "GET"}
+	graph := Route{Name: "GraphNamespaces", Method: "GET"}
+	istioWrite := Route{Name: "IstioConfigUpdate", Method: "PATCH"}
+	write := Route{Name: "IstioUpgradeMigrationCreate", Method: "POST"}
+
+	assert.Empty(apiKeyForbiddenReason(apiKey, read, []string{"bookinfo"}))
+	assert.Empty(apiKeyForbiddenReason(apiKey, graph, nil))
+	assert.Equal("API key [pipeline] can't access namespace [prod]", apiKeyForbiddenReason(apiKey, read, []string{"bookinfo", "prod"}))
+	assert.Equal("API key [pipeline] has the read scope", apiKeyForbiddenReason(apiKey, istioWrite, []string{"bookinfo"}))
+	assert.Equal("API keys can't be managed with an API key", apiKeyForbiddenReason(apiKey, Route{Name: "ApiKeys", Method: "GET"}, nil))
+	// Changes of other features need the write scope too
+	assert.Empty(apiKeyForbiddenReason(apiKey, Route{Name: "PodConfigDump", Method: "GET"}, []string{"bookinfo"}))
+	assert.Equal("API key [pipeline] has the read scope", apiKeyForbiddenReason(apiKey, Route{Name: "PodConfigDumpSnapshot", Method: "POST"}, []string{"bookinfo"}))
+
+	apiKey.Scope = models.ApiKeyScopeWrite
+	assert.Empty(apiKeyForbiddenReason(apiKey, write, []string{"bookinfo"}))
+	assert.Empty(apiKeyForbiddenReason(apiKey, Route{Name: "PodConfigDumpSnapshot", Method: "POST"}, []string{"bookinfo"}))
+	// Changes that are not for a namespace are out of the scope of the key
+	assert.Equal("API key [pipeline] is limited to namespaces, it can't make changes which are not for a namespace", apiKeyForbiddenReason(apiKey, write, nil))
+	assert.Empty(apiKeyForbiddenReason(apiKey, read, nil))
+}
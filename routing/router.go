@@ -75,7 +75,7 @@ func NewRouter() *mux.Router {
 	for _, route := range apiRoutes.Routes {
 		handlerFunction := metricHandler(route.HandlerFunc, route)
 		if route.Authenticated {
-			handlerFunction = authenticationHandler.Handle(handlerFunction)
+			handlerFunction = authenticationHandler.Handle(authorizationHandler(handlerFunction, route))
 		} else {
 			handlerFunction = authenticationHandler.HandleUnauthenticated(handlerFunction)
 		}
@@ -141,6 +141,73 @@ func NewRoutes() (r *Routes) {
 			handlers.AuthenticationInfo,
 			false,
 		},
+		// swagger:route GET /auth/api_keys auth apiKeys
+		// ---
+		// Endpoint to list the API keys of the ServiceAccounts the user can impersonate, with their last use
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: apiKeys
+		//
+		{
+			"ApiKeys",
+			"GET",
+			"/api/auth/api_keys",
+			handlers.ApiKeys,
+			true,
+		},
+		// swagger:route POST /auth/api_keys auth apiKeyCreate
+		// ---
+		// Endpoint to issue an API key for automation clients. The value of the key is only returned by this
+		// call. The user must be allowed to impersonate the ServiceAccount of the key.
+		//
+		//     Consumes:
+		//     - application/json
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      403: forbiddenError
+		//      400: badRequestError
+		//      200: apiKeyCreated
+		//
+		{
+			"ApiKeyCreate",
+			"POST",
+			"/api/auth/api_keys",
+			handlers.ApiKeyCreate,
+			true,
+		},
+		// swagger:route DELETE /auth/api_keys/{key} auth apiKeyRevoke
+		// ---
+		// Endpoint to revoke an API key
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      403: forbiddenError
+		//      200
+		//
+		{
+			"ApiKeyRevoke",
+			"DELETE",
+			"/api/auth/api_keys/{key}",
+			handlers.ApiKeyRevoke,
+			true,
+		},
 		// swagger:route GET /auth/openid_redirect auth openidRedirect
 		// ---
 		// Endpoint to redirect the browser of the user to the authentication
@@ -164,6 +231,45 @@ func NewRoutes() (r *Routes) {
 			handlers.OpenIdRedirect,
 			false,
 		},
+		// swagger:route GET /auth/openid_logout auth openidLogout
+		// ---
+		// Endpoint to logout the user and redirect the browser of the user to the
+		// end session endpoint of the configured OpenId provider.
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: noContent
+		{
+			"OpenIdLogout",
+			"GET",
+			"/api/auth/openid_logout",
+			handlers.OpenIdLogout,
+			false,
+		},
+		// swagger:route POST /auth/openid_backchannel_logout auth openidBackChannelLogout
+		// ---
+		// Endpoint for the configured OpenId provider to end the sessions of a user
+		// in Kiali, when the user logs out from the provider.
+		//
+		//     Consumes:
+		//     - application/x-www-form-urlencoded
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200
+		{
+			"OpenIdBackChannelLogout",
+			"POST",
+			"/api/auth/openid_backchannel_logout",
+			handlers.OpenIdBackChannelLogout,
+			false,
+		},
 		// swagger:route GET /status status getStatus
 		// ---
 		// Endpoint to get the status of Kiali
@@ -309,6 +415,72 @@ func NewRoutes() (r *Routes) {
 			handlers.IstioConfigUpdate,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/istio/{object_type}/{object}/history config istioConfigHistory
+		// ---
+		// Endpoint to list the revisions kept for an Istio object modified through Kiali
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: istioConfigHistory
+		//
+		{
+			"IstioConfigHistory",
+			"GET",
+			"/api/namespaces/{namespace}/istio/{object_type}/{object}/history",
+			handlers.IstioConfigHistory,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/istio/{object_type}/{object}/history/diff config istioConfigRevisionDiff
+		// ---
+		// Endpoint to compare two revisions of an Istio object
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: istioConfigRevisionDiff
+		//
+		{
+			"IstioConfigRevisionDiff",
+			"GET",
+			"/api/namespaces/{namespace}/istio/{object_type}/{object}/history/diff",
+			handlers.IstioConfigRevisionDiff,
+			true,
+		},
+		// swagger:route POST /namespaces/{namespace}/istio/{object_type}/{object}/history/{revision}/restore config istioConfigRevisionRestore
+		// ---
+		// Endpoint to bring an Istio object back to a previous revision, recreating it when it was deleted
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: istioConfigDetailsResponse
+		//
+		{
+			"IstioConfigRevisionRestore",
+			"POST",
+			"/api/namespaces/{namespace}/istio/{object_type}/{object}/history/{revision}/restore",
+			handlers.IstioConfigRevisionRestore,
+			true,
+		},
 		// swagger:route POST /namespaces/{namespace}/istio/{object_type} config istioConfigCreate
 		// ---
 		// Endpoint to create an Istio object by using an Istio Config item
@@ -476,6 +648,49 @@ func NewRoutes() (r *Routes) {
 			handlers.AppTraces,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/apps/{app}/traces/analytics traces appTracesAnalytics
+		// ---
+		// Endpoint to get latency and error analytics computed over the traces of a given app
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: traceAnalyticsResponse
+		//
+		{
+			"AppTracesAnalytics",
+			"GET",
+			"/api/namespaces/{namespace}/apps/{app}/traces/analytics",
+			handlers.AppTracesAnalytics,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/apps/{app}/traces/compare traces appTracesComparison
+		// ---
+		// Endpoint to compare the traces of a given app in two time windows, call by call
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: traceComparisonResponse
+		//
+		{
+			"AppTracesComparison",
+			"GET",
+			"/api/namespaces/{namespace}/apps/{app}/traces/compare",
+			handlers.AppTracesComparison,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/services/{service}/traces traces serviceTraces
 		// ---
 		// Endpoint to get the traces of a given service
@@ -541,7 +756,8 @@ func NewRoutes() (r *Routes) {
 		},
 		// swagger:route GET /traces/{traceID} traces traceDetails
 		// ---
-		// Endpoint to get a specific trace from ID
+		// Endpoint to get a specific trace from ID. With the compareWith parameter, the trace is compared
+		// to another one and the comparison is returned instead (traceComparisonResponse).
 		//
 		//     Produces:
 		//     - application/json
@@ -718,6 +934,9 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - text/csv
+		//     - application/openmetrics-text
+		//     - application/vnd.apache.parquet
 		//
 		//     Schemes: http, https
 		//
@@ -739,6 +958,9 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - text/csv
+		//     - application/openmetrics-text
+		//     - application/vnd.apache.parquet
 		//
 		//     Schemes: http, https
 		//
@@ -760,6 +982,9 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - text/csv
+		//     - application/openmetrics-text
+		//     - application/vnd.apache.parquet
 		//
 		//     Schemes: http, https
 		//
@@ -781,6 +1006,9 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - text/csv
+		//     - application/openmetrics-text
+		//     - application/vnd.apache.parquet
 		//
 		//     Schemes: http, https
 		//
@@ -970,6 +1198,9 @@ func NewRoutes() (r *Routes) {
 		//
 		//     Produces:
 		//     - application/json
+		//     - text/csv
+		//     - application/openmetrics-text
+		//     - application/vnd.apache.parquet
 		//
 		//     Schemes: http, https
 		//
@@ -1048,6 +1279,157 @@ func NewRoutes() (r *Routes) {
 			handlers.MeshTls,
 			true,
 		},
+		// swagger:route GET /mesh/stale_proxies pods meshStaleProxies
+		// ---
+		// Endpoint to list the proxies of the mesh out of sync for longer than a threshold, grouped by istiod revision
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      400: badRequestError
+		//      200: staleProxies
+		//
+		{
+			"MeshStaleProxies",
+			"GET",
+			"/api/mesh/stale_proxies",
+			handlers.StaleProxies,
+			true,
+		},
+		// swagger:route GET /mesh/upgrade istio istioUpgradeStatus
+		// ---
+		// Endpoint to list the namespaces and workloads of each Istio revision, and the proxies not yet on the revision of their namespace
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      403: forbiddenError
+		//      200: istioUpgradeStatus
+		//
+		{
+			"IstioUpgradeStatus",
+			"GET",
+			"/api/mesh/upgrade",
+			handlers.IstioUpgradeStatus,
+			true,
+		},
+		// swagger:route POST /mesh/upgrade/precheck istio istioUpgradePreCheck
+		// ---
+		// Endpoint to check whether namespaces can be migrated to the upgrade revision
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      403: forbiddenError
+		//      400: badRequestError
+		//      200: upgradePreCheck
+		//
+		{
+			"IstioUpgradePreCheck",
+			"POST",
+			"/api/mesh/upgrade/precheck",
+			handlers.IstioUpgradePreCheck,
+			true,
+		},
+		// swagger:route GET /mesh/upgrade/migrations istio istioUpgradeMigrationList
+		// ---
+		// Endpoint to list the migrations of namespaces to the upgrade revision, limited to the namespaces accessible to the user
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      403: forbiddenError
+		//      200: istioUpgradeMigrations
+		//
+		{
+			"IstioUpgradeMigrationList",
+			"GET",
+			"/api/mesh/upgrade/migrations",
+			handlers.IstioUpgradeMigrationList,
+			true,
+		},
+		// swagger:route POST /mesh/upgrade/migrations istio istioUpgradeMigrationCreate
+		// ---
+		// Endpoint to start migrating namespaces to the upgrade revision, in batches
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      403: forbiddenError
+		//      400: badRequestError
+		//      202: istioUpgradeMigration
+		//
+		{
+			"IstioUpgradeMigrationCreate",
+			"POST",
+			"/api/mesh/upgrade/migrations",
+			handlers.IstioUpgradeMigrationCreate,
+			true,
+		},
+		// swagger:route GET /mesh/upgrade/migrations/{migration} istio istioUpgradeMigrationDetails
+		// ---
+		// Endpoint to get the progress of a migration to the upgrade revision
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      403: forbiddenError
+		//      200: istioUpgradeMigration
+		//
+		{
+			"IstioUpgradeMigrationDetails",
+			"GET",
+			"/api/mesh/upgrade/migrations/{migration}",
+			handlers.IstioUpgradeMigrationDetails,
+			true,
+		},
+		// swagger:route POST /mesh/upgrade/migrations/{migration}/rollback istio istioUpgradeMigrationRollback
+		// ---
+		// Endpoint to move the namespaces of a migration back to their previous revision
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      403: forbiddenError
+		//      200: istioUpgradeMigration
+		//
+		{
+			"IstioUpgradeMigrationRollback",
+			"POST",
+			"/api/mesh/upgrade/migrations/{migration}/rollback",
+			handlers.IstioUpgradeMigrationRollback,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/tls tls namespaceTls
 		// ---
 		// Get TLS status for the given namespace
@@ -1090,6 +1472,26 @@ func NewRoutes() (r *Routes) {
 			handlers.IstioStatus,
 			true,
 		},
+		// swagger:route GET /istio/control_planes status istioControlPlanes
+		// ---
+		// Get the control planes of the mesh by revision, with their health, version and injected namespaces
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: istioControlPlanesResponse
+		//      500: internalError
+		//
+		{
+			"IstioControlPlanes",
+			"GET",
+			"/api/istio/control_planes",
+			handlers.IstioControlPlanes,
+			true,
+		},
 		// swagger:route GET /istio/certs certs istioCerts
 		// ---
 		// Get certificates (internal) information used by Istio
@@ -1387,6 +1789,156 @@ func NewRoutes() (r *Routes) {
 			handlers.ConfigDumpResourceEntries,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/config_dump_diff pods podProxyDumpDiff
+		// ---
+		// Endpoint to compare the proxy config of a pod with the one of another pod, or with an earlier snapshot
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: configDumpDiff
+		//
+		{
+			"PodConfigDumpDiff",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/config_dump_diff",
+			handlers.ConfigDumpDiff,
+			true,
+		},
+		// swagger:route POST /namespaces/{namespace}/pods/{pod}/config_dump_snapshots pods podProxyDumpSnapshot
+		// ---
+		// Endpoint to keep a snapshot of the proxy config of a pod, to be compared later with its live config
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      201: configDumpSnapshot
+		//
+		{
+			"PodConfigDumpSnapshot",
+			"POST",
+			"/api/namespaces/{namespace}/pods/{pod}/config_dump_snapshots",
+			handlers.ConfigDumpSnapshot,
+			true,
+		},
+		// swagger:route POST /namespaces/{namespace}/pods/{pod}/route_simulation pods podRouteSimulation
+		// ---
+		// Endpoint to find where the proxy of a pod would send a request, evaluated offline from its config
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: routeSimulation
+		//
+		{
+			"PodRouteSimulation",
+			"POST",
+			"/api/namespaces/{namespace}/pods/{pod}/route_simulation",
+			handlers.RouteSimulation,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/proxy_stats pods podProxyStats
+		// ---
+		// Endpoint to get the stats of the proxy of a pod, by type
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: proxyStats
+		//
+		{
+			"PodProxyStats",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/proxy_stats",
+			handlers.ProxyStats,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/proxy_clusters pods podProxyClusters
+		// ---
+		// Endpoint to get the circuit breakers, outlier detection and endpoints health of the proxy of a pod
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: proxyClusters
+		//
+		{
+			"PodProxyClusters",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/proxy_clusters",
+			handlers.ProxyClusters,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/proxy_server_info pods podProxyServerInfo
+		// ---
+		// Endpoint to get the state, version and uptime of the proxy of a pod
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: proxyServerInfo
+		//
+		{
+			"PodProxyServerInfo",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/proxy_server_info",
+			handlers.ProxyServerInfo,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/proxy_sync_history pods podProxySyncHistory
+		// ---
+		// Endpoint to get how the proxy of a pod synchronized with the control plane over time
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: proxySyncHistory
+		//
+		{
+			"PodProxySyncHistory",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/proxy_sync_history",
+			handlers.ProxySyncHistory,
+			true,
+		},
 		// swagger:route POST /namespaces/{namespace}/pods/{pod}/logging pods podProxyLogging
 		// ---
 		// Endpoint to set pod proxy log level
@@ -1409,6 +1961,92 @@ func NewRoutes() (r *Routes) {
 			handlers.LoggingUpdate,
 			true,
 		},
+		// swagger:route DELETE /namespaces/{namespace}/pods/{pod}/logging pods podProxyLoggingRevert
+		// ---
+		// Endpoint to restore the pod proxy log levels as they were before being raised through Kiali
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      200: noContent
+		//
+		{
+			"PodProxyLoggingRevert",
+			"DELETE",
+			"/api/namespaces/{namespace}/pods/{pod}/logging",
+			handlers.LoggingRevert,
+			true,
+		},
+		// swagger:route POST /namespaces/{namespace}/logging pods namespaceProxyLogging
+		// ---
+		// Endpoint to set the proxy log levels of the pods of a workload, of an app, or matching a label selector
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: proxyLoggingResults
+		//
+		{
+			"NamespaceProxyLogging",
+			"POST",
+			"/api/namespaces/{namespace}/logging",
+			handlers.LoggingBulkUpdate,
+			true,
+		},
+		// swagger:route GET /proxy_logging pods proxyLoggingList
+		// ---
+		// Endpoint to list the proxies whose log levels were raised through Kiali
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      200: proxyLoggingList
+		//
+		{
+			"ProxyLoggingList",
+			"GET",
+			"/api/proxy_logging",
+			handlers.LoggingList,
+			true,
+		},
+		// swagger:route GET /audit audit auditEvents
+		// ---
+		// Endpoint to list the latest write operations made through Kiali, newest first, for the cluster admins
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      403: forbiddenError
+		//      400: badRequestError
+		//      200: auditEvents
+		//
+		{
+			"AuditEvents",
+			"GET",
+			"/api/audit",
+			handlers.AuditEvents,
+			true,
+		},
 		// swagger:route GET /iter8
 		// ---
 		// Endpoint to check if iter8 adapter is present in the cluster and if user can write adapter config
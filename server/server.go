@@ -2,6 +2,7 @@ package server
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/NYTimes/gziphandler"
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
@@ -49,6 +51,18 @@ func NewServer() *Server {
 		MinVersion: tls.VersionTLS12,
 	}
 
+	// Client certificates are verified when they are presented, the authentication handler rejects the
+	// requests without one. Health probes don't need a certificate.
+	if conf.Auth.Strategy == config.AuthStrategyX509 {
+		clientCAs, err := business.LoadX509ClientCAs()
+		if err != nil {
+			log.Errorf("No client certificate will be accepted: %v", err)
+			clientCAs = x509.NewCertPool()
+		}
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsConfig.ClientCAs = clientCAs
+	}
+
 	// create the server definition that will handle both console and api server traffic
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf("%v:%v", conf.Server.Address, conf.Server.Port),
@@ -87,6 +101,12 @@ func (s *Server) Start() {
 	if conf.Server.MetricsEnabled {
 		StartMetricsServer()
 	}
+
+	if conf.Server.AuditLog {
+		audit.Start()
+	}
+
+	business.Start()
 }
 
 // Stop the HTTP server
@@ -95,6 +115,7 @@ func (s *Server) Stop() {
 	business.Stop()
 	log.Infof("Server endpoint will stop at [%v]", s.httpServer.Addr)
 	s.httpServer.Close()
+	audit.Stop()
 }
 
 func corsAllowed(next http.Handler) http.Handler {
@@ -0,0 +1,256 @@
+package tempo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+// Client for the Grafana Tempo API. It implements jaeger.ClientInterface: traces found through the
+// Tempo search API are fetched by ID and converted from OTLP to the Jaeger JSON model.
+type Client struct {
+	httpClient http.Client
+	baseURL    *url.URL
+}
+
+var _ jaeger.ClientInterface = (*Client)(nil)
+
+// The traces found by a search are fetched concurrently, up to this number at a time
+const maxConcurrentTraceFetches = 10
+
+// Tempo returns 20 traces when the search has no limit, the count of error traces is capped at this number instead
+const maxErrorTraces = 1000
+
+// searchResponse is the response of the /api/search endpoint
+type searchResponse struct {
+	Traces []struct {
+		TraceID string `json:"traceID"`
+	} `json:"traces"`
+}
+
+func NewClient(token string) (*Client, error) {
+	cfg := config.Get()
+	cfgTracing := cfg.ExternalServices.Tracing
+
+	if !cfgTracing.Enabled {
+		return nil, errors.New("tracing is not enabled")
+	}
+	auth := cfgTracing.Auth
+	if auth.UseKialiToken {
+		auth.Token = token
+	}
+
+	u, errParse := url.Parse(cfgTracing.InClusterURL)
+	if !cfg.InCluster {
+		u, errParse = url.Parse(cfgTracing.URL)
+	}
+	if errParse != nil {
+		log.Errorf("Error parsing Tempo URL: %s", errParse)
+		return nil, errParse
+	}
+
+	timeout := time.Duration(5000 * time.Millisecond)
+	transport, err := httputil.CreateTransport(&auth, &http.Transport{}, timeout, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("Create Tempo HTTP client %s", u)
+	return &Client{httpClient: http.Client{Transport: transport, Timeout: timeout}, baseURL: u}, nil
+}
+
+// GetAppTraces searches the traces of an app, then fetches them
+func (in *Client) GetAppTraces(namespace, app string, q models.TracingQuery) (*jaeger.JaegerResponse, error) {
+	serviceName := jaeger.BuildServiceName(namespace, app)
+	search, err := in.search(serviceName, q)
+	if err != nil {
+		return nil, err
+	}
+	traces, err := in.getTraces(search)
+	if err != nil {
+		return nil, err
+	}
+
+	r := jaeger.JaegerResponse{
+		Data:              []jaegerModels.Trace{},
+		JaegerServiceName: serviceName,
+	}
+	for _, trace := range traces {
+		if trace != nil {
+			r.Data = append(r.Data, *trace)
+		}
+	}
+	return &r, nil
+}
+
+// GetTraceDetail fetches a specific trace from its ID
+func (in *Client) GetTraceDetail(traceID string) (*jaeger.JaegerSingleTrace, error) {
+	trace, err := in.getTrace(traceID)
+	if err != nil || trace == nil {
+		// Not found
+		return nil, err
+	}
+	return &jaeger.JaegerSingleTrace{Data: *trace}, nil
+}
+
+// GetErrorTraces fetches number of traces in error for the given app
+func (in *Client) GetErrorTraces(ns, app string, duration time.Duration) (int, error) {
+	now := time.Now()
+	query := models.TracingQuery{
+		Start: now.Add(-duration),
+		End:   now,
+		Tags:  map[string]string{"error": "true"},
+		Limit: maxErrorTraces,
+	}
+	// The traces are counted from the search, without fetching them
+	search, err := in.search(jaeger.BuildServiceName(ns, app), query)
+	if err != nil {
+		return 0, err
+	}
+	return len(search.Traces), nil
+}
+
+func (in *Client) GetServiceStatus() (bool, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/ready")
+	_, _, err := in.get(u.String())
+	return err == nil, err
+}
+
+func (in *Client) search(serviceName string, q models.TracingQuery) (*searchResponse, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/api/search")
+	u.RawQuery = searchQuery(serviceName, q).Encode()
+
+	resp, code, err := in.get(u.String())
+	if err != nil {
+		log.Errorf("Tempo search error: %s [code: %d, URL: %v]", err, code, u)
+		return nil, err
+	}
+	var search searchResponse
+	if err := json.Unmarshal(resp, &search); err != nil {
+		log.Errorf("Error unmarshalling Tempo search response: %s [URL: %v]", err, u)
+		return nil, err
+	}
+	return &search, nil
+}
+
+// getTraces fetches the traces found by a search, in the order of the search. Traces not found are nil.
+func (in *Client) getTraces(search *searchResponse) ([]*jaegerModels.Trace, error) {
+	traces := make([]*jaegerModels.Trace, len(search.Traces))
+	errs := make([]error, len(search.Traces))
+	semaphore := make(chan struct{}, maxConcurrentTraceFetches)
+	var wg sync.WaitGroup
+	for i, found := range search.Traces {
+		wg.Add(1)
+		go func(i int, traceID string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			traces[i], errs[i] = in.getTrace(traceID)
+		}(i, found.TraceID)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return traces, nil
+}
+
+func (in *Client) getTrace(traceID string) (*jaegerModels.Trace, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/api/traces/"+traceID)
+	resp, code, err := in.get(u.String())
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		log.Errorf("Tempo query error: %s [code: %d, URL: %v]", err, code, u)
+		return nil, err
+	}
+	var trace otlpTrace
+	if err := json.Unmarshal(resp, &trace); err != nil {
+		log.Errorf("Error unmarshalling Tempo trace: %s [URL: %v]", err, u)
+		return nil, err
+	}
+	return convertTrace(trace), nil
+}
+
+func (in *Client) get(endpoint string) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Tempo answers with protobuf unless JSON is explicitly requested
+	req.Header.Add("Accept", "application/json")
+	resp, err := in.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err == nil && resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("unexpected Tempo response status %s", resp.Status)
+	}
+	return body, resp.StatusCode, err
+}
+
+// searchQuery returns the parameters of a search. The operation is searched as the name of a span, so that the
+// search limit applies to the traces of the operation.
+func searchQuery(serviceName string, query models.TracingQuery) url.Values {
+	tags := []string{logfmtPair("service.name", serviceName)}
+	if query.Operation != "" {
+		tags = append(tags, logfmtPair("name", query.Operation))
+	}
+	searchTags := query.SearchTags()
+	keys := make([]string, 0, len(searchTags))
+	for k := range searchTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		tags = append(tags, logfmtPair(k, searchTags[k]))
+	}
+
+	q := url.Values{}
+	q.Set("tags", strings.Join(tags, " "))
+	q.Set("start", strconv.FormatInt(query.Start.Unix(), 10))
+	// Tempo has a precision of a second, round the end up to not miss the most recent traces
+	q.Set("end", strconv.FormatInt(query.End.Add(time.Second-1).Unix(), 10))
+	if query.MinDuration > 0 {
+		q.Set("minDuration", query.MinDuration.String())
+	}
+	if query.MaxDuration > 0 {
+		q.Set("maxDuration", query.MaxDuration.String())
+	}
+	if query.Limit > 0 {
+		q.Set("limit", strconv.Itoa(query.Limit))
+	}
+	return q
+}
+
+// logfmtPair formats a search tag the way Tempo expects it (logfmt)
+func logfmtPair(key, value string) string {
+	if value == "" || strings.ContainsAny(value, " =\"") {
+		value = strconv.Quote(value)
+	}
+	return key + "=" + value
+}
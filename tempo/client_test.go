@@ -0,0 +1,188 @@
+package tempo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// Trace 0af7651916cd43dd8448eb211c80319c, as returned by Tempo: productpage calls reviews, which fails
+const tempoTrace = `{
+  "batches": [
+    {
+      "resource": {"attributes": [
+        {"key": "service.name", "value": {"stringValue": "productpage.bookinfo"}},
+        {"key": "k8s.pod.name", "value": {"stringValue": "productpage-v1-abc"}}
+      ]},
+      "instrumentationLibrarySpans": [{"spans": [
+        {"traceId": "CvdlGRbNQ92ESOshHIAxnA==", "spanId": "APBnqgupArc=", "name": "GET /productpage", "kind": "SPAN_KIND_SERVER",
+         "startTimeUnixNano": "1600000000000000000", "endTimeUnixNano": "1600000000050000000",
+         "attributes": [{"key": "http.status_code", "value": {"intValue": "200"}}]}
+      ]}]
+    },
+    {
+      "resource": {"attributes": [{"key": "service.name", "value": {"stringValue": "reviews.bookinfo"}}]},
+      "scopeSpans": [{"spans": [
+        {"traceId": "CvdlGRbNQ92ESOshHIAxnA==", "spanId": "ERERERERERE=", "parentSpanId": "APBnqgupArc=", "name": "GET /reviews", "kind": 2,
+         "startTimeUnixNano": "1600000000010000000", "endTimeUnixNano": "1600000000040000000",
+         "status": {"code": "STATUS_CODE_ERROR", "message": "boom"},
+         "attributes": [{"key": "retry", "value": {"boolValue": true}}, {"key": "tags", "value": {"arrayValue": {"values": [{"stringValue": "a"}, {"intValue": 1}]}}}],
+         "events": [{"timeUnixNano": "1600000000020000000", "name": "exception", "attributes": [{"key": "exception.message", "value": {"stringValue": "boom"}}]}]}
+      ]}]
+    }
+  ]
+}`
+
+func fakeTempo(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Accept"))
+		switch r.URL.Path {
+		case "/tempo/api/search":
+			assert.Equal(t, `service.name=reviews.bookinfo error=true`, r.URL.Query().Get("tags"))
+			_, _ = w.Write([]byte(`{"traces": [{"traceID": "0af7651916cd43dd8448eb211c80319c"}, {"traceID": "deleted"}]}`))
+		case "/tempo/api/traces/0af7651916cd43dd8448eb211c80319c":
+			_, _ = w.Write([]byte(tempoTrace))
+		case "/tempo/ready":
+			_, _ = w.Write([]byte("ready"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func fakeClient(server *httptest.Server) *Client {
+	u, _ := url.Parse(server.URL + "/tempo")
+	return &Client{httpClient: *server.Client(), baseURL: u}
+}
+
+func TestGetTraceDetailConvertsOTLP(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+	server := fakeTempo(t)
+	defer server.Close()
+
+	trace, err := fakeClient(server).GetTraceDetail("0af7651916cd43dd8448eb211c80319c")
+	assert.NoError(err)
+	assert.Equal(jaegerModels.TraceID("0af7651916cd43dd8448eb211c80319c"), trace.Data.TraceID)
+	assert.Equal("productpage.bookinfo", trace.Data.Processes["p1"].ServiceName)
+	assert.Equal([]jaegerModels.KeyValue{{Key: "k8s.pod.name", Type: jaegerModels.StringType, Value: "productpage-v1-abc"}}, trace.Data.Processes["p1"].Tags)
+	assert.Equal("reviews.bookinfo", trace.Data.Processes["p2"].ServiceName)
+	assert.Len(trace.Data.Spans, 2)
+
+	root := trace.Data.Spans[0]
+	assert.Equal(jaegerModels.SpanID("00f067aa0ba902b7"), root.SpanID)
+	assert.Equal(jaegerModels.ProcessID("p1"), root.ProcessID)
+	assert.Equal(uint64(1600000000000000), root.StartTime)
+	assert.Equal(uint64(50000), root.Duration)
+	assert.Empty(root.References)
+	assert.Equal([]jaegerModels.KeyValue{
+		{Key: "http.status_code", Type: jaegerModels.Int64Type, Value: int64(200)},
+		{Key: "span.kind", Type: jaegerModels.StringType, Value: "server"},
+	}, root.Tags)
+
+	child := trace.Data.Spans[1]
+	assert.Equal(jaegerModels.ProcessID("p2"), child.ProcessID)
+	assert.Equal([]jaegerModels.Reference{{RefType: jaegerModels.ChildOf, TraceID: root.TraceID, SpanID: root.SpanID}}, child.References)
+	assert.Equal(uint64(30000), child.Duration)
+	assert.Equal([]jaegerModels.KeyValue{
+		{Key: "retry", Type: jaegerModels.BoolType, Value: true},
+		{Key: "tags", Type: jaegerModels.StringType, Value: `["a",1]`},
+		{Key: "span.kind", Type: jaegerModels.StringType, Value: "server"},
+		{Key: "error", Type: jaegerModels.BoolType, Value: true},
+		{Key: "otel.status_description", Type: jaegerModels.StringType, Value: "boom"},
+	}, child.Tags)
+	assert.Equal([]jaegerModels.Log{{Timestamp: 1600000000020000, Fields: []jaegerModels.KeyValue{
+		{Key: "event", Type: jaegerModels.StringType, Value: "exception"},
+		{Key: "exception.message", Type: jaegerModels.StringType, Value: "boom"},
+	}}}, child.Logs)
+}
+
+func TestGetTraceDetailNotFound(t *testing.T) {
+	config.Set(config.NewConfig())
+	server := fakeTempo(t)
+	defer server.Close()
+
+	trace, err := fakeClient(server).GetTraceDetail("unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, trace)
+}
+
+func TestGetAppTracesSearchesThenFetches(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+	server := fakeTempo(t)
+	defer server.Close()
+
+	traces, err := fakeClient(server).GetAppTraces("bookinfo", "reviews", models.TracingQuery{Tags: map[string]string{"error": "true"}})
+	assert.NoError(err)
+	// the second trace, not found anymore, is skipped
+	assert.Len(traces.Data, 1)
+	assert.Equal(jaegerModels.TraceID("0af7651916cd43dd8448eb211c80319c"), traces.Data[0].TraceID)
+}
+
+func TestGetErrorTracesCountsSearchResults(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+	server := fakeTempo(t)
+	defer server.Close()
+
+	client := fakeClient(server)
+	count, err := client.GetErrorTraces("bookinfo", "reviews", time.Minute)
+	assert.NoError(err)
+	assert.Equal(2, count)
+
+	available, err := client.GetServiceStatus()
+	assert.NoError(err)
+	assert.True(available)
+}
+
+func TestSearchQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	q := searchQuery("reviews", models.TracingQuery{
+		Start:       time.Unix(1000, 0),
+		End:         time.Unix(1600, 0),
+		Tags:        map[string]string{"http.url": "/a b", "error": "true"},
+		MinDuration: 100 * time.Millisecond,
+		Limit:       20,
+	})
+	assert.Equal(`service.name=reviews error=true http.url="/a b"`, q.Get("tags"))
+	assert.Equal("1000", q.Get("start"))
+	assert.Equal("1600", q.Get("end"))
+	assert.Equal("100ms", q.Get("minDuration"))
+	assert.Equal("20", q.Get("limit"))
+	assert.Empty(q.Get("maxDuration"))
+}
+
+func TestSearchQueryFilters(t *testing.T) {
+	assert := assert.New(t)
+
+	q := searchQuery("reviews", models.TracingQuery{
+		Start:          time.Unix(1000, 0),
+		End:            time.Unix(1600, 200000000),
+		MaxDuration:    2 * time.Second,
+		HTTPStatusCode: "503",
+		Operation:      "GET /ratings",
+	})
+	// the operation is searched, so that the limit applies to its traces
+	assert.Equal(`service.name=reviews name="GET /ratings" http.status_code=503`, q.Get("tags"))
+	// the end is rounded up to the second
+	assert.Equal("1601", q.Get("end"))
+	assert.Equal("2s", q.Get("maxDuration"))
+}
+
+func TestConvertID(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("0af7651916cd43dd8448eb211c80319c", convertID("CvdlGRbNQ92ESOshHIAxnA==", 16))
+	assert.Equal("0af7651916cd43dd8448eb211c80319c", convertID("0AF7651916CD43DD8448EB211C80319C", 16))
+	assert.Equal("00f067aa0ba902b7", convertID("00f067aa0ba902b7", 8))
+}
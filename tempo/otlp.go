@@ -0,0 +1,232 @@
+package tempo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+)
+
+// OTLP JSON model, as returned by the Tempo trace-by-ID API. Tempo names the resource spans "batches",
+// newer versions "resourceSpans"; same for "instrumentationLibrarySpans", renamed "scopeSpans".
+type otlpTrace struct {
+	Batches       []otlpResourceSpans `json:"batches"`
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans                  []otlpScopeSpans `json:"scopeSpans"`
+	InstrumentationLibrarySpans []otlpScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+type otlpScopeSpans struct {
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId"`
+	Name              string         `json:"name"`
+	Kind              otlpEnum       `json:"kind"`
+	StartTimeUnixNano otlpUint64     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   otlpUint64     `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes"`
+	Events            []struct {
+		TimeUnixNano otlpUint64     `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes"`
+	} `json:"events"`
+	Status struct {
+		Code    otlpEnum `json:"code"`
+		Message string   `json:"message"`
+	} `json:"status"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string   `json:"stringValue"`
+	BoolValue   *bool     `json:"boolValue"`
+	IntValue    otlpInt64 `json:"intValue"`
+	DoubleValue *float64  `json:"doubleValue"`
+	BytesValue  *string   `json:"bytesValue"`
+	ArrayValue  *struct {
+		Values []otlpAnyValue `json:"values"`
+	} `json:"arrayValue"`
+}
+
+// otlpUint64 is a 64 bits integer, that the protobuf JSON mapping encodes as a string
+type otlpUint64 uint64
+
+func (u *otlpUint64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseUint(string(bytes.Trim(b, `"`)), 10, 64)
+	*u = otlpUint64(v)
+	return err
+}
+
+type otlpInt64 struct {
+	value *int64
+}
+
+func (i *otlpInt64) UnmarshalJSON(b []byte) error {
+	v, err := strconv.ParseInt(string(bytes.Trim(b, `"`)), 10, 64)
+	i.value = &v
+	return err
+}
+
+// otlpEnum holds an enum either by name ("SPAN_KIND_SERVER") or by number ("2")
+type otlpEnum string
+
+func (e *otlpEnum) UnmarshalJSON(b []byte) error {
+	*e = otlpEnum(bytes.Trim(b, `"`))
+	return nil
+}
+
+var spanKinds = map[otlpEnum]string{
+	"1": "internal", "SPAN_KIND_INTERNAL": "internal",
+	"2": "server", "SPAN_KIND_SERVER": "server",
+	"3": "client", "SPAN_KIND_CLIENT": "client",
+	"4": "producer", "SPAN_KIND_PRODUCER": "producer",
+	"5": "consumer", "SPAN_KIND_CONSUMER": "consumer",
+}
+
+func (e otlpEnum) isStatusError() bool {
+	return e == "2" || e == "STATUS_CODE_ERROR"
+}
+
+// convertTrace converts an OTLP trace to the Jaeger JSON model: each resource becomes a process, the
+// span kind and error status become the "span.kind" and "error" tags, as set by the Jaeger clients.
+func convertTrace(trace otlpTrace) *jaegerModels.Trace {
+	resources := append(trace.Batches, trace.ResourceSpans...)
+	converted := jaegerModels.Trace{
+		Spans:     []jaegerModels.Span{},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{},
+	}
+	for i, resource := range resources {
+		processID := jaegerModels.ProcessID(fmt.Sprintf("p%d", i+1))
+		process := jaegerModels.Process{Tags: []jaegerModels.KeyValue{}}
+		for _, attr := range resource.Resource.Attributes {
+			if attr.Key == "service.name" && attr.Value.StringValue != nil {
+				process.ServiceName = *attr.Value.StringValue
+			} else {
+				process.Tags = append(process.Tags, convertKeyValue(attr))
+			}
+		}
+		converted.Processes[processID] = process
+
+		for _, scope := range append(resource.ScopeSpans, resource.InstrumentationLibrarySpans...) {
+			for _, span := range scope.Spans {
+				s := convertSpan(span)
+				s.ProcessID = processID
+				converted.Spans = append(converted.Spans, s)
+			}
+		}
+	}
+	if len(converted.Spans) == 0 {
+		return nil
+	}
+	converted.TraceID = converted.Spans[0].TraceID
+	return &converted
+}
+
+func convertSpan(span otlpSpan) jaegerModels.Span {
+	traceID := jaegerModels.TraceID(convertID(span.TraceID, 16))
+	s := jaegerModels.Span{
+		TraceID:       traceID,
+		SpanID:        jaegerModels.SpanID(convertID(span.SpanID, 8)),
+		OperationName: span.Name,
+		References:    []jaegerModels.Reference{},
+		StartTime:     uint64(span.StartTimeUnixNano) / 1000,
+		Tags:          []jaegerModels.KeyValue{},
+		Logs:          []jaegerModels.Log{},
+	}
+	if span.EndTimeUnixNano > span.StartTimeUnixNano {
+		s.Duration = uint64(span.EndTimeUnixNano-span.StartTimeUnixNano) / 1000
+	}
+	if span.ParentSpanID != "" {
+		s.References = append(s.References, jaegerModels.Reference{
+			RefType: jaegerModels.ChildOf,
+			TraceID: traceID,
+			SpanID:  jaegerModels.SpanID(convertID(span.ParentSpanID, 8)),
+		})
+	}
+	for _, attr := range span.Attributes {
+		s.Tags = append(s.Tags, convertKeyValue(attr))
+	}
+	if kind, ok := spanKinds[span.Kind]; ok {
+		s.Tags = append(s.Tags, jaegerModels.KeyValue{Key: "span.kind", Type: jaegerModels.StringType, Value: kind})
+	}
+	if span.Status.Code.isStatusError() {
+		s.Tags = append(s.Tags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true})
+		if span.Status.Message != "" {
+			s.Tags = append(s.Tags, jaegerModels.KeyValue{Key: "otel.status_description", Type: jaegerModels.StringType, Value: span.Status.Message})
+		}
+	}
+	for _, event := range span.Events {
+		l := jaegerModels.Log{
+			Timestamp: uint64(event.TimeUnixNano) / 1000,
+			Fields:    []jaegerModels.KeyValue{{Key: "event", Type: jaegerModels.StringType, Value: event.Name}},
+		}
+		for _, attr := range event.Attributes {
+			l.Fields = append(l.Fields, convertKeyValue(attr))
+		}
+		s.Logs = append(s.Logs, l)
+	}
+	return s
+}
+
+// convertID converts a trace or span ID to the hex form used by Jaeger. The protobuf JSON mapping
+// encodes IDs in base64, but some OTLP producers use hex.
+func convertID(id string, size int) string {
+	if len(id) == 2*size {
+		if _, err := hex.DecodeString(id); err == nil {
+			return strings.ToLower(id)
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil {
+		return hex.EncodeToString(b)
+	}
+	return id
+}
+
+func convertKeyValue(kv otlpKeyValue) jaegerModels.KeyValue {
+	valueType, value := convertValue(kv.Value)
+	return jaegerModels.KeyValue{Key: kv.Key, Type: valueType, Value: value}
+}
+
+func convertValue(v otlpAnyValue) (jaegerModels.ValueType, interface{}) {
+	switch {
+	case v.StringValue != nil:
+		return jaegerModels.StringType, *v.StringValue
+	case v.BoolValue != nil:
+		return jaegerModels.BoolType, *v.BoolValue
+	case v.IntValue.value != nil:
+		return jaegerModels.Int64Type, *v.IntValue.value
+	case v.DoubleValue != nil:
+		return jaegerModels.Float64Type, *v.DoubleValue
+	case v.BytesValue != nil:
+		return jaegerModels.BinaryType, *v.BytesValue
+	case v.ArrayValue != nil:
+		// Jaeger has no array type, arrays are flattened into a JSON string
+		values := make([]interface{}, 0, len(v.ArrayValue.Values))
+		for _, item := range v.ArrayValue.Values {
+			_, value := convertValue(item)
+			values = append(values, value)
+		}
+		b, _ := json.Marshal(values)
+		return jaegerModels.StringType, string(b)
+	}
+	return jaegerModels.StringType, ""
+}